@@ -6,7 +6,11 @@ package apply
 import (
 	"context"
 	"fmt"
+	"io"
+	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -16,10 +20,16 @@ import (
 	"k8s.io/kubectl/pkg/util/i18n"
 	"sigs.k8s.io/cli-utils/cmd/flagutils"
 	"sigs.k8s.io/cli-utils/cmd/printers"
+	jsonprinter "sigs.k8s.io/cli-utils/cmd/printers/json"
+	tableprinter "sigs.k8s.io/cli-utils/cmd/printers/table"
 	"sigs.k8s.io/cli-utils/pkg/apply"
+	"sigs.k8s.io/cli-utils/pkg/apply/record"
+	"sigs.k8s.io/cli-utils/pkg/apply/retry"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
 	"sigs.k8s.io/cli-utils/pkg/manifestreader"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	printtable "sigs.k8s.io/cli-utils/pkg/print/table"
 	"sigs.k8s.io/cli-utils/pkg/provider"
 )
 
@@ -46,6 +56,15 @@ func GetApplyRunner(provider provider.Provider, loader manifestreader.ManifestLo
 
 	cmd.Flags().StringVar(&r.output, "output", printers.DefaultPrinter(),
 		fmt.Sprintf("Output format, must be one of %s", strings.Join(printers.SupportedPrinters(), ",")))
+	cmd.Flags().StringVar(&r.reportFile, flagutils.ReportFileFlag, "", flagutils.ReportFileHelp)
+	cmd.Flags().BoolVar(&r.keepOpen, flagutils.KeepOpenFlag, false, flagutils.KeepOpenHelp)
+	cmd.Flags().StringVar(&r.summaryFile, flagutils.SummaryFileFlag, "", flagutils.SummaryFileHelp)
+	cmd.Flags().StringVar(&r.recordFile, flagutils.RecordFlag, "", flagutils.RecordHelp)
+	cmd.Flags().StringVar(&r.columns, flagutils.ColumnsFlag, "", flagutils.ColumnsHelp)
+	cmd.Flags().StringVar(&r.sortBy, flagutils.SortByFlag, "", flagutils.SortByHelp)
+	cmd.Flags().BoolVar(&r.statusSummary, "status-summary", false,
+		"If true, print a summary of how long each resource took to become Current. "+
+			"Requires --reconcile-timeout to be set. Only supported by the events and json outputs.")
 	cmd.Flags().DurationVar(&r.period, "poll-period", 2*time.Second,
 		"Polling period for resource statuses.")
 	cmd.Flags().DurationVar(&r.reconcileTimeout, "reconcile-timeout", time.Duration(0),
@@ -59,6 +78,41 @@ func GetApplyRunner(provider provider.Provider, loader manifestreader.ManifestLo
 	cmd.Flags().StringVar(&r.inventoryPolicy, flagutils.InventoryPolicyFlag, flagutils.InventoryPolicyStrict,
 		"It determines the behavior when the resources don't belong to current inventory. Available options "+
 			fmt.Sprintf("%q and %q.", flagutils.InventoryPolicyStrict, flagutils.InventoryPolicyAdopt))
+	cmd.Flags().IntVar(&r.retryAttempts, "retry-attempts", 1,
+		"Maximum number of attempts for applying or pruning a resource when it fails with a "+
+			"transient error, like a conflict or throttling. Resources are only attempted once by default.")
+	cmd.Flags().DurationVar(&r.retryBackoff, "retry-backoff", retry.DefaultInitialBackoff,
+		"How long to wait before the first retry. The wait is doubled for every following retry.")
+	cmd.Flags().BoolVar(&r.recreate, "recreate-on-immutable-change", false,
+		"If true, delete and create a resource again if applying it fails because an immutable field "+
+			"has changed. Without the flag, only resources with the "+
+			fmt.Sprintf("%s=%s annotation are recreated.", common.OnImmutableChangeAnnotation, common.OnImmutableChangeRecreate))
+	cmd.Flags().StringVar(&r.recreatePropagationPolicy, "recreate-propagation-policy",
+		"Background", "Propagation policy for deleting resources that are recreated")
+	cmd.Flags().DurationVar(&r.recreateTimeout, "recreate-timeout", time.Minute,
+		"Timeout threshold for waiting for a recreated resource to be deleted before creating it again")
+	cmd.Flags().DurationVar(&r.hookTimeout, "hook-timeout", 5*time.Minute,
+		"Timeout threshold for waiting for pre-apply and post-apply hooks to complete")
+	cmd.Flags().BoolVar(&r.atomic, "atomic", false,
+		"If true, roll back all changes made by the apply if any resource fails to apply, "+
+			"or doesn't reconcile before --reconcile-timeout. Errors while pruning don't cause "+
+			"a rollback.")
+	cmd.Flags().BoolVar(&r.checkPermissions, "check-permissions", false,
+		"If true, check that all the required permissions are granted before making any changes, "+
+			"and fail with a list of the missing permissions if they are not. The check uses "+
+			"SelfSubjectAccessReviews, which must be allowed in the cluster.")
+	cmd.Flags().StringVar(&r.stagesFile, flagutils.StagesFileFlag, "", flagutils.StagesFileHelp)
+	cmd.Flags().StringVarP(&r.filter.Selector, flagutils.SelectorFlag, "l", "", flagutils.SelectorHelp)
+	cmd.Flags().StringSliceVar(&r.filter.Kinds, flagutils.KindFlag, nil, flagutils.KindHelp)
+	cmd.Flags().StringSliceVar(&r.filter.Names, flagutils.NameFlag, nil, flagutils.NameHelp)
+	cmd.Flags().BoolVar(&r.watch, "watch", false,
+		"If true, keep running and apply the package again whenever the files in the package "+
+			"directory change, and periodically to correct drift.")
+	cmd.Flags().DurationVar(&r.watchPeriod, "watch-period", 2*time.Second,
+		"How often to check the package directory for changes when using --watch.")
+	cmd.Flags().DurationVar(&r.resyncPeriod, "resync-period", 5*time.Minute,
+		"How often to apply the package again when using --watch, even if it hasn't changed. "+
+			"Set to 0 to only apply when the package changes.")
 
 	r.Command = cmd
 	return r
@@ -66,8 +120,11 @@ func GetApplyRunner(provider provider.Provider, loader manifestreader.ManifestLo
 
 func ApplyCommand(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
 	provider := provider.NewProvider(f)
-	loader := manifestreader.NewManifestLoader(f)
-	return GetApplyRunner(provider, loader, ioStreams).Command
+	loaderOptions := &manifestreader.LoaderOptions{}
+	loader := manifestreader.NewManifestLoaderWithOptions(f, loaderOptions)
+	cmd := GetApplyRunner(provider, loader, ioStreams).Command
+	cmd.Flags().BoolVar(&loaderOptions.Validate, flagutils.ValidateFlag, true, flagutils.ValidateHelp)
+	return cmd
 }
 
 type ApplyRunner struct {
@@ -78,18 +135,50 @@ type ApplyRunner struct {
 	provider   provider.Provider
 	loader     manifestreader.ManifestLoader
 
-	serverSideOptions      common.ServerSideOptions
-	output                 string
-	period                 time.Duration
-	reconcileTimeout       time.Duration
-	noPrune                bool
-	prunePropagationPolicy string
-	pruneTimeout           time.Duration
-	inventoryPolicy        string
+	serverSideOptions         common.ServerSideOptions
+	output                    string
+	statusSummary             bool
+	reportFile                string
+	keepOpen                  bool
+	summaryFile               string
+	recordFile                string
+	columns                   string
+	sortBy                    string
+	period                    time.Duration
+	reconcileTimeout          time.Duration
+	noPrune                   bool
+	prunePropagationPolicy    string
+	pruneTimeout              time.Duration
+	inventoryPolicy           string
+	retryAttempts             int
+	retryBackoff              time.Duration
+	recreate                  bool
+	recreatePropagationPolicy string
+	recreateTimeout           time.Duration
+	hookTimeout               time.Duration
+	atomic                    bool
+	checkPermissions          bool
+	stagesFile                string
+	filter                    object.Filter
+	watch                     bool
+	watchPeriod               time.Duration
+	resyncPeriod              time.Duration
 }
 
 func (r *ApplyRunner) RunE(cmd *cobra.Command, args []string) error {
-	prunePropPolicy, err := convertPropagationPolicy(r.prunePropagationPolicy)
+	if r.statusSummary && !printers.SupportsStatusSummary(r.output) {
+		return fmt.Errorf("--status-summary is not supported by the %q printer", r.output)
+	}
+	// Status events are only emitted while waiting for the resources to
+	// reconcile, so there is nothing to summarize without a timeout.
+	if r.statusSummary && r.reconcileTimeout == 0 {
+		return fmt.Errorf("--status-summary requires --reconcile-timeout to be set")
+	}
+	prunePropPolicy, err := convertPropagationPolicy("prune-propagation-policy", r.prunePropagationPolicy)
+	if err != nil {
+		return err
+	}
+	recreatePropPolicy, err := convertPropagationPolicy("recreate-propagation-policy", r.recreatePropagationPolicy)
 	if err != nil {
 		return err
 	}
@@ -97,6 +186,20 @@ func (r *ApplyRunner) RunE(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	if err := r.filter.Validate(); err != nil {
+		return err
+	}
+	var columns []printtable.ColumnDefinition
+	if r.columns != "" {
+		columns, err = tableprinter.ParseColumns(r.columns)
+		if err != nil {
+			return err
+		}
+	}
+	sortBy, err := tableprinter.ParseSortBy(r.sortBy, columns)
+	if err != nil {
+		return err
+	}
 
 	// Only emit status events if we are waiting for status.
 	//TODO: This is not the right way to do this. There are situations where
@@ -107,39 +210,40 @@ func (r *ApplyRunner) RunE(cmd *cobra.Command, args []string) error {
 		emitStatusEvents = true
 	}
 
+	if r.watch {
+		if len(args) == 0 {
+			return fmt.Errorf("--watch requires a package directory, it can't be used with stdin")
+		}
+		if r.recordFile != "" {
+			return fmt.Errorf("--watch can't be used with --%s", flagutils.RecordFlag)
+		}
+		if r.watchPeriod <= 0 {
+			return fmt.Errorf("--watch-period must be greater than zero")
+		}
+		if r.resyncPeriod < 0 {
+			return fmt.Errorf("--resync-period can't be negative")
+		}
+	}
+
 	// TODO: Fix DemandOneDirectory to no longer return FileNameFlags
 	// since we are no longer using them.
 	_, err = common.DemandOneDirectory(args)
 	if err != nil {
 		return err
 	}
-	reader, err := r.loader.ManifestReader(cmd.InOrStdin(), args)
-	if err != nil {
-		return err
-	}
-	objs, err := reader.Read()
-	if err != nil {
-		return err
-	}
 
-	inv, objs, err := r.loader.InventoryInfo(objs)
+	// Create the record file before starting, so we don't start making
+	// changes to the cluster if it can't be created.
+	recorder, err := record.CreateFile(r.recordFile, common.DryRunNone)
 	if err != nil {
 		return err
 	}
+	defer recorder.Close()
 
-	if r.PreProcess != nil {
-		inventoryPolicy, err = r.PreProcess(inv, common.DryRunNone)
-		if err != nil {
-			return err
-		}
-	}
-
-	// Run the applier. It will return a channel where we can receive updates
-	// to keep track of progress and any issues.
 	if err := r.Applier.Initialize(); err != nil {
 		return err
 	}
-	ch := r.Applier.Run(context.Background(), inv, objs, apply.Options{
+	options := apply.Options{
 		ServerSideOptions: r.serverSideOptions,
 		PollInterval:      r.period,
 		ReconcileTimeout:  r.reconcileTimeout,
@@ -151,17 +255,128 @@ func (r *ApplyRunner) RunE(cmd *cobra.Command, args []string) error {
 		PrunePropagationPolicy: prunePropPolicy,
 		PruneTimeout:           r.pruneTimeout,
 		InventoryPolicy:        inventoryPolicy,
-	})
+		RetryPolicy: retry.Policy{
+			MaxAttempts:    r.retryAttempts,
+			InitialBackoff: r.retryBackoff,
+		},
+		RecreateOptions: common.RecreateOptions{
+			Recreate:          r.recreate,
+			PropagationPolicy: recreatePropPolicy,
+			Timeout:           r.recreateTimeout,
+		},
+		HookTimeout:      r.hookTimeout,
+		Atomic:           r.atomic,
+		Filter:           r.filter,
+		CheckPermissions: r.checkPermissions,
+	}
+	printerOptions := printers.Options{
+		StatusSummary: r.statusSummary,
+		ReportFile:    r.reportFile,
+		KeepOpen:      r.keepOpen,
+		Columns:       columns,
+		SortBy:        sortBy,
+	}
+
+	if !r.watch {
+		_, err = r.applyOnce(context.Background(), cmd.InOrStdin(), args, recorder, options, printerOptions)
+		return err
+	}
+
+	// The summary for each iteration is printed to stderr with the
+	// json output, so stdout only contains the json events.
+	out := r.ioStreams.Out
+	if r.output == printers.JSONPrinter {
+		out = r.ioStreams.ErrOut
+	}
+	w := &watcher{
+		dir:          args[0],
+		watchPeriod:  r.watchPeriod,
+		resyncPeriod: r.resyncPeriod,
+		out:          out,
+		fingerprint:  manifestreader.Fingerprint,
+		apply: func(ctx context.Context) (jsonprinter.SummaryCounts, error) {
+			return r.applyOnce(ctx, cmd.InOrStdin(), args, nil, options, printerOptions)
+		},
+		jitter: jitter,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		select {
+		case <-signals:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return w.run(ctx)
+}
+
+// applyOnce reads the package and applies it, printing the events
+// from the applier as they arrive. It returns the counts for the
+// operations performed.
+func (r *ApplyRunner) applyOnce(ctx context.Context, in io.Reader, args []string, recorder *record.FileWriter,
+	options apply.Options, printerOptions printers.Options) (jsonprinter.SummaryCounts, error) {
+	reader, err := r.loader.ManifestReader(in, args)
+	if err != nil {
+		return jsonprinter.SummaryCounts{}, err
+	}
+	objs, err := reader.Read()
+	if err != nil {
+		return jsonprinter.SummaryCounts{}, err
+	}
+
+	inv, objs, err := r.loader.InventoryInfo(objs)
+	if err != nil {
+		return jsonprinter.SummaryCounts{}, err
+	}
+
+	// The stages are read for every apply, so changes to the stages
+	// file are picked up with --watch.
+	options.Stages, err = flagutils.LoadStages(r.stagesFile, args)
+	if err != nil {
+		return jsonprinter.SummaryCounts{}, err
+	}
+
+	if r.PreProcess != nil {
+		options.InventoryPolicy, err = r.PreProcess(inv, common.DryRunNone)
+		if err != nil {
+			return jsonprinter.SummaryCounts{}, err
+		}
+	}
+
+	// Run the applier. It will return a channel where we can receive updates
+	// to keep track of progress and any issues.
+	ch := r.Applier.Run(ctx, inv, objs, options)
+
+	ch = recorder.Tee(ch)
+
+	summary := jsonprinter.NewSummaryRecorder("apply", inv)
+	ch = summary.Tee(ch)
 
 	// The printer will print updates from the channel. It will block
 	// until the channel is closed.
-	printer := printers.GetPrinter(r.output, r.ioStreams)
-	return printer.Print(ch, common.DryRunNone)
+	printer := printers.GetPrinterWithOptions(r.output, r.ioStreams, printerOptions)
+	err = printer.Print(ch, common.DryRunNone)
+	counts := summary.Summary(err).Counts
+	if r.summaryFile != "" {
+		if writeErr := summary.WriteFile(r.summaryFile, err); writeErr != nil && err == nil {
+			return counts, writeErr
+		}
+	}
+	if recordErr := recorder.Err(); recordErr != nil && err == nil {
+		return counts, recordErr
+	}
+	return counts, err
 }
 
 // convertPropagationPolicy converts a propagationPolicy described as a
 // string to a DeletionPropagation type that is passed into the Applier.
-func convertPropagationPolicy(propagationPolicy string) (metav1.DeletionPropagation, error) {
+// The flag is the name of the flag the value was set with, so the error
+// points to it.
+func convertPropagationPolicy(flag, propagationPolicy string) (metav1.DeletionPropagation, error) {
 	switch propagationPolicy {
 	case string(metav1.DeletePropagationForeground):
 		return metav1.DeletePropagationForeground, nil
@@ -171,6 +386,6 @@ func convertPropagationPolicy(propagationPolicy string) (metav1.DeletionPropagat
 		return metav1.DeletePropagationOrphan, nil
 	default:
 		return metav1.DeletePropagationBackground, fmt.Errorf(
-			"prune propagation policy must be one of Background, Foreground, Orphan")
+			"--%s must be one of Background, Foreground, Orphan", flag)
 	}
 }
@@ -0,0 +1,83 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package apply
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConvertPropagationPolicy(t *testing.T) {
+	testCases := map[string]struct {
+		flag           string
+		value          string
+		expectedPolicy metav1.DeletionPropagation
+		expectedErrMsg string
+	}{
+		"foreground": {
+			flag:           "prune-propagation-policy",
+			value:          "Foreground",
+			expectedPolicy: metav1.DeletePropagationForeground,
+		},
+		"orphan": {
+			flag:           "recreate-propagation-policy",
+			value:          "Orphan",
+			expectedPolicy: metav1.DeletePropagationOrphan,
+		},
+		"invalid value names the flag": {
+			flag:           "recreate-propagation-policy",
+			value:          "Later",
+			expectedErrMsg: "--recreate-propagation-policy must be one of Background, Foreground, Orphan",
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			policy, err := convertPropagationPolicy(tc.flag, tc.value)
+			if tc.expectedErrMsg != "" {
+				if assert.Error(t, err) {
+					assert.Equal(t, tc.expectedErrMsg, err.Error())
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedPolicy, policy)
+		})
+	}
+}
+
+func TestApplyRunner_StatusSummary(t *testing.T) {
+	testCases := map[string]struct {
+		output           string
+		reconcileTimeout time.Duration
+		expectedErrMsg   string
+	}{
+		"unsupported printer": {
+			output:           "table",
+			reconcileTimeout: time.Minute,
+			expectedErrMsg:   `--status-summary is not supported by the "table" printer`,
+		},
+		"no reconcile timeout": {
+			output:         "events",
+			expectedErrMsg: "--status-summary requires --reconcile-timeout to be set",
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			r := &ApplyRunner{
+				output:           tc.output,
+				statusSummary:    true,
+				reconcileTimeout: tc.reconcileTimeout,
+			}
+			err := r.RunE(nil, nil)
+			if assert.Error(t, err) {
+				assert.Equal(t, tc.expectedErrMsg, err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package apply
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	jsonprinter "sigs.k8s.io/cli-utils/cmd/printers/json"
+)
+
+const (
+	// initialBackoff is how long to wait before retrying after the
+	// first failed iteration. It is doubled for every consecutive
+	// failure, up to the resync period.
+	initialBackoff = 5 * time.Second
+	// maxBackoff is the upper bound for the backoff if periodic
+	// resync is disabled.
+	maxBackoff = 5 * time.Minute
+	// jitterFactor is the maximum fraction added to the resync period
+	// and the backoff, so many watchers don't apply at the same time.
+	jitterFactor = 0.1
+)
+
+// Reasons for starting an iteration, used in the summary.
+const (
+	reasonInitial      = "initial"
+	reasonFilesChanged = "files changed"
+	reasonResync       = "resync"
+	reasonRetry        = "retry"
+)
+
+// watcher applies the package in a loop. The package is applied
+// again when the files in the package directory change, periodically
+// to correct any drift, and with backoff after a failed iteration.
+type watcher struct {
+	// dir is the package directory polled for changes.
+	dir string
+	// watchPeriod is how often the package directory is checked
+	// for changes.
+	watchPeriod time.Duration
+	// resyncPeriod is how often the package is applied again if it
+	// hasn't changed. Periodic resync is disabled if it is zero.
+	resyncPeriod time.Duration
+	// out is where the summary for each iteration is printed.
+	out io.Writer
+
+	// fingerprint returns a value that changes whenever the files in
+	// the package directory change.
+	fingerprint func(dir string) (string, error)
+	// apply runs a single iteration and returns the counts from it.
+	apply func(ctx context.Context) (jsonprinter.SummaryCounts, error)
+	// jitter adds a random amount to a duration.
+	jitter func(d time.Duration) time.Duration
+}
+
+// run applies the package until the context is cancelled. It only
+// returns an error if the package directory can't be read before the
+// first iteration.
+func (w *watcher) run(ctx context.Context) error {
+	fingerprint, err := w.fingerprint(w.dir)
+	if err != nil {
+		return err
+	}
+
+	reason := reasonInitial
+	var failures, total, totalFailed int
+	for iteration := 1; ; iteration++ {
+		start := time.Now()
+		counts, err := w.apply(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		total++
+
+		var delay time.Duration
+		var next string
+		if err != nil {
+			failures++
+			totalFailed++
+			delay = w.backoff(failures)
+			next = reasonRetry
+			fmt.Fprintf(w.out, "Iteration %d (%s) failed after %s: %v. Retrying in %s. [%d iterations, %d failed]\n",
+				iteration, reason, since(start), err, delay.Round(time.Millisecond), total, totalFailed)
+		} else {
+			failures = 0
+			next = reasonResync
+			if w.resyncPeriod > 0 {
+				delay = w.jitter(w.resyncPeriod)
+			}
+			fmt.Fprintf(w.out, "Iteration %d (%s) succeeded in %s: %s. %s [%d iterations, %d failed]\n",
+				iteration, reason, since(start), formatCounts(counts), nextResync(delay), total, totalFailed)
+		}
+
+		reason, fingerprint, err = w.waitForNext(ctx, delay, next, fingerprint)
+		if err != nil {
+			return nil
+		}
+	}
+}
+
+// waitForNext blocks until the next iteration should start. This is
+// either when the files in the package directory change, or when the
+// delay has passed. It returns the reason for the next iteration along
+// with the latest fingerprint, or the context error if the context is
+// cancelled. A delay of zero means the next iteration only starts if
+// the files change.
+func (w *watcher) waitForNext(ctx context.Context, delay time.Duration, next,
+	fingerprint string) (string, string, error) {
+	var timeout <-chan time.Time
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	ticker := time.NewTicker(w.watchPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return "", fingerprint, ctx.Err()
+		case <-timeout:
+			return next, fingerprint, nil
+		case <-ticker.C:
+			current, err := w.fingerprint(w.dir)
+			if err != nil {
+				// The directory might be in the middle of being
+				// updated, so just check again on the next tick.
+				fmt.Fprintf(w.out, "error checking %s for changes: %v\n", w.dir, err)
+				continue
+			}
+			if current != fingerprint {
+				return reasonFilesChanged, current, nil
+			}
+		}
+	}
+}
+
+// backoff returns how long to wait after the given number of
+// consecutive failures.
+func (w *watcher) backoff(failures int) time.Duration {
+	max := maxBackoff
+	if w.resyncPeriod > 0 {
+		max = w.resyncPeriod
+	}
+	d := initialBackoff
+	for i := 1; i < failures && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	return w.jitter(d)
+}
+
+// jitter adds up to jitterFactor of the duration to the duration.
+func jitter(d time.Duration) time.Duration {
+	return wait.Jitter(d, jitterFactor)
+}
+
+// formatCounts returns a single line with the counts from an iteration.
+func formatCounts(c jsonprinter.SummaryCounts) string {
+	return fmt.Sprintf("%d created, %d configured, %d unchanged, %d serverside applied, %d recreated, %d failed, %d pruned",
+		c.Apply.Created, c.Apply.Configured, c.Apply.Unchanged, c.Apply.ServersideApplied, c.Apply.Recreated,
+		c.Apply.Failed+c.Prune.Failed, c.Prune.Pruned)
+}
+
+func nextResync(delay time.Duration) string {
+	if delay == 0 {
+		return "Waiting for changes."
+	}
+	return fmt.Sprintf("Next resync in %s.", delay.Round(time.Millisecond))
+}
+
+func since(start time.Time) time.Duration {
+	return time.Since(start).Round(time.Millisecond)
+}
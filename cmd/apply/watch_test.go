@@ -0,0 +1,182 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package apply
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	jsonprinter "sigs.k8s.io/cli-utils/cmd/printers/json"
+)
+
+// fakePackage returns a fingerprint that changes when the given
+// iterations have finished.
+type fakePackage struct {
+	mu         sync.Mutex
+	iterations int
+	changeAt   map[int]bool
+	version    int
+}
+
+func (f *fakePackage) fingerprint(string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return fmt.Sprintf("v%d", f.version), nil
+}
+
+func (f *fakePackage) finished() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.iterations++
+	if f.changeAt[f.iterations] {
+		f.version++
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	counts := jsonprinter.SummaryCounts{
+		Apply: jsonprinter.ApplyCounts{Created: 1, Unchanged: 2},
+		Prune: jsonprinter.PruneCounts{Pruned: 1},
+	}
+
+	testCases := map[string]struct {
+		results      []error
+		resyncPeriod time.Duration
+		changeAt     map[int]bool
+		expected     []string
+	}{
+		"resync after success": {
+			results:      []error{nil, nil},
+			resyncPeriod: time.Minute,
+			expected: []string{
+				"Iteration 1 (initial) succeeded",
+				"Iteration 2 (resync) succeeded",
+			},
+		},
+		"retry after failure": {
+			results:      []error{fmt.Errorf("boom"), fmt.Errorf("boom"), nil},
+			resyncPeriod: time.Minute,
+			expected: []string{
+				"Iteration 1 (initial) failed",
+				"Iteration 2 (retry) failed",
+				"Iteration 3 (retry) succeeded",
+			},
+		},
+		"apply when files change": {
+			results:  []error{nil, nil},
+			changeAt: map[int]bool{1: true, 2: true},
+			expected: []string{
+				"Iteration 1 (initial) succeeded",
+				"Iteration 2 (files changed) succeeded",
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			pkg := &fakePackage{changeAt: tc.changeAt}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			iteration := 0
+			out := &bytes.Buffer{}
+			w := &watcher{
+				dir:          "pkg",
+				watchPeriod:  time.Millisecond,
+				resyncPeriod: tc.resyncPeriod,
+				out:          out,
+				fingerprint:  pkg.fingerprint,
+				apply: func(context.Context) (jsonprinter.SummaryCounts, error) {
+					// Stop the watcher once all the results have been
+					// returned.
+					if iteration == len(tc.results) {
+						cancel()
+						return counts, ctx.Err()
+					}
+					err := tc.results[iteration]
+					iteration++
+					pkg.finished()
+					return counts, err
+				},
+				jitter: func(time.Duration) time.Duration {
+					return 10 * time.Millisecond
+				},
+			}
+
+			done := make(chan error)
+			go func() {
+				done <- w.run(ctx)
+			}()
+			select {
+			case err := <-done:
+				assert.NoError(t, err)
+			case <-time.After(10 * time.Second):
+				t.Fatalf("watcher didn't stop")
+			}
+
+			lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+			if !assert.Len(t, lines, len(tc.expected)) {
+				return
+			}
+			for i, line := range lines {
+				assert.True(t, strings.HasPrefix(line, tc.expected[i]),
+					"expected %q to start with %q", line, tc.expected[i])
+			}
+		})
+	}
+}
+
+func TestWatcherBackoff(t *testing.T) {
+	testCases := map[string]struct {
+		resyncPeriod time.Duration
+		failures     int
+		expected     time.Duration
+	}{
+		"first failure": {
+			resyncPeriod: 5 * time.Minute,
+			failures:     1,
+			expected:     5 * time.Second,
+		},
+		"doubles for each failure": {
+			resyncPeriod: 5 * time.Minute,
+			failures:     3,
+			expected:     20 * time.Second,
+		},
+		"limited by resync period": {
+			resyncPeriod: 30 * time.Second,
+			failures:     10,
+			expected:     30 * time.Second,
+		},
+		"limited by max backoff without resync": {
+			resyncPeriod: 0,
+			failures:     100,
+			expected:     maxBackoff,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			w := &watcher{
+				resyncPeriod: tc.resyncPeriod,
+				jitter:       func(d time.Duration) time.Duration { return d },
+			}
+			assert.Equal(t, tc.expected, w.backoff(tc.failures))
+		})
+	}
+}
+
+func TestFormatCounts(t *testing.T) {
+	counts := jsonprinter.SummaryCounts{
+		Apply: jsonprinter.ApplyCounts{Created: 1, Configured: 2, Unchanged: 3, Failed: 1},
+		Prune: jsonprinter.PruneCounts{Pruned: 4, Failed: 1},
+	}
+	assert.Equal(t, "1 created, 2 configured, 3 unchanged, 0 serverside applied, 0 recreated, 2 failed, 4 pruned",
+		formatCounts(counts))
+}
@@ -13,7 +13,9 @@ import (
 	"k8s.io/kubectl/pkg/util/i18n"
 	"sigs.k8s.io/cli-utils/cmd/flagutils"
 	"sigs.k8s.io/cli-utils/cmd/printers"
+	jsonprinter "sigs.k8s.io/cli-utils/cmd/printers/json"
 	"sigs.k8s.io/cli-utils/pkg/apply"
+	"sigs.k8s.io/cli-utils/pkg/apply/record"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
 	"sigs.k8s.io/cli-utils/pkg/manifestreader"
@@ -37,6 +39,10 @@ func GetDestroyRunner(provider provider.Provider, loader manifestreader.Manifest
 
 	cmd.Flags().StringVar(&r.output, "output", printers.DefaultPrinter(),
 		fmt.Sprintf("Output format, must be one of %s", strings.Join(printers.SupportedPrinters(), ",")))
+	cmd.Flags().StringVar(&r.reportFile, flagutils.ReportFileFlag, "", flagutils.ReportFileHelp)
+	cmd.Flags().BoolVar(&r.keepOpen, flagutils.KeepOpenFlag, false, flagutils.KeepOpenHelp)
+	cmd.Flags().StringVar(&r.summaryFile, flagutils.SummaryFileFlag, "", flagutils.SummaryFileHelp)
+	cmd.Flags().StringVar(&r.recordFile, flagutils.RecordFlag, "", flagutils.RecordHelp)
 	cmd.Flags().StringVar(&r.inventoryPolicy, flagutils.InventoryPolicyFlag, flagutils.InventoryPolicyStrict,
 		"It determines the behavior when the resources don't belong to current inventory. Available options "+
 			fmt.Sprintf("%q and %q.", flagutils.InventoryPolicyStrict, flagutils.InventoryPolicyAdopt))
@@ -62,6 +68,10 @@ type DestroyRunner struct {
 	loader     manifestreader.ManifestLoader
 
 	output          string
+	reportFile      string
+	keepOpen        bool
+	summaryFile     string
+	recordFile      string
 	inventoryPolicy string
 }
 
@@ -92,6 +102,14 @@ func (r *DestroyRunner) RunE(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Create the record file before starting, so we don't start making
+	// changes to the cluster if it can't be created.
+	recorder, err := record.CreateFile(r.recordFile, r.Destroyer.DryRunStrategy)
+	if err != nil {
+		return err
+	}
+	defer recorder.Close()
+
 	// Run the destroyer. It will return a channel where we can receive updates
 	// to keep track of progress and any issues.
 	err = r.Destroyer.Initialize()
@@ -103,8 +121,28 @@ func (r *DestroyRunner) RunE(cmd *cobra.Command, args []string) error {
 	}
 	ch := r.Destroyer.Run(inv, option)
 
+	ch = recorder.Tee(ch)
+
+	var summary *jsonprinter.SummaryRecorder
+	if r.summaryFile != "" {
+		summary = jsonprinter.NewSummaryRecorder("destroy", inv)
+		ch = summary.Tee(ch)
+	}
+
 	// The printer will print updates from the channel. It will block
 	// until the channel is closed.
-	printer := printers.GetPrinter(r.output, r.ioStreams)
-	return printer.Print(ch, r.Destroyer.DryRunStrategy)
+	printer := printers.GetPrinterWithOptions(r.output, r.ioStreams, printers.Options{
+		ReportFile: r.reportFile,
+		KeepOpen:   r.keepOpen,
+	})
+	err = printer.Print(ch, r.Destroyer.DryRunStrategy)
+	if summary != nil {
+		if writeErr := summary.WriteFile(r.summaryFile, err); writeErr != nil && err == nil {
+			return writeErr
+		}
+	}
+	if recordErr := recorder.Err(); recordErr != nil && err == nil {
+		return recordErr
+	}
+	return err
 }
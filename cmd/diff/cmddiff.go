@@ -4,6 +4,9 @@
 package diff
 
 import (
+	"bytes"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 
@@ -14,38 +17,148 @@ import (
 	"k8s.io/kubectl/pkg/cmd/diff"
 	"k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/util/i18n"
+	"sigs.k8s.io/cli-utils/cmd/flagutils"
 	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/manifestreader"
+	"sigs.k8s.io/cli-utils/pkg/provider"
 )
 
 const tmpDirPrefix = "diff-cmd"
 
+var diffLong = i18n.T(`Diff local config against cluster applied version.
+
+Changes to the fields listed in the .diffignore file in the package
+directory, or in the cli-utils.sigs.k8s.io/diff-ignore annotation on
+an object, are left out of the diff.
+
+The exit status is 0 if there are no differences, 1 if there are
+differences and greater than 1 if an error happened.`)
+
 // NewCmdDiff returns cobra command to implement client-side diff of package
 // directory. For each local config file, get the resource in the cluster
 // and diff the local config resource against the resource in the cluster.
 func NewCmdDiff(f util.Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
 	options := diff.NewDiffOptions(ioStreams)
+	var inventoryPolicy string
+	var output string
+	var ignoreFile string
 	cmd := &cobra.Command{
 		Use:                   "diff (DIRECTORY | STDIN)",
 		DisableFlagsInUseLine: true,
 		Short:                 i18n.T("Diff local config against cluster applied version"),
+		Long:                  diffLong,
 		Args:                  cobra.MaximumNArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
-			cleanupFunc, err := Initialize(options, f, args)
+		RunE: func(cmd *cobra.Command, args []string) error {
+			policy, err := flagutils.ConvertInventoryPolicy(inventoryPolicy)
+			if err != nil {
+				return diffError(err)
+			}
+			if output != "" && output != JSONOutput && output != YAMLOutput {
+				return diffError(fmt.Errorf("unknown output format %q, must be one of %s or %s",
+					output, JSONOutput, YAMLOutput))
+			}
+			// Stdin can only be read once, but is needed both for the
+			// diff and for finding the inventory.
+			var input []byte
+			if len(args) == 0 {
+				input, err = ioutil.ReadAll(cmd.InOrStdin())
+				if err != nil {
+					return diffError(err)
+				}
+			}
+			cleanupFunc, err := initialize(options, f, args, bytes.NewReader(input))
 			defer cleanupFunc()
-			util.CheckErr(err)
-			util.CheckErr(options.Run())
+			if err == nil {
+				err = runDiff(f, args, bytes.NewReader(input), policy, ignoreFile, options, output)
+			}
+			return diffError(err)
 		},
 	}
 
+	cmd.Flags().BoolVar(&options.ServerSideApply, "server-side", false,
+		"If true, compute the diff with a server-side apply dry-run.")
+	cmd.Flags().BoolVar(&options.ForceConflicts, "force-conflicts", false,
+		"If true, show the result of taking ownership of fields with field manager "+
+			"conflicts. Only used with --server-side.")
+	cmd.Flags().StringVar(&options.FieldManager, "field-manager", common.DefaultFieldManager,
+		"The client owner of the fields being applied on the server-side.")
+	cmd.Flags().StringVar(&inventoryPolicy, flagutils.InventoryPolicyFlag, flagutils.InventoryPolicyStrict,
+		"It determines the behavior when the resources don't belong to current inventory. Available options "+
+			fmt.Sprintf("%q and %q.", flagutils.InventoryPolicyStrict, flagutils.InventoryPolicyAdopt))
+	cmd.Flags().StringVarP(&output, "output", "o", "",
+		fmt.Sprintf("If set, print the changed fields for each object as %s or %s "+
+			"instead of running the diff program.", JSONOutput, YAMLOutput))
+	cmd.Flags().StringVar(&ignoreFile, flagutils.IgnoreFileFlag, "", flagutils.IgnoreFileHelp)
+
 	return cmd
 }
 
-// Initialize fills in the DiffOptions in preparation for DiffOptions.Run().
+// runDiff computes the inventory plan, reads the ignore rules and runs
+// the diff, printing it either with the diff program or in the given
+// structured format.
+func runDiff(f util.Factory, args []string, in io.Reader, policy inventory.InventoryPolicy,
+	ignoreFile string, o *diff.DiffOptions, output string) error {
+	rules, err := flagutils.LoadIgnoreRules(ignoreFile, args)
+	if err != nil {
+		return err
+	}
+	plan, err := planFromInput(f, args, in, policy)
+	if err != nil {
+		return err
+	}
+	opts := Options{
+		Plan:        plan,
+		IgnoreRules: rules,
+	}
+	if output == "" {
+		return RunWithOptions(o, opts)
+	}
+	return RunStructured(o, opts, output)
+}
+
+// planFromInput reads the inventory and the objects from the input, and
+// computes the inventory plan. A package without an inventory template
+// has no plan, since nothing would be pruned or adopted.
+func planFromInput(f util.Factory, args []string, in io.Reader,
+	policy inventory.InventoryPolicy) (*InventoryPlan, error) {
+	loader := manifestreader.NewManifestLoader(f)
+	reader, err := loader.ManifestReader(in, args)
+	if err != nil {
+		return nil, err
+	}
+	objs, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	inv, objs, err := loader.InventoryInfo(objs)
+	if err != nil {
+		if _, ok := err.(inventory.NoInventoryObjError); ok {
+			return nil, nil
+		}
+		return nil, err
+	}
+	invClient, err := provider.NewProvider(f).InventoryClient()
+	if err != nil {
+		return nil, err
+	}
+	return PlanInventory(f, invClient, inv, objs, policy)
+}
+
+// Initialize fills in the DiffOptions in preparation for Run. The
+// server-side apply settings on the options are left as they are.
 // Returns a cleanup function for removing temp files after expanding stdin, or
 // error if there is an error filling in the options or if there
 // is not one argument that is a directory.
 func Initialize(o *diff.DiffOptions, f util.Factory, args []string) (func(), error) {
+	return initialize(o, f, args, os.Stdin)
+}
+
+func initialize(o *diff.DiffOptions, f util.Factory, args []string, in io.Reader) (func(), error) {
 	cleanupFunc := func() {}
+	if o.ForceConflicts && !o.ServerSideApply {
+		return cleanupFunc, fmt.Errorf("--force-conflicts only works with --server-side")
+	}
 	// Validate the only argument is a (package) directory path.
 	filenameFlags, err := common.DemandOneDirectory(args)
 	if err != nil {
@@ -62,7 +175,7 @@ func Initialize(o *diff.DiffOptions, f util.Factory, args []string) (func(), err
 		}
 		filenameFlags.Filenames = &[]string{tmpDir}
 		klog.V(6).Infof("stdin diff command temp dir: %s", tmpDir)
-		if err := common.FilterInputFile(os.Stdin, tmpDir); err != nil {
+		if err := common.FilterInputFile(in, tmpDir); err != nil {
 			return cleanupFunc, err
 		}
 	} else {
@@ -75,9 +188,13 @@ func Initialize(o *diff.DiffOptions, f util.Factory, args []string) (func(), err
 	}
 	o.FilenameOptions = filenameFlags.ToOptions()
 
-	o.OpenAPISchema, err = f.OpenAPISchema()
-	if err != nil {
-		return cleanupFunc, err
+	// The OpenAPI schema is only needed for computing the patch
+	// on the client.
+	if !o.ServerSideApply {
+		o.OpenAPISchema, err = f.OpenAPISchema()
+		if err != nil {
+			return cleanupFunc, err
+		}
 	}
 
 	o.DiscoveryClient, err = f.ToDiscoveryClient()
@@ -99,10 +216,6 @@ func Initialize(o *diff.DiffOptions, f util.Factory, args []string) (func(), err
 
 	o.Builder = f.NewBuilder()
 
-	// We don't support server-side apply diffing yet.
-	o.ServerSideApply = false
-	o.ForceConflicts = false
-
 	return cleanupFunc, nil
 }
 
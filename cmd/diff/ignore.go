@@ -0,0 +1,71 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package diff
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/kubectl/pkg/cmd/diff"
+	pkgdiff "sigs.k8s.io/cli-utils/pkg/diff"
+)
+
+// strippedObject is a diff.Object where the ignored fields have been
+// removed from the live and merged versions.
+type strippedObject struct {
+	name   string
+	live   *unstructured.Unstructured
+	merged *unstructured.Unstructured
+}
+
+var _ diff.Object = strippedObject{}
+
+// ignoreFields returns a copy of the object without the fields that
+// are ignored by the rules or by the ignore annotation on the object.
+// The same fields are removed from both versions, so changes to them
+// don't show up in the diff.
+func ignoreFields(obj diff.Object, rules pkgdiff.IgnoreRules) (diff.Object, error) {
+	live, err := toUnstructured(obj.Live())
+	if err != nil {
+		return nil, err
+	}
+	mergedObj, err := obj.Merged()
+	if err != nil {
+		return nil, err
+	}
+	merged, err := toUnstructured(mergedObj)
+	if err != nil {
+		return nil, err
+	}
+	paths := rules.PathsFor(live, merged)
+	stripped := strippedObject{name: obj.Name()}
+	if stripped.live, err = pkgdiff.RemovePaths(live, paths); err != nil {
+		return nil, err
+	}
+	if stripped.merged, err = pkgdiff.RemovePaths(merged, paths); err != nil {
+		return nil, err
+	}
+	return stripped, nil
+}
+
+// Live returns the live version of the object. A missing object is
+// returned as an untyped nil, so the diff printer skips it.
+func (s strippedObject) Live() runtime.Object {
+	if s.live == nil {
+		return nil
+	}
+	return s.live
+}
+
+// Merged returns the merged version of the object.
+func (s strippedObject) Merged() (runtime.Object, error) {
+	if s.merged == nil {
+		return nil, nil
+	}
+	return s.merged, nil
+}
+
+// Name returns the name used for the files given to the diff program.
+func (s strippedObject) Name() string {
+	return s.name
+}
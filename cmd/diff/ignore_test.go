@@ -0,0 +1,82 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	pkgdiff "sigs.k8s.io/cli-utils/pkg/diff"
+)
+
+// fakeObject is a diff.Object with fixed live and merged versions.
+type fakeObject struct {
+	live   runtime.Object
+	merged runtime.Object
+}
+
+func (f fakeObject) Live() runtime.Object {
+	return f.live
+}
+
+func (f fakeObject) Merged() (runtime.Object, error) {
+	return f.merged, nil
+}
+
+func (f fakeObject) Name() string {
+	return "apps.v1.Deployment.default.foo"
+}
+
+func TestIgnoreFields(t *testing.T) {
+	rules := pkgdiff.IgnoreRules{
+		{Group: "apps", Kind: "Deployment", Paths: []string{".spec.replicas"}},
+	}
+
+	testCases := map[string]struct {
+		obj            fakeObject
+		expectedLive   *unstructured.Unstructured
+		expectedMerged *unstructured.Unstructured
+	}{
+		"ignored fields are removed from both versions": {
+			obj: fakeObject{
+				live:   newDeployment(5),
+				merged: newDeployment(1),
+			},
+			expectedLive:   withoutReplicas(newDeployment(5)),
+			expectedMerged: withoutReplicas(newDeployment(1)),
+		},
+		"missing live object": {
+			obj: fakeObject{
+				merged: newDeployment(1),
+			},
+			expectedMerged: withoutReplicas(newDeployment(1)),
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			obj, err := ignoreFields(tc.obj, rules)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tc.obj.Name(), obj.Name())
+
+			if tc.expectedLive == nil {
+				assert.Nil(t, obj.Live())
+			} else {
+				assert.Equal(t, tc.expectedLive, obj.Live())
+			}
+			merged, err := obj.Merged()
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedMerged, merged)
+		})
+	}
+}
+
+func withoutReplicas(u *unstructured.Unstructured) *unstructured.Unstructured {
+	unstructured.RemoveNestedField(u.Object, "spec", "replicas")
+	return u
+}
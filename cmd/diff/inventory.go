@@ -0,0 +1,317 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package diff
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/kubectl/pkg/cmd/util"
+	"sigs.k8s.io/cli-utils/cmd/flagutils"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/ordering"
+)
+
+// InventoryAction is what apply would do with an object because of the
+// inventory, in addition to applying the local objects.
+type InventoryAction string
+
+const (
+	// ActionPrune means the object is no longer in the local config
+	// and will be deleted.
+	ActionPrune InventoryAction = "Prune"
+	// ActionPruneSkipped means the object is no longer in the local
+	// config, but will not be deleted.
+	ActionPruneSkipped InventoryAction = "PruneSkipped"
+	// ActionAdopt means the object exists in the cluster and will be
+	// taken over by the inventory.
+	ActionAdopt InventoryAction = "Adopt"
+	// ActionReject means the object will not be applied because of the
+	// inventory policy.
+	ActionReject InventoryAction = "Reject"
+)
+
+// InventoryChange describes what will happen to a single object.
+type InventoryChange struct {
+	Identifier object.ObjMetadata
+	Action     InventoryAction
+	Reason     string
+	// Object is the live object. It is only set for prunes.
+	Object *unstructured.Unstructured
+}
+
+// InventoryPlan contains the changes caused by the inventory. It is
+// computed without making any changes to the cluster.
+type InventoryPlan struct {
+	Changes []InventoryChange
+}
+
+// getObjectFunc returns the live object for the identifier. It returns
+// a NotFound error if the object doesn't exist.
+type getObjectFunc func(id object.ObjMetadata) (*unstructured.Unstructured, error)
+
+// PlanInventory computes which objects apply would prune, and which
+// objects would be rejected or adopted under the inventory policy. It
+// uses the same rules as the applier, but doesn't update the inventory.
+func PlanInventory(f util.Factory, invClient inventory.InventoryClient, inv inventory.InventoryInfo,
+	localObjs []*unstructured.Unstructured, policy inventory.InventoryPolicy) (*InventoryPlan, error) {
+	clusterInv, err := invClient.GetClusterObjs(inv)
+	if err != nil {
+		return nil, err
+	}
+	getObj, err := liveObjectGetter(f)
+	if err != nil {
+		return nil, err
+	}
+	return planInventory(inv, localObjs, clusterInv, policy, getObj)
+}
+
+func planInventory(inv inventory.InventoryInfo, localObjs []*unstructured.Unstructured,
+	clusterInv []object.ObjMetadata, policy inventory.InventoryPolicy,
+	getObj getObjectFunc) (*InventoryPlan, error) {
+	plan := &InventoryPlan{}
+
+	localIds := object.UnstructuredsToObjMetas(localObjs)
+	for _, id := range localIds {
+		live, err := getObj(id)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		if ok, err := inventory.CanApply(inv, live, policy); !ok {
+			plan.Changes = append(plan.Changes, InventoryChange{
+				Identifier: id,
+				Action:     ActionReject,
+				Reason:     rejectReason(live, err),
+			})
+			continue
+		}
+		if owner := inventory.OwningInventory(live); owner != inv.ID() {
+			reason := "the object is not owned by any inventory"
+			if owner != "" {
+				reason = fmt.Sprintf("the object is owned by inventory %q", owner)
+			}
+			plan.Changes = append(plan.Changes, InventoryChange{
+				Identifier: id,
+				Action:     ActionAdopt,
+				Reason:     reason,
+			})
+		}
+	}
+
+	namespaces := localNamespaces(inv, localIds)
+	pruneIds := object.SetDiff(clusterInv, localIds)
+	// Prune happens in the reverse order of apply.
+	sort.Sort(sort.Reverse(ordering.SortableMetas(pruneIds)))
+	for _, id := range pruneIds {
+		live, err := getObj(id)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		change := InventoryChange{
+			Identifier: id,
+			Action:     ActionPruneSkipped,
+		}
+		switch {
+		case !inventory.CanPrune(inv, live, policy):
+			change.Reason = "the object is not owned by this inventory"
+		case preventDeletion(live):
+			change.Reason = "the object has a lifecycle directive that prevents deletion"
+		case id.GroupKind == object.CoreV1Namespace.GroupKind() && namespaces.Has(id.Name):
+			change.Reason = "the namespace contains applied objects"
+		default:
+			change.Action = ActionPrune
+			change.Object = live
+		}
+		plan.Changes = append(plan.Changes, change)
+	}
+	return plan, nil
+}
+
+// Rejected returns true if the object with the identifier will not be
+// applied because of the inventory policy.
+func (p *InventoryPlan) Rejected(id object.ObjMetadata) bool {
+	if p == nil {
+		return false
+	}
+	for _, c := range p.Changes {
+		if c.Action == ActionReject && c.Identifier == id {
+			return true
+		}
+	}
+	return false
+}
+
+// prunes returns the changes for objects that will be pruned.
+func (p *InventoryPlan) prunes() []InventoryChange {
+	if p == nil {
+		return nil
+	}
+	var prunes []InventoryChange
+	for _, c := range p.Changes {
+		if c.Action == ActionPrune {
+			prunes = append(prunes, c)
+		}
+	}
+	return prunes
+}
+
+// hasChanges returns true if the plan contains any changes other than
+// skipped prunes, which leave the objects as they are.
+func (p *InventoryPlan) hasChanges() bool {
+	if p == nil {
+		return false
+	}
+	for _, c := range p.Changes {
+		if c.Action != ActionPruneSkipped {
+			return true
+		}
+	}
+	return false
+}
+
+// Print prints the changes to the writer. Nothing is printed if there
+// are no changes.
+func (p *InventoryPlan) Print(w io.Writer) error {
+	if p == nil || len(p.Changes) == 0 {
+		return nil
+	}
+	if _, err := fmt.Fprintln(w, "Inventory changes:"); err != nil {
+		return err
+	}
+	for _, c := range p.Changes {
+		line := fmt.Sprintf("  %-13s %s", c.Action, displayName(c.Identifier))
+		if c.Reason != "" {
+			line = fmt.Sprintf("%s: %s", line, c.Reason)
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func rejectReason(live *unstructured.Unstructured, err error) string {
+	switch err.(type) {
+	case *inventory.NeedAdoptionError:
+		return fmt.Sprintf("the object is not owned by any inventory, use --%s=%s to adopt it",
+			flagutils.InventoryPolicyFlag, flagutils.InventoryPolicyAdopt)
+	case *inventory.InventoryOverlapError:
+		return fmt.Sprintf("the object is owned by inventory %q", inventory.OwningInventory(live))
+	}
+	if err != nil {
+		return err.Error()
+	}
+	return "rejected by the inventory policy"
+}
+
+func preventDeletion(obj *unstructured.Unstructured) bool {
+	for key, value := range obj.GetAnnotations() {
+		if common.NoDeletion(key, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// localNamespaces returns the namespaces of the local objects and the
+// inventory. Apply never prunes these namespaces.
+func localNamespaces(inv inventory.InventoryInfo, localIds []object.ObjMetadata) sets.String {
+	namespaces := sets.NewString()
+	for _, id := range localIds {
+		if ns := strings.TrimSpace(strings.ToLower(id.Namespace)); ns != "" {
+			namespaces.Insert(ns)
+		}
+	}
+	if ns := strings.TrimSpace(strings.ToLower(inv.Namespace())); ns != "" {
+		namespaces.Insert(ns)
+	}
+	return namespaces
+}
+
+// liveObjectGetter returns a function that looks up objects in the
+// cluster. Objects with a type that is unknown to the cluster are
+// reported as not found.
+func liveObjectGetter(f util.Factory) (getObjectFunc, error) {
+	dynamicClient, err := f.DynamicClient()
+	if err != nil {
+		return nil, err
+	}
+	mapper, err := f.ToRESTMapper()
+	if err != nil {
+		return nil, err
+	}
+	return func(id object.ObjMetadata) (*unstructured.Unstructured, error) {
+		mapping, err := mapper.RESTMapping(id.GroupKind)
+		if err != nil {
+			if meta.IsNoMatchError(err) {
+				return nil, apierrors.NewNotFound(schema.GroupResource{
+					Group:    id.GroupKind.Group,
+					Resource: id.GroupKind.Kind,
+				}, id.Name)
+			}
+			return nil, err
+		}
+		return dynamicClient.Resource(mapping.Resource).Namespace(id.Namespace).
+			Get(context.TODO(), id.Name, metav1.GetOptions{})
+	}, nil
+}
+
+// removedObject is a diff.Object for an object that will be pruned.
+// The merged version is empty, so the diff shows the full object as
+// removed.
+type removedObject struct {
+	obj *unstructured.Unstructured
+}
+
+func (r removedObject) Live() runtime.Object {
+	return r.obj
+}
+
+func (r removedObject) Merged() (runtime.Object, error) {
+	return nil, nil
+}
+
+// Name returns the file name used for the object. It uses the same
+// format as kubectl.
+func (r removedObject) Name() string {
+	gvk := r.obj.GroupVersionKind()
+	group := ""
+	if gvk.Group != "" {
+		group = fmt.Sprintf("%v.", gvk.Group)
+	}
+	return group + fmt.Sprintf("%v.%v.%v.%v", gvk.Version, gvk.Kind,
+		r.obj.GetNamespace(), r.obj.GetName())
+}
+
+// displayName returns the identifier on the form group/kind
+// namespace/name. The group and namespace are left out if empty.
+func displayName(id object.ObjMetadata) string {
+	kind := id.GroupKind.Kind
+	if id.GroupKind.Group != "" {
+		kind = fmt.Sprintf("%s/%s", id.GroupKind.Group, kind)
+	}
+	name := id.Name
+	if id.Namespace != "" {
+		name = fmt.Sprintf("%s/%s", id.Namespace, name)
+	}
+	return fmt.Sprintf("%s %s", kind, name)
+}
@@ -0,0 +1,233 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package diff
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+var inv = inventory.WrapInventoryInfoObj(&unstructured.Unstructured{
+	Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":      "inventory",
+			"namespace": "test",
+			"labels": map[string]interface{}{
+				common.InventoryLabel: "inv-id",
+			},
+		},
+	},
+})
+
+func newObj(kind, namespace, name string, annotations map[string]string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("v1")
+	u.SetKind(kind)
+	u.SetNamespace(namespace)
+	u.SetName(name)
+	if annotations != nil {
+		u.SetAnnotations(annotations)
+	}
+	return u
+}
+
+func owned(id string) map[string]string {
+	return map[string]string{
+		"config.k8s.io/owning-inventory": id,
+	}
+}
+
+func TestPlanInventory(t *testing.T) {
+	testCases := map[string]struct {
+		localObjs       []*unstructured.Unstructured
+		clusterInv      []*unstructured.Unstructured
+		liveObjs        []*unstructured.Unstructured
+		policy          inventory.InventoryPolicy
+		expectedActions map[string]InventoryAction
+	}{
+		"no changes": {
+			localObjs:       []*unstructured.Unstructured{newObj("ConfigMap", "test", "a", nil)},
+			clusterInv:      []*unstructured.Unstructured{newObj("ConfigMap", "test", "a", nil)},
+			liveObjs:        []*unstructured.Unstructured{newObj("ConfigMap", "test", "a", owned("inv-id"))},
+			policy:          inventory.InventoryPolicyMustMatch,
+			expectedActions: map[string]InventoryAction{},
+		},
+		"objects removed from the config are pruned": {
+			localObjs: []*unstructured.Unstructured{newObj("ConfigMap", "test", "a", nil)},
+			clusterInv: []*unstructured.Unstructured{
+				newObj("ConfigMap", "test", "a", nil),
+				newObj("ConfigMap", "test", "b", nil),
+				newObj("ConfigMap", "test", "gone", nil),
+			},
+			liveObjs: []*unstructured.Unstructured{
+				newObj("ConfigMap", "test", "a", owned("inv-id")),
+				newObj("ConfigMap", "test", "b", owned("inv-id")),
+			},
+			policy: inventory.InventoryPolicyMustMatch,
+			expectedActions: map[string]InventoryAction{
+				"b": ActionPrune,
+			},
+		},
+		"prune skipped": {
+			localObjs: []*unstructured.Unstructured{newObj("ConfigMap", "test", "a", nil)},
+			clusterInv: []*unstructured.Unstructured{
+				newObj("ConfigMap", "test", "a", nil),
+				newObj("ConfigMap", "test", "other", nil),
+				newObj("ConfigMap", "test", "keep", nil),
+				newObj("Namespace", "", "test", nil),
+			},
+			liveObjs: []*unstructured.Unstructured{
+				newObj("ConfigMap", "test", "a", owned("inv-id")),
+				newObj("ConfigMap", "test", "other", owned("other-id")),
+				newObj("ConfigMap", "test", "keep", map[string]string{
+					"config.k8s.io/owning-inventory": "inv-id",
+					common.OnRemoveAnnotation:        common.OnRemoveKeep,
+				}),
+				newObj("Namespace", "", "test", owned("inv-id")),
+			},
+			policy: inventory.InventoryPolicyMustMatch,
+			expectedActions: map[string]InventoryAction{
+				"other": ActionPruneSkipped,
+				"keep":  ActionPruneSkipped,
+				"test":  ActionPruneSkipped,
+			},
+		},
+		"rejected with strict policy": {
+			localObjs: []*unstructured.Unstructured{
+				newObj("ConfigMap", "test", "unowned", nil),
+				newObj("ConfigMap", "test", "other", nil),
+			},
+			liveObjs: []*unstructured.Unstructured{
+				newObj("ConfigMap", "test", "unowned", nil),
+				newObj("ConfigMap", "test", "other", owned("other-id")),
+			},
+			policy: inventory.InventoryPolicyMustMatch,
+			expectedActions: map[string]InventoryAction{
+				"unowned": ActionReject,
+				"other":   ActionReject,
+			},
+		},
+		"adopted with adopt policy": {
+			localObjs: []*unstructured.Unstructured{
+				newObj("ConfigMap", "test", "unowned", nil),
+				newObj("ConfigMap", "test", "other", nil),
+			},
+			liveObjs: []*unstructured.Unstructured{
+				newObj("ConfigMap", "test", "unowned", nil),
+				newObj("ConfigMap", "test", "other", owned("other-id")),
+			},
+			policy: inventory.AdoptIfNoInventory,
+			expectedActions: map[string]InventoryAction{
+				"unowned": ActionAdopt,
+				"other":   ActionReject,
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			live := make(map[object.ObjMetadata]*unstructured.Unstructured)
+			for _, obj := range tc.liveObjs {
+				live[object.UnstructuredToObjMeta(obj)] = obj
+			}
+			getObj := func(id object.ObjMetadata) (*unstructured.Unstructured, error) {
+				if obj, found := live[id]; found {
+					return obj, nil
+				}
+				return nil, apierrors.NewNotFound(schema.GroupResource{}, id.Name)
+			}
+
+			plan, err := planInventory(inv, tc.localObjs,
+				object.UnstructuredsToObjMetas(tc.clusterInv), tc.policy, getObj)
+			assert.NoError(t, err)
+
+			actions := make(map[string]InventoryAction)
+			for _, c := range plan.Changes {
+				actions[c.Identifier.Name] = c.Action
+				if c.Action == ActionPrune {
+					assert.NotNil(t, c.Object)
+				}
+				if c.Action == ActionReject {
+					assert.True(t, plan.Rejected(c.Identifier))
+				}
+			}
+			assert.Equal(t, tc.expectedActions, actions)
+		})
+	}
+}
+
+func TestInventoryPlanPrint(t *testing.T) {
+	plan := &InventoryPlan{
+		Changes: []InventoryChange{
+			{
+				Identifier: object.ObjMetadata{
+					Namespace: "test",
+					Name:      "a",
+					GroupKind: schema.GroupKind{Kind: "ConfigMap"},
+				},
+				Action: ActionPrune,
+			},
+			{
+				Identifier: object.ObjMetadata{
+					Namespace: "test",
+					Name:      "b",
+					GroupKind: schema.GroupKind{Group: "apps", Kind: "Deployment"},
+				},
+				Action: ActionReject,
+				Reason: `the object is owned by inventory "other"`,
+			},
+		},
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, plan.Print(&buf))
+	assert.Equal(t, `Inventory changes:
+  Prune         ConfigMap test/a
+  Reject        apps/Deployment test/b: the object is owned by inventory "other"
+`, buf.String())
+
+	buf.Reset()
+	var empty *InventoryPlan
+	assert.NoError(t, empty.Print(&buf))
+	assert.Empty(t, buf.String())
+}
+
+func TestPlanFromInputWithoutInventory(t *testing.T) {
+	tf := cmdtesting.NewTestFactory().WithNamespace("test")
+	defer tf.Cleanup()
+
+	dir, err := ioutil.TempDir("", "diff-test")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	manifest := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+data:
+  key: value
+`
+	err = ioutil.WriteFile(filepath.Join(dir, "cm.yaml"), []byte(manifest), 0600)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	plan, err := planFromInput(tf, []string{dir}, nil, inventory.InventoryPolicyMustMatch)
+	assert.NoError(t, err)
+	assert.Nil(t, plan)
+}
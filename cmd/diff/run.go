@@ -0,0 +1,248 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package diff
+
+import (
+	"fmt"
+	"io"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/klog"
+	"k8s.io/kubectl/pkg/cmd/diff"
+	"k8s.io/kubectl/pkg/scheme"
+	pkgdiff "sigs.k8s.io/cli-utils/pkg/diff"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// maxRetries is the number of times we try to compute the diff for an
+// object that keeps changing. It matches the kubectl diff command.
+const maxRetries = 4
+
+// Conflict describes a field manager conflict found while computing
+// the diff with a server-side apply dry-run.
+type Conflict struct {
+	// Object identifies the object, on the form group/kind namespace/name.
+	Object string `json:"object"`
+	// Fields lists the conflicting fields and the message from the
+	// server for each of them.
+	Fields []ConflictField `json:"fields"`
+}
+
+// ConflictField is a single field with a field manager conflict.
+type ConflictField struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Options contains the settings for the diff that are not part of the
+// kubectl DiffOptions.
+type Options struct {
+	// Plan contains the changes caused by the inventory. Objects that
+	// will be pruned are shown as removed, objects rejected by the
+	// inventory policy are left out of the diff, and all the inventory
+	// changes are printed after the diff. It can be nil.
+	Plan *InventoryPlan
+	// IgnoreRules lists fields that are left out of the diff, in
+	// addition to the fields listed in the ignore annotation on each
+	// object.
+	IgnoreRules pkgdiff.IgnoreRules
+}
+
+// Run computes the diff and runs the diff program against the result.
+// It works like DiffOptions.Run from kubectl, except that field manager
+// conflicts from a server-side apply dry-run don't stop the diff. The
+// conflicting objects are left out of the diff, and the conflicts are
+// printed after it. An error is returned if there were any conflicts,
+// since applying would fail.
+func Run(o *diff.DiffOptions) error {
+	return RunWithOptions(o, Options{})
+}
+
+// RunWithOptions is like Run, but also includes the changes from the
+// inventory plan and leaves out the ignored fields.
+func RunWithOptions(o *diff.DiffOptions, opts Options) error {
+	plan := opts.Plan
+	differ, err := diff.NewDiffer("LIVE", "MERGED")
+	if err != nil {
+		return err
+	}
+	defer differ.TearDown()
+
+	conflicts, err := visitObjects(o, opts, func(_ object.ObjMetadata, obj diff.Object) error {
+		return differ.Diff(obj, diff.Printer{})
+	})
+	if err != nil {
+		return err
+	}
+	for _, c := range plan.prunes() {
+		if err := differ.Diff(removedObject{obj: c.Object}, diff.Printer{}); err != nil {
+			return err
+		}
+	}
+
+	// The diff program exits with a non-zero exit code if there are
+	// differences. We still want to report the conflicts in that case.
+	diffErr := differ.Run(o.Diff)
+	if err := plan.Print(o.Diff.IOStreams.Out); err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		if err := printConflicts(o.Diff.IOStreams.Out, o.FieldManager, conflicts); err != nil {
+			return err
+		}
+		return conflictsError(conflicts)
+	}
+	// Objects that are rejected by the inventory policy don't show up
+	// in the diff, but apply would still not be a no-op.
+	if diffErr == nil && plan.hasChanges() {
+		return errDiffFound
+	}
+	return diffErr
+}
+
+// visitObjects reads the local objects and calls fn with the live and
+// merged versions of each of them, without the ignored fields. Objects
+// rejected by the inventory plan are skipped. Field manager conflicts
+// are returned rather than treated as errors.
+func visitObjects(o *diff.DiffOptions, opts Options,
+	fn func(id object.ObjMetadata, obj diff.Object) error) ([]Conflict, error) {
+	r := o.Builder.
+		Unstructured().
+		NamespaceParam(o.CmdNamespace).DefaultNamespace().
+		FilenameParam(o.EnforceNamespace, &o.FilenameOptions).
+		Flatten().
+		Do()
+	if err := r.Err(); err != nil {
+		return nil, err
+	}
+
+	var conflicts []Conflict
+	err := r.Visit(func(info *resource.Info, err error) error {
+		if err != nil {
+			return err
+		}
+		if opts.Plan.Rejected(infoIdentifier(info)) {
+			return nil
+		}
+		if err := o.DryRunVerifier.HasSupport(info.Mapping.GroupVersionKind); err != nil {
+			return err
+		}
+		err = diffObject(o, info, func(id object.ObjMetadata, obj diff.Object) error {
+			stripped, err := ignoreFields(obj, opts.IgnoreRules)
+			if err != nil {
+				return err
+			}
+			return fn(id, stripped)
+		})
+		if conflict, found := fieldManagerConflict(info, err); found {
+			conflicts = append(conflicts, conflict)
+			return nil
+		}
+		return err
+	})
+	return conflicts, err
+}
+
+// diffObject calls fn with the live and merged versions of the object.
+// Like kubectl, it retries if the object changes while the diff is
+// computed.
+func diffObject(o *diff.DiffOptions, info *resource.Info,
+	fn func(id object.ObjMetadata, obj diff.Object) error) error {
+	local := info.Object.DeepCopyObject()
+	var err error
+	for i := 1; i <= maxRetries; i++ {
+		if err = info.Get(); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return err
+			}
+			info.Object = nil
+		}
+
+		force := i == maxRetries
+		if force {
+			klog.Warningf("Object (%v: %v) keeps changing, diffing without lock",
+				info.Mapping.GroupVersionKind, info.Name)
+		}
+		obj := diff.InfoObject{
+			LocalObj:        local,
+			Info:            info,
+			Encoder:         scheme.DefaultJSONEncoder(),
+			OpenAPI:         o.OpenAPISchema,
+			Force:           force,
+			ServerSideApply: o.ServerSideApply,
+			FieldManager:    o.FieldManager,
+			ForceConflicts:  o.ForceConflicts,
+			IOStreams:       o.Diff.IOStreams,
+		}
+		err = fn(infoIdentifier(info), obj)
+		// With server-side apply, a conflict means another field
+		// manager owns some of the fields. Retrying will not help.
+		if o.ServerSideApply || !apierrors.IsConflict(err) {
+			break
+		}
+	}
+	return err
+}
+
+func conflictsError(conflicts []Conflict) error {
+	return fmt.Errorf("%d object(s) have field manager conflicts, use --force-conflicts "+
+		"to take ownership of the fields", len(conflicts))
+}
+
+// fieldManagerConflict returns the conflict if the error is caused by
+// field manager conflicts from server-side apply.
+func fieldManagerConflict(info *resource.Info, err error) (Conflict, bool) {
+	if !apierrors.IsConflict(err) {
+		return Conflict{}, false
+	}
+	statusErr, ok := err.(apierrors.APIStatus)
+	if !ok {
+		return Conflict{}, false
+	}
+	conflict := Conflict{
+		Object: displayName(infoIdentifier(info)),
+	}
+	if details := statusErr.Status().Details; details != nil {
+		for _, cause := range details.Causes {
+			if cause.Type != metav1.CauseTypeFieldManagerConflict {
+				continue
+			}
+			conflict.Fields = append(conflict.Fields, ConflictField{
+				Field:   cause.Field,
+				Message: cause.Message,
+			})
+		}
+	}
+	if len(conflict.Fields) == 0 {
+		return Conflict{}, false
+	}
+	return conflict, true
+}
+
+func printConflicts(w io.Writer, fieldManager string, conflicts []Conflict) error {
+	if _, err := fmt.Fprintf(w, "Field manager conflicts for %q:\n", fieldManager); err != nil {
+		return err
+	}
+	for _, c := range conflicts {
+		if _, err := fmt.Fprintf(w, "  %s\n", c.Object); err != nil {
+			return err
+		}
+		for _, f := range c.Fields {
+			if _, err := fmt.Fprintf(w, "    %s: %s\n", f.Field, f.Message); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func infoIdentifier(info *resource.Info) object.ObjMetadata {
+	return object.ObjMetadata{
+		Namespace: info.Namespace,
+		Name:      info.Name,
+		GroupKind: info.Mapping.GroupVersionKind.GroupKind(),
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+func TestFieldManagerConflict(t *testing.T) {
+	info := &resource.Info{
+		Namespace: "default",
+		Name:      "foo",
+		Mapping: &meta.RESTMapping{
+			GroupVersionKind: schema.GroupVersionKind{
+				Group:   "apps",
+				Version: "v1",
+				Kind:    "Deployment",
+			},
+		},
+	}
+	gr := schema.GroupResource{Group: "apps", Resource: "deployments"}
+
+	testCases := map[string]struct {
+		err              error
+		expectedFound    bool
+		expectedConflict Conflict
+	}{
+		"no error": {
+			err:           nil,
+			expectedFound: false,
+		},
+		"other error": {
+			err:           fmt.Errorf("something went wrong"),
+			expectedFound: false,
+		},
+		"resource version conflict": {
+			err:           apierrors.NewConflict(gr, "foo", fmt.Errorf("object has been modified")),
+			expectedFound: false,
+		},
+		"field manager conflicts": {
+			err: &apierrors.StatusError{
+				ErrStatus: metav1.Status{
+					Status: metav1.StatusFailure,
+					Code:   409,
+					Reason: metav1.StatusReasonConflict,
+					Details: &metav1.StatusDetails{
+						Causes: []metav1.StatusCause{
+							{
+								Type:    metav1.CauseTypeFieldManagerConflict,
+								Message: `conflict with "helm" using apps/v1`,
+								Field:   ".spec.replicas",
+							},
+							{
+								Type:    metav1.CauseTypeFieldValueInvalid,
+								Message: "ignored",
+								Field:   ".spec",
+							},
+						},
+					},
+				},
+			},
+			expectedFound: true,
+			expectedConflict: Conflict{
+				Object: "apps/Deployment default/foo",
+				Fields: []ConflictField{
+					{
+						Field:   ".spec.replicas",
+						Message: `conflict with "helm" using apps/v1`,
+					},
+				},
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			conflict, found := fieldManagerConflict(info, tc.err)
+			assert.Equal(t, tc.expectedFound, found)
+			assert.Equal(t, tc.expectedConflict, conflict)
+		})
+	}
+}
+
+func TestPrintConflicts(t *testing.T) {
+	var buf bytes.Buffer
+	err := printConflicts(&buf, "kubectl", []Conflict{
+		{
+			Object: "apps/Deployment default/foo",
+			Fields: []ConflictField{
+				{
+					Field:   ".spec.replicas",
+					Message: `conflict with "helm" using apps/v1`,
+				},
+			},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, `Field manager conflicts for "kubectl":
+  apps/Deployment default/foo
+    .spec.replicas: conflict with "helm" using apps/v1
+`, buf.String())
+}
@@ -0,0 +1,245 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package diff
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/kubectl/pkg/cmd/diff"
+	"k8s.io/utils/exec"
+	pkgdiff "sigs.k8s.io/cli-utils/pkg/diff"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// ResultAPIVersion is the version of the structured diff schema.
+	// It must be changed whenever a backwards incompatible change is
+	// made to the schema.
+	ResultAPIVersion = "cli-utils.sigs.k8s.io/v1alpha1"
+	// ResultKind is the kind of the structured diff document.
+	ResultKind = "DiffResult"
+
+	// JSONOutput and YAMLOutput are the supported structured formats.
+	JSONOutput = "json"
+	YAMLOutput = "yaml"
+)
+
+// errDiffFound is returned if there are differences. It has exit code
+// 1, which follows the convention of the diff program: 0 means no
+// differences, 1 means differences were found and anything higher
+// means there was an error.
+var errDiffFound = exec.CodeExitError{
+	Err:  errors.New("differences found"),
+	Code: 1,
+}
+
+// ObjectAction is what apply will do with an object.
+type ObjectAction string
+
+const (
+	ObjectCreate ObjectAction = "Create"
+	ObjectUpdate ObjectAction = "Update"
+	ObjectDelete ObjectAction = "Delete"
+)
+
+// Result is the structured diff document.
+type Result struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	// Objects contains the objects that will change. Unchanged objects
+	// are left out.
+	Objects []ObjectDiff `json:"objects"`
+	// Inventory contains the changes caused by the inventory, like
+	// prunes and objects rejected by the inventory policy.
+	Inventory []InventoryResult `json:"inventory,omitempty"`
+	// Conflicts contains the field manager conflicts from a
+	// server-side apply dry-run.
+	Conflicts []Conflict `json:"conflicts,omitempty"`
+}
+
+// ObjectDiff contains the changed fields for a single object.
+type ObjectDiff struct {
+	Group     string           `json:"group"`
+	Version   string           `json:"version"`
+	Kind      string           `json:"kind"`
+	Namespace string           `json:"namespace"`
+	Name      string           `json:"name"`
+	Action    ObjectAction     `json:"action"`
+	Changes   []pkgdiff.Change `json:"changes,omitempty"`
+}
+
+// InventoryResult is a single change from the inventory plan.
+type InventoryResult struct {
+	Group     string          `json:"group"`
+	Kind      string          `json:"kind"`
+	Namespace string          `json:"namespace"`
+	Name      string          `json:"name"`
+	Action    InventoryAction `json:"action"`
+	Reason    string          `json:"reason,omitempty"`
+}
+
+// HasChanges returns true if apply would change anything.
+func (r *Result) HasChanges() bool {
+	for _, i := range r.Inventory {
+		if i.Action != ActionPruneSkipped {
+			return true
+		}
+	}
+	return len(r.Objects) > 0
+}
+
+// ComputeResult computes the structured diff for the objects in the
+// options. Field manager conflicts are included in the result rather
+// than returned as an error.
+func ComputeResult(o *diff.DiffOptions, opts Options) (*Result, error) {
+	result := &Result{
+		APIVersion: ResultAPIVersion,
+		Kind:       ResultKind,
+		Objects:    []ObjectDiff{},
+	}
+	conflicts, err := visitObjects(o, opts, func(id object.ObjMetadata, obj diff.Object) error {
+		live, err := toUnstructured(obj.Live())
+		if err != nil {
+			return err
+		}
+		mergedObj, err := obj.Merged()
+		if err != nil {
+			return err
+		}
+		merged, err := toUnstructured(mergedObj)
+		if err != nil {
+			return err
+		}
+		if od, changed := objectDiff(live, merged); changed {
+			result.Objects = append(result.Objects, od)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Conflicts = conflicts
+
+	if opts.Plan != nil {
+		for _, c := range opts.Plan.Changes {
+			if c.Action == ActionPrune {
+				od, _ := objectDiff(c.Object, nil)
+				result.Objects = append(result.Objects, od)
+			}
+			result.Inventory = append(result.Inventory, InventoryResult{
+				Group:     c.Identifier.GroupKind.Group,
+				Kind:      c.Identifier.GroupKind.Kind,
+				Namespace: c.Identifier.Namespace,
+				Name:      c.Identifier.Name,
+				Action:    c.Action,
+				Reason:    c.Reason,
+			})
+		}
+	}
+	return result, nil
+}
+
+// RunStructured computes the structured diff and prints it in the
+// given format. The returned error follows the same exit code contract
+// as the diff program.
+func RunStructured(o *diff.DiffOptions, opts Options, format string) error {
+	result, err := ComputeResult(o, opts)
+	if err != nil {
+		return err
+	}
+	if err := printResult(o.Diff.IOStreams.Out, result, format); err != nil {
+		return err
+	}
+	if len(result.Conflicts) > 0 {
+		return conflictsError(result.Conflicts)
+	}
+	if result.HasChanges() {
+		return errDiffFound
+	}
+	return nil
+}
+
+// objectDiff returns the diff between the live and merged versions of
+// an object, and whether anything has changed.
+func objectDiff(live, merged *unstructured.Unstructured) (ObjectDiff, bool) {
+	var od ObjectDiff
+	var obj *unstructured.Unstructured
+	switch {
+	case live == nil && merged == nil:
+		return od, false
+	case live == nil:
+		obj = merged
+		od.Action = ObjectCreate
+	case merged == nil:
+		obj = live
+		od.Action = ObjectDelete
+	default:
+		obj = merged
+		od.Action = ObjectUpdate
+		od.Changes = pkgdiff.Compare(live, merged)
+		if len(od.Changes) == 0 {
+			return od, false
+		}
+	}
+	gvk := obj.GroupVersionKind()
+	od.Group = gvk.Group
+	od.Version = gvk.Version
+	od.Kind = gvk.Kind
+	od.Namespace = obj.GetNamespace()
+	od.Name = obj.GetName()
+	return od, true
+}
+
+func toUnstructured(obj runtime.Object) (*unstructured.Unstructured, error) {
+	if obj == nil {
+		return nil, nil
+	}
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		return u, nil
+	}
+	m, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: m}, nil
+}
+
+func printResult(w io.Writer, result *Result, format string) error {
+	var b []byte
+	var err error
+	switch format {
+	case JSONOutput:
+		b, err = json.MarshalIndent(result, "", "  ")
+		b = append(b, '\n')
+	case YAMLOutput:
+		b, err = yaml.Marshal(result)
+	default:
+		return fmt.Errorf("unknown output format %q, must be one of %s or %s",
+			format, JSONOutput, YAMLOutput)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// diffError returns the error the diff command exits with. The exit
+// status is 1 if there are differences, and errors have an exit status
+// above 1, like the exit status of the diff program.
+func diffError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if exitErr, ok := err.(exec.ExitError); ok && exitErr.ExitStatus() > 0 {
+		return exitErr
+	}
+	return exec.CodeExitError{Err: err, Code: 2}
+}
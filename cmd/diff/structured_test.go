@@ -0,0 +1,272 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/exec"
+	pkgdiff "sigs.k8s.io/cli-utils/pkg/diff"
+)
+
+func newDeployment(replicas int64) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"name":      "foo",
+				"namespace": "default",
+			},
+			"spec": map[string]interface{}{
+				"replicas": replicas,
+			},
+		},
+	}
+}
+
+func TestObjectDiff(t *testing.T) {
+	testCases := map[string]struct {
+		live            *unstructured.Unstructured
+		merged          *unstructured.Unstructured
+		expectedChanged bool
+		expectedDiff    ObjectDiff
+	}{
+		"unchanged": {
+			live:            newDeployment(1),
+			merged:          newDeployment(1),
+			expectedChanged: false,
+		},
+		"created": {
+			merged:          newDeployment(1),
+			expectedChanged: true,
+			expectedDiff: ObjectDiff{
+				Group:     "apps",
+				Version:   "v1",
+				Kind:      "Deployment",
+				Namespace: "default",
+				Name:      "foo",
+				Action:    ObjectCreate,
+			},
+		},
+		"deleted": {
+			live:            newDeployment(1),
+			expectedChanged: true,
+			expectedDiff: ObjectDiff{
+				Group:     "apps",
+				Version:   "v1",
+				Kind:      "Deployment",
+				Namespace: "default",
+				Name:      "foo",
+				Action:    ObjectDelete,
+			},
+		},
+		"updated": {
+			live:            newDeployment(1),
+			merged:          newDeployment(3),
+			expectedChanged: true,
+			expectedDiff: ObjectDiff{
+				Group:     "apps",
+				Version:   "v1",
+				Kind:      "Deployment",
+				Namespace: "default",
+				Name:      "foo",
+				Action:    ObjectUpdate,
+				Changes: []pkgdiff.Change{
+					{
+						Path: ".spec.replicas",
+						Type: pkgdiff.Modified,
+						Old:  int64(1),
+						New:  int64(3),
+					},
+				},
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			od, changed := objectDiff(tc.live, tc.merged)
+			assert.Equal(t, tc.expectedChanged, changed)
+			if tc.expectedChanged {
+				assert.Equal(t, tc.expectedDiff, od)
+			}
+		})
+	}
+}
+
+func TestResultHasChanges(t *testing.T) {
+	testCases := map[string]struct {
+		result   Result
+		expected bool
+	}{
+		"empty": {
+			result:   Result{},
+			expected: false,
+		},
+		"changed object": {
+			result: Result{
+				Objects: []ObjectDiff{{Action: ObjectUpdate}},
+			},
+			expected: true,
+		},
+		"only skipped prunes": {
+			result: Result{
+				Inventory: []InventoryResult{{Action: ActionPruneSkipped}},
+			},
+			expected: false,
+		},
+		"rejected object": {
+			result: Result{
+				Inventory: []InventoryResult{{Action: ActionReject}},
+			},
+			expected: true,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.result.HasChanges())
+		})
+	}
+}
+
+func TestPrintResult(t *testing.T) {
+	result := &Result{
+		APIVersion: ResultAPIVersion,
+		Kind:       ResultKind,
+		Objects: []ObjectDiff{
+			{
+				Group:     "apps",
+				Version:   "v1",
+				Kind:      "Deployment",
+				Namespace: "default",
+				Name:      "foo",
+				Action:    ObjectUpdate,
+				Changes: []pkgdiff.Change{
+					{
+						Path: ".spec.replicas",
+						Type: pkgdiff.Modified,
+						Old:  int64(1),
+						New:  int64(3),
+					},
+				},
+			},
+		},
+	}
+
+	testCases := map[string]struct {
+		format         string
+		expectedOutput string
+		expectedErr    bool
+	}{
+		"json": {
+			format: JSONOutput,
+			expectedOutput: `{
+  "apiVersion": "cli-utils.sigs.k8s.io/v1alpha1",
+  "kind": "DiffResult",
+  "objects": [
+    {
+      "group": "apps",
+      "version": "v1",
+      "kind": "Deployment",
+      "namespace": "default",
+      "name": "foo",
+      "action": "Update",
+      "changes": [
+        {
+          "path": ".spec.replicas",
+          "type": "Modified",
+          "old": 1,
+          "new": 3
+        }
+      ]
+    }
+  ]
+}
+`,
+		},
+		"yaml": {
+			format: YAMLOutput,
+			expectedOutput: `apiVersion: cli-utils.sigs.k8s.io/v1alpha1
+kind: DiffResult
+objects:
+- action: Update
+  changes:
+  - new: 3
+    old: 1
+    path: .spec.replicas
+    type: Modified
+  group: apps
+  kind: Deployment
+  name: foo
+  namespace: default
+  version: v1
+`,
+		},
+		"unknown format": {
+			format:      "xml",
+			expectedErr: true,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := printResult(&buf, result, tc.format)
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedOutput, buf.String())
+		})
+	}
+}
+
+func TestDiffError(t *testing.T) {
+	testCases := map[string]struct {
+		err            error
+		expectedStatus int
+	}{
+		"nil": {
+			err:            nil,
+			expectedStatus: 0,
+		},
+		"differences found": {
+			err:            errDiffFound,
+			expectedStatus: 1,
+		},
+		"diff program failed": {
+			err:            exec.CodeExitError{Err: fmt.Errorf("failed"), Code: 3},
+			expectedStatus: 3,
+		},
+		"other error": {
+			err:            fmt.Errorf("something went wrong"),
+			expectedStatus: 2,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			err := diffError(tc.err)
+			if tc.expectedStatus == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			exitErr, ok := err.(exec.ExitError)
+			if !assert.True(t, ok) {
+				return
+			}
+			assert.Equal(t, tc.expectedStatus, exitErr.ExitStatus())
+			if tc.err != errDiffFound {
+				assert.Contains(t, exitErr.Error(), tc.err.Error())
+			}
+		})
+	}
+}
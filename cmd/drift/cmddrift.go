@@ -0,0 +1,167 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package drift
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"sigs.k8s.io/cli-utils/cmd/flagutils"
+	"sigs.k8s.io/cli-utils/cmd/printers"
+	"sigs.k8s.io/cli-utils/cmd/printers/printer"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/drift"
+	"sigs.k8s.io/cli-utils/pkg/manifestreader"
+	"sigs.k8s.io/cli-utils/pkg/print/list"
+	"sigs.k8s.io/cli-utils/pkg/provider"
+)
+
+var driftLong = i18n.T(`Check the objects in the package and the inventory for drift.
+
+An object has drifted if it has been deleted from the cluster, if fields
+set by the package are now owned by a different field manager, or if the
+owning inventory annotation has been changed. Objects of the same types
+and in the same namespaces as the package that belong to the package but
+are not in the inventory are also reported. They are found with the
+--selector flag, or by the owning inventory annotation.
+
+The command exits with a non-zero exit code if any object has drifted.`)
+
+// GetDriftRunner creates and returns the DriftRunner which stores the cobra command.
+func GetDriftRunner(provider provider.Provider, loader manifestreader.ManifestLoader,
+	ioStreams genericclioptions.IOStreams) *DriftRunner {
+	r := &DriftRunner{
+		Detector:  drift.NewDetector(provider),
+		ioStreams: ioStreams,
+		loader:    loader,
+	}
+	cmd := &cobra.Command{
+		Use:                   "drift (DIRECTORY | STDIN)",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Check the applied objects for changes made outside of the package"),
+		Long:                  driftLong,
+		Args:                  cobra.MaximumNArgs(1),
+		RunE:                  r.RunE,
+	}
+
+	cmd.Flags().StringVar(&r.output, "output", printers.DefaultPrinter(),
+		fmt.Sprintf("Output format, must be one of %s. The table and interactive outputs "+
+			"are not supported.", strings.Join(printers.SupportedPrinters(), ",")))
+	cmd.Flags().StringSliceVar(&r.fieldManagers, "field-manager", defaultFieldManagers(),
+		"The field managers used when applying the package. Fields set by the package "+
+			"that are owned by other field managers are reported as drift.")
+	cmd.Flags().StringVarP(&r.selector, "selector", "l", "",
+		"Label selector matching the objects that belong to the package. Matching objects "+
+			"that are not in the inventory are reported as drift.")
+	cmd.Flags().StringVar(&r.ignoreFile, flagutils.IgnoreFileFlag, "", flagutils.IgnoreFileHelp)
+
+	r.Command = cmd
+	return r
+}
+
+// DriftCommand creates the DriftRunner, returning the cobra command associated with it.
+func DriftCommand(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	provider := provider.NewProvider(f)
+	loader := manifestreader.NewManifestLoader(f)
+	return GetDriftRunner(provider, loader, ioStreams).Command
+}
+
+// DriftRunner encapsulates data necessary to run the drift command.
+type DriftRunner struct {
+	Command   *cobra.Command
+	ioStreams genericclioptions.IOStreams
+	Detector  *drift.Detector
+	loader    manifestreader.ManifestLoader
+
+	output        string
+	fieldManagers []string
+	selector      string
+	ignoreFile    string
+}
+
+// RunE is the function run from the cobra command.
+func (r *DriftRunner) RunE(cmd *cobra.Command, args []string) error {
+	printer, err := r.printer()
+	if err != nil {
+		return err
+	}
+	var selector labels.Selector
+	if r.selector != "" {
+		selector, err = labels.Parse(r.selector)
+		if err != nil {
+			return fmt.Errorf("invalid selector %q: %v", r.selector, err)
+		}
+	}
+	if _, err := common.DemandOneDirectory(args); err != nil {
+		return err
+	}
+	rules, err := flagutils.LoadIgnoreRules(r.ignoreFile, args)
+	if err != nil {
+		return err
+	}
+
+	reader, err := r.loader.ManifestReader(cmd.InOrStdin(), args)
+	if err != nil {
+		return err
+	}
+	objs, err := reader.Read()
+	if err != nil {
+		return err
+	}
+	inv, objs, err := r.loader.InventoryInfo(objs)
+	if err != nil {
+		return err
+	}
+
+	if err := r.Detector.Initialize(); err != nil {
+		return err
+	}
+	ch := r.Detector.Run(inv, objs, drift.Options{
+		FieldManagers: r.fieldManagers,
+		IgnoreRules:   rules,
+		Selector:      selector,
+	})
+
+	// The printer will print updates from the channel. It will block
+	// until the channel is closed. It returns an error if any object
+	// has drifted.
+	return printer.Print(ch, common.DryRunNone)
+}
+
+// printer returns the printer for the output. Drift events are printed
+// by the list printers, which fail if the formatter for the output
+// can't print them. The other printers only show the resources from
+// the init event, which drift detection doesn't send.
+func (r *DriftRunner) printer() (printer.Printer, error) {
+	supported := printers.SupportedPrinters()
+	found := false
+	for _, p := range supported {
+		if p == r.output {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("output must be one of %s", strings.Join(supported, ","))
+	}
+	p := printers.GetPrinter(r.output, r.ioStreams)
+	if _, ok := p.(*list.BaseListPrinter); !ok {
+		return nil, fmt.Errorf("the %q output doesn't support drift", r.output)
+	}
+	return p, nil
+}
+
+// defaultFieldManagers returns the field manager used for server-side
+// apply, and the field manager the API server records for client-side
+// apply, which is the name of the binary from the user agent.
+func defaultFieldManagers() []string {
+	return []string{common.DefaultFieldManager, filepath.Base(os.Args[0])}
+}
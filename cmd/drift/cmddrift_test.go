@@ -0,0 +1,83 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package drift
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+func TestDriftRunner_Printer(t *testing.T) {
+	depID := object.ObjMetadata{
+		GroupKind: schema.GroupKind{
+			Group: "apps",
+			Kind:  "Deployment",
+		},
+		Namespace: "default",
+		Name:      "foo",
+	}
+
+	testCases := map[string]struct {
+		output         string
+		expectedErrMsg string
+	}{
+		"events": {
+			output:         "events",
+			expectedErrMsg: "1 resources drifted",
+		},
+		"json": {
+			output:         "json",
+			expectedErrMsg: "1 resources drifted",
+		},
+		"junit fails on drift events": {
+			output:         "junit",
+			expectedErrMsg: "the output format doesn't support drift events",
+		},
+		"table": {
+			output:         "table",
+			expectedErrMsg: `the "table" output doesn't support drift`,
+		},
+		"interactive": {
+			output:         "interactive",
+			expectedErrMsg: `the "interactive" output doesn't support drift`,
+		},
+		"unknown": {
+			output:         "yaml",
+			expectedErrMsg: "output must be one of",
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			ioStreams, _, _, _ := genericclioptions.NewTestIOStreams() //nolint:dogsled
+			r := &DriftRunner{
+				ioStreams: ioStreams,
+				output:    tc.output,
+			}
+			p, err := r.printer()
+			if err == nil {
+				ch := make(chan event.Event, 1)
+				ch <- event.Event{
+					Type: event.DriftType,
+					DriftEvent: event.DriftEvent{
+						Type:       event.DriftEventResourceUpdate,
+						Operation:  event.DeletedOutOfBand,
+						Identifier: depID,
+					},
+				}
+				close(ch)
+				err = p.Print(ch, common.DryRunNone)
+			}
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.expectedErrMsg)
+			}
+		})
+	}
+}
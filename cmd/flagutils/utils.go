@@ -6,6 +6,8 @@ package flagutils
 import (
 	"fmt"
 
+	"sigs.k8s.io/cli-utils/pkg/apply/stage"
+	"sigs.k8s.io/cli-utils/pkg/diff"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
 )
 
@@ -13,6 +15,56 @@ const (
 	InventoryPolicyFlag   = "inventory-policy"
 	InventoryPolicyStrict = "strict"
 	InventoryPolicyAdopt  = "adopt"
+
+	ReportFileFlag = "report-file"
+	ReportFileHelp = "File to write the report to when using the junit, markdown or html output. " +
+		"If not set, the report is written to stdout."
+
+	SummaryFileFlag = "summary-file"
+	SummaryFileHelp = "If set, write a summary of the result to this file once the command " +
+		"has finished. The summary is written as YAML if the file has a .yaml or .yml " +
+		"extension, and as JSON otherwise."
+
+	RecordFlag = "record"
+	RecordHelp = "If set, record all events to this file, so they can be replayed " +
+		"later with the replay command."
+
+	KeepOpenFlag = "keep-open"
+	KeepOpenHelp = "If true, keep the interactive output open after the command is done, " +
+		"until 'q' is pressed."
+
+	ColumnsFlag = "columns"
+	ColumnsHelp = "Comma-separated list of columns to show when using the table output. " +
+		"Custom columns can be added as HEADER:{.json.path}."
+
+	SortByFlag = "sort-by"
+	SortByHelp = "Column or JSONPath expression to sort the table output by. " +
+		"Prefix with '-' to sort in descending order."
+
+	IgnoreFileFlag = "ignore-file"
+	IgnoreFileHelp = "Path to a file with rules for fields to ignore. " +
+		"Defaults to the " + diff.IgnoreFileName + " file in the package directory."
+
+	SelectorFlag = "selector"
+	SelectorHelp = "Label selector for the objects in the package to apply, like app=web. " +
+		"Objects that are not selected are neither applied nor pruned, and stay in the inventory."
+
+	KindFlag = "kind"
+	KindHelp = "Only apply the objects in the package with these kinds, like Deployment or " +
+		"Deployment.apps. Objects of other kinds are neither applied nor pruned."
+
+	NameFlag = "name"
+	NameHelp = "Only apply the objects in the package with these names. Objects with other " +
+		"names are neither applied nor pruned."
+
+	ValidateFlag = "validate"
+	ValidateHelp = "If true, validate the objects in the package against the schemas for their types " +
+		"before making any changes, and report all the errors found. CustomResourceDefinitions in " +
+		"the package are used for their custom resources."
+
+	StagesFileFlag = "stages-file"
+	StagesFileHelp = "Path to a file with the stages to apply the resources in. " +
+		"Defaults to the " + stage.FileName + " file in the package directory."
 )
 
 func ConvertInventoryPolicy(policy string) (inventory.InventoryPolicy, error) {
@@ -26,3 +78,30 @@ func ConvertInventoryPolicy(policy string) (inventory.InventoryPolicy, error) {
 			"inventory policy must be one of strict, adopt")
 	}
 }
+
+// LoadIgnoreRules reads the ignore rules from the ignore file if it is
+// set, or else from the ignore file in the package directory if there
+// is one. There is no package directory if the manifests are read from
+// stdin.
+func LoadIgnoreRules(ignoreFile string, args []string) (diff.IgnoreRules, error) {
+	if ignoreFile != "" {
+		return diff.ReadIgnoreFile(ignoreFile)
+	}
+	if len(args) == 0 {
+		return nil, nil
+	}
+	return diff.LoadPackageIgnoreRules(args[0])
+}
+
+// LoadStages reads the stages from the stages file if it is set, or
+// else from the stages file in the package directory if there is one.
+// There is no package directory if the manifests are read from stdin.
+func LoadStages(stagesFile string, args []string) ([]stage.Stage, error) {
+	if stagesFile != "" {
+		return stage.ReadFile(stagesFile)
+	}
+	if len(args) == 0 {
+		return nil, nil
+	}
+	return stage.LoadPackageStages(args[0])
+}
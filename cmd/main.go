@@ -5,6 +5,7 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"os"
 	"strings"
 
@@ -12,11 +13,14 @@ import (
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/util/logs"
+	"k8s.io/utils/exec"
 	"sigs.k8s.io/cli-utils/cmd/apply"
 	"sigs.k8s.io/cli-utils/cmd/destroy"
 	"sigs.k8s.io/cli-utils/cmd/diff"
+	"sigs.k8s.io/cli-utils/cmd/drift"
 	"sigs.k8s.io/cli-utils/cmd/initcmd"
 	"sigs.k8s.io/cli-utils/cmd/preview"
+	"sigs.k8s.io/cli-utils/cmd/replay"
 	"sigs.k8s.io/cli-utils/cmd/status"
 	"sigs.k8s.io/cli-utils/pkg/errors"
 	"sigs.k8s.io/cli-utils/pkg/util/factory"
@@ -54,7 +58,7 @@ func main() {
 		ErrOut: os.Stderr,
 	}
 
-	names := []string{"init", "apply", "preview", "diff", "destroy", "status"}
+	names := []string{"init", "apply", "preview", "diff", "destroy", "status", "replay", "drift"}
 	initCmd := initcmd.NewCmdInit(f, ioStreams)
 	updateHelp(names, initCmd)
 	applyCmd := apply.ApplyCommand(f, ioStreams)
@@ -67,13 +71,25 @@ func main() {
 	updateHelp(names, destroyCmd)
 	statusCmd := status.StatusCommand(f)
 	updateHelp(names, statusCmd)
+	replayCmd := replay.ReplayCommand(ioStreams)
+	updateHelp(names, replayCmd)
+	driftCmd := drift.DriftCommand(f, ioStreams)
+	updateHelp(names, driftCmd)
 
-	cmd.AddCommand(initCmd, applyCmd, diffCmd, destroyCmd, previewCmd, statusCmd)
+	cmd.AddCommand(initCmd, applyCmd, diffCmd, destroyCmd, previewCmd, statusCmd, replayCmd, driftCmd)
 
 	logs.InitLogs()
 	defer logs.FlushLogs()
 
 	if err := cmd.Execute(); err != nil {
+		// The diff command reports the result with the exit status, so
+		// the error is only printed if the status means it failed.
+		if exitErr, ok := err.(exec.ExitError); ok {
+			if exitErr.ExitStatus() > 1 {
+				fmt.Fprintf(cmd.ErrOrStderr(), "error: %v\n", exitErr)
+			}
+			os.Exit(exitErr.ExitStatus())
+		}
 		errors.CheckErr(cmd.ErrOrStderr(), err, "kapply")
 	}
 }
@@ -15,11 +15,14 @@ import (
 
 	"sigs.k8s.io/cli-utils/cmd/flagutils"
 	"sigs.k8s.io/cli-utils/cmd/printers"
+	jsonprinter "sigs.k8s.io/cli-utils/cmd/printers/json"
 	"sigs.k8s.io/cli-utils/pkg/apply"
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/record"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
 	"sigs.k8s.io/cli-utils/pkg/manifestreader"
+	"sigs.k8s.io/cli-utils/pkg/object"
 	"sigs.k8s.io/cli-utils/pkg/provider"
 )
 
@@ -55,9 +58,16 @@ func GetPreviewRunner(provider provider.Provider, loader manifestreader.Manifest
 	cmd.Flags().BoolVar(&previewDestroy, "destroy", previewDestroy, "If true, preview of destroy operations will be displayed.")
 	cmd.Flags().StringVar(&r.output, "output", printers.DefaultPrinter(),
 		fmt.Sprintf("Output format, must be one of %s", strings.Join(printers.SupportedPrinters(), ",")))
+	cmd.Flags().StringVar(&r.reportFile, flagutils.ReportFileFlag, "", flagutils.ReportFileHelp)
+	cmd.Flags().BoolVar(&r.keepOpen, flagutils.KeepOpenFlag, false, flagutils.KeepOpenHelp)
+	cmd.Flags().StringVar(&r.summaryFile, flagutils.SummaryFileFlag, "", flagutils.SummaryFileHelp)
+	cmd.Flags().StringVar(&r.recordFile, flagutils.RecordFlag, "", flagutils.RecordHelp)
 	cmd.Flags().StringVar(&r.inventoryPolicy, flagutils.InventoryPolicyFlag, flagutils.InventoryPolicyStrict,
 		"It determines the behavior when the resources don't belong to current inventory. Available options "+
 			fmt.Sprintf("%q and %q.", flagutils.InventoryPolicyStrict, flagutils.InventoryPolicyAdopt))
+	cmd.Flags().StringVarP(&r.filter.Selector, flagutils.SelectorFlag, "l", "", flagutils.SelectorHelp)
+	cmd.Flags().StringSliceVar(&r.filter.Kinds, flagutils.KindFlag, nil, flagutils.KindHelp)
+	cmd.Flags().StringSliceVar(&r.filter.Names, flagutils.NameFlag, nil, flagutils.NameHelp)
 
 	r.Command = cmd
 	return r
@@ -66,8 +76,18 @@ func GetPreviewRunner(provider provider.Provider, loader manifestreader.Manifest
 // PreviewCommand creates the PreviewRunner, returning the cobra command associated with it.
 func PreviewCommand(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
 	provider := provider.NewProvider(f)
-	loader := manifestreader.NewManifestLoader(f)
-	return GetPreviewRunner(provider, loader, ioStreams).Command
+	loaderOptions := &manifestreader.LoaderOptions{}
+	loader := manifestreader.NewManifestLoaderWithOptions(f, loaderOptions)
+	cmd := GetPreviewRunner(provider, loader, ioStreams).Command
+	cmd.Flags().BoolVar(&loaderOptions.Validate, flagutils.ValidateFlag, true, flagutils.ValidateHelp)
+	// The manifests are only used to find the inventory when previewing
+	// destroy, so they are not validated.
+	cmd.PreRun = func(*cobra.Command, []string) {
+		if previewDestroy {
+			loaderOptions.Validate = false
+		}
+	}
+	return cmd
 }
 
 // PreviewRunner encapsulates data necessary to run the preview command.
@@ -82,7 +102,12 @@ type PreviewRunner struct {
 
 	serverSideOptions common.ServerSideOptions
 	output            string
+	reportFile        string
+	keepOpen          bool
+	summaryFile       string
+	recordFile        string
 	inventoryPolicy   string
+	filter            object.Filter
 }
 
 // RunE is the function run from the cobra command.
@@ -102,6 +127,13 @@ func (r *PreviewRunner) RunE(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	if err := r.filter.Validate(); err != nil {
+		return err
+	}
+	if previewDestroy && !r.filter.IsEmpty() {
+		return fmt.Errorf("--%s, --%s and --%s can't be used with --destroy",
+			flagutils.SelectorFlag, flagutils.KindFlag, flagutils.NameFlag)
+	}
 
 	reader, err := r.loader.ManifestReader(cmd.InOrStdin(), args)
 	if err != nil {
@@ -124,6 +156,14 @@ func (r *PreviewRunner) RunE(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Create the record file before starting, so we don't start making
+	// changes to the cluster if it can't be created.
+	recorder, err := record.CreateFile(r.recordFile, drs)
+	if err != nil {
+		return err
+	}
+	defer recorder.Close()
+
 	// if destroy flag is set in preview, transmit it to destroyer DryRunStrategy flag
 	// and pivot execution to destroy with dry-run
 	if !r.Destroyer.DryRunStrategy.ClientOrServerDryRun() {
@@ -148,6 +188,7 @@ func (r *PreviewRunner) RunE(cmd *cobra.Command, args []string) error {
 			DryRunStrategy:    drs,
 			ServerSideOptions: r.serverSideOptions,
 			InventoryPolicy:   inventoryPolicy,
+			Filter:            r.filter,
 		})
 	} else {
 		err = r.Destroyer.Initialize()
@@ -160,8 +201,28 @@ func (r *PreviewRunner) RunE(cmd *cobra.Command, args []string) error {
 		ch = r.Destroyer.Run(inv, option)
 	}
 
+	ch = recorder.Tee(ch)
+
+	var summary *jsonprinter.SummaryRecorder
+	if r.summaryFile != "" {
+		summary = jsonprinter.NewSummaryRecorder("preview", inv)
+		ch = summary.Tee(ch)
+	}
+
 	// The printer will print updates from the channel. It will block
 	// until the channel is closed.
-	printer := printers.GetPrinter(r.output, r.ioStreams)
-	return printer.Print(ch, drs)
+	printer := printers.GetPrinterWithOptions(r.output, r.ioStreams, printers.Options{
+		ReportFile: r.reportFile,
+		KeepOpen:   r.keepOpen,
+	})
+	err = printer.Print(ch, drs)
+	if summary != nil {
+		if writeErr := summary.WriteFile(r.summaryFile, err); writeErr != nil && err == nil {
+			return writeErr
+		}
+	}
+	if recordErr := recorder.Err(); recordErr != nil && err == nil {
+		return recordErr
+	}
+	return err
 }
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -14,6 +15,7 @@ import (
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
 	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/collector"
 	"sigs.k8s.io/cli-utils/pkg/object"
 	"sigs.k8s.io/cli-utils/pkg/print/list"
 )
@@ -39,10 +41,17 @@ func (ef *formatter) FormatApplyEvent(ae event.ApplyEvent, as *list.ApplyStats,
 		if as.ServersideApplied > 0 {
 			output += fmt.Sprintf(", %d serverside applied", as.ServersideApplied)
 		}
+		if as.Recreated > 0 {
+			output += fmt.Sprintf(", %d recreated", as.Recreated)
+		}
 		ef.print(output)
 		for id, se := range c.LatestStatus() {
 			ef.printResourceStatus(id, se)
 		}
+	case event.ApplyEventRetry:
+		ef.print("%s apply failed, retrying in %s (attempt %d of %d): %s",
+			resourceIDToString(ae.Identifier.GroupKind, ae.Identifier.Name),
+			ae.Retry.Delay, ae.Retry.Attempt, ae.Retry.MaxAttempts, ae.Error.Error())
 	case event.ApplyEventResourceUpdate:
 		gk := ae.Identifier.GroupKind
 		name := ae.Identifier.Name
@@ -80,6 +89,10 @@ func (ef *formatter) FormatPruneEvent(pe event.PruneEvent, ps *list.PruneStats)
 	case event.PruneEventFailed:
 		ef.print("%s prune failed: %s", resourceIDToString(pe.Identifier.GroupKind, pe.Identifier.Name),
 			pe.Error.Error())
+	case event.PruneEventRetry:
+		ef.print("%s prune failed, retrying in %s (attempt %d of %d): %s",
+			resourceIDToString(pe.Identifier.GroupKind, pe.Identifier.Name),
+			pe.Retry.Delay, pe.Retry.Attempt, pe.Retry.MaxAttempts, pe.Error.Error())
 	}
 	return nil
 }
@@ -105,10 +118,82 @@ func (ef *formatter) FormatDeleteEvent(de event.DeleteEvent, ds *list.DeleteStat
 	return nil
 }
 
+// driftDescriptions are the printed descriptions of the drift operations.
+var driftDescriptions = map[event.DriftEventOperation]string{
+	event.InSync:           "in sync",
+	event.DeletedOutOfBand: "deleted out of band",
+	event.FieldsChanged:    "fields changed by other field managers",
+	event.InventoryChanged: "owning inventory changed",
+	event.NotInInventory:   "not in inventory",
+}
+
+func (ef *formatter) FormatDriftEvent(de event.DriftEvent, ds *list.DriftStats) error {
+	switch de.Type {
+	case event.DriftEventCompleted:
+		ef.print("%d resource(s) checked, %d in sync, %d drifted", ds.InSync+ds.Drifted(),
+			ds.InSync, ds.Drifted())
+	case event.DriftEventResourceUpdate:
+		id := resourceIDToString(de.Identifier.GroupKind, de.Identifier.Name)
+		if len(de.Details) == 0 {
+			ef.print("%s %s", id, driftDescriptions[de.Operation])
+		} else {
+			ef.print("%s %s: %s", id, driftDescriptions[de.Operation], strings.Join(de.Details, ", "))
+		}
+	}
+	return nil
+}
+
+func (ef *formatter) FormatRollbackEvent(re event.RollbackEvent, rs *list.RollbackStats) error {
+	switch re.Type {
+	case event.RollbackEventCompleted:
+		ef.print("rollback completed: %d restored, %d deleted, %d failed", rs.Restored, rs.Deleted, rs.Failed)
+	case event.RollbackEventResourceUpdate:
+		id := resourceIDToString(re.Identifier.GroupKind, re.Identifier.Name)
+		switch re.Operation {
+		case event.Restored:
+			ef.print("%s restored (rollback)", id)
+		case event.RollbackDeleted:
+			ef.print("%s deleted (rollback)", id)
+		}
+	case event.RollbackEventFailed:
+		if re.Identifier.Name == "" {
+			ef.print("rollback failed: %s", re.Error.Error())
+		} else {
+			ef.print("%s rollback failed: %s", resourceIDToString(re.Identifier.GroupKind, re.Identifier.Name),
+				re.Error.Error())
+		}
+	}
+	return nil
+}
+
 func (ef *formatter) FormatErrorEvent(_ event.ErrorEvent) error {
 	return nil
 }
 
+func (ef *formatter) FormatStatusSummary(ts collector.TimelineSummary) error {
+	if len(ts.Resources) == 0 {
+		return nil
+	}
+	var currentCount int
+	for _, rd := range ts.Resources {
+		if rd.Current {
+			currentCount++
+		}
+	}
+	ef.print("%d of %d resource(s) became Current in %s, slowest first:",
+		currentCount, len(ts.Resources), ts.End.Sub(ts.Start).Round(time.Second))
+	for _, rd := range ts.Resources {
+		id := resourceIDToString(rd.Identifier.GroupKind, rd.Identifier.Name)
+		if rd.Current {
+			ef.print("%s became Current after %s", id, rd.Duration.Round(time.Second))
+		} else {
+			ef.print("%s did not become Current, still %s after %s", id, rd.Status,
+				rd.Duration.Round(time.Second))
+		}
+	}
+	return nil
+}
+
 func (ef *formatter) printResourceStatus(id object.ObjMetadata, se event.StatusEvent) {
 	ef.print("%s is %s: %s", resourceIDToString(id.GroupKind, id.Name),
 		se.Resource.Status.String(), se.Resource.Message)
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -14,6 +15,7 @@ import (
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
 	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/collector"
 	pollevent "sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
 	"sigs.k8s.io/cli-utils/pkg/object"
@@ -65,6 +67,20 @@ func TestFormatter_FormatApplyEvent(t *testing.T) {
 			},
 			expected: "deployment.apps/my-dep failed: this is a test error (preview-server)",
 		},
+		"retry event": {
+			previewStrategy: common.DryRunNone,
+			event: event.ApplyEvent{
+				Type:       event.ApplyEventRetry,
+				Identifier: createIdentifier("apps", "Deployment", "", "my-dep"),
+				Error:      fmt.Errorf("too many requests"),
+				Retry: event.Retry{
+					Attempt:     1,
+					MaxAttempts: 3,
+					Delay:       2 * time.Second,
+				},
+			},
+			expected: "deployment.apps/my-dep apply failed, retrying in 2s (attempt 1 of 3): too many requests",
+		},
 		"completed event": {
 			previewStrategy: common.DryRunNone,
 			event: event.ApplyEvent{
@@ -149,6 +165,39 @@ func TestFormatter_FormatStatusEvent(t *testing.T) {
 	}
 }
 
+func TestFormatter_FormatStatusSummary(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	summary := collector.TimelineSummary{
+		Start: start,
+		End:   start.Add(30 * time.Second),
+		Resources: []collector.ResourceDuration{
+			{
+				Identifier: createIdentifier("apps", "StatefulSet", "default", "sts"),
+				Duration:   30 * time.Second,
+				Status:     status.InProgressStatus,
+			},
+			{
+				Identifier: createIdentifier("apps", "Deployment", "default", "dep"),
+				Current:    true,
+				Duration:   12 * time.Second,
+				Status:     status.CurrentStatus,
+			},
+		},
+	}
+
+	ioStreams, _, out, _ := genericclioptions.NewTestIOStreams() //nolint:dogsled
+	formatter := NewFormatter(ioStreams, common.DryRunNone)
+	err := formatter.(list.StatusSummaryFormatter).FormatStatusSummary(summary)
+	assert.NoError(t, err)
+
+	expected := `
+1 of 2 resource(s) became Current in 30s, slowest first:
+statefulset.apps/sts did not become Current, still InProgress after 30s
+deployment.apps/dep became Current after 12s
+`
+	assert.Equal(t, strings.TrimSpace(expected), strings.TrimSpace(out.String()))
+}
+
 func TestFormatter_FormatPruneEvent(t *testing.T) {
 	testCases := map[string]struct {
 		previewStrategy common.DryRunStrategy
@@ -183,6 +232,20 @@ func TestFormatter_FormatPruneEvent(t *testing.T) {
 			},
 			expected: "deployment.apps/my-dep prune failed: this is a test",
 		},
+		"prune retry event": {
+			previewStrategy: common.DryRunNone,
+			event: event.PruneEvent{
+				Type:       event.PruneEventRetry,
+				Identifier: createIdentifier("apps", "Deployment", "", "my-dep"),
+				Error:      fmt.Errorf("conflict"),
+				Retry: event.Retry{
+					Attempt:     2,
+					MaxAttempts: 5,
+					Delay:       time.Second,
+				},
+			},
+			expected: "deployment.apps/my-dep prune failed, retrying in 1s (attempt 2 of 5): conflict",
+		},
 		"prune event with completed status": {
 			previewStrategy: common.DryRunNone,
 			event: event.PruneEvent{
@@ -269,6 +332,119 @@ func TestFormatter_FormatDeleteEvent(t *testing.T) {
 	}
 }
 
+func TestFormatter_FormatDriftEvent(t *testing.T) {
+	testCases := map[string]struct {
+		event      event.DriftEvent
+		driftStats *list.DriftStats
+		expected   string
+	}{
+		"resource in sync": {
+			event: event.DriftEvent{
+				Operation:  event.InSync,
+				Type:       event.DriftEventResourceUpdate,
+				Identifier: createIdentifier("apps", "Deployment", "default", "my-dep"),
+			},
+			expected: "deployment.apps/my-dep in sync",
+		},
+		"fields changed": {
+			event: event.DriftEvent{
+				Operation:  event.FieldsChanged,
+				Type:       event.DriftEventResourceUpdate,
+				Identifier: createIdentifier("apps", "Deployment", "default", "my-dep"),
+				Details: []string{
+					`.spec.replicas changed by "kubectl-edit"`,
+					`.spec.paused changed by "kubectl-edit"`,
+				},
+			},
+			expected: `deployment.apps/my-dep fields changed by other field managers: ` +
+				`.spec.replicas changed by "kubectl-edit", .spec.paused changed by "kubectl-edit"`,
+		},
+		"drift event with completed status": {
+			event: event.DriftEvent{
+				Type: event.DriftEventCompleted,
+			},
+			driftStats: &list.DriftStats{
+				InSync:           3,
+				DeletedOutOfBand: 1,
+				NotInInventory:   1,
+			},
+			expected: "5 resource(s) checked, 3 in sync, 2 drifted",
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			ioStreams, _, out, _ := genericclioptions.NewTestIOStreams() //nolint:dogsled
+			formatter := NewFormatter(ioStreams, common.DryRunNone)
+			err := formatter.(list.DriftFormatter).FormatDriftEvent(tc.event, tc.driftStats)
+			assert.NoError(t, err)
+
+			assert.Equal(t, strings.TrimSpace(tc.expected), strings.TrimSpace(out.String()))
+		})
+	}
+}
+
+func TestFormatter_FormatRollbackEvent(t *testing.T) {
+	testCases := map[string]struct {
+		event         event.RollbackEvent
+		rollbackStats *list.RollbackStats
+		expected      string
+	}{
+		"resource restored": {
+			event: event.RollbackEvent{
+				Operation:  event.Restored,
+				Type:       event.RollbackEventResourceUpdate,
+				Identifier: createIdentifier("apps", "Deployment", "default", "my-dep"),
+			},
+			expected: "deployment.apps/my-dep restored (rollback)",
+		},
+		"resource deleted": {
+			event: event.RollbackEvent{
+				Operation:  event.RollbackDeleted,
+				Type:       event.RollbackEventResourceUpdate,
+				Identifier: createIdentifier("", "ConfigMap", "default", "my-cm"),
+			},
+			expected: "configmap/my-cm deleted (rollback)",
+		},
+		"resource failed": {
+			event: event.RollbackEvent{
+				Type:       event.RollbackEventFailed,
+				Identifier: createIdentifier("apps", "Deployment", "default", "my-dep"),
+				Error:      fmt.Errorf("forbidden"),
+			},
+			expected: "deployment.apps/my-dep rollback failed: forbidden",
+		},
+		"inventory failed": {
+			event: event.RollbackEvent{
+				Type:  event.RollbackEventFailed,
+				Error: fmt.Errorf("error restoring inventory: forbidden"),
+			},
+			expected: "rollback failed: error restoring inventory: forbidden",
+		},
+		"rollback completed": {
+			event: event.RollbackEvent{
+				Type: event.RollbackEventCompleted,
+			},
+			rollbackStats: &list.RollbackStats{
+				Restored: 2,
+				Deleted:  1,
+			},
+			expected: "rollback completed: 2 restored, 1 deleted, 0 failed",
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			ioStreams, _, out, _ := genericclioptions.NewTestIOStreams() //nolint:dogsled
+			formatter := NewFormatter(ioStreams, common.DryRunNone)
+			err := formatter.(list.RollbackFormatter).FormatRollbackEvent(tc.event, tc.rollbackStats)
+			assert.NoError(t, err)
+
+			assert.Equal(t, strings.TrimSpace(tc.expected), strings.TrimSpace(out.String()))
+		})
+	}
+}
+
 func createObject(group, kind, namespace, name string) *unstructured.Unstructured {
 	return &unstructured.Unstructured{
 		Object: map[string]interface{}{
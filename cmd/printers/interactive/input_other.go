@@ -0,0 +1,19 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package interactive
+
+import (
+	"io"
+	"time"
+)
+
+// waitReadable always treats the reader as readable, since polling
+// is not supported on this platform. The key reader then stops after
+// the next key press instead.
+func waitReadable(io.Reader, time.Duration) (bool, error) {
+	return true, nil
+}
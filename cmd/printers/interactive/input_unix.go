@@ -0,0 +1,34 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package interactive
+
+import (
+	"io"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// waitReadable waits up to the timeout for input to be available, so
+// the key reader can check whether it should stop between key presses
+// instead of being blocked on reading. Readers without a file
+// descriptor are always treated as readable.
+func waitReadable(r io.Reader, timeout time.Duration) (bool, error) {
+	f, ok := r.(interface{ Fd() uintptr })
+	if !ok {
+		return true, nil
+	}
+	fds := []unix.PollFd{{Fd: int32(f.Fd()), Events: unix.POLLIN}}
+	n, err := unix.Poll(fds, int(timeout/time.Millisecond))
+	if err == unix.EINTR {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,43 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package interactive
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadKeys_Stop(t *testing.T) {
+	r, w, err := os.Pipe()
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer r.Close()
+	defer w.Close()
+
+	stop := make(chan struct{})
+	keys := readKeys(r, stop)
+	_, err = w.Write([]byte("j"))
+	assert.NoError(t, err)
+	assert.Equal(t, []key{keyDown}, <-keys)
+
+	// The reader must exit without any more input, so it doesn't
+	// read from the terminal after the UI is closed.
+	done := make(chan struct{})
+	go func() {
+		stopKeys(stop, keys)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the key reader to stop")
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package interactive
+
+// key is a key press that the UI reacts to.
+type key int
+
+const (
+	keyUp key = iota
+	keyDown
+	keyToggle
+	keyFilterAll
+	keyFilterFailed
+	keyFilterInProgress
+	keyQuit
+	keyInterrupt
+)
+
+const (
+	ctrlC  = 3
+	escape = 27
+)
+
+// parseKeys converts the bytes read from a terminal in raw mode into
+// keys. Bytes that don't map to any key are ignored.
+func parseKeys(b []byte) []key {
+	var keys []key
+	for i := 0; i < len(b); i++ {
+		switch b[i] {
+		case escape:
+			// Arrow keys are sent as ESC [ A and ESC [ B.
+			if i+2 < len(b) && b[i+1] == '[' {
+				switch b[i+2] {
+				case 'A':
+					keys = append(keys, keyUp)
+				case 'B':
+					keys = append(keys, keyDown)
+				}
+				i += 2
+			}
+		case 'k':
+			keys = append(keys, keyUp)
+		case 'j':
+			keys = append(keys, keyDown)
+		case '\r', '\n', ' ':
+			keys = append(keys, keyToggle)
+		case 'a':
+			keys = append(keys, keyFilterAll)
+		case 'f':
+			keys = append(keys, keyFilterFailed)
+		case 'p':
+			keys = append(keys, keyFilterInProgress)
+		case 'q':
+			keys = append(keys, keyQuit)
+		case ctrlC:
+			keys = append(keys, keyInterrupt)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,44 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package interactive
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseKeys(t *testing.T) {
+	testCases := map[string]struct {
+		input        []byte
+		expectedKeys []key
+	}{
+		"arrow keys": {
+			input:        []byte{escape, '[', 'A', escape, '[', 'B'},
+			expectedKeys: []key{keyUp, keyDown},
+		},
+		"letters": {
+			input:        []byte("jkafpq"),
+			expectedKeys: []key{keyDown, keyUp, keyFilterAll, keyFilterFailed, keyFilterInProgress, keyQuit},
+		},
+		"toggle": {
+			input:        []byte("\r "),
+			expectedKeys: []key{keyToggle, keyToggle},
+		},
+		"ctrl-c": {
+			input:        []byte{ctrlC},
+			expectedKeys: []key{keyInterrupt},
+		},
+		"unknown keys are ignored": {
+			input:        []byte{'x', escape, '[', 'C'},
+			expectedKeys: nil,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			assert.Equal(t, tc.expectedKeys, parseKeys(tc.input))
+		})
+	}
+}
@@ -0,0 +1,576 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package interactive
+
+import (
+	"fmt"
+	"sort"
+	"time"
+	"unicode/utf8"
+
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
+	pe "sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// phase is the phase a resource is currently in. Resources are
+// grouped by phase in the UI.
+type phase int
+
+const (
+	applyPhase phase = iota
+	waitPhase
+	prunePhase
+	deletePhase
+)
+
+var phaseNames = map[phase]string{
+	applyPhase:  "APPLY",
+	waitPhase:   "WAIT",
+	prunePhase:  "PRUNE",
+	deletePhase: "DELETE",
+}
+
+// filter determines which resources are visible.
+type filter int
+
+const (
+	filterAll filter = iota
+	filterFailed
+	filterInProgress
+)
+
+var filterNames = map[filter]string{
+	filterAll:        "all",
+	filterFailed:     "failed",
+	filterInProgress: "in progress",
+}
+
+// resourceState captures the latest seen state of a single resource.
+type resourceState struct {
+	identifier object.ObjMetadata
+	action     event.ResourceAction
+	// stage is the name of the stage the resource is applied in, if
+	// the resources are applied in stages.
+	stage string
+
+	applyOp  *event.ApplyEventOperation
+	pruneOp  *event.PruneEventOperation
+	deleteOp *event.DeleteEventOperation
+
+	// err is the error from applying, pruning or deleting the resource.
+	err error
+	// resourceStatus is the latest status seen for the resource. It is
+	// nil if no status has been seen.
+	resourceStatus *pe.ResourceStatus
+	// timedOut is true if the resource didn't reach the desired
+	// status before the timeout.
+	timedOut bool
+
+	expanded bool
+}
+
+func (r *resourceState) phase() phase {
+	switch {
+	case r.deleteOp != nil:
+		return deletePhase
+	case r.action == event.PruneAction:
+		return prunePhase
+	case r.applyOp != nil && r.resourceStatus != nil:
+		return waitPhase
+	default:
+		return applyPhase
+	}
+}
+
+func (r *resourceState) failed() bool {
+	if r.err != nil || r.timedOut {
+		return true
+	}
+	if r.applyOp != nil && *r.applyOp == event.Failed {
+		return true
+	}
+	return r.resourceStatus != nil &&
+		(r.resourceStatus.Status == status.FailedStatus || r.resourceStatus.Error != nil)
+}
+
+func (r *resourceState) inProgress() bool {
+	if r.failed() {
+		return false
+	}
+	switch r.phase() {
+	case applyPhase:
+		return r.applyOp == nil
+	case waitPhase:
+		return r.resourceStatus.Status != status.CurrentStatus
+	case prunePhase:
+		return r.pruneOp == nil
+	default:
+		return false
+	}
+}
+
+// result returns the result of the operation performed on the resource.
+func (r *resourceState) result() string {
+	switch {
+	case r.err != nil:
+		return "Failed"
+	case r.deleteOp != nil:
+		return r.deleteOp.String()
+	case r.action == event.PruneAction && r.pruneOp != nil:
+		return r.pruneOp.String()
+	case r.action == event.ApplyAction && r.applyOp != nil:
+		return r.applyOp.String()
+	default:
+		return "Pending"
+	}
+}
+
+// action is the result of handling a key press.
+type action int
+
+const (
+	actionNone action = iota
+	actionQuit
+	actionInterrupt
+)
+
+// model contains all the state of the UI. It is only used from a
+// single goroutine, so it doesn't need any locking.
+type model struct {
+	preview   bool
+	start     time.Time
+	resources map[object.ObjMetadata]*resourceState
+	// stages contains the names of the stages, in the order they are
+	// applied.
+	stages []string
+
+	filter filter
+	// cursor is the index of the selected resource in the list
+	// of visible resources.
+	cursor int
+
+	// keepOpen is true if the UI stays open after all events have
+	// been processed, until the user quits.
+	keepOpen bool
+	// done is true once all events have been processed.
+	done bool
+	// err is the fatal error from the event stream, if any.
+	err error
+}
+
+func newModel(preview bool, start time.Time) *model {
+	return &model{
+		preview:   preview,
+		start:     start,
+		resources: make(map[object.ObjMetadata]*resourceState),
+	}
+}
+
+// resource returns the state for the resource, creating it with the
+// given action if it doesn't already exist.
+func (m *model) resource(id object.ObjMetadata, a event.ResourceAction) *resourceState {
+	r, found := m.resources[id]
+	if !found {
+		r = &resourceState{
+			identifier: id,
+			action:     a,
+		}
+		m.resources[id] = r
+	}
+	return r
+}
+
+// processEvent updates the state based on the event.
+func (m *model) processEvent(e event.Event) {
+	switch e.Type {
+	case event.InitType:
+		for _, rg := range e.InitEvent.ResourceGroups {
+			if rg.Stage != "" {
+				m.stages = append(m.stages, rg.Stage)
+			}
+			for _, id := range rg.Identifiers {
+				m.resource(id, rg.Action).stage = rg.Stage
+			}
+		}
+	case event.ApplyType:
+		if ae := e.ApplyEvent; ae.Type == event.ApplyEventResourceUpdate {
+			r := m.resource(ae.Identifier, event.ApplyAction)
+			op := ae.Operation
+			r.applyOp = &op
+			r.err = ae.Error
+		}
+	case event.StatusType:
+		if se := e.StatusEvent; se.Type == event.StatusEventResourceUpdate && se.Resource != nil {
+			// Status is only shown for resources we know about.
+			if r, found := m.resources[se.Resource.Identifier]; found {
+				r.resourceStatus = se.Resource
+			}
+		}
+	case event.PruneType:
+		pruneEvent := e.PruneEvent
+		switch pruneEvent.Type {
+		case event.PruneEventResourceUpdate:
+			r := m.resource(pruneEvent.Identifier, event.PruneAction)
+			op := pruneEvent.Operation
+			r.pruneOp = &op
+		case event.PruneEventFailed:
+			m.resource(pruneEvent.Identifier, event.PruneAction).err = pruneEvent.Error
+		}
+	case event.DeleteType:
+		de := e.DeleteEvent
+		id := de.Identifier
+		if id.Name == "" && de.Object != nil {
+			id = object.UnstructuredToObjMeta(de.Object)
+		}
+		switch de.Type {
+		case event.DeleteEventResourceUpdate:
+			r := m.resource(id, event.PruneAction)
+			op := de.Operation
+			r.deleteOp = &op
+		case event.DeleteEventFailed:
+			// The operation is set so the resource is shown in the
+			// delete phase. The result will be shown as Failed.
+			r := m.resource(id, event.PruneAction)
+			op := event.Deleted
+			r.deleteOp = &op
+			r.err = de.Error
+		}
+	case event.ErrorType:
+		m.err = e.ErrorEvent.Err
+		if timeoutErr, ok := taskrunner.IsTimeoutError(e.ErrorEvent.Err); ok {
+			for _, tr := range timeoutErr.TimedOutResources {
+				if r, found := m.resources[tr.Identifier]; found {
+					r.timedOut = true
+				}
+			}
+		}
+	}
+}
+
+// visible returns the resources that match the filter, ordered by
+// phase and then by identifier.
+func (m *model) visible() []*resourceState {
+	var res []*resourceState
+	for _, r := range m.resources {
+		switch m.filter {
+		case filterFailed:
+			if !r.failed() {
+				continue
+			}
+		case filterInProgress:
+			if !r.inProgress() {
+				continue
+			}
+		}
+		res = append(res, r)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if pi, pj := res[i].phase(), res[j].phase(); pi != pj {
+			return pi < pj
+		}
+		return lessIdentifier(res[i].identifier, res[j].identifier)
+	})
+	return res
+}
+
+func lessIdentifier(i, j object.ObjMetadata) bool {
+	if i.Namespace != j.Namespace {
+		return i.Namespace < j.Namespace
+	}
+	if i.GroupKind.Group != j.GroupKind.Group {
+		return i.GroupKind.Group < j.GroupKind.Group
+	}
+	if i.GroupKind.Kind != j.GroupKind.Kind {
+		return i.GroupKind.Kind < j.GroupKind.Kind
+	}
+	return i.Name < j.Name
+}
+
+// handleKey updates the state based on the key. The returned action
+// tells the caller whether the UI should exit.
+func (m *model) handleKey(k key) action {
+	visible := m.visible()
+	switch k {
+	case keyUp:
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case keyDown:
+		if m.cursor < len(visible)-1 {
+			m.cursor++
+		}
+	case keyToggle:
+		if m.cursor < len(visible) {
+			r := visible[m.cursor]
+			r.expanded = !r.expanded
+		}
+	case keyFilterAll:
+		m.setFilter(filterAll)
+	case keyFilterFailed:
+		m.setFilter(filterFailed)
+	case keyFilterInProgress:
+		m.setFilter(filterInProgress)
+	case keyQuit:
+		// We only allow quitting once all events have been processed,
+		// otherwise the final result would never be shown.
+		if m.done {
+			return actionQuit
+		}
+	case keyInterrupt:
+		return actionInterrupt
+	}
+	return actionNone
+}
+
+func (m *model) setFilter(f filter) {
+	m.filter = f
+	m.cursor = 0
+}
+
+// counts returns the number of resources that are done, failed and
+// in progress.
+func (m *model) counts() (done, failed, inProgress int) {
+	for _, r := range m.resources {
+		switch {
+		case r.failed():
+			failed++
+		case r.inProgress():
+			inProgress++
+		default:
+			done++
+		}
+	}
+	return done, failed, inProgress
+}
+
+// header returns the summary line with the counts and elapsed time.
+func (m *model) header(now time.Time) string {
+	title := "kapply"
+	if m.preview {
+		title += " (preview)"
+	}
+	done, failed, inProgress := m.counts()
+	elapsed := now.Sub(m.start).Round(time.Second)
+	line := fmt.Sprintf("%s  elapsed %s  resources %d  done %d  failed %d  in progress %d",
+		title, elapsed, len(m.resources), done, failed, inProgress)
+	switch {
+	case m.done && m.err != nil:
+		line += fmt.Sprintf("  ERROR: %s", m.err.Error())
+	case m.done:
+		line += "  COMPLETED"
+	}
+	return line
+}
+
+// stageProgress returns the line with the number of resources that
+// are done in each stage, or an empty string if the resources are not
+// applied in stages.
+func (m *model) stageProgress() string {
+	if len(m.stages) == 0 {
+		return ""
+	}
+	line := "stages"
+	for _, name := range m.stages {
+		var total, done, failed int
+		for _, r := range m.resources {
+			if r.stage != name {
+				continue
+			}
+			total++
+			switch {
+			case r.failed():
+				failed++
+			case !r.inProgress():
+				done++
+			}
+		}
+		line += fmt.Sprintf("  %s %d/%d done", name, done, total)
+		if failed > 0 {
+			line += fmt.Sprintf(" (%d failed)", failed)
+		}
+	}
+	return line
+}
+
+// render returns the lines that should be shown in a terminal with
+// the given size.
+func (m *model) render(width, height int, now time.Time) []string {
+	lines := []string{
+		m.header(now),
+	}
+	if stages := m.stageProgress(); stages != "" {
+		lines = append(lines, stages)
+	}
+	lines = append(lines,
+		m.help(),
+		"",
+	)
+
+	visible := m.visible()
+	if m.cursor >= len(visible) && len(visible) > 0 {
+		m.cursor = len(visible) - 1
+	}
+
+	var body []string
+	cursorLine := 0
+	var currentPhase phase = -1
+	for i, r := range visible {
+		if p := r.phase(); p != currentPhase {
+			currentPhase = p
+			body = append(body, fmt.Sprintf("%s (%d)", phaseNames[p], countPhase(visible, p)))
+		}
+		if i == m.cursor {
+			cursorLine = len(body)
+		}
+		body = append(body, m.renderResource(r, i == m.cursor)...)
+	}
+	if len(visible) == 0 {
+		body = append(body, fmt.Sprintf("no resources matching the filter %q", filterNames[m.filter]))
+	}
+
+	// Only show the part of the body that fits on the screen, making
+	// sure the selected resource is always visible.
+	available := height - len(lines)
+	if available < 1 {
+		available = 1
+	}
+	offset := 0
+	if cursorLine >= available {
+		offset = cursorLine - available + 1
+	}
+	end := offset + available
+	if end > len(body) {
+		end = len(body)
+	}
+	lines = append(lines, body[offset:end]...)
+
+	for i := range lines {
+		lines[i] = truncate(lines[i], width)
+	}
+	return lines
+}
+
+func (m *model) help() string {
+	help := "[up/down] select  [enter] expand  [a] all  [f] failed  [p] in progress  "
+	if m.keepOpen {
+		help += "[q] quit when done  "
+	}
+	return help + fmt.Sprintf("filter: %s", filterNames[m.filter])
+}
+
+func countPhase(resources []*resourceState, p phase) int {
+	count := 0
+	for _, r := range resources {
+		if r.phase() == p {
+			count++
+		}
+	}
+	return count
+}
+
+// renderResource returns the line for the resource, followed by the
+// details if the resource is expanded.
+func (m *model) renderResource(r *resourceState, selected bool) []string {
+	cursor := "  "
+	if selected {
+		cursor = "> "
+	}
+	expand := "+"
+	if r.expanded {
+		expand = "-"
+	}
+	st := "-"
+	if r.resourceStatus != nil {
+		st = r.resourceStatus.Status.String()
+	}
+	if r.timedOut {
+		st += " (timeout)"
+	}
+	lines := []string{
+		fmt.Sprintf("%s%s %-50s %-12s %s", cursor, expand, resourceName(r.identifier), r.result(), st),
+	}
+	if !r.expanded {
+		return lines
+	}
+
+	const indent = "      "
+	if r.resourceStatus != nil {
+		if r.resourceStatus.Message != "" {
+			lines = append(lines, indent+"message: "+r.resourceStatus.Message)
+		}
+		if r.resourceStatus.Error != nil {
+			lines = append(lines, indent+"status error: "+r.resourceStatus.Error.Error())
+		}
+	}
+	if r.err != nil {
+		lines = append(lines, indent+"error: "+r.err.Error())
+	}
+	if r.resourceStatus != nil && len(r.resourceStatus.GeneratedResources) > 0 {
+		lines = append(lines, indent+"generated resources:")
+		lines = append(lines, renderGenerated(r.resourceStatus.GeneratedResources, indent+"  ")...)
+	}
+	if len(lines) == 1 {
+		lines = append(lines, indent+"no details available")
+	}
+	return lines
+}
+
+func renderGenerated(resources pe.ResourceStatuses, prefix string) []string {
+	var lines []string
+	for i, rs := range resources {
+		branch, next := `├─ `, `│  `
+		if i == len(resources)-1 {
+			branch, next = `└─ `, "   "
+		}
+		line := fmt.Sprintf("%s%s%s %s", prefix, branch, resourceName(rs.Identifier), rs.Status)
+		if rs.Message != "" {
+			line += ": " + rs.Message
+		}
+		lines = append(lines, line)
+		lines = append(lines, renderGenerated(rs.GeneratedResources, prefix+next)...)
+	}
+	return lines
+}
+
+func resourceName(id object.ObjMetadata) string {
+	name := fmt.Sprintf("%s/%s", id.GroupKind.Kind, id.Name)
+	if id.Namespace != "" {
+		name = fmt.Sprintf("%s/%s", id.Namespace, name)
+	}
+	return name
+}
+
+// summary returns the lines printed once the UI has exited. It
+// contains the header and all failed resources, so the result is
+// still available after the screen has been restored.
+func (m *model) summary(now time.Time) []string {
+	lines := []string{m.header(now)}
+	f := m.filter
+	m.filter = filterFailed
+	defer func() { m.filter = f }()
+	for _, r := range m.visible() {
+		line := fmt.Sprintf("  %s %s", resourceName(r.identifier), r.result())
+		switch {
+		case r.err != nil:
+			line += ": " + r.err.Error()
+		case r.timedOut:
+			line += ": timed out"
+		case r.resourceStatus != nil:
+			line += fmt.Sprintf(": %s %s", r.resourceStatus.Status, r.resourceStatus.Message)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// truncate makes sure the line is no wider than the given width.
+func truncate(s string, width int) string {
+	if width <= 0 || utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	return string([]rune(s)[:width])
+}
@@ -0,0 +1,318 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package interactive
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
+	pe "sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+var (
+	depID = createIdentifier("apps", "Deployment", "default", "dep")
+	rsID  = createIdentifier("apps", "ReplicaSet", "default", "dep-123")
+	cmID  = createIdentifier("", "ConfigMap", "default", "cm")
+	svcID = createIdentifier("", "Service", "default", "svc")
+	oldID = createIdentifier("", "Secret", "default", "old")
+)
+
+func createIdentifier(group, kind, namespace, name string) object.ObjMetadata {
+	return object.ObjMetadata{
+		Namespace: namespace,
+		Name:      name,
+		GroupKind: schema.GroupKind{
+			Group: group,
+			Kind:  kind,
+		},
+	}
+}
+
+// testEvents returns events for a Deployment that is still in progress,
+// a ConfigMap that failed to apply, a Service that hasn't been applied
+// yet and a Secret that has been pruned.
+func testEvents() []event.Event {
+	created := event.Created
+	return []event.Event{
+		{
+			Type: event.InitType,
+			InitEvent: event.InitEvent{
+				ResourceGroups: []event.ResourceGroup{
+					{
+						Action:      event.ApplyAction,
+						Identifiers: []object.ObjMetadata{depID, cmID, svcID},
+					},
+					{
+						Action:      event.PruneAction,
+						Identifiers: []object.ObjMetadata{oldID},
+					},
+				},
+			},
+		},
+		{
+			Type: event.ApplyType,
+			ApplyEvent: event.ApplyEvent{
+				Type:       event.ApplyEventResourceUpdate,
+				Operation:  created,
+				Identifier: depID,
+			},
+		},
+		{
+			Type: event.ApplyType,
+			ApplyEvent: event.ApplyEvent{
+				Type:       event.ApplyEventResourceUpdate,
+				Operation:  event.Failed,
+				Identifier: cmID,
+				Error:      fmt.Errorf("forbidden"),
+			},
+		},
+		{
+			Type: event.StatusType,
+			StatusEvent: event.StatusEvent{
+				Type: event.StatusEventResourceUpdate,
+				Resource: &pe.ResourceStatus{
+					Identifier: depID,
+					Status:     status.InProgressStatus,
+					Message:    "Replicas: 1/2",
+					GeneratedResources: pe.ResourceStatuses{
+						{
+							Identifier: rsID,
+							Status:     status.InProgressStatus,
+							Message:    "Ready: 1/2",
+						},
+					},
+				},
+			},
+		},
+		{
+			Type: event.PruneType,
+			PruneEvent: event.PruneEvent{
+				Type:       event.PruneEventResourceUpdate,
+				Operation:  event.Pruned,
+				Identifier: oldID,
+			},
+		},
+	}
+}
+
+func newTestModel() *model {
+	m := newModel(false, time.Time{})
+	for _, e := range testEvents() {
+		m.processEvent(e)
+	}
+	return m
+}
+
+func visibleNames(m *model) []string {
+	var names []string
+	for _, r := range m.visible() {
+		names = append(names, resourceName(r.identifier))
+	}
+	return names
+}
+
+func TestModel_Filter(t *testing.T) {
+	testCases := map[string]struct {
+		keys          []key
+		expectedNames []string
+	}{
+		"all resources grouped by phase": {
+			keys: []key{keyFilterAll},
+			expectedNames: []string{
+				"default/ConfigMap/cm",
+				"default/Service/svc",
+				"default/Deployment/dep",
+				"default/Secret/old",
+			},
+		},
+		"failed": {
+			keys: []key{keyFilterFailed},
+			expectedNames: []string{
+				"default/ConfigMap/cm",
+			},
+		},
+		"in progress": {
+			keys: []key{keyFilterInProgress},
+			expectedNames: []string{
+				"default/Service/svc",
+				"default/Deployment/dep",
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			m := newTestModel()
+			for _, k := range tc.keys {
+				m.handleKey(k)
+			}
+			assert.Equal(t, tc.expectedNames, visibleNames(m))
+		})
+	}
+}
+
+func TestModel_Counts(t *testing.T) {
+	m := newTestModel()
+	done, failed, inProgress := m.counts()
+	assert.Equal(t, 1, done)
+	assert.Equal(t, 1, failed)
+	assert.Equal(t, 2, inProgress)
+
+	m.processEvent(event.Event{
+		Type: event.ErrorType,
+		ErrorEvent: event.ErrorEvent{
+			Err: &taskrunner.TimeoutError{
+				TimedOutResources: []taskrunner.TimedOutResource{
+					{
+						Identifier: depID,
+						Status:     status.InProgressStatus,
+					},
+				},
+			},
+		},
+	})
+	done, failed, inProgress = m.counts()
+	assert.Equal(t, 1, done)
+	assert.Equal(t, 2, failed)
+	assert.Equal(t, 1, inProgress)
+}
+
+func TestModel_HandleKey(t *testing.T) {
+	testCases := map[string]struct {
+		done           bool
+		keys           []key
+		expectedCursor int
+		expectedAction action
+	}{
+		"move down and up": {
+			keys:           []key{keyDown, keyDown, keyUp},
+			expectedCursor: 1,
+			expectedAction: actionNone,
+		},
+		"cursor stays within the list": {
+			keys:           []key{keyUp, keyDown, keyDown, keyDown, keyDown, keyDown},
+			expectedCursor: 3,
+			expectedAction: actionNone,
+		},
+		"changing filter resets cursor": {
+			keys:           []key{keyDown, keyFilterInProgress},
+			expectedCursor: 0,
+			expectedAction: actionNone,
+		},
+		"quit is ignored until done": {
+			keys:           []key{keyQuit},
+			expectedAction: actionNone,
+		},
+		"quit when done": {
+			done:           true,
+			keys:           []key{keyQuit},
+			expectedAction: actionQuit,
+		},
+		"interrupt": {
+			keys:           []key{keyInterrupt},
+			expectedAction: actionInterrupt,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			m := newTestModel()
+			m.done = tc.done
+			var a action
+			for _, k := range tc.keys {
+				a = m.handleKey(k)
+			}
+			assert.Equal(t, tc.expectedCursor, m.cursor)
+			assert.Equal(t, tc.expectedAction, a)
+		})
+	}
+}
+
+func TestModel_Render(t *testing.T) {
+	m := newTestModel()
+	now := m.start.Add(5 * time.Second)
+
+	lines := m.render(200, 50, now)
+	output := strings.Join(lines, "\n")
+	assert.Contains(t, lines[0], "elapsed 5s  resources 4  done 1  failed 1  in progress 2")
+	assert.Contains(t, output, "APPLY (2)")
+	assert.Contains(t, output, "WAIT (1)")
+	assert.Contains(t, output, "PRUNE (1)")
+	assert.NotContains(t, output, "Replicas: 1/2")
+	assert.NotContains(t, output, "[q] quit")
+
+	// Quitting is only possible if the UI is kept open.
+	m.keepOpen = true
+	assert.Contains(t, strings.Join(m.render(200, 50, now), "\n"), "[q] quit when done")
+	m.keepOpen = false
+
+	// Expand the Deployment to show the message and the
+	// generated resources.
+	m.handleKey(keyDown)
+	m.handleKey(keyDown)
+	m.handleKey(keyToggle)
+	lines = m.render(200, 50, now)
+	output = strings.Join(lines, "\n")
+	assert.Contains(t, output, "message: Replicas: 1/2")
+	assert.Contains(t, output, "└─ default/ReplicaSet/dep-123 InProgress: Ready: 1/2")
+
+	// Lines are truncated to the width of the terminal.
+	for _, line := range m.render(20, 50, now) {
+		assert.True(t, len([]rune(line)) <= 20, "line %q is too long", line)
+	}
+
+	// Only the lines that fit are shown, with the selected resource
+	// still visible.
+	lines = m.render(200, 5, now)
+	assert.Len(t, lines, 5)
+	assert.Contains(t, strings.Join(lines, "\n"), "> - default/Deployment/dep")
+}
+
+func TestModel_StageProgress(t *testing.T) {
+	m := newTestModel()
+	assert.Equal(t, "", m.stageProgress())
+
+	m = newModel(false, time.Time{})
+	events := testEvents()
+	events[0].InitEvent.ResourceGroups = []event.ResourceGroup{
+		{
+			Action:      event.ApplyAction,
+			Stage:       "canary",
+			Identifiers: []object.ObjMetadata{cmID},
+		},
+		{
+			Action:      event.ApplyAction,
+			Stage:       "web",
+			Identifiers: []object.ObjMetadata{depID},
+		},
+		{
+			Action:      event.ApplyAction,
+			Identifiers: []object.ObjMetadata{svcID},
+		},
+	}
+	for _, e := range events {
+		m.processEvent(e)
+	}
+	assert.Equal(t, "stages  canary 0/1 done (1 failed)  web 0/1 done", m.stageProgress())
+	lines := m.render(200, 50, m.start)
+	assert.Equal(t, m.stageProgress(), lines[1])
+}
+
+func TestModel_Summary(t *testing.T) {
+	m := newTestModel()
+	m.done = true
+	lines := m.summary(m.start)
+	assert.Equal(t, []string{
+		"kapply  elapsed 0s  resources 4  done 1  failed 1  in progress 2  COMPLETED",
+		"  default/ConfigMap/cm Failed: forbidden",
+	}, lines)
+}
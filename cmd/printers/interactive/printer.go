@@ -0,0 +1,205 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package interactive provides a printer that shows the progress of
+// apply in an interactive terminal UI. Resources are grouped by the
+// phase they are in, and the list can be filtered to only show failed
+// or in progress resources. Each resource can be expanded to show the
+// status message, errors and any generated resources.
+//
+// The UI is only used if both stdin and stdout are terminals. If not,
+// the printer falls back to the table printer.
+package interactive
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/kubectl/pkg/util/term"
+	"sigs.k8s.io/cli-utils/cmd/printers/table"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	printcommon "sigs.k8s.io/cli-utils/pkg/print/common"
+)
+
+const (
+	defaultWidth  = 80
+	defaultHeight = 24
+
+	refreshInterval = 500 * time.Millisecond
+	// pollInterval is how often the key reader checks whether it
+	// should stop while waiting for input.
+	pollInterval = 50 * time.Millisecond
+)
+
+// errInterrupted is returned if the user interrupts the UI with ctrl-c.
+// Since the terminal is in raw mode, it doesn't generate a signal.
+var errInterrupted = errors.New("interrupted")
+
+type Printer struct {
+	IOStreams genericclioptions.IOStreams
+	// KeepOpen keeps the UI open after all events have been processed,
+	// until the user quits. By default, the UI exits once the command
+	// is done.
+	KeepOpen bool
+}
+
+func (p *Printer) Print(ch <-chan event.Event, previewStrategy common.DryRunStrategy) error {
+	tty := term.TTY{
+		In:  p.IOStreams.In,
+		Out: p.IOStreams.Out,
+		Raw: true,
+	}
+	if !tty.IsTerminalIn() || !tty.IsTerminalOut() {
+		tp := &table.Printer{
+			IOStreams: p.IOStreams,
+		}
+		return tp.Print(ch, previewStrategy)
+	}
+
+	m := newModel(previewStrategy.ClientOrServerDryRun(), time.Now())
+	m.keepOpen = p.KeepOpen
+	var err error
+	if safeErr := tty.Safe(func() error {
+		err = p.run(ch, m, tty)
+		return nil
+	}); safeErr != nil {
+		return safeErr
+	}
+	// The terminal is no longer in raw mode, so we print the summary
+	// with regular line endings.
+	for _, line := range m.summary(time.Now()) {
+		if _, printErr := fmt.Fprintln(p.IOStreams.Out, line); printErr != nil {
+			return printErr
+		}
+	}
+	return err
+}
+
+// run shows the UI until all events have been processed, and the user
+// quits if the UI is kept open. It returns the fatal error from the
+// event stream, if any.
+func (p *Printer) run(ch <-chan event.Event, m *model, tty term.TTY) error {
+	p.printf("%c[?1049h%c[?25l", printcommon.ESC, printcommon.ESC)
+	defer p.printf("%c[?25h%c[?1049l", printcommon.ESC, printcommon.ESC)
+
+	stop := make(chan struct{})
+	keys := readKeys(p.IOStreams.In, stop)
+	// Stop reading keys before the terminal is restored, so the input
+	// that follows goes to the shell.
+	defer stopKeys(stop, keys)
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+
+	p.draw(m, tty)
+	for {
+		select {
+		case e, ok := <-ch:
+			if !ok {
+				m.done = true
+				if !p.KeepOpen {
+					return m.err
+				}
+				// Setting the channel to nil means we will no longer
+				// select on it.
+				ch = nil
+				break
+			}
+			m.processEvent(e)
+			continue
+		case <-ticker.C:
+		case ks, ok := <-keys:
+			if !ok {
+				// If we can no longer read the input, there is no
+				// way for the user to quit, so we exit when done.
+				keys = nil
+				if m.done {
+					return m.err
+				}
+				break
+			}
+			for _, k := range ks {
+				switch m.handleKey(k) {
+				case actionQuit:
+					return m.err
+				case actionInterrupt:
+					return errInterrupted
+				}
+			}
+		}
+		if keys == nil && m.done {
+			return m.err
+		}
+		p.draw(m, tty)
+	}
+}
+
+// draw redraws the full screen. The terminal is in raw mode, so lines
+// must end with both carriage return and newline.
+func (p *Printer) draw(m *model, tty term.TTY) {
+	width, height := defaultWidth, defaultHeight
+	if size := tty.GetSize(); size != nil && size.Width > 0 && size.Height > 0 {
+		width, height = int(size.Width), int(size.Height)
+	}
+	lines := m.render(width, height, time.Now())
+	p.printf("%c[H%c[2J%s", printcommon.ESC, printcommon.ESC, strings.Join(lines, "\r\n"))
+}
+
+func (p *Printer) printf(format string, a ...interface{}) {
+	// There isn't much we can do if writing to the terminal fails, and
+	// the error will be visible to the user anyway.
+	_, _ = fmt.Fprintf(p.IOStreams.Out, format, a...)
+}
+
+// readKeys starts a goroutine that reads key presses from the
+// provided reader. The returned channel is closed if reading fails or
+// once the stop channel is closed.
+func readKeys(r io.Reader, stop <-chan struct{}) <-chan []key {
+	keys := make(chan []key)
+	go func() {
+		defer close(keys)
+		buf := make([]byte, 32)
+		for {
+			ready, err := waitReadable(r, pollInterval)
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			if err != nil {
+				return
+			}
+			if !ready {
+				continue
+			}
+			n, err := r.Read(buf)
+			if n > 0 {
+				if ks := parseKeys(buf[:n]); len(ks) > 0 {
+					select {
+					case keys <- ks:
+					case <-stop:
+						return
+					}
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return keys
+}
+
+// stopKeys stops the goroutine started by readKeys and waits for it
+// to exit.
+func stopKeys(stop chan struct{}, keys <-chan []key) {
+	close(stop)
+	for range keys {
+		// Discard the keys read before the goroutine noticed it
+		// should stop.
+	}
+}
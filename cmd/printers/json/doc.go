@@ -8,7 +8,7 @@
 // Every event will contain the following properties:
 //  * timestamp: RFC3339-formatted timestamp describing when the event happened.
 //  * type: Describes the type of the operation which the event is related to. Values
-//    can be apply, status, prune, delete, or error.
+//    can be apply, status, prune, delete, drift, rollback or error.
 //  * eventType: Describes the type of the event. The set of possible values depends on the
 //    the value of the type field.
 //
@@ -19,22 +19,31 @@
 // pertains to a particular resource, the fields group, kind, name and namespace
 // will always be present.
 //
-// Events of type apply can have two different values for eventType, each which comes
+// Events of type apply can have three different values for eventType, each which comes
 // with a specific set of fields:
 //  * resourceApplied: A resource has been applied to the cluster.
 //    * fields identifying the resource.
 //    * operation: The operation that was performed on the resource. Must be one of
-//      created, configured, unchanged and serversideApplied.
+//      created, configured, unchanged, serversideApplied and recreated.
+//  * resourceRetry: Applying a resource failed with a transient error and will be
+//    retried. Only printed if a retry policy has been set.
+//    * fields identifying the resource.
+//    * attempt: The number of the attempt that failed, starting at 1.
+//    * maxAttempts: The maximum number of attempts.
+//    * delaySeconds: How long until the next attempt.
+//    * error: The error from the failed attempt.
 //  * completed: All resources have been applied.
 //    * count: Total number of resources applied
 //    * createdCount: Number of resources created.
 //    * configuredCount: Number of resources configured.
 //    * unchangedCount: Number of resources unchanged.
 //    * serversideAppliedCount: Number of resources applied serverside.
+//    * recreatedCount: Number of resources deleted and created again because
+//      of changes to immutable fields.
 //
 // Events of type status is a notification when either the status of resource
 // has changed, or when a set of resources has reached their desired status. Events
-// of type status can have three different values for eventType:
+// of type status can have four different values for eventType:
 //  * resourceStatus: The status has changed for a resource.
 //    * fields identifying the resource.
 //    * status: The new status for the resource.
@@ -43,8 +52,20 @@
 //  * error: An error occurred when trying to get the status for a resource.
 //    * fields identifying the resource.
 //    * error: The error message.
+//  * summary: How long it took for resources to become Current. Only printed
+//    at the end if the status summary has been enabled.
+//    * durationSeconds: The total duration of the operation.
+//    * resources: A list of resources, sorted with the slowest first. Each
+//      entry has the following fields:
+//      * fields identifying the resource.
+//      * current: Whether the resource became Current.
+//      * status: The latest status for the resource.
+//      * durationSeconds: The time it took for the resource to become Current, or
+//        the total duration of the operation if it never became Current.
+//      * transitions: A list of all status changes for the resource, each with
+//        the fields status, message and timestamp.
 //
-// Events of type prune can have two different values for eventType, each which comes
+// Events of type prune can have three different values for eventType, each which comes
 // with a specific set of fields:
 //  * resourcePruned: A resource has been pruned or was intended to be pruned but has been
 //    skipped due to the presence of a lifecycle directive.
@@ -55,6 +76,8 @@
 //    * count: Total number of resources pruned or skipped.
 //    * prunedCount: Number of resources pruned.
 //    * skippedCount: Number of resources skipped.
+//  * resourceRetry: Pruning a resource failed with a transient error and will be
+//    retried. It has the same fields as the resourceRetry event for apply.
 //
 // Events of type delete can have two different values for eventType, each which comes
 // with a specific set of fields:
@@ -68,8 +91,79 @@
 //    * deletedCount: Number of resources deleted.
 //    * skippedCount: Number of resources skipped.
 //
+// Events of type drift are printed by the drift command. They can have two
+// different values for eventType, each which comes with a specific set of fields:
+//  * resourceDrift: A resource has been checked for drift.
+//    * fields identifying the resource.
+//    * operation: The result of the check. Must be one of inSync,
+//      deletedOutOfBand, fieldsChanged, inventoryChanged and notInInventory.
+//    * details: A list of messages describing the drift, like the fields
+//      changed by other field managers. Only present if there are details.
+//  * completed: All resources have been checked.
+//    * count: Total number of resources checked.
+//    * inSyncCount: Number of resources that have not drifted.
+//    * driftedCount: Number of resources that have drifted.
+//    * deletedOutOfBandCount, fieldsChangedCount, inventoryChangedCount and
+//      notInInventoryCount: Number of resources for each kind of drift.
+//
+// Events of type rollback are printed by the apply command with the
+// --atomic flag, when the apply has failed and the changes are rolled
+// back. They can have three different values for eventType:
+//  * resourceRolledBack: A resource has been rolled back.
+//    * fields identifying the resource.
+//    * operation: The operation that was performed on the resource. Must be
+//      one of restored or deleted.
+//  * resourceFailed: A resource, or the inventory, could not be rolled back.
+//    * fields identifying the resource. They are empty for the inventory.
+//    * error: The error message.
+//  * completed: The rollback has finished.
+//    * restoredCount: Number of resources restored to their previous state.
+//    * deletedCount: Number of resources created by the apply that have
+//      been deleted.
+//    * failedCount: Number of resources that could not be rolled back.
+//
 // Events of type error means there is an unrecoverable error and further
 // processing will stop. Only a single value for eventType is possible:
 //  * error: A fatal error has happened.
 //    * error: The error message.
+//
+// Summary file
+//
+// The apply, preview, destroy and status commands can write a single
+// summary document once they have finished, by setting the --summary-file
+// flag. The document is written as YAML if the file has a .yaml or .yml
+// extension, and as JSON otherwise. The schema is versioned through the
+// apiVersion field, which will change if backwards incompatible changes
+// are made. The current version is cli-utils.sigs.k8s.io/v1alpha1, and
+// the document has the following fields:
+//  * apiVersion: The version of the schema.
+//  * kind: Always Summary.
+//  * command: The command that wrote the summary, one of apply, preview,
+//    destroy or status.
+//  * inventory: The inventory used by the command, with the fields name,
+//    namespace and id.
+//  * startTime: RFC3339-formatted timestamp describing when the command started.
+//  * durationSeconds: The total duration of the command.
+//  * exitCode: The exit code for the command.
+//  * reason: The reason for the exit code. Must be one of Success, Timeout,
+//    ResourcesFailed or Error.
+//  * error: The error message, if the command failed.
+//  * counts: The number of resources for each operation.
+//    * apply: The fields created, configured, unchanged, serversideApplied,
+//      recreated and failed.
+//    * prune: The fields pruned, skipped and failed.
+//    * delete: The fields deleted, skipped and failed.
+//  * resources: A list of resources, in the order they were first seen.
+//    Each entry has the following fields:
+//    * fields identifying the resource.
+//    * action: The last action performed on the resource. Must be one of
+//      apply, prune, delete or status.
+//    * operation: The result of the action, like Created or Pruned.
+//    * error: The error message, if the action failed.
+//    * status: The final status of the resource.
+//    * message: Text that provides more information about the final status.
+//    * current: Whether the resource became Current.
+//    * durationSeconds: The time it took for the resource to become Current, or
+//      the total duration if it never became Current. Only present if status
+//      was computed for the resource.
 package json
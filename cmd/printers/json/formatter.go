@@ -6,11 +6,13 @@ package json
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
 	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/collector"
 	"sigs.k8s.io/cli-utils/pkg/object"
 	"sigs.k8s.io/cli-utils/pkg/print/list"
 )
@@ -37,6 +39,7 @@ func (jf *formatter) FormatApplyEvent(ae event.ApplyEvent, as *list.ApplyStats,
 			"unchangedCount":  as.Unchanged,
 			"configuredCount": as.Configured,
 			"serverSideCount": as.ServersideApplied,
+			"recreatedCount":  as.Recreated,
 			"failedCount":     as.Failed,
 		}); err != nil {
 			return err
@@ -61,6 +64,8 @@ func (jf *formatter) FormatApplyEvent(ae event.ApplyEvent, as *list.ApplyStats,
 		}
 
 		return jf.printEvent("apply", "resourceApplied", eventInfo)
+	case event.ApplyEventRetry:
+		return jf.printEvent("apply", "resourceRetry", retryEventInfo(ae.Identifier, ae.Retry, ae.Error))
 	}
 	return nil
 }
@@ -110,10 +115,27 @@ func (jf *formatter) FormatPruneEvent(pe event.PruneEvent, ps *list.PruneStats)
 			"name":      pe.Identifier.Name,
 			"error":     pe.Error.Error(),
 		})
+	case event.PruneEventRetry:
+		return jf.printEvent("prune", "resourceRetry", retryEventInfo(pe.Identifier, pe.Retry, pe.Error))
 	}
 	return nil
 }
 
+// retryEventInfo returns the fields for an event about a failed attempt
+// that will be retried.
+func retryEventInfo(id object.ObjMetadata, r event.Retry, err error) map[string]interface{} {
+	return map[string]interface{}{
+		"group":        id.GroupKind.Group,
+		"kind":         id.GroupKind.Kind,
+		"namespace":    id.Namespace,
+		"name":         id.Name,
+		"attempt":      r.Attempt,
+		"maxAttempts":  r.MaxAttempts,
+		"delaySeconds": r.Delay.Seconds(),
+		"error":        err.Error(),
+	}
+}
+
 func (jf *formatter) FormatDeleteEvent(de event.DeleteEvent, ds *list.DeleteStats) error {
 	switch de.Type {
 	case event.DeleteEventCompleted:
@@ -143,12 +165,119 @@ func (jf *formatter) FormatDeleteEvent(de event.DeleteEvent, ds *list.DeleteStat
 	return nil
 }
 
+func (jf *formatter) FormatDriftEvent(de event.DriftEvent, ds *list.DriftStats) error {
+	switch de.Type {
+	case event.DriftEventCompleted:
+		return jf.printEvent("drift", "completed", map[string]interface{}{
+			"count":                 ds.InSync + ds.Drifted(),
+			"inSyncCount":           ds.InSync,
+			"driftedCount":          ds.Drifted(),
+			"deletedOutOfBandCount": ds.DeletedOutOfBand,
+			"fieldsChangedCount":    ds.FieldsChanged,
+			"inventoryChangedCount": ds.InventoryChanged,
+			"notInInventoryCount":   ds.NotInInventory,
+		})
+	case event.DriftEventResourceUpdate:
+		gk := de.Identifier.GroupKind
+		eventInfo := map[string]interface{}{
+			"group":     gk.Group,
+			"kind":      gk.Kind,
+			"namespace": de.Identifier.Namespace,
+			"name":      de.Identifier.Name,
+			"operation": driftOperation(de.Operation),
+		}
+		if len(de.Details) > 0 {
+			eventInfo["details"] = de.Details
+		}
+		return jf.printEvent("drift", "resourceDrift", eventInfo)
+	}
+	return nil
+}
+
+// driftOperation returns the name of the drift operation in the json
+// output, like inSync or deletedOutOfBand.
+func driftOperation(op event.DriftEventOperation) string {
+	s := op.String()
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+func (jf *formatter) FormatRollbackEvent(re event.RollbackEvent, rs *list.RollbackStats) error {
+	switch re.Type {
+	case event.RollbackEventCompleted:
+		return jf.printEvent("rollback", "completed", map[string]interface{}{
+			"restoredCount": rs.Restored,
+			"deletedCount":  rs.Deleted,
+			"failedCount":   rs.Failed,
+		})
+	case event.RollbackEventResourceUpdate:
+		gk := re.Identifier.GroupKind
+		return jf.printEvent("rollback", "resourceRolledBack", map[string]interface{}{
+			"group":     gk.Group,
+			"kind":      gk.Kind,
+			"namespace": re.Identifier.Namespace,
+			"name":      re.Identifier.Name,
+			"operation": rollbackOperation(re.Operation),
+		})
+	case event.RollbackEventFailed:
+		gk := re.Identifier.GroupKind
+		return jf.printEvent("rollback", "resourceFailed", map[string]interface{}{
+			"group":     gk.Group,
+			"kind":      gk.Kind,
+			"namespace": re.Identifier.Namespace,
+			"name":      re.Identifier.Name,
+			"error":     re.Error.Error(),
+		})
+	}
+	return nil
+}
+
+// rollbackOperation returns the name of the rollback operation in the
+// json output, which matches the counts in the completed event.
+func rollbackOperation(op event.RollbackEventOperation) string {
+	switch op {
+	case event.Restored:
+		return "restored"
+	case event.RollbackDeleted:
+		return "deleted"
+	default:
+		return op.String()
+	}
+}
+
 func (jf *formatter) FormatErrorEvent(ee event.ErrorEvent) error {
 	return jf.printEvent("error", "error", map[string]interface{}{
 		"error": ee.Err.Error(),
 	})
 }
 
+func (jf *formatter) FormatStatusSummary(ts collector.TimelineSummary) error {
+	resources := make([]interface{}, 0, len(ts.Resources))
+	for _, rd := range ts.Resources {
+		transitions := make([]interface{}, 0, len(rd.Timeline.Transitions))
+		for _, tr := range rd.Timeline.Transitions {
+			transitions = append(transitions, map[string]interface{}{
+				"status":    tr.Status.String(),
+				"message":   tr.Message,
+				"timestamp": tr.Time.UTC().Format(time.RFC3339),
+			})
+		}
+		resources = append(resources, map[string]interface{}{
+			"group":           rd.Identifier.GroupKind.Group,
+			"kind":            rd.Identifier.GroupKind.Kind,
+			"namespace":       rd.Identifier.Namespace,
+			"name":            rd.Identifier.Name,
+			"current":         rd.Current,
+			"status":          rd.Status.String(),
+			"durationSeconds": rd.Duration.Seconds(),
+			"transitions":     transitions,
+		})
+	}
+	return jf.printEvent("status", "summary", map[string]interface{}{
+		"durationSeconds": ts.End.Sub(ts.Start).Seconds(),
+		"resources":       resources,
+	})
+}
+
 func (jf *formatter) printEvent(t, eventType string, content map[string]interface{}) error {
 	m := make(map[string]interface{})
 	m["timestamp"] = time.Now().UTC().Format(time.RFC3339)
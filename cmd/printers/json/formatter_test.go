@@ -5,14 +5,17 @@ package json
 
 import (
 	"encoding/json"
+	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
 	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/collector"
 	pollevent "sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
 	"sigs.k8s.io/cli-utils/pkg/object"
@@ -67,6 +70,34 @@ func TestFormatter_FormatApplyEvent(t *testing.T) {
 				},
 			},
 		},
+		"retry event": {
+			previewStrategy: common.DryRunNone,
+			event: event.ApplyEvent{
+				Type:       event.ApplyEventRetry,
+				Identifier: createIdentifier("apps", "Deployment", "default", "my-dep"),
+				Error:      fmt.Errorf("too many requests"),
+				Retry: event.Retry{
+					Attempt:     1,
+					MaxAttempts: 3,
+					Delay:       2 * time.Second,
+				},
+			},
+			expected: []map[string]interface{}{
+				{
+					"eventType":    "resourceRetry",
+					"group":        "apps",
+					"kind":         "Deployment",
+					"name":         "my-dep",
+					"namespace":    "default",
+					"attempt":      1,
+					"maxAttempts":  3,
+					"delaySeconds": 2,
+					"error":        "too many requests",
+					"timestamp":    "",
+					"type":         "apply",
+				},
+			},
+		},
 		"resource updated with server dryrun": {
 			previewStrategy: common.DryRunServer,
 			event: event.ApplyEvent{
@@ -119,6 +150,7 @@ func TestFormatter_FormatApplyEvent(t *testing.T) {
 					"createdCount":    0,
 					"eventType":       "completed",
 					"failedCount":     0,
+					"recreatedCount":  0,
 					"serverSideCount": 1,
 					"type":            "apply",
 					"unchangedCount":  0,
@@ -208,6 +240,63 @@ func TestFormatter_FormatStatusEvent(t *testing.T) {
 	}
 }
 
+func TestFormatter_FormatStatusSummary(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	id := createIdentifier("apps", "Deployment", "default", "dep")
+	summary := collector.TimelineSummary{
+		Start: start,
+		End:   start.Add(30 * time.Second),
+		Resources: []collector.ResourceDuration{
+			{
+				Identifier: id,
+				Current:    true,
+				Duration:   12 * time.Second,
+				Status:     status.CurrentStatus,
+				Timeline: collector.Timeline{
+					Identifier: id,
+					Transitions: []collector.Transition{
+						{
+							Status:  status.CurrentStatus,
+							Message: "Deployment is available",
+							Time:    start.Add(12 * time.Second),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	ioStreams, _, out, _ := genericclioptions.NewTestIOStreams() //nolint:dogsled
+	formatter := NewFormatter(ioStreams, common.DryRunNone)
+	err := formatter.(list.StatusSummaryFormatter).FormatStatusSummary(summary)
+	assert.NoError(t, err)
+
+	assertOutput(t, map[string]interface{}{
+		"eventType":       "summary",
+		"type":            "status",
+		"timestamp":       "",
+		"durationSeconds": 30,
+		"resources": []interface{}{
+			map[string]interface{}{
+				"group":           "apps",
+				"kind":            "Deployment",
+				"namespace":       "default",
+				"name":            "dep",
+				"current":         true,
+				"status":          "Current",
+				"durationSeconds": float64(12),
+				"transitions": []interface{}{
+					map[string]interface{}{
+						"status":    "Current",
+						"message":   "Deployment is available",
+						"timestamp": "2020-01-01T00:00:12Z",
+					},
+				},
+			},
+		},
+	}, out.String())
+}
+
 func TestFormatter_FormatPruneEvent(t *testing.T) {
 	testCases := map[string]struct {
 		previewStrategy common.DryRunStrategy
@@ -357,6 +446,171 @@ func TestFormatter_FormatDeleteEvent(t *testing.T) {
 	}
 }
 
+func TestFormatter_FormatDriftEvent(t *testing.T) {
+	testCases := map[string]struct {
+		event      event.DriftEvent
+		driftStats *list.DriftStats
+		expected   map[string]interface{}
+	}{
+		"resource deleted out of band": {
+			event: event.DriftEvent{
+				Operation:  event.DeletedOutOfBand,
+				Type:       event.DriftEventResourceUpdate,
+				Identifier: createIdentifier("apps", "Deployment", "default", "my-dep"),
+			},
+			expected: map[string]interface{}{
+				"eventType": "resourceDrift",
+				"group":     "apps",
+				"kind":      "Deployment",
+				"name":      "my-dep",
+				"namespace": "default",
+				"operation": "deletedOutOfBand",
+				"timestamp": "",
+				"type":      "drift",
+			},
+		},
+		"owning inventory changed": {
+			event: event.DriftEvent{
+				Operation:  event.InventoryChanged,
+				Type:       event.DriftEventResourceUpdate,
+				Identifier: createIdentifier("apps", "Deployment", "default", "my-dep"),
+				Details:    []string{`owned by inventory "other"`},
+			},
+			expected: map[string]interface{}{
+				"details":   []interface{}{`owned by inventory "other"`},
+				"eventType": "resourceDrift",
+				"group":     "apps",
+				"kind":      "Deployment",
+				"name":      "my-dep",
+				"namespace": "default",
+				"operation": "inventoryChanged",
+				"timestamp": "",
+				"type":      "drift",
+			},
+		},
+		"drift event with completed status": {
+			event: event.DriftEvent{
+				Type: event.DriftEventCompleted,
+			},
+			driftStats: &list.DriftStats{
+				InSync:        2,
+				FieldsChanged: 1,
+			},
+			expected: map[string]interface{}{
+				"count":                 3,
+				"deletedOutOfBandCount": 0,
+				"driftedCount":          1,
+				"eventType":             "completed",
+				"fieldsChangedCount":    1,
+				"inSyncCount":           2,
+				"inventoryChangedCount": 0,
+				"notInInventoryCount":   0,
+				"timestamp":             "",
+				"type":                  "drift",
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			ioStreams, _, out, _ := genericclioptions.NewTestIOStreams() //nolint:dogsled
+			formatter := NewFormatter(ioStreams, common.DryRunNone)
+			err := formatter.(list.DriftFormatter).FormatDriftEvent(tc.event, tc.driftStats)
+			assert.NoError(t, err)
+
+			assertOutput(t, tc.expected, out.String())
+		})
+	}
+}
+
+func TestFormatter_FormatRollbackEvent(t *testing.T) {
+	testCases := map[string]struct {
+		event         event.RollbackEvent
+		rollbackStats *list.RollbackStats
+		expected      map[string]interface{}
+	}{
+		"resource restored": {
+			event: event.RollbackEvent{
+				Operation:  event.Restored,
+				Type:       event.RollbackEventResourceUpdate,
+				Identifier: createIdentifier("apps", "Deployment", "default", "my-dep"),
+			},
+			expected: map[string]interface{}{
+				"eventType": "resourceRolledBack",
+				"group":     "apps",
+				"kind":      "Deployment",
+				"name":      "my-dep",
+				"namespace": "default",
+				"operation": "restored",
+				"timestamp": "",
+				"type":      "rollback",
+			},
+		},
+		"resource deleted": {
+			event: event.RollbackEvent{
+				Operation:  event.RollbackDeleted,
+				Type:       event.RollbackEventResourceUpdate,
+				Identifier: createIdentifier("apps", "Deployment", "default", "my-dep"),
+			},
+			expected: map[string]interface{}{
+				"eventType": "resourceRolledBack",
+				"group":     "apps",
+				"kind":      "Deployment",
+				"name":      "my-dep",
+				"namespace": "default",
+				"operation": "deleted",
+				"timestamp": "",
+				"type":      "rollback",
+			},
+		},
+		"resource failed": {
+			event: event.RollbackEvent{
+				Type:       event.RollbackEventFailed,
+				Identifier: createIdentifier("apps", "Deployment", "default", "my-dep"),
+				Error:      fmt.Errorf("forbidden"),
+			},
+			expected: map[string]interface{}{
+				"error":     "forbidden",
+				"eventType": "resourceFailed",
+				"group":     "apps",
+				"kind":      "Deployment",
+				"name":      "my-dep",
+				"namespace": "default",
+				"timestamp": "",
+				"type":      "rollback",
+			},
+		},
+		"rollback completed": {
+			event: event.RollbackEvent{
+				Type: event.RollbackEventCompleted,
+			},
+			rollbackStats: &list.RollbackStats{
+				Restored: 2,
+				Deleted:  1,
+			},
+			expected: map[string]interface{}{
+				"deletedCount":  1,
+				"eventType":     "completed",
+				"failedCount":   0,
+				"restoredCount": 2,
+				"timestamp":     "",
+				"type":          "rollback",
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			ioStreams, _, out, _ := genericclioptions.NewTestIOStreams() //nolint:dogsled
+			formatter := NewFormatter(ioStreams, common.DryRunNone)
+			err := formatter.(list.RollbackFormatter).FormatRollbackEvent(tc.event, tc.rollbackStats)
+			assert.NoError(t, err)
+
+			assertOutput(t, tc.expected, out.String())
+		})
+	}
+}
+
 // nolint:unparam
 func assertOutput(t *testing.T, expectedMap map[string]interface{}, actual string) bool {
 	var m map[string]interface{}
@@ -0,0 +1,386 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package json
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/errors"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/collector"
+	pollevent "sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/print/list"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// SummaryAPIVersion is the version of the summary document schema.
+	// It must be changed whenever a backwards incompatible change is
+	// made to the schema.
+	SummaryAPIVersion = "cli-utils.sigs.k8s.io/v1alpha1"
+	// SummaryKind is the kind of the summary document.
+	SummaryKind = "Summary"
+)
+
+// Reasons for the exit code in the summary document.
+const (
+	ReasonSuccess         = "Success"
+	ReasonTimeout         = "Timeout"
+	ReasonResourcesFailed = "ResourcesFailed"
+	ReasonError           = "Error"
+)
+
+// Summary is the document written at the end of a command when
+// a summary file has been requested. See the package documentation
+// for a description of the schema.
+type Summary struct {
+	APIVersion      string            `json:"apiVersion"`
+	Kind            string            `json:"kind"`
+	Command         string            `json:"command"`
+	Inventory       SummaryInventory  `json:"inventory"`
+	StartTime       string            `json:"startTime"`
+	DurationSeconds float64           `json:"durationSeconds"`
+	ExitCode        int               `json:"exitCode"`
+	Reason          string            `json:"reason"`
+	Error           string            `json:"error,omitempty"`
+	Counts          SummaryCounts     `json:"counts"`
+	Resources       []ResourceSummary `json:"resources"`
+}
+
+// SummaryInventory identifies the inventory used by the command.
+type SummaryInventory struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	ID        string `json:"id"`
+}
+
+// SummaryCounts contains the counts for each of the operations.
+type SummaryCounts struct {
+	Apply  ApplyCounts  `json:"apply"`
+	Prune  PruneCounts  `json:"prune"`
+	Delete DeleteCounts `json:"delete"`
+}
+
+// ApplyCounts contains the counts from the list.ApplyStats.
+type ApplyCounts struct {
+	Created           int `json:"created"`
+	Configured        int `json:"configured"`
+	Unchanged         int `json:"unchanged"`
+	ServersideApplied int `json:"serversideApplied"`
+	Recreated         int `json:"recreated"`
+	Failed            int `json:"failed"`
+}
+
+// PruneCounts contains the counts from the list.PruneStats.
+type PruneCounts struct {
+	Pruned  int `json:"pruned"`
+	Skipped int `json:"skipped"`
+	Failed  int `json:"failed"`
+}
+
+// DeleteCounts contains the counts from the list.DeleteStats.
+type DeleteCounts struct {
+	Deleted int `json:"deleted"`
+	Skipped int `json:"skipped"`
+	Failed  int `json:"failed"`
+}
+
+// ResourceSummary contains the final result for a single resource.
+type ResourceSummary struct {
+	Group     string `json:"group"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Action is the last action performed on the resource. One of
+	// apply, prune, delete or status.
+	Action string `json:"action"`
+	// Operation is the result of the action, like Created or Pruned.
+	Operation       string   `json:"operation,omitempty"`
+	Error           string   `json:"error,omitempty"`
+	Status          string   `json:"status,omitempty"`
+	Message         string   `json:"message,omitempty"`
+	Current         bool     `json:"current"`
+	DurationSeconds *float64 `json:"durationSeconds,omitempty"`
+}
+
+// SummaryRecorder records the events from a command so a single
+// summary document can be written once the command has finished.
+// It is safe for use by multiple goroutines.
+type SummaryRecorder struct {
+	mux sync.Mutex
+
+	command   string
+	inventory SummaryInventory
+	start     time.Time
+	timeline  *collector.TimelineRecorder
+
+	applyStats  list.ApplyStats
+	pruneStats  list.PruneStats
+	deleteStats list.DeleteStats
+
+	resources map[object.ObjMetadata]*ResourceSummary
+	// order keeps track of the order in which resources were
+	// first seen, so the output is deterministic.
+	order []object.ObjMetadata
+}
+
+// NewSummaryRecorder returns a new SummaryRecorder for the given
+// command and inventory. The inventory can be nil.
+func NewSummaryRecorder(command string, inv inventory.InventoryInfo) *SummaryRecorder {
+	s := &SummaryRecorder{
+		command:   command,
+		start:     time.Now(),
+		timeline:  collector.NewTimelineRecorder(),
+		resources: make(map[object.ObjMetadata]*ResourceSummary),
+	}
+	if inv != nil {
+		s.inventory = SummaryInventory{
+			Name:      inv.Name(),
+			Namespace: inv.Namespace(),
+			ID:        inv.ID(),
+		}
+	}
+	return s
+}
+
+// Tee returns a channel that receives all events from the provided
+// channel, after they have been recorded. The returned channel is
+// closed when the provided channel is closed.
+func (s *SummaryRecorder) Tee(ch <-chan event.Event) <-chan event.Event {
+	out := make(chan event.Event)
+	go func() {
+		defer close(out)
+		for e := range ch {
+			s.RecordEvent(e)
+			out <- e
+		}
+	}()
+	return out
+}
+
+// RecordEvent records a single event from the applier or destroyer.
+func (s *SummaryRecorder) RecordEvent(e event.Event) {
+	switch e.Type {
+	case event.ApplyType:
+		ae := e.ApplyEvent
+		if ae.Type != event.ApplyEventResourceUpdate {
+			return
+		}
+		s.mux.Lock()
+		defer s.mux.Unlock()
+		switch ae.Operation {
+		case event.ServersideApplied:
+			s.applyStats.ServersideApplied++
+		case event.Created:
+			s.applyStats.Created++
+		case event.Unchanged:
+			s.applyStats.Unchanged++
+		case event.Configured:
+			s.applyStats.Configured++
+		case event.Failed:
+			s.applyStats.Failed++
+		case event.Recreated:
+			s.applyStats.Recreated++
+		}
+		s.update(ae.Identifier, "apply", ae.Operation.String(), ae.Error)
+	case event.StatusType:
+		if e.StatusEvent.Type == event.StatusEventResourceUpdate {
+			s.RecordStatus(e.StatusEvent.Resource)
+		}
+	case event.PruneType:
+		pe := e.PruneEvent
+		s.mux.Lock()
+		defer s.mux.Unlock()
+		switch pe.Type {
+		case event.PruneEventResourceUpdate:
+			switch pe.Operation {
+			case event.Pruned:
+				s.pruneStats.Pruned++
+			case event.PruneSkipped:
+				s.pruneStats.Skipped++
+			}
+			s.update(pe.Identifier, "prune", pe.Operation.String(), nil)
+		case event.PruneEventFailed:
+			s.pruneStats.Failed++
+			s.update(pe.Identifier, "prune", "", pe.Error)
+		}
+	case event.DeleteType:
+		de := e.DeleteEvent
+		id := de.Identifier
+		if id.Name == "" && de.Object != nil {
+			id = object.UnstructuredToObjMeta(de.Object)
+		}
+		s.mux.Lock()
+		defer s.mux.Unlock()
+		switch de.Type {
+		case event.DeleteEventResourceUpdate:
+			switch de.Operation {
+			case event.Deleted:
+				s.deleteStats.Deleted++
+			case event.DeleteSkipped:
+				s.deleteStats.Skipped++
+			}
+			s.update(id, "delete", de.Operation.String(), nil)
+		case event.DeleteEventFailed:
+			s.deleteStats.Failed++
+			s.update(id, "delete", "", de.Error)
+		}
+	}
+}
+
+// RecordStatus records the status for a resource. It is used directly
+// by the status command, which doesn't use the applier events.
+func (s *SummaryRecorder) RecordStatus(rs *pollevent.ResourceStatus) {
+	if rs == nil {
+		return
+	}
+	s.timeline.Record(rs)
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	r := s.resource(rs.Identifier)
+	if r.Action == "" {
+		r.Action = "status"
+	}
+	r.Status = rs.Status.String()
+	r.Message = rs.Message
+	if rs.Error != nil && r.Error == "" {
+		r.Error = rs.Error.Error()
+	}
+}
+
+// update sets the last action and operation for a resource. The caller
+// must hold the lock.
+func (s *SummaryRecorder) update(id object.ObjMetadata, action, operation string, err error) {
+	r := s.resource(id)
+	r.Action = action
+	r.Operation = operation
+	r.Error = ""
+	if err != nil {
+		r.Error = err.Error()
+	}
+}
+
+// resource returns the summary for the resource, creating it if it
+// doesn't already exist. The caller must hold the lock.
+func (s *SummaryRecorder) resource(id object.ObjMetadata) *ResourceSummary {
+	r, found := s.resources[id]
+	if !found {
+		r = &ResourceSummary{
+			Group:     id.GroupKind.Group,
+			Kind:      id.GroupKind.Kind,
+			Namespace: id.Namespace,
+			Name:      id.Name,
+		}
+		s.resources[id] = r
+		s.order = append(s.order, id)
+	}
+	return r
+}
+
+// Summary returns the summary document. The provided error is the
+// error returned by the command, and is used to compute the exit code
+// and reason.
+func (s *SummaryRecorder) Summary(err error) *Summary {
+	ts := s.timeline.Summary()
+	durations := make(map[object.ObjMetadata]collector.ResourceDuration)
+	for _, rd := range ts.Resources {
+		durations[rd.Identifier] = rd
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	summary := &Summary{
+		APIVersion: SummaryAPIVersion,
+		Kind:       SummaryKind,
+		Command:    s.command,
+		Inventory:  s.inventory,
+		StartTime:  s.start.UTC().Format(time.RFC3339),
+		Counts: SummaryCounts{
+			Apply: ApplyCounts{
+				Created:           s.applyStats.Created,
+				Configured:        s.applyStats.Configured,
+				Unchanged:         s.applyStats.Unchanged,
+				ServersideApplied: s.applyStats.ServersideApplied,
+				Recreated:         s.applyStats.Recreated,
+				Failed:            s.applyStats.Failed,
+			},
+			Prune: PruneCounts{
+				Pruned:  s.pruneStats.Pruned,
+				Skipped: s.pruneStats.Skipped,
+				Failed:  s.pruneStats.Failed,
+			},
+			Delete: DeleteCounts{
+				Deleted: s.deleteStats.Deleted,
+				Skipped: s.deleteStats.Skipped,
+				Failed:  s.deleteStats.Failed,
+			},
+		},
+		Resources: make([]ResourceSummary, 0, len(s.order)),
+	}
+	summary.DurationSeconds = time.Since(s.start).Seconds()
+
+	for _, id := range s.order {
+		r := *s.resources[id]
+		if rd, found := durations[id]; found {
+			r.Current = rd.Current
+			d := rd.Duration.Seconds()
+			r.DurationSeconds = &d
+		}
+		summary.Resources = append(summary.Resources, r)
+	}
+
+	summary.ExitCode = errors.ExitCodeForError(err)
+	switch {
+	case err == nil:
+		summary.Reason = ReasonSuccess
+	case summary.ExitCode == errors.TimeoutErrorExitCode:
+		summary.Reason = ReasonTimeout
+	case s.applyStats.Failed+s.pruneStats.Failed+s.deleteStats.Failed > 0:
+		summary.Reason = ReasonResourcesFailed
+	default:
+		summary.Reason = ReasonError
+	}
+	if err != nil {
+		summary.Error = err.Error()
+	}
+	return summary
+}
+
+// WriteFile writes the summary document to the file with the given
+// path. The document is written as YAML if the file has a .yaml or
+// .yml extension, and as JSON otherwise.
+func (s *SummaryRecorder) WriteFile(path string, err error) error {
+	b, marshalErr := s.Summary(err).Marshal(path)
+	if marshalErr != nil {
+		return marshalErr
+	}
+	if writeErr := ioutil.WriteFile(path, b, 0644); writeErr != nil {
+		return fmt.Errorf("error writing summary file: %v", writeErr)
+	}
+	return nil
+}
+
+// Marshal returns the summary document in the format given by the
+// extension of the path.
+func (s *Summary) Marshal(path string) ([]byte, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return yaml.Marshal(s)
+	default:
+		b, err := json.MarshalIndent(s, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(b, '\n'), nil
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package json
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
+	pollevent "sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/yaml"
+)
+
+func TestSummaryRecorder(t *testing.T) {
+	depID := createIdentifier("apps", "Deployment", "default", "my-dep")
+	cmID := createIdentifier("", "ConfigMap", "default", "my-cm")
+
+	testCases := map[string]struct {
+		events            []event.Event
+		err               error
+		expectedExitCode  int
+		expectedReason    string
+		expectedCounts    SummaryCounts
+		expectedResources []ResourceSummary
+	}{
+		"successful apply with prune": {
+			events: []event.Event{
+				{
+					Type: event.ApplyType,
+					ApplyEvent: event.ApplyEvent{
+						Type:       event.ApplyEventResourceUpdate,
+						Operation:  event.Created,
+						Identifier: depID,
+					},
+				},
+				{
+					Type: event.StatusType,
+					StatusEvent: event.StatusEvent{
+						Type: event.StatusEventResourceUpdate,
+						Resource: &pollevent.ResourceStatus{
+							Identifier: depID,
+							Status:     status.CurrentStatus,
+							Message:    "Deployment is available",
+						},
+					},
+				},
+				{
+					Type: event.PruneType,
+					PruneEvent: event.PruneEvent{
+						Type:       event.PruneEventResourceUpdate,
+						Operation:  event.Pruned,
+						Identifier: cmID,
+					},
+				},
+			},
+			expectedExitCode: 0,
+			expectedReason:   ReasonSuccess,
+			expectedCounts: SummaryCounts{
+				Apply: ApplyCounts{Created: 1},
+				Prune: PruneCounts{Pruned: 1},
+			},
+			expectedResources: []ResourceSummary{
+				{
+					Group:     "apps",
+					Kind:      "Deployment",
+					Namespace: "default",
+					Name:      "my-dep",
+					Action:    "apply",
+					Operation: "Created",
+					Status:    "Current",
+					Message:   "Deployment is available",
+					Current:   true,
+				},
+				{
+					Kind:      "ConfigMap",
+					Namespace: "default",
+					Name:      "my-cm",
+					Action:    "prune",
+					Operation: "Pruned",
+				},
+			},
+		},
+		"failed apply": {
+			events: []event.Event{
+				{
+					Type: event.ApplyType,
+					ApplyEvent: event.ApplyEvent{
+						Type:       event.ApplyEventResourceUpdate,
+						Operation:  event.Failed,
+						Identifier: cmID,
+						Error:      fmt.Errorf("forbidden"),
+					},
+				},
+			},
+			err:              fmt.Errorf("1 resources failed"),
+			expectedExitCode: 1,
+			expectedReason:   ReasonResourcesFailed,
+			expectedCounts: SummaryCounts{
+				Apply: ApplyCounts{Failed: 1},
+			},
+			expectedResources: []ResourceSummary{
+				{
+					Kind:      "ConfigMap",
+					Namespace: "default",
+					Name:      "my-cm",
+					Action:    "apply",
+					Operation: "Failed",
+					Error:     "forbidden",
+				},
+			},
+		},
+		"timeout": {
+			events: []event.Event{
+				{
+					Type: event.ApplyType,
+					ApplyEvent: event.ApplyEvent{
+						Type:       event.ApplyEventResourceUpdate,
+						Operation:  event.Configured,
+						Identifier: depID,
+					},
+				},
+				{
+					Type: event.StatusType,
+					StatusEvent: event.StatusEvent{
+						Type: event.StatusEventResourceUpdate,
+						Resource: &pollevent.ResourceStatus{
+							Identifier: depID,
+							Status:     status.InProgressStatus,
+						},
+					},
+				},
+			},
+			err:              &taskrunner.TimeoutError{},
+			expectedExitCode: 3,
+			expectedReason:   ReasonTimeout,
+			expectedCounts: SummaryCounts{
+				Apply: ApplyCounts{Configured: 1},
+			},
+			expectedResources: []ResourceSummary{
+				{
+					Group:     "apps",
+					Kind:      "Deployment",
+					Namespace: "default",
+					Name:      "my-dep",
+					Action:    "apply",
+					Operation: "Configured",
+					Status:    "InProgress",
+				},
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			recorder := NewSummaryRecorder("apply", nil)
+			for _, e := range tc.events {
+				recorder.RecordEvent(e)
+			}
+			summary := recorder.Summary(tc.err)
+
+			assert.Equal(t, SummaryAPIVersion, summary.APIVersion)
+			assert.Equal(t, SummaryKind, summary.Kind)
+			assert.Equal(t, "apply", summary.Command)
+			assert.Equal(t, tc.expectedExitCode, summary.ExitCode)
+			assert.Equal(t, tc.expectedReason, summary.Reason)
+			assert.Equal(t, tc.expectedCounts, summary.Counts)
+
+			// The durations depend on time, so we only verify that
+			// they are set for resources with status.
+			for i := range summary.Resources {
+				r := &summary.Resources[i]
+				assert.Equal(t, r.Status != "", r.DurationSeconds != nil)
+				r.DurationSeconds = nil
+			}
+			assert.Equal(t, tc.expectedResources, summary.Resources)
+		})
+	}
+}
+
+func TestSummaryRecorder_WriteFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "summary-test")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"summary.json", "summary.yaml"} {
+		t.Run(name, func(t *testing.T) {
+			recorder := NewSummaryRecorder("destroy", nil)
+			recorder.RecordEvent(event.Event{
+				Type: event.DeleteType,
+				DeleteEvent: event.DeleteEvent{
+					Type:       event.DeleteEventResourceUpdate,
+					Operation:  event.Deleted,
+					Identifier: createIdentifier("", "ConfigMap", "default", "my-cm"),
+				},
+			})
+			path := filepath.Join(dir, name)
+			if !assert.NoError(t, recorder.WriteFile(path, nil)) {
+				t.FailNow()
+			}
+
+			b, err := ioutil.ReadFile(path)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			// The YAML unmarshaller also handles JSON.
+			var summary Summary
+			if !assert.NoError(t, yaml.Unmarshal(b, &summary)) {
+				t.FailNow()
+			}
+			assert.Equal(t, "destroy", summary.Command)
+			assert.Equal(t, ReasonSuccess, summary.Reason)
+			assert.Equal(t, 1, summary.Counts.Delete.Deleted)
+			assert.Len(t, summary.Resources, 1)
+		})
+	}
+}
@@ -0,0 +1,235 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package junit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/print/junit"
+	"sigs.k8s.io/cli-utils/pkg/print/list"
+)
+
+// NewFormatterFactory returns a FormatterFactory for formatters that
+// write the JUnit report to the file with the given path, or to
+// the output stream if the path is empty.
+func NewFormatterFactory(reportFile string) list.FormatterFactory {
+	return func(ioStreams genericclioptions.IOStreams,
+		previewStrategy common.DryRunStrategy) list.Formatter {
+		return NewFormatter(ioStreams, previewStrategy, reportFile)
+	}
+}
+
+// NewFormatter returns a formatter that collects the events and
+// writes a JUnit report once all events have been processed. Every
+// resource becomes a test case in a test suite for the operation
+// performed on it.
+func NewFormatter(ioStreams genericclioptions.IOStreams,
+	previewStrategy common.DryRunStrategy, reportFile string) list.Formatter {
+	start := now()
+	return &formatter{
+		ioStreams:       ioStreams,
+		previewStrategy: previewStrategy,
+		reportFile:      reportFile,
+		start:           start,
+		applySuite:      junit.NewTestSuite("apply", start),
+		statusSuite:     junit.NewTestSuite("status", start),
+		pruneSuite:      junit.NewTestSuite("prune", start),
+		deleteSuite:     junit.NewTestSuite("delete", start),
+		errorSuite:      junit.NewTestSuite("errors", start),
+		timedOut:        make(map[object.ObjMetadata]taskrunner.TimedOutResource),
+	}
+}
+
+// now returns the current time. It is a variable to allow unit testing.
+var now = time.Now
+
+type formatter struct {
+	ioStreams       genericclioptions.IOStreams
+	previewStrategy common.DryRunStrategy
+	reportFile      string
+	start           time.Time
+
+	applySuite  *junit.TestSuite
+	statusSuite *junit.TestSuite
+	pruneSuite  *junit.TestSuite
+	deleteSuite *junit.TestSuite
+	errorSuite  *junit.TestSuite
+
+	// statusCollector is the collector passed in with the apply
+	// and status events. It is used to create the test cases for
+	// status once all events have been processed.
+	statusCollector list.Collector
+	// timedOut contains the resources that didn't reach the
+	// desired status before the timeout.
+	timedOut map[object.ObjMetadata]taskrunner.TimedOutResource
+}
+
+func (jf *formatter) FormatApplyEvent(ae event.ApplyEvent, _ *list.ApplyStats, c list.Collector) error {
+	jf.statusCollector = c
+	if ae.Type != event.ApplyEventResourceUpdate {
+		return nil
+	}
+	tc := jf.newTestCase(jf.applySuite, ae.Identifier)
+	if ae.Error != nil {
+		tc.Failure = &junit.Failure{
+			Message: fmt.Sprintf("apply failed: %s", ae.Error.Error()),
+			Type:    ae.Operation.String(),
+			Text:    ae.Error.Error(),
+		}
+	}
+	jf.applySuite.AddTestCase(tc)
+	return nil
+}
+
+func (jf *formatter) FormatStatusEvent(_ event.StatusEvent, c list.Collector) error {
+	jf.statusCollector = c
+	return nil
+}
+
+func (jf *formatter) FormatPruneEvent(pe event.PruneEvent, _ *list.PruneStats) error {
+	switch pe.Type {
+	case event.PruneEventResourceUpdate:
+		tc := jf.newTestCase(jf.pruneSuite, pe.Identifier)
+		if pe.Operation == event.PruneSkipped {
+			tc.Skipped = &junit.Skipped{
+				Message: "prune skipped",
+			}
+		}
+		jf.pruneSuite.AddTestCase(tc)
+	case event.PruneEventFailed:
+		tc := jf.newTestCase(jf.pruneSuite, pe.Identifier)
+		tc.Failure = &junit.Failure{
+			Message: fmt.Sprintf("prune failed: %s", pe.Error.Error()),
+			Text:    pe.Error.Error(),
+		}
+		jf.pruneSuite.AddTestCase(tc)
+	}
+	return nil
+}
+
+func (jf *formatter) FormatDeleteEvent(de event.DeleteEvent, _ *list.DeleteStats) error {
+	id := de.Identifier
+	if id.Name == "" && de.Object != nil {
+		id = object.UnstructuredToObjMeta(de.Object)
+	}
+	switch de.Type {
+	case event.DeleteEventResourceUpdate:
+		tc := jf.newTestCase(jf.deleteSuite, id)
+		if de.Operation == event.DeleteSkipped {
+			tc.Skipped = &junit.Skipped{
+				Message: "delete skipped",
+			}
+		}
+		jf.deleteSuite.AddTestCase(tc)
+	case event.DeleteEventFailed:
+		tc := jf.newTestCase(jf.deleteSuite, id)
+		tc.Failure = &junit.Failure{
+			Message: fmt.Sprintf("deletion failed: %s", de.Error.Error()),
+			Text:    de.Error.Error(),
+		}
+		jf.deleteSuite.AddTestCase(tc)
+	}
+	return nil
+}
+
+func (jf *formatter) FormatErrorEvent(ee event.ErrorEvent) error {
+	// Timeouts are reported on the individual resources in the
+	// status suite.
+	if timeoutErr, ok := taskrunner.IsTimeoutError(ee.Err); ok {
+		for _, tr := range timeoutErr.TimedOutResources {
+			jf.timedOut[tr.Identifier] = tr
+		}
+		return nil
+	}
+	jf.errorSuite.AddTestCase(&junit.TestCase{
+		Name:      "error",
+		ClassName: jf.errorSuite.Name,
+		Failure: &junit.Failure{
+			Message: ee.Err.Error(),
+			Text:    ee.Err.Error(),
+		},
+	})
+	return nil
+}
+
+// Finish creates the test cases for status and writes the report.
+func (jf *formatter) Finish() error {
+	jf.addStatusTestCases()
+	report := junit.NewTestSuites(jf.applySuite, jf.statusSuite, jf.pruneSuite,
+		jf.deleteSuite, jf.errorSuite)
+	report.Time = now().Sub(jf.start).Seconds()
+	return report.WriteFile(jf.reportFile, jf.ioStreams.Out)
+}
+
+// addStatusTestCases adds a test case for every resource we have seen
+// status for. A resource fails if it timed out or has the Failed status.
+func (jf *formatter) addStatusTestCases() {
+	var ids []object.ObjMetadata
+	if jf.statusCollector != nil {
+		for id := range jf.statusCollector.LatestStatus() {
+			ids = append(ids, id)
+		}
+	}
+	for id := range jf.timedOut {
+		if jf.statusCollector == nil {
+			ids = append(ids, id)
+			continue
+		}
+		if _, found := jf.statusCollector.LatestStatus()[id]; !found {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i].String() < ids[j].String()
+	})
+
+	for _, id := range ids {
+		tc := jf.newTestCase(jf.statusSuite, id)
+		if tr, found := jf.timedOut[id]; found {
+			msg := fmt.Sprintf("timed out with status %s", tr.Status)
+			if tr.Message != "" {
+				msg = fmt.Sprintf("%s: %s", msg, tr.Message)
+			}
+			tc.Failure = &junit.Failure{
+				Message: msg,
+				Type:    "Timeout",
+				Text:    msg,
+			}
+		} else if se, found := jf.statusCollector.LatestStatus()[id]; found &&
+			se.Resource != nil && se.Resource.Status == status.FailedStatus {
+			msg := fmt.Sprintf("resource has status %s: %s", se.Resource.Status, se.Resource.Message)
+			tc.Failure = &junit.Failure{
+				Message: msg,
+				Type:    se.Resource.Status.String(),
+				Text:    msg,
+			}
+		}
+		jf.statusSuite.AddTestCase(tc)
+	}
+}
+
+// newTestCase creates a test case for the resource. The namespace
+// is included in the classname so CI systems can group the test cases.
+func (jf *formatter) newTestCase(suite *junit.TestSuite, id object.ObjMetadata) *junit.TestCase {
+	className := suite.Name
+	if jf.previewStrategy.ClientOrServerDryRun() {
+		className += ".preview"
+	}
+	if id.Namespace != "" {
+		className += "." + id.Namespace
+	}
+	return &junit.TestCase{
+		Name:      fmt.Sprintf("%s/%s", strings.ToLower(id.GroupKind.String()), id.Name),
+		ClassName: className,
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package junit
+
+import (
+	"encoding/xml"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	pollevent "sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/print/junit"
+	"sigs.k8s.io/cli-utils/pkg/print/list"
+)
+
+func TestJUnitPrinter(t *testing.T) {
+	dep := createIdentifier("apps", "Deployment", "default", "dep")
+	sts := createIdentifier("apps", "StatefulSet", "default", "sts")
+	cm := createIdentifier("", "ConfigMap", "default", "cm")
+	secret := createIdentifier("", "Secret", "default", "secret")
+
+	testCases := map[string]struct {
+		events           []event.Event
+		expectedErr      bool
+		expectedFailures map[string]string
+		expectedSkipped  []string
+		expectedTests    int
+	}{
+		"apply with prune": {
+			events: []event.Event{
+				applyEvent(dep, event.Created, nil),
+				applyEvent(sts, event.Failed, fmt.Errorf("invalid spec")),
+				{
+					Type: event.ApplyType,
+					ApplyEvent: event.ApplyEvent{
+						Type: event.ApplyEventCompleted,
+					},
+				},
+				{
+					Type: event.PruneType,
+					PruneEvent: event.PruneEvent{
+						Type:       event.PruneEventResourceUpdate,
+						Operation:  event.Pruned,
+						Identifier: cm,
+					},
+				},
+				{
+					Type: event.PruneType,
+					PruneEvent: event.PruneEvent{
+						Type:       event.PruneEventResourceUpdate,
+						Operation:  event.PruneSkipped,
+						Identifier: secret,
+					},
+				},
+			},
+			expectedErr:   true,
+			expectedTests: 4,
+			expectedFailures: map[string]string{
+				"statefulset.apps/sts": "apply failed: invalid spec",
+			},
+			expectedSkipped: []string{"secret/secret"},
+		},
+		"status timeout": {
+			events: []event.Event{
+				applyEvent(dep, event.Created, nil),
+				{
+					Type: event.ApplyType,
+					ApplyEvent: event.ApplyEvent{
+						Type: event.ApplyEventCompleted,
+					},
+				},
+				statusEvent(dep, status.InProgressStatus, "Replicas: 0/1"),
+				{
+					Type: event.ErrorType,
+					ErrorEvent: event.ErrorEvent{
+						Err: &taskrunner.TimeoutError{
+							Identifiers: []object.ObjMetadata{dep},
+							Condition:   taskrunner.AllCurrent,
+							TimedOutResources: []taskrunner.TimedOutResource{
+								{
+									Identifier: dep,
+									Status:     status.InProgressStatus,
+									Message:    "Replicas: 0/1",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedErr:   true,
+			expectedTests: 2,
+			expectedFailures: map[string]string{
+				"deployment.apps/dep": "timed out with status InProgress: Replicas: 0/1",
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			ioStreams, _, out, _ := genericclioptions.NewTestIOStreams() //nolint:dogsled
+			printer := &list.BaseListPrinter{
+				IOStreams:        ioStreams,
+				FormatterFactory: NewFormatterFactory(""),
+			}
+
+			ch := make(chan event.Event, len(tc.events))
+			for _, e := range tc.events {
+				ch <- e
+			}
+			close(ch)
+
+			err := printer.Print(ch, common.DryRunNone)
+			assert.Equal(t, tc.expectedErr, err != nil)
+
+			var report junit.TestSuites
+			if !assert.NoError(t, xml.Unmarshal(out.Bytes(), &report)) {
+				t.FailNow()
+			}
+			assert.Equal(t, tc.expectedTests, report.Tests)
+			assert.Equal(t, len(tc.expectedFailures), report.Failures)
+
+			failures := make(map[string]string)
+			var skipped []string
+			for _, s := range report.Suites {
+				for _, c := range s.TestCases {
+					if c.Failure != nil {
+						failures[c.Name] = c.Failure.Message
+					}
+					if c.Skipped != nil {
+						skipped = append(skipped, c.Name)
+					}
+				}
+			}
+			assert.Equal(t, tc.expectedFailures, failures)
+			assert.Equal(t, tc.expectedSkipped, skipped)
+		})
+	}
+}
+
+func applyEvent(id object.ObjMetadata, op event.ApplyEventOperation, err error) event.Event {
+	return event.Event{
+		Type: event.ApplyType,
+		ApplyEvent: event.ApplyEvent{
+			Type:       event.ApplyEventResourceUpdate,
+			Operation:  op,
+			Identifier: id,
+			Error:      err,
+		},
+	}
+}
+
+func statusEvent(id object.ObjMetadata, s status.Status, msg string) event.Event {
+	return event.Event{
+		Type: event.StatusType,
+		StatusEvent: event.StatusEvent{
+			Type: event.StatusEventResourceUpdate,
+			Resource: &pollevent.ResourceStatus{
+				Identifier: id,
+				Status:     s,
+				Message:    msg,
+			},
+		},
+	}
+}
+
+func createIdentifier(group, kind, namespace, name string) object.ObjMetadata {
+	return object.ObjMetadata{
+		Namespace: namespace,
+		Name:      name,
+		GroupKind: schema.GroupKind{
+			Group: group,
+			Kind:  kind,
+		},
+	}
+}
+
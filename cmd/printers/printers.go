@@ -6,36 +6,118 @@ package printers
 import (
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"sigs.k8s.io/cli-utils/cmd/printers/events"
+	"sigs.k8s.io/cli-utils/cmd/printers/interactive"
 	"sigs.k8s.io/cli-utils/cmd/printers/json"
+	"sigs.k8s.io/cli-utils/cmd/printers/junit"
 	"sigs.k8s.io/cli-utils/cmd/printers/printer"
+	"sigs.k8s.io/cli-utils/cmd/printers/report"
 	"sigs.k8s.io/cli-utils/cmd/printers/table"
 	"sigs.k8s.io/cli-utils/pkg/print/list"
+	printtable "sigs.k8s.io/cli-utils/pkg/print/table"
 )
 
 const (
-	EventsPrinter = "events"
-	TablePrinter  = "table"
-	JSONPrinter   = "json"
+	EventsPrinter   = "events"
+	TablePrinter    = "table"
+	JSONPrinter     = "json"
+	JUnitPrinter    = "junit"
+	MarkdownPrinter = "markdown"
+	HTMLPrinter     = "html"
+	// InteractivePrinter shows an interactive UI if both stdin and
+	// stdout are terminals, and falls back to the table printer if not.
+	InteractivePrinter = "interactive"
 )
 
+// Options contains settings that are shared by the printers. Not all
+// printers support every option.
+type Options struct {
+	// StatusSummary enables printing a summary of how long it took for
+	// each resource to become Current. Only supported by the events and
+	// json printers.
+	StatusSummary bool
+
+	// ReportFile is the file the junit, markdown and html printers
+	// write the report to. If it is empty, the report is written to
+	// the output stream.
+	ReportFile string
+
+	// Columns are the columns printed by the table printer. If empty,
+	// the default columns are used.
+	Columns []printtable.ColumnDefinition
+
+	// SortBy is the column the table printer sorts the resources by.
+	// If nil, the resources are not sorted.
+	SortBy *printtable.SortBy
+
+	// KeepOpen keeps the interactive printer open after all events
+	// have been processed, until the user quits.
+	KeepOpen bool
+}
+
 func GetPrinter(printerType string, ioStreams genericclioptions.IOStreams) printer.Printer {
+	return GetPrinterWithOptions(printerType, ioStreams, Options{})
+}
+
+// GetPrinterWithOptions returns the printer for the given type, configured
+// with the provided options.
+func GetPrinterWithOptions(printerType string, ioStreams genericclioptions.IOStreams,
+	opts Options) printer.Printer {
 	switch printerType { //nolint:gocritic
 	case TablePrinter:
 		return &table.Printer{
 			IOStreams: ioStreams,
+			Columns:   opts.Columns,
+			SortBy:    opts.SortBy,
+		}
+	case InteractivePrinter:
+		return &interactive.Printer{
+			IOStreams: ioStreams,
+			KeepOpen:  opts.KeepOpen,
 		}
 	case JSONPrinter:
 		return &list.BaseListPrinter{
 			IOStreams:        ioStreams,
 			FormatterFactory: json.NewFormatter,
+			StatusSummary:    opts.StatusSummary,
+		}
+	case JUnitPrinter:
+		return &list.BaseListPrinter{
+			IOStreams:        ioStreams,
+			FormatterFactory: junit.NewFormatterFactory(opts.ReportFile),
 		}
+	case MarkdownPrinter:
+		return &list.BaseListPrinter{
+			IOStreams:        ioStreams,
+			FormatterFactory: report.NewMarkdownFormatterFactory(opts.ReportFile),
+		}
+	case HTMLPrinter:
+		return &list.BaseListPrinter{
+			IOStreams:        ioStreams,
+			FormatterFactory: report.NewHTMLFormatterFactory(opts.ReportFile),
+		}
+	default:
+		return &list.BaseListPrinter{
+			IOStreams:        ioStreams,
+			FormatterFactory: events.NewFormatter,
+			StatusSummary:    opts.StatusSummary,
+		}
+	}
+}
+
+// SupportsStatusSummary returns whether the printer for the given type
+// can print the summary enabled by Options.StatusSummary.
+func SupportsStatusSummary(printerType string) bool {
+	switch printerType {
+	case TablePrinter, InteractivePrinter, JUnitPrinter, MarkdownPrinter, HTMLPrinter:
+		return false
 	default:
-		return events.NewPrinter(ioStreams)
+		return true
 	}
 }
 
 func SupportedPrinters() []string {
-	return []string{EventsPrinter, TablePrinter, JSONPrinter}
+	return []string{EventsPrinter, TablePrinter, JSONPrinter, JUnitPrinter, InteractivePrinter,
+		MarkdownPrinter, HTMLPrinter}
 }
 
 func DefaultPrinter() string {
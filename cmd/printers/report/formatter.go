@@ -0,0 +1,287 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package report provides formatters that render a summary of all
+// events as a markdown or html report once all events have been
+// processed. The reports are intended to be posted as comments on
+// pull requests, so the resources are grouped by the operation and
+// each group can be collapsed.
+package report
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/print/list"
+)
+
+// section is a group of resources in the report.
+type section int
+
+const (
+	sectionCreated section = iota
+	sectionConfigured
+	sectionServersideApplied
+	sectionRecreated
+	sectionUnchanged
+	sectionApplyFailed
+	sectionPruned
+	sectionPruneSkipped
+	sectionPruneFailed
+	sectionDeleted
+	sectionDeleteSkipped
+	sectionDeleteFailed
+	numSections
+)
+
+// sectionTitles contains the titles for every section, for preview
+// and for when changes are made to the cluster.
+var sectionTitles = map[section][2]string{
+	sectionCreated:           {"To create", "Created"},
+	sectionConfigured:        {"To configure", "Configured"},
+	sectionServersideApplied: {"To apply server-side", "Applied server-side"},
+	sectionRecreated:         {"To recreate", "Recreated"},
+	sectionUnchanged:         {"Unchanged", "Unchanged"},
+	sectionApplyFailed:       {"Apply failed", "Apply failed"},
+	sectionPruned:            {"To prune", "Pruned"},
+	sectionPruneSkipped:      {"Prune skipped", "Prune skipped"},
+	sectionPruneFailed:       {"Prune failed", "Prune failed"},
+	sectionDeleted:           {"To delete", "Deleted"},
+	sectionDeleteSkipped:     {"Delete skipped", "Delete skipped"},
+	sectionDeleteFailed:      {"Delete failed", "Delete failed"},
+}
+
+var failedSections = map[section]bool{
+	sectionApplyFailed:  true,
+	sectionPruneFailed:  true,
+	sectionDeleteFailed: true,
+}
+
+var applySections = map[event.ApplyEventOperation]section{
+	event.Created:           sectionCreated,
+	event.Configured:        sectionConfigured,
+	event.ServersideApplied: sectionServersideApplied,
+	event.Recreated:         sectionRecreated,
+	event.Unchanged:         sectionUnchanged,
+	event.Failed:            sectionApplyFailed,
+}
+
+// entry is a single resource in a section.
+type entry struct {
+	Identifier object.ObjMetadata
+	Error      string
+}
+
+// report contains everything needed to render the report.
+type report struct {
+	Title    string
+	Sections []reportSection
+	// Kinds contains the number of resources for every kind in every
+	// section. Only sections that contain resources are included.
+	Kinds  []kindCounts
+	Errors []string
+}
+
+type reportSection struct {
+	Title string
+	// Failed is true if the section contains resources where the
+	// operation failed. These sections are expanded by default.
+	Failed  bool
+	Entries []entry
+}
+
+type kindCounts struct {
+	Kind   string
+	Counts []int
+}
+
+// renderFunc writes the report in a specific format.
+type renderFunc func(w io.Writer, r *report) error
+
+// NewMarkdownFormatterFactory returns a FormatterFactory for formatters
+// that write a markdown report to the file with the given path, or to
+// the output stream if the path is empty.
+func NewMarkdownFormatterFactory(reportFile string) list.FormatterFactory {
+	return newFormatterFactory(reportFile, renderMarkdown)
+}
+
+// NewHTMLFormatterFactory returns a FormatterFactory for formatters
+// that write an html report to the file with the given path, or to
+// the output stream if the path is empty.
+func NewHTMLFormatterFactory(reportFile string) list.FormatterFactory {
+	return newFormatterFactory(reportFile, renderHTML)
+}
+
+func newFormatterFactory(reportFile string, render renderFunc) list.FormatterFactory {
+	return func(ioStreams genericclioptions.IOStreams,
+		previewStrategy common.DryRunStrategy) list.Formatter {
+		return &formatter{
+			ioStreams:       ioStreams,
+			previewStrategy: previewStrategy,
+			reportFile:      reportFile,
+			render:          render,
+			sections:        make(map[section][]entry),
+		}
+	}
+}
+
+type formatter struct {
+	ioStreams       genericclioptions.IOStreams
+	previewStrategy common.DryRunStrategy
+	reportFile      string
+	render          renderFunc
+
+	sections map[section][]entry
+	errors   []string
+}
+
+func (f *formatter) add(s section, id object.ObjMetadata, err error) {
+	e := entry{
+		Identifier: id,
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	f.sections[s] = append(f.sections[s], e)
+}
+
+func (f *formatter) FormatApplyEvent(ae event.ApplyEvent, _ *list.ApplyStats, _ list.Collector) error {
+	if ae.Type != event.ApplyEventResourceUpdate {
+		return nil
+	}
+	s, found := applySections[ae.Operation]
+	if !found {
+		return fmt.Errorf("unknown apply operation %s", ae.Operation.String())
+	}
+	f.add(s, ae.Identifier, ae.Error)
+	return nil
+}
+
+func (f *formatter) FormatStatusEvent(event.StatusEvent, list.Collector) error {
+	return nil
+}
+
+func (f *formatter) FormatPruneEvent(pe event.PruneEvent, _ *list.PruneStats) error {
+	switch pe.Type {
+	case event.PruneEventResourceUpdate:
+		if pe.Operation == event.PruneSkipped {
+			f.add(sectionPruneSkipped, pe.Identifier, nil)
+		} else {
+			f.add(sectionPruned, pe.Identifier, nil)
+		}
+	case event.PruneEventFailed:
+		f.add(sectionPruneFailed, pe.Identifier, pe.Error)
+	}
+	return nil
+}
+
+func (f *formatter) FormatDeleteEvent(de event.DeleteEvent, _ *list.DeleteStats) error {
+	id := de.Identifier
+	if id.Name == "" && de.Object != nil {
+		id = object.UnstructuredToObjMeta(de.Object)
+	}
+	switch de.Type {
+	case event.DeleteEventResourceUpdate:
+		if de.Operation == event.DeleteSkipped {
+			f.add(sectionDeleteSkipped, id, nil)
+		} else {
+			f.add(sectionDeleted, id, nil)
+		}
+	case event.DeleteEventFailed:
+		f.add(sectionDeleteFailed, id, de.Error)
+	}
+	return nil
+}
+
+func (f *formatter) FormatErrorEvent(ee event.ErrorEvent) error {
+	f.errors = append(f.errors, ee.Err.Error())
+	return nil
+}
+
+// Finish renders the report and writes it to the report file or the
+// output stream.
+func (f *formatter) Finish() error {
+	r := f.report()
+	if f.reportFile == "" {
+		return f.render(f.ioStreams.Out, r)
+	}
+	file, err := os.Create(f.reportFile)
+	if err != nil {
+		return fmt.Errorf("error creating report file: %v", err)
+	}
+	if err := f.render(file, r); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// report creates the report from the collected events. Sections
+// without resources are left out.
+func (f *formatter) report() *report {
+	titleIndex := 1
+	title := "Apply"
+	switch {
+	case f.previewStrategy.ServerDryRun():
+		titleIndex = 0
+		title = "Preview (server-side dry-run)"
+	case f.previewStrategy.ClientDryRun():
+		titleIndex = 0
+		title = "Preview (client-side dry-run)"
+	}
+
+	r := &report{
+		Title:  title,
+		Errors: f.errors,
+	}
+	kinds := make(map[string][]int)
+	var included []section
+	for s := section(0); s < numSections; s++ {
+		entries := f.sections[s]
+		if len(entries) == 0 {
+			continue
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			return entries[i].Identifier.String() < entries[j].Identifier.String()
+		})
+		r.Sections = append(r.Sections, reportSection{
+			Title:   sectionTitles[s][titleIndex],
+			Failed:  failedSections[s],
+			Entries: entries,
+		})
+		included = append(included, s)
+	}
+	for i, s := range included {
+		for _, e := range f.sections[s] {
+			kind := e.Identifier.GroupKind.Kind
+			if _, found := kinds[kind]; !found {
+				kinds[kind] = make([]int, len(included))
+			}
+			kinds[kind][i]++
+		}
+	}
+	for kind, counts := range kinds {
+		r.Kinds = append(r.Kinds, kindCounts{
+			Kind:   kind,
+			Counts: counts,
+		})
+	}
+	sort.Slice(r.Kinds, func(i, j int) bool {
+		return r.Kinds[i].Kind < r.Kinds[j].Kind
+	})
+	return r
+}
+
+// resourceName returns the name used for a resource in the report.
+func resourceName(id object.ObjMetadata) string {
+	if id.Namespace == "" {
+		return fmt.Sprintf("%s %s", id.GroupKind.Kind, id.Name)
+	}
+	return fmt.Sprintf("%s %s/%s", id.GroupKind.Kind, id.Namespace, id.Name)
+}
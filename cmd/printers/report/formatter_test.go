@@ -0,0 +1,225 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package report
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/print/list"
+)
+
+var (
+	dep    = createIdentifier("apps", "Deployment", "default", "dep")
+	cm     = createIdentifier("", "ConfigMap", "default", "cm")
+	cm2    = createIdentifier("", "ConfigMap", "default", "cm2")
+	ns     = createIdentifier("", "Namespace", "", "default")
+	secret = createIdentifier("", "Secret", "default", "secret")
+)
+
+func TestMarkdownFormatter(t *testing.T) {
+	testCases := map[string]struct {
+		previewStrategy common.DryRunStrategy
+		events          []event.Event
+		expectedErr     bool
+		expectedOutput  string
+	}{
+		"server-side preview": {
+			previewStrategy: common.DryRunServer,
+			events: []event.Event{
+				applyEvent(ns, event.Unchanged, nil),
+				applyEvent(dep, event.Created, nil),
+				applyEvent(cm, event.Configured, nil),
+				applyEvent(cm2, event.Failed, fmt.Errorf("admission webhook denied the request")),
+				pruneEvent(secret, event.Pruned),
+			},
+			expectedErr: true,
+			expectedOutput: `## Preview (server-side dry-run)
+
+| Kind | To create | To configure | Unchanged | Apply failed | To prune |
+| --- | ---: | ---: | ---: | ---: | ---: |
+| ConfigMap | 0 | 1 | 0 | 1 | 0 |
+| Deployment | 1 | 0 | 0 | 0 | 0 |
+| Namespace | 0 | 0 | 1 | 0 | 0 |
+| Secret | 0 | 0 | 0 | 0 | 1 |
+| **Total** | **1** | **1** | **1** | **1** | **1** |
+
+<details>
+<summary>To create (1)</summary>
+
+- Deployment default/dep
+
+</details>
+
+<details>
+<summary>To configure (1)</summary>
+
+- ConfigMap default/cm
+
+</details>
+
+<details>
+<summary>Unchanged (1)</summary>
+
+- Namespace default
+
+</details>
+
+<details open>
+<summary>Apply failed (1)</summary>
+
+- ConfigMap default/cm2
+  ` + "```" + `
+  admission webhook denied the request
+  ` + "```" + `
+
+</details>
+
+<details>
+<summary>To prune (1)</summary>
+
+- Secret default/secret
+
+</details>
+`,
+		},
+		"apply": {
+			previewStrategy: common.DryRunNone,
+			events: []event.Event{
+				applyEvent(dep, event.Configured, nil),
+				pruneEvent(secret, event.PruneSkipped),
+			},
+			expectedOutput: `## Apply
+
+| Kind | Configured | Prune skipped |
+| --- | ---: | ---: |
+| Deployment | 1 | 0 |
+| Secret | 0 | 1 |
+| **Total** | **1** | **1** |
+
+<details>
+<summary>Configured (1)</summary>
+
+- Deployment default/dep
+
+</details>
+
+<details>
+<summary>Prune skipped (1)</summary>
+
+- Secret default/secret
+
+</details>
+`,
+		},
+		"fatal error": {
+			previewStrategy: common.DryRunClient,
+			events: []event.Event{
+				{
+					Type: event.ErrorType,
+					ErrorEvent: event.ErrorEvent{
+						Err: fmt.Errorf("inventory object not found"),
+					},
+				},
+			},
+			expectedErr: true,
+			expectedOutput: `## Preview (client-side dry-run)
+
+> **Error:** inventory object not found
+
+No resources.
+`,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			out, err := printEvents(NewMarkdownFormatterFactory(""), tc.previewStrategy, tc.events)
+			if tc.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedOutput, out)
+		})
+	}
+}
+
+func TestHTMLFormatter(t *testing.T) {
+	out, err := printEvents(NewHTMLFormatterFactory(""), common.DryRunServer, []event.Event{
+		applyEvent(dep, event.Created, nil),
+		applyEvent(cm, event.Failed, fmt.Errorf("field <data> is invalid")),
+	})
+	assert.Error(t, err)
+	assert.Contains(t, out, "<h2>Preview (server-side dry-run)</h2>")
+	assert.Contains(t, out, "<tr><th>Kind</th><th>To create</th><th>Apply failed</th></tr>")
+	assert.Contains(t, out, "<tr><td>ConfigMap</td><td>0</td><td>1</td></tr>")
+	assert.Contains(t, out, "<tr><td><strong>Total</strong></td><td><strong>1</strong></td><td><strong>1</strong></td></tr>")
+	assert.Contains(t, out, "<details open>\n<summary>Apply failed (1)</summary>")
+	assert.Contains(t, out, "<li>ConfigMap default/cm<pre>field &lt;data&gt; is invalid</pre></li>")
+}
+
+func TestCodeFence(t *testing.T) {
+	assert.Equal(t, "```", codeFence("no backticks"))
+	assert.Equal(t, "```", codeFence("some `code`"))
+	assert.Equal(t, "````", codeFence("```yaml\nfoo: bar\n```"))
+}
+
+func printEvents(factory list.FormatterFactory, previewStrategy common.DryRunStrategy,
+	events []event.Event) (string, error) {
+	ioStreams, _, out, _ := genericclioptions.NewTestIOStreams()
+	printer := &list.BaseListPrinter{
+		IOStreams:        ioStreams,
+		FormatterFactory: factory,
+	}
+	ch := make(chan event.Event)
+	go func() {
+		defer close(ch)
+		for _, e := range events {
+			ch <- e
+		}
+	}()
+	err := printer.Print(ch, previewStrategy)
+	return out.String(), err
+}
+
+func applyEvent(id object.ObjMetadata, op event.ApplyEventOperation, err error) event.Event {
+	return event.Event{
+		Type: event.ApplyType,
+		ApplyEvent: event.ApplyEvent{
+			Type:       event.ApplyEventResourceUpdate,
+			Operation:  op,
+			Identifier: id,
+			Error:      err,
+		},
+	}
+}
+
+func pruneEvent(id object.ObjMetadata, op event.PruneEventOperation) event.Event {
+	return event.Event{
+		Type: event.PruneType,
+		PruneEvent: event.PruneEvent{
+			Type:       event.PruneEventResourceUpdate,
+			Operation:  op,
+			Identifier: id,
+		},
+	}
+}
+
+func createIdentifier(group, kind, namespace, name string) object.ObjMetadata {
+	return object.ObjMetadata{
+		Namespace: namespace,
+		Name:      name,
+		GroupKind: schema.GroupKind{
+			Group: group,
+			Kind:  kind,
+		},
+	}
+}
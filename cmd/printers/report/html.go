@@ -0,0 +1,55 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package report
+
+import (
+	"html/template"
+	"io"
+)
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"resourceName": resourceName,
+	"total": func(r *report, i int) int {
+		total := 0
+		for _, k := range r.Kinds {
+			total += k.Counts[i]
+		}
+		return total
+	},
+}).Parse(`<h2>{{ .Title }}</h2>
+{{- range .Errors }}
+<p><strong>Error:</strong> {{ . }}</p>
+{{- end }}
+{{- if not .Sections }}
+<p>No resources.</p>
+{{- else }}
+<table>
+<thead>
+<tr><th>Kind</th>{{ range .Sections }}<th>{{ .Title }}</th>{{ end }}</tr>
+</thead>
+<tbody>
+{{- range .Kinds }}
+<tr><td>{{ .Kind }}</td>{{ range .Counts }}<td>{{ . }}</td>{{ end }}</tr>
+{{- end }}
+<tr><td><strong>Total</strong></td>{{ range $i, $s := .Sections }}<td><strong>{{ total $ $i }}</strong></td>{{ end }}</tr>
+</tbody>
+</table>
+{{- range .Sections }}
+<details{{ if .Failed }} open{{ end }}>
+<summary>{{ .Title }} ({{ len .Entries }})</summary>
+<ul>
+{{- range .Entries }}
+<li>{{ resourceName .Identifier }}{{ if .Error }}<pre>{{ .Error }}</pre>{{ end }}</li>
+{{- end }}
+</ul>
+</details>
+{{- end }}
+{{- end }}
+`))
+
+// renderHTML writes the report as an html fragment. The sections use
+// the details element so they can be collapsed.
+func renderHTML(w io.Writer, r *report) error {
+	return htmlTemplate.Execute(w, r)
+}
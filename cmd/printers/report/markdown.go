@@ -0,0 +1,94 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// renderMarkdown writes the report as GitHub flavored markdown. The
+// sections use the details element so they can be collapsed.
+func renderMarkdown(w io.Writer, r *report) error {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "## %s\n\n", r.Title)
+
+	for _, e := range r.Errors {
+		fmt.Fprintf(&b, "> **Error:** %s\n\n", strings.ReplaceAll(e, "\n", " "))
+	}
+
+	if len(r.Sections) == 0 {
+		b.WriteString("No resources.\n")
+		_, err := w.Write(b.Bytes())
+		return err
+	}
+
+	b.WriteString("| Kind |")
+	for _, s := range r.Sections {
+		fmt.Fprintf(&b, " %s |", s.Title)
+	}
+	b.WriteString("\n| --- |")
+	for range r.Sections {
+		b.WriteString(" ---: |")
+	}
+	b.WriteString("\n")
+	totals := make([]int, len(r.Sections))
+	for _, k := range r.Kinds {
+		fmt.Fprintf(&b, "| %s |", k.Kind)
+		for i, c := range k.Counts {
+			fmt.Fprintf(&b, " %d |", c)
+			totals[i] += c
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("| **Total** |")
+	for _, t := range totals {
+		fmt.Fprintf(&b, " **%d** |", t)
+	}
+	b.WriteString("\n")
+
+	for _, s := range r.Sections {
+		open := ""
+		if s.Failed {
+			open = " open"
+		}
+		fmt.Fprintf(&b, "\n<details%s>\n<summary>%s (%d)</summary>\n\n", open, s.Title, len(s.Entries))
+		for _, e := range s.Entries {
+			fmt.Fprintf(&b, "- %s\n", resourceName(e.Identifier))
+			if e.Error != "" {
+				fence := codeFence(e.Error)
+				fmt.Fprintf(&b, "  %s\n", fence)
+				for _, line := range strings.Split(e.Error, "\n") {
+					fmt.Fprintf(&b, "  %s\n", line)
+				}
+				fmt.Fprintf(&b, "  %s\n", fence)
+			}
+		}
+		b.WriteString("\n</details>\n")
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// codeFence returns a fence for a code block that is longer than
+// any sequence of backticks in the text.
+func codeFence(text string) string {
+	longest, current := 0, 0
+	for _, c := range text {
+		if c == '`' {
+			current++
+			if current > longest {
+				longest = current
+			}
+		} else {
+			current = 0
+		}
+	}
+	if longest < 3 {
+		return "```"
+	}
+	return strings.Repeat("`", longest+1)
+}
@@ -79,6 +79,12 @@ type ResourceInfo struct {
 	// DeleteOpResult contains the result after
 	// a delete operation on a resource
 	DeleteOpResult *event.DeleteEventOperation
+
+	// Retry contains information about the last failed
+	// attempt to apply or prune the resource, while the
+	// operation is being retried. It is cleared once the
+	// operation has a result.
+	Retry *event.Retry
 }
 
 // Identifier returns the identifier for the given resource.
@@ -200,30 +206,42 @@ func (r *ResourceStateCollector) processStatusEvent(e event.StatusEvent) {
 
 // processApplyEvent handles events relating to apply operations
 func (r *ResourceStateCollector) processApplyEvent(e event.ApplyEvent) {
-	if e.Type == event.ApplyEventResourceUpdate {
-		identifier := e.Identifier
-		klog.V(7).Infof("processing apply event for %s", identifier)
-		previous, found := r.resourceInfos[identifier]
-		if !found {
-			klog.V(4).Infof("%s apply event not found in ResourceInfos; no processing", identifier)
-			return
-		}
-		previous.ApplyOpResult = &e.Operation
+	if e.Type != event.ApplyEventResourceUpdate && e.Type != event.ApplyEventRetry {
+		return
+	}
+	identifier := e.Identifier
+	klog.V(7).Infof("processing apply event for %s", identifier)
+	previous, found := r.resourceInfos[identifier]
+	if !found {
+		klog.V(4).Infof("%s apply event not found in ResourceInfos; no processing", identifier)
+		return
+	}
+	if e.Type == event.ApplyEventRetry {
+		previous.Retry = &e.Retry
+		return
 	}
+	previous.ApplyOpResult = &e.Operation
+	previous.Retry = nil
 }
 
 // processPruneEvent handles event related to prune operations.
 func (r *ResourceStateCollector) processPruneEvent(e event.PruneEvent) {
-	if e.Type == event.PruneEventResourceUpdate {
-		identifier := e.Identifier
-		klog.V(7).Infof("processing prune event for %s", identifier)
-		previous, found := r.resourceInfos[identifier]
-		if !found {
-			klog.V(4).Infof("%s prune event not found in ResourceInfos; no processing", identifier)
-			return
-		}
-		previous.PruneOpResult = &e.Operation
+	if e.Type != event.PruneEventResourceUpdate && e.Type != event.PruneEventRetry {
+		return
+	}
+	identifier := e.Identifier
+	klog.V(7).Infof("processing prune event for %s", identifier)
+	previous, found := r.resourceInfos[identifier]
+	if !found {
+		klog.V(4).Infof("%s prune event not found in ResourceInfos; no processing", identifier)
+		return
+	}
+	if e.Type == event.PruneEventRetry {
+		previous.Retry = &e.Retry
+		return
 	}
+	previous.PruneOpResult = &e.Operation
+	previous.Retry = nil
 }
 
 // ResourceState contains the latest state for all the resources.
@@ -5,6 +5,7 @@ package table
 
 import (
 	"testing"
+	"time"
 
 	"gotest.tools/assert"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -195,6 +196,52 @@ func TestResourceStateCollector_ProcessStatusEvent(t *testing.T) {
 	}
 }
 
+func TestResourceStateCollector_ProcessRetryEvents(t *testing.T) {
+	rsc := newResourceStateCollector([]event.ResourceGroup{
+		{
+			Action:      event.ApplyAction,
+			Identifiers: []object.ObjMetadata{depID},
+		},
+		{
+			Action:      event.PruneAction,
+			Identifiers: []object.ObjMetadata{customID},
+		},
+	})
+	retry := event.Retry{
+		Attempt:     1,
+		MaxAttempts: 3,
+		Delay:       time.Second,
+	}
+
+	rsc.processApplyEvent(event.ApplyEvent{
+		Type:       event.ApplyEventRetry,
+		Identifier: depID,
+		Retry:      retry,
+	})
+	rsc.processPruneEvent(event.PruneEvent{
+		Type:       event.PruneEventRetry,
+		Identifier: customID,
+		Retry:      retry,
+	})
+	assert.DeepEqual(t, &retry, rsc.resourceInfos[depID].Retry)
+	assert.DeepEqual(t, &retry, rsc.resourceInfos[customID].Retry)
+
+	rsc.processApplyEvent(event.ApplyEvent{
+		Type:       event.ApplyEventResourceUpdate,
+		Identifier: depID,
+		Operation:  event.Configured,
+	})
+	rsc.processPruneEvent(event.PruneEvent{
+		Type:       event.PruneEventResourceUpdate,
+		Identifier: customID,
+		Operation:  event.Pruned,
+	})
+	assert.Assert(t, rsc.resourceInfos[depID].Retry == nil)
+	assert.Assert(t, rsc.resourceInfos[customID].Retry == nil)
+	assert.Equal(t, event.Configured, *rsc.resourceInfos[depID].ApplyOpResult)
+	assert.Equal(t, event.Pruned, *rsc.resourceInfos[customID].PruneOpResult)
+}
+
 func getID(e event.StatusEvent) (object.ObjMetadata, bool) {
 	if e.Resource == nil {
 		return object.ObjMetadata{}, false
@@ -16,6 +16,26 @@ import (
 
 type Printer struct {
 	IOStreams genericclioptions.IOStreams
+	// Columns is optional. If not set, the default columns are used.
+	Columns []table.ColumnDefinition
+	// SortBy is optional. If set, the resources are sorted by the
+	// given column.
+	SortBy *table.SortBy
+}
+
+// ParseColumns parses a comma-separated list of columns. In addition
+// to the pre-defined columns in the table package, the action column
+// is available.
+func ParseColumns(spec string) ([]table.ColumnDefinition, error) {
+	return table.ParseColumns(spec, actionColumnDef)
+}
+
+// ParseSortBy parses the column to sort by. Names are looked up in the
+// provided columns, then the action column and finally the pre-defined
+// columns in the table package.
+func ParseSortBy(spec string, cols []table.ColumnDefinition) (*table.SortBy, error) {
+	available := append([]table.ColumnDefinition{}, cols...)
+	return table.ParseSortBy(spec, append(available, actionColumnDef))
 }
 
 func (t *Printer) Print(ch <-chan event.Event, _ common.DryRunStrategy) error {
@@ -64,9 +84,8 @@ func (t *Printer) Print(ch <-chan event.Event, _ common.DryRunStrategy) error {
 	return err
 }
 
-// columns defines the columns we want to print
-//TODO: We should have the number of columns and their widths be
-// dependent on the space available.
+// columns defines the columns we print by default. The width of the
+// columns is adjusted to the terminal if possible.
 var (
 	actionColumnDef = table.ColumnDef{
 		// Column containing the resource type and name. Currently it does not
@@ -87,12 +106,16 @@ var (
 			}
 
 			var text string
-			switch resInfo.ResourceAction {
-			case event.ApplyAction:
+			switch {
+			case resInfo.Retry != nil:
+				// The operation failed with a transient error and the
+				// next attempt is pending.
+				text = fmt.Sprintf("Retry %d/%d", resInfo.Retry.Attempt+1, resInfo.Retry.MaxAttempts)
+			case resInfo.ResourceAction == event.ApplyAction:
 				if resInfo.ApplyOpResult != nil {
 					text = resInfo.ApplyOpResult.String()
 				}
-			case event.PruneAction:
+			case resInfo.ResourceAction == event.PruneAction:
 				if resInfo.PruneOpResult != nil {
 					text = resInfo.PruneOpResult.String()
 				}
@@ -122,9 +145,14 @@ var (
 func (t *Printer) runPrintLoop(coll *ResourceStateCollector, stop chan struct{}) chan struct{} {
 	finished := make(chan struct{})
 
+	cols := t.Columns
+	if len(cols) == 0 {
+		cols = columns
+	}
 	baseTablePrinter := table.BaseTablePrinter{
 		IOStreams: t.IOStreams,
-		Columns:   columns,
+		Columns:   table.FitColumns(cols, table.TerminalWidth(t.IOStreams.Out)),
+		SortBy:    t.SortBy,
 	}
 
 	linesPrinted := baseTablePrinter.PrintTable(coll.LatestState(), 0)
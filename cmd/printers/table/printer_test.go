@@ -48,6 +48,17 @@ func TestActionColumnDef(t *testing.T) {
 			columnWidth:    15,
 			expectedOutput: "Pruned",
 		},
+		"retrying": {
+			resource: &ResourceInfo{
+				ResourceAction: event.ApplyAction,
+				Retry: &event.Retry{
+					Attempt:     1,
+					MaxAttempts: 3,
+				},
+			},
+			columnWidth:    15,
+			expectedOutput: "Retry 2/3",
+		},
 		"trimmed output": {
 			resource: &ResourceInfo{
 				ResourceAction: event.ApplyAction,
@@ -0,0 +1,83 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package replay
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"sigs.k8s.io/cli-utils/cmd/flagutils"
+	"sigs.k8s.io/cli-utils/cmd/printers"
+	"sigs.k8s.io/cli-utils/pkg/apply/record"
+)
+
+// GetReplayRunner creates and returns the ReplayRunner which stores the cobra command.
+func GetReplayRunner(ioStreams genericclioptions.IOStreams) *ReplayRunner {
+	r := &ReplayRunner{
+		ioStreams: ioStreams,
+	}
+	cmd := &cobra.Command{
+		Use:                   "replay FILE",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Replay events recorded with the --record flag"),
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  r.RunE,
+	}
+
+	cmd.Flags().StringVar(&r.output, "output", printers.DefaultPrinter(),
+		fmt.Sprintf("Output format, must be one of %s", strings.Join(printers.SupportedPrinters(), ",")))
+	cmd.Flags().StringVar(&r.reportFile, flagutils.ReportFileFlag, "", flagutils.ReportFileHelp)
+	cmd.Flags().BoolVar(&r.keepOpen, flagutils.KeepOpenFlag, false, flagutils.KeepOpenHelp)
+	cmd.Flags().Float64Var(&r.speed, "speed", 1,
+		"Speed of the replay relative to the recording. A value of 2 replays "+
+			"the events twice as fast, and 0 replays them without any delay.")
+
+	r.Command = cmd
+	return r
+}
+
+// ReplayCommand creates the ReplayRunner, returning the cobra command associated with it.
+func ReplayCommand(ioStreams genericclioptions.IOStreams) *cobra.Command {
+	return GetReplayRunner(ioStreams).Command
+}
+
+// ReplayRunner encapsulates data necessary to run the replay command.
+type ReplayRunner struct {
+	Command   *cobra.Command
+	ioStreams genericclioptions.IOStreams
+
+	output     string
+	reportFile string
+	keepOpen   bool
+	speed      float64
+}
+
+// RunE reads the recording and feeds the events to the printer. It
+// returns the same error as the printer did when the events were
+// recorded, so the exit code is also the same.
+func (r *ReplayRunner) RunE(_ *cobra.Command, args []string) error {
+	rec, err := record.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("error reading recording: %v", err)
+	}
+	drs, err := rec.DryRunStrategy()
+	if err != nil {
+		return err
+	}
+	ch, err := rec.Replay(r.speed)
+	if err != nil {
+		return err
+	}
+
+	// The printer will print updates from the channel. It will block
+	// until the channel is closed.
+	printer := printers.GetPrinterWithOptions(r.output, r.ioStreams, printers.Options{
+		ReportFile: r.reportFile,
+		KeepOpen:   r.keepOpen,
+	})
+	return printer.Print(ch, drs)
+}
@@ -0,0 +1,209 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package status
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/go-errors/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"sigs.k8s.io/cli-utils/cmd/status/printers"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/collector"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+const stdinFilename = "-"
+
+// GetComputeRunner returns a new ComputeRunner with the cobra command
+// for computing status of resources from manifests on disk.
+func GetComputeRunner() *ComputeRunner {
+	r := &ComputeRunner{}
+	c := &cobra.Command{
+		Use:   "compute [-f FILENAME]",
+		Short: "Compute the status of resources from manifests without a cluster",
+		Long: "Compute the status of every resource in the provided manifests. " +
+			"Manifests can be read from files or stdin and can contain multiple " +
+			"documents or Lists, like the output from 'kubectl get -o yaml'. " +
+			"The command exits with a non-zero exit code if any resource " +
+			"is not Current.",
+		Args: cobra.NoArgs,
+		RunE: r.runE,
+	}
+	c.Flags().StringSliceVarP(&r.filenames, "filename", "f", []string{},
+		"Files that contain the manifests. Use '-' to read from stdin. "+
+			"If no files are provided, the manifests are read from stdin.")
+	c.Flags().StringVar(&r.output, "output", "events", "Output format.")
+
+	r.Command = c
+	return r
+}
+
+// ComputeRunner captures the parameters for the compute command and
+// contains the run function.
+type ComputeRunner struct {
+	Command *cobra.Command
+
+	filenames []string
+	output    string
+}
+
+// runE reads all resources from the provided files, computes the status
+// for each of them and passes the result to one of the status printers.
+func (r *ComputeRunner) runE(cmd *cobra.Command, _ []string) error {
+	objs, err := r.readObjects(cmd.InOrStdin())
+	if err != nil {
+		return err
+	}
+
+	if len(objs) == 0 {
+		_, _ = fmt.Fprint(cmd.OutOrStdout(), "no resources found\n")
+		return nil
+	}
+
+	printer, err := printers.CreatePrinter(r.output, genericclioptions.IOStreams{
+		In:     cmd.InOrStdin(),
+		Out:    cmd.OutOrStdout(),
+		ErrOut: cmd.ErrOrStderr(),
+	})
+	if err != nil {
+		return errors.WrapPrefix(err, "error creating printer", 1)
+	}
+
+	resourceStatuses := computeStatuses(objs)
+	identifiers := make([]object.ObjMetadata, 0, len(resourceStatuses))
+	for _, rs := range resourceStatuses {
+		identifiers = append(identifiers, rs.Identifier)
+	}
+
+	// The printers consume a channel of events, so we just feed the
+	// computed statuses into a channel and close it when done. The
+	// channel is buffered so all events can be sent before the
+	// printer starts.
+	eventChannel := make(chan event.Event, len(resourceStatuses))
+	for _, rs := range resourceStatuses {
+		eventChannel <- event.Event{
+			EventType: event.ResourceUpdateEvent,
+			Resource:  rs,
+		}
+	}
+	close(eventChannel)
+	printer.Print(eventChannel, identifiers, func(*collector.ResourceStatusCollector, event.Event) {})
+
+	var notCurrent int
+	for _, rs := range resourceStatuses {
+		if rs.Status != status.CurrentStatus {
+			notCurrent++
+		}
+	}
+	if notCurrent > 0 {
+		return fmt.Errorf("%d of %d resources are not Current", notCurrent, len(resourceStatuses))
+	}
+	return nil
+}
+
+// readObjects reads the resources from all the files provided by the
+// filename flag, or from the provided reader if no files were provided.
+func (r *ComputeRunner) readObjects(stdin io.Reader) ([]*unstructured.Unstructured, error) {
+	filenames := r.filenames
+	if len(filenames) == 0 {
+		filenames = []string{stdinFilename}
+	}
+
+	var objs []*unstructured.Unstructured
+	for _, filename := range filenames {
+		if filename == stdinFilename {
+			o, err := decodeObjects(stdin)
+			if err != nil {
+				return nil, errors.WrapPrefix(err, "error reading from stdin", 1)
+			}
+			objs = append(objs, o...)
+			continue
+		}
+		f, err := os.Open(filename)
+		if err != nil {
+			return nil, err
+		}
+		o, err := decodeObjects(f)
+		_ = f.Close()
+		if err != nil {
+			return nil, errors.WrapPrefix(err, fmt.Sprintf("error reading %s", filename), 1)
+		}
+		objs = append(objs, o...)
+	}
+	return objs, nil
+}
+
+// decodeObjects decodes all the YAML or JSON documents from the reader
+// into Unstructured objects. Any Lists will be expanded, so the returned
+// slice only contains the items in the List.
+func decodeObjects(reader io.Reader) ([]*unstructured.Unstructured, error) {
+	var objs []*unstructured.Unstructured
+	decoder := yaml.NewYAMLOrJSONDecoder(reader, 4096)
+	for {
+		var raw json.RawMessage
+		err := decoder.Decode(&raw)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		// Empty documents are ignored.
+		if len(bytes.TrimSpace(raw)) == 0 || bytes.Equal(raw, []byte("null")) {
+			continue
+		}
+		// We use the UnstructuredJSONScheme here rather than decoding
+		// into a map, since it makes sure numbers are decoded into int64
+		// rather than float64.
+		obj, err := runtime.Decode(unstructured.UnstructuredJSONScheme, raw)
+		if err != nil {
+			return nil, err
+		}
+		switch o := obj.(type) {
+		case *unstructured.Unstructured:
+			objs = append(objs, o)
+		case *unstructured.UnstructuredList:
+			for i := range o.Items {
+				objs = append(objs, &o.Items[i])
+			}
+		default:
+			return nil, fmt.Errorf("unexpected object type %T", obj)
+		}
+	}
+	return objs, nil
+}
+
+// computeStatuses computes the status for each of the provided resources.
+// If status can not be computed for a resource, it will have the Unknown
+// status and the error will be available in the Error field.
+func computeStatuses(objs []*unstructured.Unstructured) []*event.ResourceStatus {
+	var resourceStatuses []*event.ResourceStatus
+	for _, obj := range objs {
+		rs := &event.ResourceStatus{
+			Identifier: object.UnstructuredToObjMeta(obj),
+			Resource:   obj,
+		}
+		res, err := status.Compute(obj)
+		if err != nil {
+			rs.Status = status.UnknownStatus
+			rs.Message = err.Error()
+			rs.Error = err
+		} else {
+			rs.Status = res.Status
+			rs.Message = res.Message
+		}
+		resourceStatuses = append(resourceStatuses, rs)
+	}
+	return resourceStatuses
+}
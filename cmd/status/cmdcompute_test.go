@@ -0,0 +1,159 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package status
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	currentDeployment = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+  namespace: default
+  generation: 1
+status:
+  observedGeneration: 1
+  replicas: 1
+  updatedReplicas: 1
+  readyReplicas: 1
+  availableReplicas: 1
+  conditions:
+  - type: Available
+    status: "True"
+  - type: Progressing
+    status: "True"
+    reason: NewReplicaSetAvailable
+`
+
+	inProgressStatefulSet = `
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: bar
+  namespace: default
+  generation: 2
+status:
+  observedGeneration: 1
+`
+
+	configMapList = `
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: cm1
+    namespace: default
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: cm2
+    namespace: default
+`
+)
+
+func TestComputeCommand(t *testing.T) {
+	testCases := map[string]struct {
+		stdin          string
+		files          map[string]string
+		expectedErrMsg string
+		expectedOutput string
+	}{
+		"no resources": {
+			stdin:          "",
+			expectedOutput: "no resources found\n",
+		},
+		"all resources current from stdin": {
+			stdin: currentDeployment,
+			expectedOutput: `
+deployment.apps/foo is Current: Deployment is available. Replicas: 1
+`,
+		},
+		"resource not current": {
+			stdin: currentDeployment + "\n---\n" + inProgressStatefulSet,
+			expectedOutput: `
+deployment.apps/foo is Current: Deployment is available. Replicas: 1
+statefulset.apps/bar is InProgress: StatefulSet generation is 2, but latest observed generation is 1
+`,
+			expectedErrMsg: "1 of 2 resources are not Current",
+		},
+		"list from file": {
+			files: map[string]string{
+				"dump.yaml": configMapList,
+			},
+			expectedOutput: `
+configmap/cm1 is Current: Resource is always ready
+configmap/cm2 is Current: Resource is always ready
+`,
+		},
+		"multiple files": {
+			files: map[string]string{
+				"a.yaml": currentDeployment,
+				"b.yaml": inProgressStatefulSet,
+			},
+			expectedOutput: `
+deployment.apps/foo is Current: Deployment is available. Replicas: 1
+statefulset.apps/bar is InProgress: StatefulSet generation is 2, but latest observed generation is 1
+`,
+			expectedErrMsg: "1 of 2 resources are not Current",
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "compute-test")
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			defer os.RemoveAll(dir)
+
+			var filenames []string
+			for _, name := range []string{"a.yaml", "b.yaml", "dump.yaml"} {
+				content, found := tc.files[name]
+				if !found {
+					continue
+				}
+				path := filepath.Join(dir, name)
+				if !assert.NoError(t, ioutil.WriteFile(path, []byte(content), 0600)) {
+					t.FailNow()
+				}
+				filenames = append(filenames, path)
+			}
+
+			runner := &ComputeRunner{
+				filenames: filenames,
+				output:    "events",
+			}
+
+			cmd := &cobra.Command{}
+			cmd.SetIn(strings.NewReader(tc.stdin))
+			var buf bytes.Buffer
+			cmd.SetOut(&buf)
+
+			err = runner.runE(cmd, []string{})
+
+			if tc.expectedErrMsg != "" {
+				if !assert.Error(t, err) {
+					t.FailNow()
+				}
+				assert.Contains(t, err.Error(), tc.expectedErrMsg)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, strings.TrimSpace(tc.expectedOutput), strings.TrimSpace(buf.String()))
+		})
+	}
+}
@@ -12,7 +12,10 @@ import (
 	"github.com/spf13/cobra"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"sigs.k8s.io/cli-utils/cmd/flagutils"
+	jsonprinter "sigs.k8s.io/cli-utils/cmd/printers/json"
 	"sigs.k8s.io/cli-utils/cmd/status/printers"
+	statusprinter "sigs.k8s.io/cli-utils/cmd/status/printers/printer"
 	"sigs.k8s.io/cli-utils/pkg/apply/poller"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/polling"
@@ -21,6 +24,7 @@ import (
 	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
 	"sigs.k8s.io/cli-utils/pkg/manifestreader"
+	"sigs.k8s.io/cli-utils/pkg/print/table"
 	"sigs.k8s.io/cli-utils/pkg/provider"
 	"sigs.k8s.io/cli-utils/pkg/util/factory"
 )
@@ -40,8 +44,16 @@ func GetStatusRunner(provider provider.Provider, loader manifestreader.ManifestL
 	c.Flags().StringVar(&r.pollUntil, "poll-until", "known",
 		"When to stop polling. Must be one of 'known', 'current', 'deleted', or 'forever'.")
 	c.Flags().StringVar(&r.output, "output", "events", "Output format.")
+	c.Flags().StringVar(&r.reportFile, flagutils.ReportFileFlag, "", flagutils.ReportFileHelp)
+	c.Flags().StringVar(&r.summaryFile, flagutils.SummaryFileFlag, "", flagutils.SummaryFileHelp)
+	c.Flags().StringVar(&r.columns, flagutils.ColumnsFlag, "", flagutils.ColumnsHelp)
+	c.Flags().StringVar(&r.sortBy, flagutils.SortByFlag, "", flagutils.SortByHelp)
 	c.Flags().DurationVar(&r.timeout, "timeout", 0,
 		"How long to wait before exiting")
+	c.Flags().BoolVar(&r.statusSummary, "status-summary", false,
+		"If true, print a summary of how long each resource took to become Current. "+
+			"Only supported by the events output.")
+	c.AddCommand(GetComputeRunner().Command)
 
 	r.Command = c
 	return r
@@ -65,6 +77,12 @@ type StatusRunner struct {
 	timeout   time.Duration
 	output    string
 
+	statusSummary bool
+	reportFile    string
+	summaryFile   string
+	columns       string
+	sortBy        string
+
 	pollerFactoryFunc func(cmdutil.Factory) (poller.Poller, error)
 }
 
@@ -77,6 +95,18 @@ func (r *StatusRunner) runE(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	var columns []table.ColumnDefinition
+	if r.columns != "" {
+		columns, err = table.ParseColumns(r.columns)
+		if err != nil {
+			return err
+		}
+	}
+	sortBy, err := table.ParseSortBy(r.sortBy, columns)
+	if err != nil {
+		return err
+	}
+
 	reader, err := r.loader.ManifestReader(cmd.InOrStdin(), args)
 	if err != nil {
 		return err
@@ -116,14 +146,22 @@ func (r *StatusRunner) runE(cmd *cobra.Command, args []string) error {
 
 	// Fetch a printer implementation based on the desired output format as
 	// specified in the output flag.
-	printer, err := printers.CreatePrinter(r.output, genericclioptions.IOStreams{
+	printer, err := printers.CreatePrinterWithOptions(r.output, genericclioptions.IOStreams{
 		In:     cmd.InOrStdin(),
 		Out:    cmd.OutOrStdout(),
 		ErrOut: cmd.ErrOrStderr(),
+	}, printers.Options{
+		ReportFile: r.reportFile,
+		Columns:    columns,
+		SortBy:     sortBy,
 	})
 	if err != nil {
 		return errors.WrapPrefix(err, "error creating printer", 1)
 	}
+	summaryPrinter, supportsSummary := printer.(statusprinter.SummaryPrinter)
+	if r.statusSummary && !supportsSummary {
+		return fmt.Errorf("--status-summary is not supported by the %q printer", r.output)
+	}
 
 	// If the user has specified a timeout, we create a context with timeout,
 	// otherwise we create a context with cancel.
@@ -157,7 +195,44 @@ func (r *StatusRunner) runE(cmd *cobra.Command, args []string) error {
 		UseCache:     true,
 	})
 
+	// If the summary is enabled, we record every status update
+	// in addition to invoking the original ObserverFunc.
+	var timeline *collector.TimelineRecorder
+	if r.statusSummary {
+		timeline = collector.NewTimelineRecorder()
+		observer := cancelFunc
+		cancelFunc = func(rsc *collector.ResourceStatusCollector, e event.Event) {
+			if e.EventType == event.ResourceUpdateEvent {
+				timeline.Record(e.Resource)
+			}
+			observer(rsc, e)
+		}
+	}
+
+	// If a summary file has been requested, we also record every status
+	// update for the summary document.
+	var summary *jsonprinter.SummaryRecorder
+	if r.summaryFile != "" {
+		summary = jsonprinter.NewSummaryRecorder("status", inv)
+		observer := cancelFunc
+		cancelFunc = func(rsc *collector.ResourceStatusCollector, e event.Event) {
+			if e.EventType == event.ResourceUpdateEvent {
+				summary.RecordStatus(e.Resource)
+			}
+			observer(rsc, e)
+		}
+	}
+
 	printer.Print(eventChannel, identifiers, cancelFunc)
+
+	if timeline != nil {
+		if err := summaryPrinter.PrintStatusSummary(timeline.Summary()); err != nil {
+			return err
+		}
+	}
+	if summary != nil {
+		return summary.WriteFile(r.summaryFile, nil)
+	}
 	return nil
 }
 
@@ -58,6 +58,7 @@ func TestStatusCommand(t *testing.T) {
 		pollUntil      string
 		printer        string
 		timeout        time.Duration
+		statusSummary  bool
 		input          string
 		inventory      []object.ObjMetadata
 		events         []pollevent.Event
@@ -183,6 +184,40 @@ statefulset.apps/bar is NotFound: notFound
 deployment.apps/foo is NotFound: notFound
 `,
 		},
+		"status summary with events printer": {
+			pollUntil:     "current",
+			printer:       "events",
+			statusSummary: true,
+			input:         inventoryTemplate,
+			inventory: []object.ObjMetadata{
+				depObject,
+			},
+			events: []pollevent.Event{
+				{
+					EventType: pollevent.ResourceUpdateEvent,
+					Resource: &pollevent.ResourceStatus{
+						Identifier: depObject,
+						Status:     status.CurrentStatus,
+						Message:    "current",
+					},
+				},
+			},
+			expectedOutput: `
+deployment.apps/foo is Current: current
+1 of 1 resource(s) became Current in 0s, slowest first:
+deployment.apps/foo became Current after 0s
+`,
+		},
+		"status summary with table printer": {
+			pollUntil:     "current",
+			printer:       "table",
+			statusSummary: true,
+			input:         inventoryTemplate,
+			inventory: []object.ObjMetadata{
+				depObject,
+			},
+			expectedErrMsg: `--status-summary is not supported by the "table" printer`,
+		},
 		"forever with timeout": {
 			pollUntil: "forever",
 			printer:   "events",
@@ -231,9 +266,10 @@ deployment.apps/foo is InProgress: inProgress
 					return &fakePoller{tc.events}, nil
 				},
 
-				pollUntil: tc.pollUntil,
-				output:    tc.printer,
-				timeout:   tc.timeout,
+				pollUntil:     tc.pollUntil,
+				output:        tc.printer,
+				timeout:       tc.timeout,
+				statusSummary: tc.statusSummary,
 			}
 
 			cmd := &cobra.Command{}
@@ -273,3 +309,24 @@ func (f *fakePoller) Poll(ctx context.Context, _ []object.ObjMetadata,
 	}()
 	return eventChannel
 }
+
+func TestStatusCommand_ComputeSubcommand(t *testing.T) {
+	tf := cmdtesting.NewTestFactory().WithNamespace("namespace")
+	defer tf.Cleanup()
+	provider := provider.NewFakeProvider(tf, nil)
+	loader := manifestreader.NewFakeLoader(tf, nil)
+	statusCmd := GetStatusRunner(provider, loader).Command
+	root := &cobra.Command{Use: "kapply"}
+	root.AddCommand(statusCmd)
+
+	found, args, err := root.Find([]string{"status", "compute", "-f", "manifests.yaml"})
+	assert.NoError(t, err)
+	assert.Equal(t, "compute", found.Name())
+	assert.Equal(t, []string{"-f", "manifests.yaml"}, args)
+
+	// Other arguments are still the package directory for status.
+	found, args, err = root.Find([]string{"status", "pkg"})
+	assert.NoError(t, err)
+	assert.Equal(t, statusCmd, found)
+	assert.Equal(t, []string{"pkg"}, args)
+}
@@ -9,9 +9,12 @@ import (
 
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"sigs.k8s.io/cli-utils/cmd/printers/events"
+	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/collector"
 	pollevent "sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
 	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/print/list"
 )
 
 // eventPrinter implements the Printer interface and outputs the resource
@@ -48,6 +51,13 @@ func (ep *eventPrinter) Print(ch <-chan pollevent.Event, identifiers []object.Ob
 	<-done
 }
 
+// PrintStatusSummary prints the summary in the same format as the
+// events printer of the apply command.
+func (ep *eventPrinter) PrintStatusSummary(ts collector.TimelineSummary) error {
+	formatter := events.NewFormatter(ep.ioStreams, common.DryRunNone)
+	return formatter.(list.StatusSummaryFormatter).FormatStatusSummary(ts)
+}
+
 func (ep *eventPrinter) printStatusEvent(se pollevent.Event) {
 	switch se.EventType {
 	case pollevent.ResourceUpdateEvent:
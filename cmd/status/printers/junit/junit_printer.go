@@ -0,0 +1,86 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package junit
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/collector"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/print/junit"
+)
+
+// junitPrinter is an implementation of the Printer interface that
+// writes a JUnit report once polling has stopped. Every resource
+// becomes a test case, which passes if the resource is Current or
+// NotFound. Otherwise the failure type is the status of the resource.
+type junitPrinter struct {
+	ioStreams  genericclioptions.IOStreams
+	reportFile string
+}
+
+// NewJUnitPrinter returns a new instance of the junitPrinter. The report
+// is written to the reportFile, or the output stream if it is empty.
+func NewJUnitPrinter(ioStreams genericclioptions.IOStreams, reportFile string) *junitPrinter {
+	return &junitPrinter{
+		ioStreams:  ioStreams,
+		reportFile: reportFile,
+	}
+}
+
+// Print takes an event channel and collects the status events until
+// the channel is closed. It then writes the report.
+func (j *junitPrinter) Print(ch <-chan event.Event, identifiers []object.ObjMetadata,
+	cancelFunc collector.ObserverFunc) {
+	start := time.Now()
+	coll := collector.NewResourceStatusCollector(identifiers)
+	// Block until the done channel is closed.
+	<-coll.ListenWithObserver(ch, cancelFunc)
+
+	suite := junit.NewTestSuite("status", start)
+	for _, rs := range coll.LatestObservation().ResourceStatuses {
+		suite.AddTestCase(newTestCase(rs))
+	}
+	suite.Time = time.Since(start).Seconds()
+	report := junit.NewTestSuites(suite)
+	if err := report.WriteFile(j.reportFile, j.ioStreams.Out); err != nil {
+		fmt.Fprintf(j.ioStreams.ErrOut, "error writing report: %v\n", err)
+	}
+}
+
+func newTestCase(rs *event.ResourceStatus) *junit.TestCase {
+	id := rs.Identifier
+	className := "status"
+	if id.Namespace != "" {
+		className += "." + id.Namespace
+	}
+	tc := &junit.TestCase{
+		Name:      fmt.Sprintf("%s/%s", strings.ToLower(id.GroupKind.String()), id.Name),
+		ClassName: className,
+	}
+	switch {
+	case rs.Error != nil:
+		tc.Failure = &junit.Failure{
+			Message: fmt.Sprintf("error computing status: %s", rs.Error.Error()),
+			Type:    "Error",
+			Text:    rs.Error.Error(),
+		}
+	case rs.Status == status.CurrentStatus || rs.Status == status.NotFoundStatus:
+	default:
+		// The printer doesn't know whether polling stopped because of a
+		// timeout, so the failure type is the status of the resource.
+		msg := fmt.Sprintf("resource has status %s: %s", rs.Status, rs.Message)
+		tc.Failure = &junit.Failure{
+			Message: msg,
+			Type:    rs.Status.String(),
+			Text:    msg,
+		}
+	}
+	return tc
+}
@@ -0,0 +1,90 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package junit
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/print/junit"
+)
+
+func TestNewTestCase(t *testing.T) {
+	depID := object.ObjMetadata{
+		GroupKind: schema.GroupKind{
+			Group: "apps",
+			Kind:  "Deployment",
+		},
+		Namespace: "default",
+		Name:      "foo",
+	}
+
+	testCases := map[string]struct {
+		resource        *event.ResourceStatus
+		expectedFailure *junit.Failure
+	}{
+		"current": {
+			resource: &event.ResourceStatus{
+				Identifier: depID,
+				Status:     status.CurrentStatus,
+			},
+		},
+		"not found": {
+			resource: &event.ResourceStatus{
+				Identifier: depID,
+				Status:     status.NotFoundStatus,
+			},
+		},
+		"failed": {
+			resource: &event.ResourceStatus{
+				Identifier: depID,
+				Status:     status.FailedStatus,
+				Message:    "Progress deadline exceeded",
+			},
+			expectedFailure: &junit.Failure{
+				Message: "resource has status Failed: Progress deadline exceeded",
+				Type:    "Failed",
+				Text:    "resource has status Failed: Progress deadline exceeded",
+			},
+		},
+		"in progress": {
+			resource: &event.ResourceStatus{
+				Identifier: depID,
+				Status:     status.InProgressStatus,
+				Message:    "Replicas: 1/2",
+			},
+			expectedFailure: &junit.Failure{
+				Message: "resource has status InProgress: Replicas: 1/2",
+				Type:    "InProgress",
+				Text:    "resource has status InProgress: Replicas: 1/2",
+			},
+		},
+		"error": {
+			resource: &event.ResourceStatus{
+				Identifier: depID,
+				Status:     status.UnknownStatus,
+				Error:      fmt.Errorf("forbidden"),
+			},
+			expectedFailure: &junit.Failure{
+				Message: "error computing status: forbidden",
+				Type:    "Error",
+				Text:    "forbidden",
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			testCase := newTestCase(tc.resource)
+			assert.Equal(t, "deployment.apps/foo", testCase.Name)
+			assert.Equal(t, "status.default", testCase.ClassName)
+			assert.Equal(t, tc.expectedFailure, testCase.Failure)
+		})
+	}
+}
@@ -23,3 +23,9 @@ type Printer interface {
 	// program terminates.
 	Print(ch <-chan event.Event, identifiers []object.ObjMetadata, cancelFunc collector.ObserverFunc)
 }
+
+// SummaryPrinter is implemented by printers that can print a summary
+// of how long each resource took to become Current.
+type SummaryPrinter interface {
+	PrintStatusSummary(ts collector.TimelineSummary) error
+}
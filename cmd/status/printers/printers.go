@@ -5,18 +5,45 @@ package printers
 
 import (
 	"sigs.k8s.io/cli-utils/cmd/status/printers/event"
+	"sigs.k8s.io/cli-utils/cmd/status/printers/junit"
 	"sigs.k8s.io/cli-utils/cmd/status/printers/printer"
 	"sigs.k8s.io/cli-utils/cmd/status/printers/table"
+	printtable "sigs.k8s.io/cli-utils/pkg/print/table"
 
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 )
 
+// Options contains settings for the printers. Not all printers
+// use every option.
+type Options struct {
+	// ReportFile is the file the junit printer writes the report to.
+	// If it is empty, the report is written to the output stream.
+	ReportFile string
+
+	// Columns are the columns printed by the table printer. If empty,
+	// the default columns are used.
+	Columns []printtable.ColumnDefinition
+
+	// SortBy is the column the table printer sorts the resources by.
+	// If nil, the resources are not sorted.
+	SortBy *printtable.SortBy
+}
+
 // CreatePrinter return an implementation of the Printer interface. The
 // actual implementation is based on the printerType requested.
 func CreatePrinter(printerType string, ioStreams genericclioptions.IOStreams) (printer.Printer, error) {
+	return CreatePrinterWithOptions(printerType, ioStreams, Options{})
+}
+
+// CreatePrinterWithOptions is like CreatePrinter, but allows the
+// printers to be configured with the provided options.
+func CreatePrinterWithOptions(printerType string, ioStreams genericclioptions.IOStreams,
+	opts Options) (printer.Printer, error) {
 	switch printerType {
 	case "table":
-		return table.NewTablePrinter(ioStreams), nil
+		return table.NewTablePrinterWithColumns(ioStreams, opts.Columns, opts.SortBy), nil
+	case "junit":
+		return junit.NewJUnitPrinter(ioStreams, opts.ReportFile), nil
 	default:
 		return event.NewEventPrinter(ioStreams), nil
 	}
@@ -22,12 +22,28 @@ const (
 // status information about resources in a table format with in-place updates.
 type tablePrinter struct {
 	ioStreams genericclioptions.IOStreams
+	columns   []table.ColumnDefinition
+	sortBy    *table.SortBy
 }
 
 // NewTablePrinter returns a new instance of the tablePrinter.
 func NewTablePrinter(ioStreams genericclioptions.IOStreams) *tablePrinter {
+	return NewTablePrinterWithColumns(ioStreams, nil, nil)
+}
+
+// NewTablePrinterWithColumns returns a new instance of the tablePrinter
+// that prints the provided columns, sorted by the sortBy column. If no
+// columns are provided, the default columns are used. The sortBy
+// parameter can be nil.
+func NewTablePrinterWithColumns(ioStreams genericclioptions.IOStreams,
+	cols []table.ColumnDefinition, sortBy *table.SortBy) *tablePrinter {
+	if len(cols) == 0 {
+		cols = columns
+	}
 	return &tablePrinter{
 		ioStreams: ioStreams,
+		columns:   cols,
+		sortBy:    sortBy,
 	}
 }
 
@@ -77,7 +93,8 @@ func (t *tablePrinter) runPrintLoop(coll *CollectorAdapter, stop <-chan struct{}
 
 	baseTablePrinter := table.BaseTablePrinter{
 		IOStreams: t.ioStreams,
-		Columns:   columns,
+		Columns:   table.FitColumns(t.columns, table.TerminalWidth(t.ioStreams.Out)),
+		SortBy:    t.sortBy,
 	}
 
 	linesPrinted := baseTablePrinter.PrintTable(coll.LatestStatus(), 0)
@@ -15,10 +15,15 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/klog"
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/hook"
 	"sigs.k8s.io/cli-utils/pkg/apply/info"
 	"sigs.k8s.io/cli-utils/pkg/apply/poller"
+	"sigs.k8s.io/cli-utils/pkg/apply/preflight"
 	"sigs.k8s.io/cli-utils/pkg/apply/prune"
+	"sigs.k8s.io/cli-utils/pkg/apply/retry"
+	"sigs.k8s.io/cli-utils/pkg/apply/rollback"
 	"sigs.k8s.io/cli-utils/pkg/apply/solver"
+	"sigs.k8s.io/cli-utils/pkg/apply/stage"
 	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
@@ -87,9 +92,12 @@ func (a *Applier) Initialize() error {
 // prepareObjects merges the currently applied objects into the
 // set of stored objects in the cluster inventory. In the process, it
 // calculates the set of objects to be pruned (pruneIds), and orders the
-// resources for the subsequent apply. Returns the sorted resources to
-// apply as well as the objects for the prune, or an error if one occurred.
-func (a *Applier) prepareObjects(localInv inventory.InventoryInfo, localObjs []*unstructured.Unstructured) (*ResourceObjects, error) {
+// resources for the subsequent apply. Only the objects selected by the
+// filter are applied, and only objects within the scope of the filter
+// are pruned. Returns the sorted resources to apply as well as the
+// objects for the prune, or an error if one occurred.
+func (a *Applier) prepareObjects(localInv inventory.InventoryInfo, localObjs []*unstructured.Unstructured,
+	filter object.Filter) (*ResourceObjects, error) {
 	klog.V(4).Infof("applier preparing %d objects", len(localObjs))
 	if localInv == nil {
 		return nil, fmt.Errorf("the local inventory can't be nil")
@@ -97,6 +105,12 @@ func (a *Applier) prepareObjects(localInv inventory.InventoryInfo, localObjs []*
 	if err := inventory.ValidateNoInventory(localObjs); err != nil {
 		return nil, err
 	}
+	if _, err := taskrunner.WaitConditionsForObjects(localObjs); err != nil {
+		return nil, err
+	}
+	if err := hook.Validate(localObjs); err != nil {
+		return nil, err
+	}
 	// Ensures the namespace exists before applying the inventory object into it.
 	if invNamespace := inventoryNamespaceInSet(localInv, localObjs); invNamespace != nil {
 		klog.V(4).Infof("applier prepareObjects applying namespace %s", invNamespace.GetName())
@@ -104,6 +118,10 @@ func (a *Applier) prepareObjects(localInv inventory.InventoryInfo, localObjs []*
 			return nil, err
 		}
 	}
+	localObjs, unselectedObjs := filter.Split(localObjs)
+	if !filter.IsEmpty() {
+		klog.V(4).Infof("applier filter selected %d objects, %d not selected", len(localObjs), len(unselectedObjs))
+	}
 	// Retrieve previous inventory objects. Must happen before inventory client merge.
 	prevInv, err := a.invClient.GetClusterObjs(localInv)
 	if err != nil {
@@ -111,24 +129,41 @@ func (a *Applier) prepareObjects(localInv inventory.InventoryInfo, localObjs []*
 	}
 	klog.V(4).Infof("%d previous inventory objects in cluster", len(prevInv))
 
-	klog.V(4).Infof("applier merging %d objects into inventory", len(localObjs))
-	currentObjs := object.UnstructuredsToObjMetas(localObjs)
+	// Hooks are not stored in the inventory, so they are never pruned.
+	_, invObjs, _ := hook.Split(localObjs)
+	klog.V(4).Infof("applier merging %d objects into inventory", len(invObjs))
+	currentObjs := object.UnstructuredsToObjMetas(invObjs)
 	// returns the objects (pruneIds) to prune after apply. The prune
 	// algorithm requires stopping if the merge is not successful. Otherwise,
-	// the stored objects in inventory could become inconsistent.
+	// the stored objects in inventory could become inconsistent. The merge
+	// keeps the objects that are not selected by the filter, since the
+	// inventory is the union of the previous and the current objects.
 	pruneIds, err := a.invClient.Merge(localInv, currentObjs)
 	if err != nil {
 		return nil, err
 	}
+	unselectedIds := object.UnstructuredsToObjMetas(unselectedObjs)
+	if !filter.IsEmpty() {
+		// The labels of the objects to prune are only checked when
+		// they are pruned, so some of these may be kept.
+		var scopedIds []object.ObjMetadata
+		for _, id := range object.SetDiff(pruneIds, unselectedIds) {
+			if filter.MatchesID(id) {
+				scopedIds = append(scopedIds, id)
+			}
+		}
+		pruneIds = scopedIds
+	}
 	klog.V(4).Infof("after inventory merge; %d objects to prune", len(pruneIds))
 	// Sort order for applied resources.
 	sort.Sort(ordering.SortableUnstructureds(localObjs))
 
 	return &ResourceObjects{
-		LocalInv:  localInv,
-		Resources: localObjs,
-		PruneIds:  pruneIds,
-		PrevInv:   prevInv,
+		LocalInv:      localInv,
+		Resources:     localObjs,
+		PruneIds:      pruneIds,
+		PrevInv:       prevInv,
+		UnselectedIds: unselectedIds,
 	}, nil
 }
 
@@ -140,6 +175,9 @@ type ResourceObjects struct {
 	Resources []*unstructured.Unstructured
 	PruneIds  []object.ObjMetadata
 	PrevInv   []object.ObjMetadata
+	// UnselectedIds are the objects in the package that are not
+	// selected by the filter, so they are not applied.
+	UnselectedIds []object.ObjMetadata
 }
 
 // ObjsForApply returns the unstructured representation for all the resources
@@ -177,6 +215,12 @@ func (r *ResourceObjects) IdsForPrevInv() []object.ObjMetadata {
 	return r.PrevInv
 }
 
+// IdsForUnselected returns the Ids for the objects in the package
+// that are not selected by the filter.
+func (r *ResourceObjects) IdsForUnselected() []object.ObjMetadata {
+	return r.UnselectedIds
+}
+
 // AllIds returns the Ids for all resources that are relevant. This
 // includes resources that will be applied or pruned.
 func (r *ResourceObjects) AllIds() []object.ObjMetadata {
@@ -202,11 +246,27 @@ func (a *Applier) Run(ctx context.Context, invInfo inventory.InventoryInfo, obje
 		// This provides us with a slice of all the objects that will be
 		// applied to the cluster. This takes care of ordering resources
 		// and handling the inventory object.
-		resourceObjects, err := a.prepareObjects(invInfo, objects)
+		if err := options.Filter.Validate(); err != nil {
+			handleError(eventChannel, err)
+			return
+		}
+		// The permissions are checked before the inventory is updated,
+		// so nothing is changed if any permission is missing.
+		if options.CheckPermissions && !options.DryRunStrategy.ClientOrServerDryRun() {
+			if err := a.checkPermissions(ctx, invInfo, objects, options); err != nil {
+				handleError(eventChannel, err)
+				return
+			}
+		}
+		resourceObjects, err := a.prepareObjects(invInfo, objects, options.Filter)
 		if err != nil {
 			handleError(eventChannel, err)
 			return
 		}
+		if err := stage.Validate(options.Stages); err != nil {
+			handleError(eventChannel, err)
+			return
+		}
 
 		mapper, err := a.provider.Factory().ToRESTMapper()
 		if err != nil {
@@ -214,6 +274,14 @@ func (a *Applier) Run(ctx context.Context, invInfo inventory.InventoryInfo, obje
 			return
 		}
 
+		// For atomic apply, the state of the objects is recorded before
+		// they are applied, so they can be rolled back if the apply fails.
+		// Nothing is changed for dry-run, so there is nothing to roll back.
+		var snapshot *rollback.Snapshot
+		if options.Atomic && !options.DryRunStrategy.ClientOrServerDryRun() {
+			snapshot = rollback.NewSnapshot()
+		}
+
 		// Fetch the queue (channel) of tasks that should be executed.
 		klog.V(4).Infoln("applier building task queue...")
 		taskQueue := (&solver.TaskQueueSolver{
@@ -229,6 +297,12 @@ func (a *Applier) Run(ctx context.Context, invInfo inventory.InventoryInfo, obje
 			PrunePropagationPolicy: options.PrunePropagationPolicy,
 			PruneTimeout:           options.PruneTimeout,
 			InventoryPolicy:        options.InventoryPolicy,
+			RetryPolicy:            options.RetryPolicy,
+			RecreateOptions:        options.RecreateOptions,
+			HookTimeout:            options.HookTimeout,
+			Snapshot:               snapshot,
+			Stages:                 options.Stages,
+			Filter:                 options.Filter,
 		})
 
 		// Send event to inform the caller about the resources that
@@ -236,16 +310,12 @@ func (a *Applier) Run(ctx context.Context, invInfo inventory.InventoryInfo, obje
 		eventChannel <- event.Event{
 			Type: event.InitType,
 			InitEvent: event.InitEvent{
-				ResourceGroups: []event.ResourceGroup{
-					{
-						Action:      event.ApplyAction,
-						Identifiers: resourceObjects.IdsForApply(),
-					},
-					{
+				ResourceGroups: append(applyResourceGroups(resourceObjects, options.Stages),
+					event.ResourceGroup{
 						Action:      event.PruneAction,
 						Identifiers: resourceObjects.IdsForPrune(),
 					},
-				},
+				),
 			},
 		}
 
@@ -259,12 +329,113 @@ func (a *Applier) Run(ctx context.Context, invInfo inventory.InventoryInfo, obje
 			EmitStatusEvents: options.EmitStatusEvents,
 		})
 		if err != nil {
+			switch {
+			case snapshot == nil:
+			case snapshot.Sealed():
+				klog.V(4).Infof("applier not rolling back after error during prune: %v", err)
+			default:
+				klog.V(4).Infof("applier rolling back after error: %v", err)
+				a.rollback(eventChannel, resourceObjects, snapshot, mapper)
+			}
 			handleError(eventChannel, err)
 		}
 	}()
 	return eventChannel
 }
 
+// checkPermissions issues a SelfSubjectAccessReview for every
+// permission the apply needs, and returns an error listing the ones
+// that are missing. The objects that may be pruned are computed from
+// the inventory in the cluster without changing it.
+func (a *Applier) checkPermissions(ctx context.Context, invInfo inventory.InventoryInfo,
+	objs []*unstructured.Unstructured, options Options) error {
+	if invInfo == nil {
+		return fmt.Errorf("the local inventory can't be nil")
+	}
+	// The inventory object is read, created and updated, so the
+	// permissions for its type are needed as well.
+	invObj := a.inventoryObj(invInfo)
+	if invObj == nil {
+		return fmt.Errorf("unable to determine the type of the inventory object %s/%s to check "+
+			"the permissions for it", invInfo.Namespace(), invInfo.Name())
+	}
+	mapper, err := a.provider.Factory().ToRESTMapper()
+	if err != nil {
+		return err
+	}
+	clientset, err := a.provider.Factory().KubernetesClientSet()
+	if err != nil {
+		return err
+	}
+	selected, _ := options.Filter.Split(objs)
+	var pruneIds []object.ObjMetadata
+	if !options.NoPrune {
+		prevInv, err := a.invClient.GetClusterObjs(invInfo)
+		if err != nil {
+			return err
+		}
+		for _, id := range object.SetDiff(prevInv, object.UnstructuredsToObjMetas(objs)) {
+			if options.Filter.MatchesID(id) {
+				pruneIds = append(pruneIds, id)
+			}
+		}
+	}
+	perms, err := preflight.Permissions(mapper, preflight.Request{
+		Objects:   selected,
+		PruneIds:  pruneIds,
+		Inventory: invObj,
+		Recreate:  options.RecreateOptions.Recreate,
+		Rollback:  options.Atomic,
+	})
+	if err != nil {
+		return err
+	}
+	klog.V(4).Infof("applier checking %d permissions", len(perms))
+	return preflight.Check(ctx, clientset.AuthorizationV1(), perms)
+}
+
+// inventoryObj returns the local inventory object for the InventoryInfo,
+// or nil if its type can't be determined. The ClusterInventoryClient
+// converts it with the function it was created with, which supports
+// other types than ConfigMap.
+func (a *Applier) inventoryObj(invInfo inventory.InventoryInfo) *unstructured.Unstructured {
+	if cic, ok := a.invClient.(*inventory.ClusterInventoryClient); ok {
+		return cic.InventoryObj(invInfo)
+	}
+	return inventory.InvInfoToConfigMap(invInfo)
+}
+
+// rollback restores the objects in the snapshot and the previous
+// contents of the inventory after a failed atomic apply.
+func (a *Applier) rollback(eventChannel chan event.Event, ro *ResourceObjects,
+	snapshot *rollback.Snapshot, mapper meta.RESTMapper) {
+	client, err := a.provider.Factory().DynamicClient()
+	if err != nil {
+		eventChannel <- createRollbackFailedEvent(fmt.Errorf("error creating client for rollback: %v", err))
+		return
+	}
+	rollback.Rollback(snapshot, client, mapper, eventChannel)
+	if err := a.invClient.Replace(ro.LocalInv, ro.PrevInv); err != nil {
+		eventChannel <- createRollbackFailedEvent(fmt.Errorf("error restoring inventory: %v", err))
+	}
+	eventChannel <- event.Event{
+		Type: event.RollbackType,
+		RollbackEvent: event.RollbackEvent{
+			Type: event.RollbackEventCompleted,
+		},
+	}
+}
+
+func createRollbackFailedEvent(err error) event.Event {
+	return event.Event{
+		Type: event.RollbackType,
+		RollbackEvent: event.RollbackEvent{
+			Type:  event.RollbackEventFailed,
+			Error: err,
+		},
+	}
+}
+
 type Options struct {
 	// Encapsulates the fields for server-side apply.
 	ServerSideOptions common.ServerSideOptions
@@ -302,6 +473,90 @@ type Options struct {
 
 	// InventoryPolicy defines the inventory policy of apply.
 	InventoryPolicy inventory.InventoryPolicy
+
+	// RetryPolicy defines how applying and pruning an object is
+	// retried if it fails with a transient error. By default, every
+	// object is only attempted once.
+	RetryPolicy retry.Policy
+
+	// RecreateOptions defines whether objects are deleted and created
+	// again if applying them fails because an immutable field has
+	// changed. If no propagation policy is provided, the default is to
+	// use the Background policy. If no timeout is provided, the default
+	// is to wait one minute for the object to be deleted.
+	RecreateOptions common.RecreateOptions
+
+	// HookTimeout defines how long to wait for the pre-apply and
+	// post-apply hooks to complete, and to be deleted before they are
+	// applied again. If this is not provided, the default is five
+	// minutes.
+	HookTimeout time.Duration
+
+	// Atomic defines whether the changes should be rolled back if
+	// any resource fails to apply, or the resources don't reconcile
+	// before the ReconcileTimeout. Resources that were changed are
+	// restored, resources that were created are deleted, and the
+	// inventory is restored to what it was before the apply.
+	// Pruning happens after all resources have been applied and
+	// reconciled, and pruned resources can't be restored, so errors
+	// during the prune, like the PruneTimeout expiring, don't cause a
+	// rollback. Resources that failed to prune are kept in the
+	// inventory, so they are pruned by the next apply.
+	Atomic bool
+
+	// Stages defines the stages the resources are applied in. The
+	// resources selected by each stage are applied, and waited on until
+	// they are Current, before the next stage. The other resources are
+	// applied after all the stages.
+	Stages []stage.Stage
+
+	// Filter limits the apply to the objects it selects. The other
+	// objects are neither applied nor pruned, and the objects in the
+	// inventory that are not selected are kept in the inventory.
+	Filter object.Filter
+
+	// CheckPermissions defines whether the applier should check that
+	// the caller is allowed to make all the changes before making any
+	// of them. If any permissions are missing, the apply fails with a
+	// list of them.
+	CheckPermissions bool
+}
+
+// applyResourceGroups returns the resource groups for the resources
+// that will be applied. There is a group for each stage that selects
+// any of the resources, followed by a group for the other resources.
+// Hooks are applied in their own phases, so they are never part of a
+// stage.
+func applyResourceGroups(ro *ResourceObjects, stages []stage.Stage) []event.ResourceGroup {
+	preHooks, regular, postHooks := hook.Split(ro.ObjsForApply())
+	groups := stage.Split(regular, stages)
+	if len(groups) == 0 {
+		return []event.ResourceGroup{
+			{
+				Action:      event.ApplyAction,
+				Identifiers: ro.IdsForApply(),
+			},
+		}
+	}
+	var resourceGroups []event.ResourceGroup
+	for _, g := range groups[:len(groups)-1] {
+		if len(g.Objects) == 0 {
+			continue
+		}
+		resourceGroups = append(resourceGroups, event.ResourceGroup{
+			Action:      event.ApplyAction,
+			Stage:       g.Stage.Name,
+			Identifiers: g.Ids(),
+		})
+	}
+	var rest []*unstructured.Unstructured
+	rest = append(rest, preHooks...)
+	rest = append(rest, groups[len(groups)-1].Objects...)
+	rest = append(rest, postHooks...)
+	return append(resourceGroups, event.ResourceGroup{
+		Action:      event.ApplyAction,
+		Identifiers: object.UnstructuredsToObjMetas(rest),
+	})
 }
 
 // setDefaults set the options to the default values if they
@@ -313,6 +568,15 @@ func setDefaults(o *Options) {
 	if o.PrunePropagationPolicy == metav1.DeletionPropagation("") {
 		o.PrunePropagationPolicy = metav1.DeletePropagationBackground
 	}
+	if o.RecreateOptions.PropagationPolicy == metav1.DeletionPropagation("") {
+		o.RecreateOptions.PropagationPolicy = metav1.DeletePropagationBackground
+	}
+	if o.RecreateOptions.Timeout == time.Duration(0) {
+		o.RecreateOptions.Timeout = time.Minute
+	}
+	if o.HookTimeout == time.Duration(0) {
+		o.HookTimeout = 5 * time.Minute
+	}
 }
 
 func handleError(eventChannel chan event.Event, err error) {
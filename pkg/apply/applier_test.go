@@ -28,6 +28,7 @@ import (
 	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
 	"k8s.io/kubectl/pkg/scheme"
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/stage"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/polling"
@@ -768,6 +769,26 @@ func TestInventoryNamespaceInSet(t *testing.T) {
 	}
 }
 
+// customInventoryInfo is an inventory that is not stored in a ConfigMap.
+type customInventoryInfo struct{}
+
+func (i customInventoryInfo) Namespace() string { return namespace }
+
+func (i customInventoryInfo) Name() string { return "custom-inventory" }
+
+func (i customInventoryInfo) ID() string { return "custom-id" }
+
+func (i customInventoryInfo) Match(id string) bool { return id == i.ID() }
+
+func TestCheckPermissionsUnknownInventoryType(t *testing.T) {
+	applier := &Applier{invClient: inventory.NewFakeInventoryClient(nil)}
+	err := applier.checkPermissions(context.Background(), customInventoryInfo{},
+		[]*unstructured.Unstructured{obj1}, Options{})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "unable to determine the type of the inventory object")
+	}
+}
+
 func TestReadAndPrepareObjects(t *testing.T) {
 	testCases := map[string]struct {
 		// local inventory input into applier.prepareObjects
@@ -782,6 +803,8 @@ func TestReadAndPrepareObjects(t *testing.T) {
 		localObjs []*unstructured.Unstructured
 		// expected calculated prune objects
 		pruneObjs []*unstructured.Unstructured
+		// filter for the objects to apply
+		filter object.Filter
 		// expected error
 		isError bool
 	}{
@@ -822,6 +845,16 @@ func TestReadAndPrepareObjects(t *testing.T) {
 			pruneObjs:   []*unstructured.Unstructured{obj2},
 			isError:     false,
 		},
+		"filter selects objects to apply and limits prune": {
+			inventory:   localInv,
+			resources:   []*unstructured.Unstructured{obj1, obj2, clusterScopedObj},
+			clusterObjs: []*unstructured.Unstructured{obj2, obj3},
+			localInv:    localInv,
+			localObjs:   []*unstructured.Unstructured{obj1},
+			pruneObjs:   []*unstructured.Unstructured{},
+			filter:      object.Filter{Kinds: []string{"Pod", "Job"}, Names: []string{"obj1"}},
+			isError:     false,
+		},
 		"inventory object not at the beginning": {
 			inventory:   localInv,
 			resources:   []*unstructured.Unstructured{obj1, obj2, clusterScopedObj},
@@ -840,7 +873,7 @@ func TestReadAndPrepareObjects(t *testing.T) {
 			fakeInvClient := inventory.NewFakeInventoryClient(clusterObjs)
 			// Create applier with fake inventory client, and call prepareObjects
 			applier := &Applier{invClient: fakeInvClient}
-			resourceObjs, err := applier.prepareObjects(tc.inventory, tc.resources)
+			resourceObjs, err := applier.prepareObjects(tc.inventory, tc.resources, tc.filter)
 			if !tc.isError && err != nil {
 				t.Fatalf("unexpected error received: %s", err)
 			}
@@ -867,6 +900,68 @@ func TestReadAndPrepareObjects(t *testing.T) {
 				t.Errorf("expected prune ids (%v), got (%v)",
 					tc.pruneObjs, resourceObjs.PruneIds)
 			}
+			// Objects that are not selected stay in the inventory.
+			for _, id := range clusterObjs {
+				if !object.SetEquals(object.Union(fakeInvClient.Objs, []object.ObjMetadata{id}), fakeInvClient.Objs) {
+					t.Errorf("expected inventory to keep %s", id)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyResourceGroups(t *testing.T) {
+	canary := obj3.DeepCopy()
+	canary.SetLabels(map[string]string{"stage": "canary"})
+	hook := obj2.DeepCopy()
+	hook.SetLabels(map[string]string{"stage": "canary"})
+	hook.SetAnnotations(map[string]string{common.HookAnnotation: "pre-apply"})
+	canaryStage := stage.Stage{Name: "canary", Selector: "stage=canary"}
+	emptyStage := stage.Stage{Name: "empty", Selector: "stage=empty"}
+
+	testCases := map[string]struct {
+		objs           []*unstructured.Unstructured
+		stages         []stage.Stage
+		expectedGroups []event.ResourceGroup
+	}{
+		"no stages": {
+			objs: []*unstructured.Unstructured{obj1, canary},
+			expectedGroups: []event.ResourceGroup{
+				{
+					Action: event.ApplyAction,
+					Identifiers: []object.ObjMetadata{
+						object.UnstructuredToObjMeta(obj1),
+						object.UnstructuredToObjMeta(canary),
+					},
+				},
+			},
+		},
+		"group for each stage with resources": {
+			objs:   []*unstructured.Unstructured{obj1, canary, hook},
+			stages: []stage.Stage{emptyStage, canaryStage},
+			expectedGroups: []event.ResourceGroup{
+				{
+					Action: event.ApplyAction,
+					Stage:  "canary",
+					Identifiers: []object.ObjMetadata{
+						object.UnstructuredToObjMeta(canary),
+					},
+				},
+				{
+					Action: event.ApplyAction,
+					Identifiers: []object.ObjMetadata{
+						object.UnstructuredToObjMeta(hook),
+						object.UnstructuredToObjMeta(obj1),
+					},
+				},
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			groups := applyResourceGroups(&ResourceObjects{Resources: tc.objs}, tc.stages)
+			assert.Equal(t, tc.expectedGroups, groups)
 		})
 	}
 }
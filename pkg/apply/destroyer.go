@@ -134,6 +134,10 @@ func runPruneEventTransformer(eventChannel chan event.Event) (chan event.Event,
 			// If it is not a Prune event, no need to make any transformation.
 			if msg.Type != event.PruneType {
 				eventChannel <- msg
+			} else if msg.PruneEvent.Type == event.PruneEventRetry {
+				// The destroyer doesn't retry deletes, and there is
+				// no matching delete event.
+				continue
 			} else {
 				var deleteEventType event.DeleteEventType
 				switch msg.PruneEvent.Type {
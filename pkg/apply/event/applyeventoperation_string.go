@@ -16,11 +16,12 @@ func _() {
 	_ = x[Unchanged-2]
 	_ = x[Configured-3]
 	_ = x[Failed-4]
+	_ = x[Recreated-5]
 }
 
-const _ApplyEventOperation_name = "ServersideAppliedCreatedUnchangedConfiguredFailed"
+const _ApplyEventOperation_name = "ServersideAppliedCreatedUnchangedConfiguredFailedRecreated"
 
-var _ApplyEventOperation_index = [...]uint8{0, 17, 24, 33, 43, 49}
+var _ApplyEventOperation_index = [...]uint8{0, 17, 24, 33, 43, 49, 58}
 
 func (i ApplyEventOperation) String() string {
 	if i < 0 || i >= ApplyEventOperation(len(_ApplyEventOperation_index)-1) {
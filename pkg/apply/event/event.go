@@ -4,6 +4,8 @@
 package event
 
 import (
+	"time"
+
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	pollevent "sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
 	"sigs.k8s.io/cli-utils/pkg/object"
@@ -20,6 +22,8 @@ const (
 	StatusType
 	PruneType
 	DeleteType
+	DriftType
+	RollbackType
 )
 
 // Event is the type of the objects that will be returned through
@@ -52,6 +56,14 @@ type Event struct {
 	// DeleteEvent contains information about object that have been
 	// deleted.
 	DeleteEvent DeleteEvent
+
+	// DriftEvent contains information about objects that have drifted
+	// from the state they were applied in.
+	DriftEvent DriftEvent
+
+	// RollbackEvent contains information about objects that have been
+	// rolled back after a failed atomic apply.
+	RollbackEvent RollbackEvent
 }
 
 type InitEvent struct {
@@ -67,7 +79,10 @@ const (
 )
 
 type ResourceGroup struct {
-	Action      ResourceAction
+	Action ResourceAction
+	// Stage is the name of the stage the resources are applied in, or
+	// empty if the resources are not applied in a stage.
+	Stage       string
 	Identifiers []object.ObjMetadata
 }
 
@@ -81,6 +96,9 @@ type ApplyEventType int
 const (
 	ApplyEventResourceUpdate ApplyEventType = iota
 	ApplyEventCompleted
+	// ApplyEventRetry means applying the resource failed with a
+	// transient error and it will be retried.
+	ApplyEventRetry
 )
 
 //go:generate stringer -type=ApplyEventOperation
@@ -92,6 +110,9 @@ const (
 	Unchanged
 	Configured
 	Failed
+	// Recreated means the resource was deleted and created again,
+	// because the change could not be applied to immutable fields.
+	Recreated
 )
 
 type ApplyEvent struct {
@@ -100,6 +121,19 @@ type ApplyEvent struct {
 	Object     *unstructured.Unstructured
 	Identifier object.ObjMetadata
 	Error      error
+	// Retry is only set for ApplyEventRetry events.
+	Retry Retry
+}
+
+// Retry contains information about a failed attempt that will be
+// retried. The error from the failed attempt is set on the event.
+type Retry struct {
+	// Attempt is the number of the attempt that failed, starting at 1.
+	Attempt int
+	// MaxAttempts is the maximum number of attempts that will be made.
+	MaxAttempts int
+	// Delay is how long to wait before the next attempt.
+	Delay time.Duration
 }
 
 //go:generate stringer -type=StatusEventType
@@ -122,6 +156,9 @@ const (
 	PruneEventResourceUpdate PruneEventType = iota
 	PruneEventCompleted
 	PruneEventFailed
+	// PruneEventRetry means deleting the resource failed with a
+	// transient error and it will be retried.
+	PruneEventRetry
 )
 
 //go:generate stringer -type=PruneEventOperation
@@ -138,6 +175,8 @@ type PruneEvent struct {
 	Object     *unstructured.Unstructured
 	Identifier object.ObjMetadata
 	Error      error
+	// Retry is only set for PruneEventRetry events.
+	Retry Retry
 }
 
 //go:generate stringer -type=DeleteEventType
@@ -164,3 +203,72 @@ type DeleteEvent struct {
 	Identifier object.ObjMetadata
 	Error      error
 }
+
+//go:generate stringer -type=DriftEventType
+type DriftEventType int
+
+const (
+	DriftEventResourceUpdate DriftEventType = iota
+	DriftEventCompleted
+)
+
+//go:generate stringer -type=DriftEventOperation
+type DriftEventOperation int
+
+const (
+	// InSync means the object has not drifted.
+	InSync DriftEventOperation = iota
+	// DeletedOutOfBand means the object is in the inventory, but has
+	// been deleted from the cluster.
+	DeletedOutOfBand
+	// FieldsChanged means fields set by the package are now owned by
+	// a different field manager.
+	FieldsChanged
+	// InventoryChanged means the owning inventory annotation on the
+	// object no longer points to the inventory.
+	InventoryChanged
+	// NotInInventory means the object belongs to the package, but is
+	// not in the inventory.
+	NotInInventory
+)
+
+type DriftEvent struct {
+	Type       DriftEventType
+	Operation  DriftEventOperation
+	Object     *unstructured.Unstructured
+	Identifier object.ObjMetadata
+	// Details describes the drift, like which fields have been
+	// changed and by which field manager.
+	Details []string
+}
+
+//go:generate stringer -type=RollbackEventType
+type RollbackEventType int
+
+const (
+	RollbackEventResourceUpdate RollbackEventType = iota
+	RollbackEventCompleted
+	RollbackEventFailed
+)
+
+//go:generate stringer -type=RollbackEventOperation
+type RollbackEventOperation int
+
+const (
+	// Restored means the object has been restored to the state it
+	// was in before the apply.
+	Restored RollbackEventOperation = iota
+	// RollbackDeleted means the object was created by the apply, and
+	// has been deleted.
+	RollbackDeleted
+)
+
+type RollbackEvent struct {
+	Type       RollbackEventType
+	Operation  RollbackEventOperation
+	Identifier object.ObjMetadata
+	// Object is the state the object is restored to. It is nil if the
+	// object is deleted.
+	Object *unstructured.Unstructured
+	Error  error
+}
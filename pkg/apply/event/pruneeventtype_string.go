@@ -14,11 +14,12 @@ func _() {
 	_ = x[PruneEventResourceUpdate-0]
 	_ = x[PruneEventCompleted-1]
 	_ = x[PruneEventFailed-2]
+	_ = x[PruneEventRetry-3]
 }
 
-const _PruneEventType_name = "PruneEventResourceUpdatePruneEventCompletedPruneEventFailed"
+const _PruneEventType_name = "PruneEventResourceUpdatePruneEventCompletedPruneEventFailedPruneEventRetry"
 
-var _PruneEventType_index = [...]uint8{0, 24, 43, 59}
+var _PruneEventType_index = [...]uint8{0, 24, 43, 59, 74}
 
 func (i PruneEventType) String() string {
 	if i < 0 || i >= PruneEventType(len(_PruneEventType_index)-1) {
@@ -17,11 +17,13 @@ func _() {
 	_ = x[StatusType-3]
 	_ = x[PruneType-4]
 	_ = x[DeleteType-5]
+	_ = x[DriftType-6]
+	_ = x[RollbackType-7]
 }
 
-const _Type_name = "InitTypeErrorTypeApplyTypeStatusTypePruneTypeDeleteType"
+const _Type_name = "InitTypeErrorTypeApplyTypeStatusTypePruneTypeDeleteTypeDriftTypeRollbackType"
 
-var _Type_index = [...]uint8{0, 8, 17, 26, 36, 45, 55}
+var _Type_index = [...]uint8{0, 8, 17, 26, 36, 45, 55, 64, 76}
 
 func (i Type) String() string {
 	if i < 0 || i >= Type(len(_Type_index)-1) {
@@ -0,0 +1,162 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package hook contains the functions for resources declared as hooks
+// with the common.HookAnnotation. Hooks are resources, like a Job
+// running a database migration or a smoke test, that are applied in
+// their own phase and waited on until they have completed, either
+// before (pre-apply) or after (post-apply) the other resources.
+package hook
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// Phase is the phase a hook is applied in.
+type Phase string
+
+const (
+	// None means the resource is not a hook.
+	None Phase = ""
+	// PreApply hooks are applied, and waited on until they have
+	// completed, before any other resources are applied.
+	PreApply Phase = "pre-apply"
+	// PostApply hooks are applied after all other resources have been
+	// applied and have reconciled.
+	PostApply Phase = "post-apply"
+)
+
+// DeletePolicy defines when a hook is deleted.
+type DeletePolicy string
+
+const (
+	// BeforeHookCreation means the hook is deleted before it is
+	// applied, so it is created again every time. This is the default.
+	BeforeHookCreation DeletePolicy = "before-hook-creation"
+	// HookSucceeded means the hook is deleted once it has completed.
+	HookSucceeded DeletePolicy = "hook-succeeded"
+)
+
+// PhaseFor returns the phase for the resource, as declared by the
+// hook annotation. It returns None if the resource is not a hook.
+func PhaseFor(obj *unstructured.Unstructured) (Phase, error) {
+	value, found := obj.GetAnnotations()[common.HookAnnotation]
+	if !found {
+		return None, nil
+	}
+	switch p := Phase(strings.TrimSpace(value)); p {
+	case PreApply, PostApply:
+		return p, nil
+	default:
+		return None, fmt.Errorf("invalid %s annotation %q on %s: must be %q or %q",
+			common.HookAnnotation, value, objName(obj), PreApply, PostApply)
+	}
+}
+
+// DeletePoliciesFor returns the delete policies for the resource, as
+// declared by the hook delete policy annotation.
+func DeletePoliciesFor(obj *unstructured.Unstructured) ([]DeletePolicy, error) {
+	value, found := obj.GetAnnotations()[common.HookDeletePolicyAnnotation]
+	if !found {
+		return []DeletePolicy{BeforeHookCreation}, nil
+	}
+	var policies []DeletePolicy
+	for _, v := range strings.Split(value, ",") {
+		switch p := DeletePolicy(strings.TrimSpace(v)); p {
+		case BeforeHookCreation, HookSucceeded:
+			policies = append(policies, p)
+		default:
+			return nil, fmt.Errorf("invalid %s annotation %q on %s: must be a list of %q and %q",
+				common.HookDeletePolicyAnnotation, value, objName(obj), BeforeHookCreation, HookSucceeded)
+		}
+	}
+	return policies, nil
+}
+
+// IsHook returns true if the resource is a pre-apply or post-apply hook.
+func IsHook(obj *unstructured.Unstructured) bool {
+	phase, err := PhaseFor(obj)
+	return err == nil && phase != None
+}
+
+// Validate checks the hook annotations on all the resources.
+func Validate(objs []*unstructured.Unstructured) error {
+	for _, obj := range objs {
+		phase, err := PhaseFor(obj)
+		if err != nil {
+			return err
+		}
+		if phase == None {
+			continue
+		}
+		if _, err := DeletePoliciesFor(obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Split divides the resources into the pre-apply hooks, the resources
+// that are not hooks, and the post-apply hooks. The order of the
+// resources is kept within each group. Resources with invalid hook
+// annotations are treated as not being hooks, so Validate should be
+// used to reject them first.
+func Split(objs []*unstructured.Unstructured) (pre, regular, post []*unstructured.Unstructured) {
+	for _, obj := range objs {
+		phase, _ := PhaseFor(obj)
+		switch phase {
+		case PreApply:
+			pre = append(pre, obj)
+		case PostApply:
+			post = append(post, obj)
+		default:
+			regular = append(regular, obj)
+		}
+	}
+	return pre, regular, post
+}
+
+// WithDeletePolicy returns the hooks among the resources that have the
+// given delete policy.
+func WithDeletePolicy(objs []*unstructured.Unstructured, policy DeletePolicy) []*unstructured.Unstructured {
+	var result []*unstructured.Unstructured
+	for _, obj := range objs {
+		if !IsHook(obj) {
+			continue
+		}
+		policies, err := DeletePoliciesFor(obj)
+		if err != nil {
+			continue
+		}
+		for _, p := range policies {
+			if p == policy {
+				result = append(result, obj)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// Ids returns the identifiers of the hooks among the resources.
+func Ids(objs []*unstructured.Unstructured) []object.ObjMetadata {
+	var ids []object.ObjMetadata
+	for _, obj := range objs {
+		if IsHook(obj) {
+			ids = append(ids, object.UnstructuredToObjMeta(obj))
+		}
+	}
+	return ids
+}
+
+func objName(obj *unstructured.Unstructured) string {
+	if obj.GetNamespace() == "" {
+		return fmt.Sprintf("%s %s", obj.GetKind(), obj.GetName())
+	}
+	return fmt.Sprintf("%s %s/%s", obj.GetKind(), obj.GetNamespace(), obj.GetName())
+}
@@ -0,0 +1,156 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package hook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cli-utils/pkg/common"
+)
+
+func newObj(name string, annotations map[string]string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "batch/v1",
+			"kind":       "Job",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": "default",
+			},
+		},
+	}
+	u.SetAnnotations(annotations)
+	return u
+}
+
+func TestPhaseFor(t *testing.T) {
+	testCases := map[string]struct {
+		annotations   map[string]string
+		expected      Phase
+		expectedError bool
+	}{
+		"no annotation": {
+			expected: None,
+		},
+		"pre-apply": {
+			annotations: map[string]string{common.HookAnnotation: "pre-apply"},
+			expected:    PreApply,
+		},
+		"post-apply with whitespace": {
+			annotations: map[string]string{common.HookAnnotation: " post-apply "},
+			expected:    PostApply,
+		},
+		"invalid": {
+			annotations:   map[string]string{common.HookAnnotation: "pre-delete"},
+			expectedError: true,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			phase, err := PhaseFor(newObj("job", tc.annotations))
+			if tc.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, phase)
+		})
+	}
+}
+
+func TestDeletePoliciesFor(t *testing.T) {
+	testCases := map[string]struct {
+		annotations   map[string]string
+		expected      []DeletePolicy
+		expectedError bool
+	}{
+		"defaults to before-hook-creation": {
+			expected: []DeletePolicy{BeforeHookCreation},
+		},
+		"hook-succeeded": {
+			annotations: map[string]string{common.HookDeletePolicyAnnotation: "hook-succeeded"},
+			expected:    []DeletePolicy{HookSucceeded},
+		},
+		"both": {
+			annotations: map[string]string{common.HookDeletePolicyAnnotation: "before-hook-creation, hook-succeeded"},
+			expected:    []DeletePolicy{BeforeHookCreation, HookSucceeded},
+		},
+		"invalid": {
+			annotations:   map[string]string{common.HookDeletePolicyAnnotation: "hook-failed"},
+			expectedError: true,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			policies, err := DeletePoliciesFor(newObj("job", tc.annotations))
+			if tc.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, policies)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	testCases := map[string]struct {
+		annotations   map[string]string
+		expectedError bool
+	}{
+		"not a hook": {},
+		"valid hook": {
+			annotations: map[string]string{
+				common.HookAnnotation:             "pre-apply",
+				common.HookDeletePolicyAnnotation: "hook-succeeded",
+			},
+		},
+		"invalid phase": {
+			annotations:   map[string]string{common.HookAnnotation: "sometimes"},
+			expectedError: true,
+		},
+		"invalid delete policy": {
+			annotations: map[string]string{
+				common.HookAnnotation:             "post-apply",
+				common.HookDeletePolicyAnnotation: "never",
+			},
+			expectedError: true,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			err := Validate([]*unstructured.Unstructured{newObj("job", tc.annotations)})
+			if tc.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSplit(t *testing.T) {
+	pre1 := newObj("pre1", map[string]string{common.HookAnnotation: "pre-apply"})
+	pre2 := newObj("pre2", map[string]string{
+		common.HookAnnotation:             "pre-apply",
+		common.HookDeletePolicyAnnotation: "hook-succeeded",
+	})
+	post := newObj("post", map[string]string{common.HookAnnotation: "post-apply"})
+	regular := newObj("regular", nil)
+
+	objs := []*unstructured.Unstructured{pre1, regular, post, pre2}
+	gotPre, gotRegular, gotPost := Split(objs)
+	assert.Equal(t, []*unstructured.Unstructured{pre1, pre2}, gotPre)
+	assert.Equal(t, []*unstructured.Unstructured{regular}, gotRegular)
+	assert.Equal(t, []*unstructured.Unstructured{post}, gotPost)
+
+	assert.Equal(t, []*unstructured.Unstructured{pre1, post}, WithDeletePolicy(objs, BeforeHookCreation))
+	assert.Equal(t, []*unstructured.Unstructured{pre2}, WithDeletePolicy(objs, HookSucceeded))
+	assert.Len(t, Ids(objs), 3)
+}
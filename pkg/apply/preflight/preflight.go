@@ -0,0 +1,223 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package preflight contains the functionality for checking that the
+// caller has all the permissions an apply needs before any changes are
+// made. Without the check, an apply can fail halfway through because
+// the caller lacks permission for one kind of resource, after other
+// resources have already been changed.
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	authorizationclient "k8s.io/client-go/kubernetes/typed/authorization/v1"
+	"k8s.io/klog"
+	"sigs.k8s.io/cli-utils/pkg/apply/hook"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// Permission is a verb on a resource that the caller needs to be
+// allowed. The Namespace is empty for cluster-scoped resources.
+type Permission struct {
+	Verb      string
+	Group     string
+	Resource  string
+	Namespace string
+}
+
+// String returns the permission in the form used by
+// "kubectl auth can-i", like "create deployments.apps -n default".
+func (p Permission) String() string {
+	resource := p.Resource
+	if p.Group != "" {
+		resource = resource + "." + p.Group
+	}
+	if p.Namespace == "" {
+		return fmt.Sprintf("%s %s", p.Verb, resource)
+	}
+	return fmt.Sprintf("%s %s -n %s", p.Verb, resource, p.Namespace)
+}
+
+// MissingPermissionsError is returned by Check if the caller is not
+// allowed one or more of the permissions.
+type MissingPermissionsError struct {
+	Missing []Permission
+}
+
+func (e *MissingPermissionsError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "missing %d permission(s) required for the apply:", len(e.Missing))
+	for _, p := range e.Missing {
+		fmt.Fprintf(&b, "\n  %s", p)
+	}
+	return b.String()
+}
+
+// Request describes the changes an apply will make.
+type Request struct {
+	// Objects are the objects that will be applied.
+	Objects []*unstructured.Unstructured
+	// PruneIds are the objects that may be pruned.
+	PruneIds []object.ObjMetadata
+	// Inventory is the inventory object, which is read, created
+	// and updated.
+	Inventory *unstructured.Unstructured
+	// Recreate is true if all the applied objects may be deleted and
+	// created again after an immutable field has changed. Hooks and
+	// objects with the on-immutable-change annotation may be deleted
+	// either way.
+	Recreate bool
+	// Rollback is true if the applied objects may be restored or
+	// deleted by a rollback.
+	Rollback bool
+}
+
+var (
+	applyVerbs     = []string{"get", "create", "patch"}
+	pruneVerbs     = []string{"get", "delete"}
+	inventoryVerbs = []string{"get", "list", "create", "update"}
+)
+
+// Permissions returns the permissions needed for the changes in the
+// request, sorted and without duplicates. The resources for custom
+// types are looked up in the CustomResourceDefinitions among the
+// applied objects if the mapper doesn't know them yet.
+func Permissions(mapper meta.RESTMapper, req Request) ([]Permission, error) {
+	crds := crdResources(req.Objects)
+	set := make(map[Permission]bool)
+	add := func(gk schema.GroupKind, namespace string, verbs ...string) error {
+		resource, namespaced, found, err := resourceFor(mapper, crds, gk)
+		if err != nil {
+			return err
+		}
+		if !found {
+			// Applying the object will fail with a better error.
+			klog.V(4).Infof("preflight skipping unknown kind %s", gk)
+			return nil
+		}
+		if !namespaced {
+			namespace = ""
+		}
+		for _, v := range verbs {
+			set[Permission{Verb: v, Group: gk.Group, Resource: resource, Namespace: namespace}] = true
+		}
+		return nil
+	}
+
+	for _, obj := range req.Objects {
+		verbs := append([]string{}, applyVerbs...)
+		if req.Recreate || hook.IsHook(obj) || common.RecreateOnImmutableChange(obj.GetAnnotations()) {
+			verbs = append(verbs, "delete")
+		}
+		if req.Rollback {
+			verbs = append(verbs, "update", "delete")
+		}
+		if err := add(obj.GroupVersionKind().GroupKind(), obj.GetNamespace(), verbs...); err != nil {
+			return nil, err
+		}
+	}
+	for _, id := range req.PruneIds {
+		if err := add(id.GroupKind, id.Namespace, pruneVerbs...); err != nil {
+			return nil, err
+		}
+	}
+	if req.Inventory != nil {
+		err := add(req.Inventory.GroupVersionKind().GroupKind(), req.Inventory.GetNamespace(), inventoryVerbs...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	perms := make([]Permission, 0, len(set))
+	for p := range set {
+		perms = append(perms, p)
+	}
+	sort.Slice(perms, func(i, j int) bool {
+		return perms[i].String() < perms[j].String()
+	})
+	return perms, nil
+}
+
+// Check issues a SelfSubjectAccessReview for each of the permissions,
+// and returns a MissingPermissionsError with all the permissions that
+// are not allowed.
+func Check(ctx context.Context, client authorizationclient.SelfSubjectAccessReviewsGetter, perms []Permission) error {
+	var missing []Permission
+	for _, p := range perms {
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Verb:      p.Verb,
+					Group:     p.Group,
+					Resource:  p.Resource,
+					Namespace: p.Namespace,
+				},
+			},
+		}
+		result, err := client.SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("error checking permission to %s: %v", p, err)
+		}
+		if !result.Status.Allowed {
+			klog.V(4).Infof("preflight permission denied: %s", p)
+			missing = append(missing, p)
+		}
+	}
+	if len(missing) > 0 {
+		return &MissingPermissionsError{Missing: missing}
+	}
+	return nil
+}
+
+type crdResource struct {
+	resource   string
+	namespaced bool
+}
+
+// crdResources returns the resources defined by the
+// CustomResourceDefinitions among the objects.
+func crdResources(objs []*unstructured.Unstructured) map[schema.GroupKind]crdResource {
+	crds := make(map[schema.GroupKind]crdResource)
+	for _, obj := range objs {
+		if !object.IsCRD(obj) {
+			continue
+		}
+		group, _, _ := unstructured.NestedString(obj.Object, "spec", "group")
+		kind, _, _ := unstructured.NestedString(obj.Object, "spec", "names", "kind")
+		plural, _, _ := unstructured.NestedString(obj.Object, "spec", "names", "plural")
+		scope, _, _ := unstructured.NestedString(obj.Object, "spec", "scope")
+		crds[schema.GroupKind{Group: group, Kind: kind}] = crdResource{
+			resource:   plural,
+			namespaced: scope != "Cluster",
+		}
+	}
+	return crds
+}
+
+// resourceFor returns the resource for the kind, and whether it is
+// namespaced. The last return value before the error is false if the
+// kind is not known.
+func resourceFor(mapper meta.RESTMapper, crds map[schema.GroupKind]crdResource,
+	gk schema.GroupKind) (string, bool, bool, error) {
+	mapping, err := mapper.RESTMapping(gk)
+	if err == nil {
+		return mapping.Resource.Resource, mapping.Scope.Name() == meta.RESTScopeNameNamespace, true, nil
+	}
+	if !meta.IsNoMatchError(err) {
+		return "", false, false, err
+	}
+	if crd, found := crds[gk]; found {
+		return crd.resource, crd.namespaced, true, nil
+	}
+	return "", false, false, nil
+}
@@ -0,0 +1,219 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	clienttesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+func newObj(apiVersion, kind, namespace, name string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": apiVersion,
+			"kind":       kind,
+			"metadata": map[string]interface{}{
+				"name": name,
+			},
+		},
+	}
+	u.SetNamespace(namespace)
+	return u
+}
+
+var (
+	deployment = newObj("apps/v1", "Deployment", "default", "web")
+	binding    = newObj("rbac.authorization.k8s.io/v1", "ClusterRoleBinding", "", "web")
+	inventory  = newObj("v1", "ConfigMap", "default", "inventory")
+	crd        = &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apiextensions.k8s.io/v1",
+			"kind":       "CustomResourceDefinition",
+			"metadata": map[string]interface{}{
+				"name": "crontabs.example.com",
+			},
+			"spec": map[string]interface{}{
+				"group": "example.com",
+				"scope": "Namespaced",
+				"names": map[string]interface{}{
+					"kind":   "CronTab",
+					"plural": "crontabs",
+				},
+			},
+		},
+	}
+	job              = withAnnotation(newObj("batch/v1", "Job", "default", "migrate"), common.HookAnnotation, "pre-apply")
+	annotatedBinding = withAnnotation(newObj("rbac.authorization.k8s.io/v1", "ClusterRoleBinding", "", "web"),
+		common.OnImmutableChangeAnnotation, common.OnImmutableChangeRecreate)
+	cronTab = newObj("example.com/v1", "CronTab", "default", "cron")
+	widget  = newObj("example.com/v1", "Widget", "default", "widget")
+)
+
+func withAnnotation(u *unstructured.Unstructured, key, value string) *unstructured.Unstructured {
+	u.SetAnnotations(map[string]string{key: value})
+	return u
+}
+
+func perms(verbs []string, group, resource, namespace string) []Permission {
+	var result []Permission
+	for _, v := range verbs {
+		result = append(result, Permission{Verb: v, Group: group, Resource: resource, Namespace: namespace})
+	}
+	return result
+}
+
+func TestPermissions(t *testing.T) {
+	testCases := map[string]struct {
+		req           Request
+		expectedPerms []Permission
+	}{
+		"apply, prune and inventory": {
+			req: Request{
+				Objects: []*unstructured.Unstructured{deployment},
+				PruneIds: []object.ObjMetadata{
+					object.UnstructuredToObjMeta(binding),
+				},
+				Inventory: inventory,
+			},
+			expectedPerms: append(append(
+				perms([]string{"create", "get", "list", "update"}, "", "configmaps", "default"),
+				perms([]string{"create", "get", "patch"}, "apps", "deployments", "default")...),
+				perms([]string{"delete", "get"}, "rbac.authorization.k8s.io", "clusterrolebindings", "")...),
+		},
+		"cluster-scoped objects have no namespace": {
+			req: Request{
+				Objects: []*unstructured.Unstructured{binding},
+			},
+			expectedPerms: perms([]string{"create", "get", "patch"}, "rbac.authorization.k8s.io", "clusterrolebindings", ""),
+		},
+		"recreate and rollback": {
+			req: Request{
+				Objects:  []*unstructured.Unstructured{deployment, deployment},
+				Recreate: true,
+				Rollback: true,
+			},
+			expectedPerms: perms([]string{"create", "delete", "get", "patch", "update"}, "apps", "deployments", "default"),
+		},
+		"hooks and objects annotated for recreate may be deleted": {
+			req: Request{
+				Objects: []*unstructured.Unstructured{job, annotatedBinding, deployment},
+			},
+			expectedPerms: append(append(
+				perms([]string{"create", "delete", "get", "patch"}, "batch", "jobs", "default"),
+				perms([]string{"create", "delete", "get", "patch"}, "rbac.authorization.k8s.io", "clusterrolebindings", "")...),
+				perms([]string{"create", "get", "patch"}, "apps", "deployments", "default")...),
+		},
+		"custom resources from crds in the set, unknown kinds are skipped": {
+			req: Request{
+				Objects: []*unstructured.Unstructured{crd, cronTab, widget},
+			},
+			expectedPerms: append(
+				perms([]string{"create", "get", "patch"}, "apiextensions.k8s.io", "customresourcedefinitions", ""),
+				perms([]string{"create", "get", "patch"}, "example.com", "crontabs", "default")...),
+		},
+	}
+
+	s := runtime.NewScheme()
+	assert.NoError(t, scheme.AddToScheme(s))
+	assert.NoError(t, apiextensionsv1.AddToScheme(s))
+	mapper := testrestmapper.TestOnlyStaticRESTMapper(s, s.PrioritizedVersionsAllGroups()...)
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			actual, err := Permissions(mapper, tc.req)
+			assert.NoError(t, err)
+			assert.ElementsMatch(t, tc.expectedPerms, actual)
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	createDeployment := Permission{Verb: "create", Group: "apps", Resource: "deployments", Namespace: "default"}
+	createBinding := Permission{Verb: "create", Group: "rbac.authorization.k8s.io", Resource: "clusterrolebindings"}
+	deleteBinding := Permission{Verb: "delete", Group: "rbac.authorization.k8s.io", Resource: "clusterrolebindings"}
+
+	testCases := map[string]struct {
+		perms           []Permission
+		denied          []string
+		reviewErr       error
+		expectedMissing []Permission
+		expectedErr     bool
+	}{
+		"all permissions allowed": {
+			perms: []Permission{createDeployment, createBinding},
+		},
+		"missing permissions are reported together": {
+			perms:           []Permission{createDeployment, createBinding, deleteBinding},
+			denied:          []string{"clusterrolebindings"},
+			expectedMissing: []Permission{createBinding, deleteBinding},
+			expectedErr:     true,
+		},
+		"review error": {
+			perms:       []Permission{createDeployment},
+			reviewErr:   fmt.Errorf("forbidden"),
+			expectedErr: true,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+			client.PrependReactor("create", "selfsubjectaccessreviews",
+				func(action clienttesting.Action) (bool, runtime.Object, error) {
+					if tc.reviewErr != nil {
+						return true, nil, tc.reviewErr
+					}
+					review := action.(clienttesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+					allowed := true
+					for _, r := range tc.denied {
+						if review.Spec.ResourceAttributes.Resource == r {
+							allowed = false
+						}
+					}
+					review.Status.Allowed = allowed
+					return true, review, nil
+				})
+
+			err := Check(context.TODO(), client.AuthorizationV1(), tc.perms)
+			if !tc.expectedErr {
+				assert.NoError(t, err)
+				return
+			}
+			if !assert.Error(t, err) {
+				return
+			}
+			if tc.expectedMissing != nil {
+				missingErr, ok := err.(*MissingPermissionsError)
+				if !assert.True(t, ok) {
+					return
+				}
+				assert.Equal(t, tc.expectedMissing, missingErr.Missing)
+			}
+		})
+	}
+}
+
+func TestMissingPermissionsError(t *testing.T) {
+	err := &MissingPermissionsError{
+		Missing: []Permission{
+			{Verb: "create", Group: "rbac.authorization.k8s.io", Resource: "clusterrolebindings"},
+			{Verb: "delete", Resource: "configmaps", Namespace: "default"},
+		},
+	}
+	assert.Equal(t, "missing 2 permission(s) required for the apply:\n"+
+		"  create clusterrolebindings.rbac.authorization.k8s.io\n"+
+		"  delete configmaps -n default", err.Error())
+}
@@ -26,6 +26,8 @@ import (
 	"k8s.io/klog"
 	"k8s.io/kubectl/pkg/cmd/util"
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/hook"
+	"sigs.k8s.io/cli-utils/pkg/apply/retry"
 	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
@@ -80,6 +82,19 @@ type Options struct {
 
 	// InventoryPolicy defines the inventory policy of prune.
 	InventoryPolicy inventory.InventoryPolicy
+
+	// RetryPolicy defines how deleting an object is retried if it
+	// fails with a transient error.
+	RetryPolicy retry.Policy
+
+	// Filter limits the prune to the objects it selects. The objects
+	// that are not selected are kept in the inventory.
+	Filter object.Filter
+
+	// UnselectedIds are the objects in the package that are not
+	// selected by the Filter. They are never pruned, and are kept in
+	// the inventory.
+	UnselectedIds []object.ObjMetadata
 }
 
 // Prune deletes the set of resources which were previously applied
@@ -120,6 +135,12 @@ func (po *PruneOptions) Prune(localInv inventory.InventoryInfo,
 	klog.V(4).Infof("prune: %d union objects stored in cluster inventory", len(clusterInv))
 	pruneObjs := object.SetDiff(clusterInv, localIds)
 	klog.V(4).Infof("prune: %d objects to prune (clusterInv - localIds)", len(pruneObjs))
+	// Objects outside the scope of the filter are kept in the inventory.
+	var keepObjs []object.ObjMetadata
+	if !o.Filter.IsEmpty() {
+		pruneObjs, keepObjs = scopePruneObjs(pruneObjs, o)
+		klog.V(4).Infof("prune: %d objects to prune within filter", len(pruneObjs))
+	}
 	// Sort the resources in reverse order using the same rules as is
 	// used for apply.
 	sort.Sort(sort.Reverse(ordering.SortableMetas(pruneObjs)))
@@ -144,6 +165,12 @@ func (po *PruneOptions) Prune(localInv inventory.InventoryInfo,
 			taskContext.CaptureResourceFailure(pruneObj)
 			continue
 		}
+		// The labels can only be checked once the object has been fetched.
+		if !o.Filter.MatchesLabels(obj) {
+			klog.V(5).Infof("prune object not selected by filter; keeping: %s", pruneObj)
+			keepObjs = append(keepObjs, pruneObj)
+			continue
+		}
 		// Do not prune objects that are in set of currently applied objects.
 		uid := string(obj.GetUID())
 		if currentUIDs.Has(uid) {
@@ -181,7 +208,20 @@ func (po *PruneOptions) Prune(localInv inventory.InventoryInfo,
 				taskContext.CaptureResourceFailure(pruneObj)
 				continue
 			}
-			err = namespacedClient.Delete(context.TODO(), pruneObj.Name, metav1.DeleteOptions{})
+			attempts := 0
+			err = o.RetryPolicy.Do(taskContext.Context(), func() error {
+				attempts++
+				err := namespacedClient.Delete(taskContext.Context(), pruneObj.Name, metav1.DeleteOptions{})
+				if attempts > 1 && apierrors.IsNotFound(err) {
+					// Deleted by an earlier attempt that seemed to fail.
+					return nil
+				}
+				return err
+			}, func(attempt retry.Attempt) {
+				klog.V(4).Infof("retrying prune of %s/%s after attempt %d: %s",
+					pruneObj.Namespace, pruneObj.Name, attempt.Number, attempt.Err)
+				taskContext.EventChannel() <- createPruneRetryEvent(pruneObj, attempt)
+			})
 			if err != nil {
 				if klog.V(4) {
 					klog.Errorf("prune failed for %s/%s (%s)", pruneObj.Namespace, pruneObj.Name, err)
@@ -194,12 +234,35 @@ func (po *PruneOptions) Prune(localInv inventory.InventoryInfo,
 		}
 		taskContext.EventChannel() <- createPruneEvent(pruneObj, obj, event.Pruned)
 	}
-	// Final inventory equals applied objects and prune failures.
-	appliedResources := taskContext.AppliedResources()
+	// Final inventory equals applied objects, prune failures and the
+	// objects outside the filter. Hooks are applied, but are not stored
+	// in the inventory.
+	appliedResources := object.SetDiff(taskContext.AppliedResources(), hook.Ids(localObjs))
 	finalInventory := append(appliedResources, pruneFailures...)
+	finalInventory = append(finalInventory, keepObjs...)
 	return po.InvClient.Replace(localInv, finalInventory)
 }
 
+// scopePruneObjs divides the objects to prune into the objects within
+// the scope of the filter, and the objects that should be kept because
+// they are in the package, but not selected, or their kind or name is
+// not selected. The labels are checked separately, once the objects
+// have been fetched from the cluster.
+func scopePruneObjs(pruneObjs []object.ObjMetadata, o Options) (scoped, keep []object.ObjMetadata) {
+	unselected := make(map[object.ObjMetadata]bool, len(o.UnselectedIds))
+	for _, id := range o.UnselectedIds {
+		unselected[id] = true
+	}
+	for _, id := range pruneObjs {
+		if unselected[id] || !o.Filter.MatchesID(id) {
+			keep = append(keep, id)
+			continue
+		}
+		scoped = append(scoped, id)
+	}
+	return scoped, keep
+}
+
 func (po *PruneOptions) namespacedClient(obj object.ObjMetadata) (dynamic.ResourceInterface, error) {
 	mapping, err := po.mapper.RESTMapping(obj.GroupKind)
 	if err != nil {
@@ -270,6 +333,24 @@ func createPruneFailedEvent(objMeta object.ObjMetadata, err error) event.Event {
 	}
 }
 
+// createPruneRetryEvent is a helper function to package a prune event for
+// a failed attempt that will be retried.
+func createPruneRetryEvent(id object.ObjMetadata, attempt retry.Attempt) event.Event {
+	return event.Event{
+		Type: event.PruneType,
+		PruneEvent: event.PruneEvent{
+			Type:       event.PruneEventRetry,
+			Identifier: id,
+			Error:      attempt.Err,
+			Retry: event.Retry{
+				Attempt:     attempt.Number,
+				MaxAttempts: attempt.MaxAttempts,
+				Delay:       attempt.Delay,
+			},
+		},
+	}
+}
+
 func canPrune(localInv inventory.InventoryInfo, obj *unstructured.Unstructured,
 	policy inventory.InventoryPolicy, uid string) bool {
 	if !inventory.CanPrune(localInv, obj, policy) {
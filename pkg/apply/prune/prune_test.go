@@ -9,7 +9,9 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -19,8 +21,10 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
 	"k8s.io/kubectl/pkg/scheme"
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/retry"
 	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
@@ -78,6 +82,22 @@ var pod = &unstructured.Unstructured{
 	},
 }
 
+var hookPod = &unstructured.Unstructured{
+	Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]interface{}{
+			"name":      "hook",
+			"namespace": testNamespace,
+			"uid":       "uid-hook",
+			"annotations": map[string]interface{}{
+				"config.k8s.io/owning-inventory": testInventoryLabel,
+				common.HookAnnotation:            "pre-apply",
+			},
+		},
+	},
+}
+
 var pdb = &unstructured.Unstructured{
 	Object: map[string]interface{}{
 		"apiVersion": "policy/v1beta1",
@@ -181,6 +201,9 @@ func TestPrune(t *testing.T) {
 		// and the objects which should be stored in the inventory object.
 		finalClusterObjs []*unstructured.Unstructured
 		pruneEventObjs   []*unstructured.Unstructured
+		// filter and unselectedObjs limit the scope of the prune.
+		filter         object.Filter
+		unselectedObjs []*unstructured.Unstructured
 	}{
 		"Past and current objects are empty; no pruned objects": {
 			pastObjs:         []*unstructured.Unstructured{},
@@ -224,6 +247,38 @@ func TestPrune(t *testing.T) {
 			finalClusterObjs: []*unstructured.Unstructured{preventDelete, pdb, role},
 			pruneEventObjs:   []*unstructured.Unstructured{preventDelete},
 		},
+		"Hooks are not stored in the inventory": {
+			pastObjs:         []*unstructured.Unstructured{pdb},
+			currentObjs:      []*unstructured.Unstructured{pdb, hookPod},
+			prunedObjs:       []*unstructured.Unstructured{},
+			finalClusterObjs: []*unstructured.Unstructured{pdb},
+			pruneEventObjs:   []*unstructured.Unstructured{},
+		},
+		"Objects with kinds outside the filter are kept": {
+			pastObjs:         []*unstructured.Unstructured{pdb, role, pod},
+			currentObjs:      []*unstructured.Unstructured{pod},
+			prunedObjs:       []*unstructured.Unstructured{},
+			finalClusterObjs: []*unstructured.Unstructured{pdb, role, pod},
+			pruneEventObjs:   []*unstructured.Unstructured{},
+			filter:           object.Filter{Kinds: []string{"Pod"}},
+		},
+		"Unselected objects in the package are kept": {
+			pastObjs:         []*unstructured.Unstructured{pdb, role, pod},
+			currentObjs:      []*unstructured.Unstructured{pod},
+			prunedObjs:       []*unstructured.Unstructured{role},
+			finalClusterObjs: []*unstructured.Unstructured{pdb, pod},
+			pruneEventObjs:   []*unstructured.Unstructured{role},
+			filter:           object.Filter{Kinds: []string{"Pod", "PodDisruptionBudget", "Role"}},
+			unselectedObjs:   []*unstructured.Unstructured{pdb},
+		},
+		"Objects with labels outside the filter are kept": {
+			pastObjs:         []*unstructured.Unstructured{pdb, role},
+			currentObjs:      []*unstructured.Unstructured{},
+			prunedObjs:       []*unstructured.Unstructured{},
+			finalClusterObjs: []*unstructured.Unstructured{pdb, role},
+			pruneEventObjs:   []*unstructured.Unstructured{},
+			filter:           object.Filter{Selector: "app=web"},
+		},
 		"Namespace not pruned if objects are still in it": {
 			pastObjs:         []*unstructured.Unstructured{namespace, pdb, pod},
 			currentObjs:      []*unstructured.Unstructured{pod},
@@ -268,6 +323,8 @@ func TestPrune(t *testing.T) {
 					// Run the prune and validate.
 					return po.Prune(currentInventory, tc.currentObjs, populateObjectIds(tc.currentObjs, t), taskContext, Options{
 						DryRunStrategy: drs,
+						Filter:         tc.filter,
+						UnselectedIds:  object.UnstructuredsToObjMetas(tc.unselectedObjs),
 					})
 				}()
 
@@ -527,6 +584,81 @@ func TestPruneWithError(t *testing.T) {
 	}
 }
 
+func TestPruneWithRetry(t *testing.T) {
+	throttled := apierrors.NewTooManyRequests("slow down", 1)
+	invalid := apierrors.NewBadRequest("invalid")
+
+	tests := map[string]struct {
+		deleteErrs         []error
+		expectedDeletes    int
+		expectedEventTypes []event.PruneEventType
+	}{
+		"succeeds after retries": {
+			deleteErrs:         []error{throttled, throttled},
+			expectedDeletes:    3,
+			expectedEventTypes: []event.PruneEventType{event.PruneEventRetry, event.PruneEventRetry, event.PruneEventResourceUpdate},
+		},
+		"fails after max attempts": {
+			deleteErrs:         []error{throttled, throttled, throttled},
+			expectedDeletes:    3,
+			expectedEventTypes: []event.PruneEventType{event.PruneEventRetry, event.PruneEventRetry, event.PruneEventFailed},
+		},
+		"non-retryable error is not retried": {
+			deleteErrs:         []error{invalid},
+			expectedDeletes:    1,
+			expectedEventTypes: []event.PruneEventType{event.PruneEventFailed},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			po := NewPruneOptions()
+			po.InvClient = inventory.NewFakeInventoryClient(object.UnstructuredsToObjMetas(
+				[]*unstructured.Unstructured{role}))
+			currentInventory := createInventoryInfo(role)
+			client := fake.NewSimpleDynamicClient(scheme.Scheme, role)
+			deletes := 0
+			client.PrependReactor("delete", "roles", func(clienttesting.Action) (bool, runtime.Object, error) {
+				deletes++
+				if deletes <= len(tc.deleteErrs) {
+					return true, nil, tc.deleteErrs[deletes-1]
+				}
+				return false, nil, nil
+			})
+			po.client = client
+			po.mapper = testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme,
+				scheme.Scheme.PrioritizedVersionsAllGroups()...)
+			eventChannel := make(chan event.Event, len(tc.expectedEventTypes))
+			taskContext := taskrunner.NewTaskContext(eventChannel)
+			err := func() error {
+				defer close(eventChannel)
+				return po.Prune(currentInventory, nil, sets.NewString(), taskContext, Options{
+					DryRunStrategy: common.DryRunNone,
+					RetryPolicy: retry.Policy{
+						MaxAttempts:    3,
+						InitialBackoff: time.Millisecond,
+					},
+				})
+			}()
+			if err != nil {
+				t.Fatalf("Unexpected error during Prune(): %#v", err)
+			}
+			if want, got := tc.expectedDeletes, deletes; want != got {
+				t.Errorf("Expected (%d) deletes, got (%d)", want, got)
+			}
+			var actualTypes []event.PruneEventType
+			for e := range eventChannel {
+				actualTypes = append(actualTypes, e.PruneEvent.Type)
+				if e.PruneEvent.Type == event.PruneEventRetry && e.PruneEvent.Retry.MaxAttempts != 3 {
+					t.Errorf("Expected max attempts 3, got %d", e.PruneEvent.Retry.MaxAttempts)
+				}
+			}
+			if !reflect.DeepEqual(tc.expectedEventTypes, actualTypes) {
+				t.Errorf("Expected prune events %v, got %v", tc.expectedEventTypes, actualTypes)
+			}
+		})
+	}
+}
+
 type fakeDynamicFailureClient struct {
 	dynamic dynamic.Interface
 }
@@ -0,0 +1,600 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package record provides functionality for recording the events from
+// the applier and destroyer to a file, and for replaying them later.
+// This makes it possible to reproduce the output of any printer without
+// access to the cluster.
+//
+// A recording is a file with one json object per line. The first line is
+// a Header, and every following line is a Record containing a single event
+// together with the time it was received. Errors are recorded with their
+// messages, and TimeoutErrors are recorded with all their fields so they
+// are reconstructed when the recording is replayed. All other errors are
+// replayed as plain errors with the same message.
+package record
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	pollevent "sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+const (
+	// APIVersion is the version of the recording format.
+	APIVersion = "cli-utils.sigs.k8s.io/v1alpha1"
+	// Kind is the kind set in the header of every recording.
+	Kind = "EventStream"
+)
+
+// Header is the first line of every recording.
+type Header struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	// DryRunStrategy is the dry-run strategy the events were
+	// produced with. One of None, Client or Server.
+	DryRunStrategy string `json:"dryRunStrategy"`
+}
+
+// Record contains a single event. Only the field matching the type of
+// the event is set.
+type Record struct {
+	Timestamp time.Time       `json:"timestamp"`
+	Type      string          `json:"type"`
+	Init      *InitRecord     `json:"initEvent,omitempty"`
+	Error     *ErrorRecord    `json:"errorEvent,omitempty"`
+	Apply     *ApplyRecord    `json:"applyEvent,omitempty"`
+	Status    *StatusRecord   `json:"statusEvent,omitempty"`
+	Prune     *PruneRecord    `json:"pruneEvent,omitempty"`
+	Delete    *DeleteRecord   `json:"deleteEvent,omitempty"`
+	Drift     *DriftRecord    `json:"driftEvent,omitempty"`
+	Rollback  *RollbackRecord `json:"rollbackEvent,omitempty"`
+}
+
+// Identifier is the serialized form of an object.ObjMetadata.
+type Identifier struct {
+	Group     string `json:"group"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+type InitRecord struct {
+	ResourceGroups []ResourceGroupRecord `json:"resourceGroups"`
+}
+
+type ResourceGroupRecord struct {
+	Action      string       `json:"action"`
+	Stage       string       `json:"stage,omitempty"`
+	Identifiers []Identifier `json:"identifiers"`
+}
+
+// ErrorRecord contains an error. Timeout is only set if the error
+// was a TimeoutError.
+type ErrorRecord struct {
+	Message string         `json:"message"`
+	Timeout *TimeoutRecord `json:"timeout,omitempty"`
+}
+
+type TimeoutRecord struct {
+	Identifiers       []Identifier             `json:"identifiers"`
+	TimeoutSeconds    float64                  `json:"timeoutSeconds"`
+	Condition         string                   `json:"condition"`
+	TimedOutResources []TimedOutResourceRecord `json:"timedOutResources"`
+}
+
+type TimedOutResourceRecord struct {
+	Identifier    Identifier `json:"identifier"`
+	Status        string     `json:"status"`
+	Message       string     `json:"message,omitempty"`
+	WaitCondition string     `json:"waitCondition,omitempty"`
+}
+
+type ApplyRecord struct {
+	Type       string                     `json:"type"`
+	Operation  string                     `json:"operation"`
+	Identifier Identifier                 `json:"identifier"`
+	Object     *unstructured.Unstructured `json:"object,omitempty"`
+	Error      string                     `json:"error,omitempty"`
+	Retry      *RetryRecord               `json:"retry,omitempty"`
+}
+
+// RetryRecord is only set for events about failed attempts that will
+// be retried.
+type RetryRecord struct {
+	Attempt      int     `json:"attempt"`
+	MaxAttempts  int     `json:"maxAttempts"`
+	DelaySeconds float64 `json:"delaySeconds"`
+}
+
+type StatusRecord struct {
+	Type     string                `json:"type"`
+	Resource *ResourceStatusRecord `json:"resource,omitempty"`
+}
+
+type ResourceStatusRecord struct {
+	Identifier         Identifier                 `json:"identifier"`
+	Status             string                     `json:"status"`
+	Message            string                     `json:"message,omitempty"`
+	Error              string                     `json:"error,omitempty"`
+	Resource           *unstructured.Unstructured `json:"resource,omitempty"`
+	GeneratedResources []*ResourceStatusRecord    `json:"generatedResources,omitempty"`
+}
+
+type PruneRecord struct {
+	Type       string                     `json:"type"`
+	Operation  string                     `json:"operation"`
+	Identifier Identifier                 `json:"identifier"`
+	Object     *unstructured.Unstructured `json:"object,omitempty"`
+	Error      string                     `json:"error,omitempty"`
+	Retry      *RetryRecord               `json:"retry,omitempty"`
+}
+
+type DeleteRecord struct {
+	Type       string                     `json:"type"`
+	Operation  string                     `json:"operation"`
+	Identifier Identifier                 `json:"identifier"`
+	Object     *unstructured.Unstructured `json:"object,omitempty"`
+	Error      string                     `json:"error,omitempty"`
+}
+
+type DriftRecord struct {
+	Type       string                     `json:"type"`
+	Operation  string                     `json:"operation"`
+	Identifier Identifier                 `json:"identifier"`
+	Object     *unstructured.Unstructured `json:"object,omitempty"`
+	Details    []string                   `json:"details,omitempty"`
+}
+
+type RollbackRecord struct {
+	Type       string                     `json:"type"`
+	Operation  string                     `json:"operation"`
+	Identifier Identifier                 `json:"identifier"`
+	Object     *unstructured.Unstructured `json:"object,omitempty"`
+	Error      string                     `json:"error,omitempty"`
+}
+
+// NewRecord creates a Record for the event. It returns an error for
+// event types that can't be recorded, so they are not silently lost.
+func NewRecord(e event.Event, timestamp time.Time) (Record, error) {
+	r := Record{
+		Timestamp: timestamp,
+		Type:      e.Type.String(),
+	}
+	switch e.Type {
+	case event.InitType:
+		init := &InitRecord{}
+		for _, rg := range e.InitEvent.ResourceGroups {
+			init.ResourceGroups = append(init.ResourceGroups, ResourceGroupRecord{
+				Action:      rg.Action.String(),
+				Stage:       rg.Stage,
+				Identifiers: fromIdentifiers(rg.Identifiers),
+			})
+		}
+		r.Init = init
+	case event.ErrorType:
+		r.Error = fromError(e.ErrorEvent.Err)
+	case event.ApplyType:
+		ae := e.ApplyEvent
+		r.Apply = &ApplyRecord{
+			Type:       ae.Type.String(),
+			Operation:  ae.Operation.String(),
+			Identifier: fromIdentifier(ae.Identifier),
+			Object:     ae.Object,
+			Error:      errorMessage(ae.Error),
+			Retry:      fromRetry(ae.Retry),
+		}
+	case event.StatusType:
+		r.Status = &StatusRecord{
+			Type:     e.StatusEvent.Type.String(),
+			Resource: fromResourceStatus(e.StatusEvent.Resource),
+		}
+	case event.PruneType:
+		pe := e.PruneEvent
+		r.Prune = &PruneRecord{
+			Type:       pe.Type.String(),
+			Operation:  pe.Operation.String(),
+			Identifier: fromIdentifier(pe.Identifier),
+			Object:     pe.Object,
+			Error:      errorMessage(pe.Error),
+			Retry:      fromRetry(pe.Retry),
+		}
+	case event.DeleteType:
+		de := e.DeleteEvent
+		r.Delete = &DeleteRecord{
+			Type:       de.Type.String(),
+			Operation:  de.Operation.String(),
+			Identifier: fromIdentifier(de.Identifier),
+			Object:     de.Object,
+			Error:      errorMessage(de.Error),
+		}
+	case event.DriftType:
+		de := e.DriftEvent
+		r.Drift = &DriftRecord{
+			Type:       de.Type.String(),
+			Operation:  de.Operation.String(),
+			Identifier: fromIdentifier(de.Identifier),
+			Object:     de.Object,
+			Details:    de.Details,
+		}
+	case event.RollbackType:
+		re := e.RollbackEvent
+		r.Rollback = &RollbackRecord{
+			Type:       re.Type.String(),
+			Operation:  re.Operation.String(),
+			Identifier: fromIdentifier(re.Identifier),
+			Object:     re.Object,
+			Error:      errorMessage(re.Error),
+		}
+	default:
+		return r, fmt.Errorf("unable to record event of type %s", e.Type)
+	}
+	return r, nil
+}
+
+// Event converts the Record back into an event.
+func (r Record) Event() (event.Event, error) {
+	t, err := parseEnum("Type", r.Type, func(i int) string { return event.Type(i).String() })
+	if err != nil {
+		return event.Event{}, err
+	}
+	e := event.Event{
+		Type: event.Type(t),
+	}
+	switch e.Type {
+	case event.InitType:
+		if r.Init == nil {
+			return e, missingEventError(r.Type)
+		}
+		for _, rg := range r.Init.ResourceGroups {
+			action, err := parseEnum("ResourceAction", rg.Action,
+				func(i int) string { return event.ResourceAction(i).String() })
+			if err != nil {
+				return e, err
+			}
+			e.InitEvent.ResourceGroups = append(e.InitEvent.ResourceGroups, event.ResourceGroup{
+				Action:      event.ResourceAction(action),
+				Stage:       rg.Stage,
+				Identifiers: toIdentifiers(rg.Identifiers),
+			})
+		}
+	case event.ErrorType:
+		if r.Error == nil {
+			return e, missingEventError(r.Type)
+		}
+		e.ErrorEvent.Err = r.Error.toError()
+	case event.ApplyType:
+		if r.Apply == nil {
+			return e, missingEventError(r.Type)
+		}
+		t, err := parseEnum("ApplyEventType", r.Apply.Type,
+			func(i int) string { return event.ApplyEventType(i).String() })
+		if err != nil {
+			return e, err
+		}
+		op, err := parseEnum("ApplyEventOperation", r.Apply.Operation,
+			func(i int) string { return event.ApplyEventOperation(i).String() })
+		if err != nil {
+			return e, err
+		}
+		e.ApplyEvent = event.ApplyEvent{
+			Type:       event.ApplyEventType(t),
+			Operation:  event.ApplyEventOperation(op),
+			Identifier: r.Apply.Identifier.toObjMetadata(),
+			Object:     r.Apply.Object,
+			Error:      toError(r.Apply.Error),
+			Retry:      r.Apply.Retry.toRetry(),
+		}
+	case event.StatusType:
+		if r.Status == nil {
+			return e, missingEventError(r.Type)
+		}
+		t, err := parseEnum("StatusEventType", r.Status.Type,
+			func(i int) string { return event.StatusEventType(i).String() })
+		if err != nil {
+			return e, err
+		}
+		e.StatusEvent = event.StatusEvent{
+			Type:     event.StatusEventType(t),
+			Resource: r.Status.Resource.toResourceStatus(),
+		}
+	case event.PruneType:
+		if r.Prune == nil {
+			return e, missingEventError(r.Type)
+		}
+		t, err := parseEnum("PruneEventType", r.Prune.Type,
+			func(i int) string { return event.PruneEventType(i).String() })
+		if err != nil {
+			return e, err
+		}
+		op, err := parseEnum("PruneEventOperation", r.Prune.Operation,
+			func(i int) string { return event.PruneEventOperation(i).String() })
+		if err != nil {
+			return e, err
+		}
+		e.PruneEvent = event.PruneEvent{
+			Type:       event.PruneEventType(t),
+			Operation:  event.PruneEventOperation(op),
+			Identifier: r.Prune.Identifier.toObjMetadata(),
+			Object:     r.Prune.Object,
+			Error:      toError(r.Prune.Error),
+			Retry:      r.Prune.Retry.toRetry(),
+		}
+	case event.DeleteType:
+		if r.Delete == nil {
+			return e, missingEventError(r.Type)
+		}
+		t, err := parseEnum("DeleteEventType", r.Delete.Type,
+			func(i int) string { return event.DeleteEventType(i).String() })
+		if err != nil {
+			return e, err
+		}
+		op, err := parseEnum("DeleteEventOperation", r.Delete.Operation,
+			func(i int) string { return event.DeleteEventOperation(i).String() })
+		if err != nil {
+			return e, err
+		}
+		e.DeleteEvent = event.DeleteEvent{
+			Type:       event.DeleteEventType(t),
+			Operation:  event.DeleteEventOperation(op),
+			Identifier: r.Delete.Identifier.toObjMetadata(),
+			Object:     r.Delete.Object,
+			Error:      toError(r.Delete.Error),
+		}
+	case event.DriftType:
+		if r.Drift == nil {
+			return e, missingEventError(r.Type)
+		}
+		t, err := parseEnum("DriftEventType", r.Drift.Type,
+			func(i int) string { return event.DriftEventType(i).String() })
+		if err != nil {
+			return e, err
+		}
+		op, err := parseEnum("DriftEventOperation", r.Drift.Operation,
+			func(i int) string { return event.DriftEventOperation(i).String() })
+		if err != nil {
+			return e, err
+		}
+		e.DriftEvent = event.DriftEvent{
+			Type:       event.DriftEventType(t),
+			Operation:  event.DriftEventOperation(op),
+			Identifier: r.Drift.Identifier.toObjMetadata(),
+			Object:     r.Drift.Object,
+			Details:    r.Drift.Details,
+		}
+	case event.RollbackType:
+		if r.Rollback == nil {
+			return e, missingEventError(r.Type)
+		}
+		t, err := parseEnum("RollbackEventType", r.Rollback.Type,
+			func(i int) string { return event.RollbackEventType(i).String() })
+		if err != nil {
+			return e, err
+		}
+		op, err := parseEnum("RollbackEventOperation", r.Rollback.Operation,
+			func(i int) string { return event.RollbackEventOperation(i).String() })
+		if err != nil {
+			return e, err
+		}
+		e.RollbackEvent = event.RollbackEvent{
+			Type:       event.RollbackEventType(t),
+			Operation:  event.RollbackEventOperation(op),
+			Identifier: r.Rollback.Identifier.toObjMetadata(),
+			Object:     r.Rollback.Object,
+			Error:      toError(r.Rollback.Error),
+		}
+	default:
+		return e, fmt.Errorf("unable to replay record of type %s", r.Type)
+	}
+	return e, nil
+}
+
+func missingEventError(t string) error {
+	return fmt.Errorf("record of type %s doesn't contain an event", t)
+}
+
+// parseEnum finds the value of an enum generated by stringer from its
+// name. Stringer returns the name of the type followed by the value in
+// parenthesis for unknown values, which is used to detect that there are
+// no more values to check.
+func parseEnum(typeName, name string, str func(int) string) (int, error) {
+	for i := 0; ; i++ {
+		s := str(i)
+		if s == name {
+			return i, nil
+		}
+		if strings.HasPrefix(s, typeName+"(") {
+			return 0, fmt.Errorf("unknown %s %q", typeName, name)
+		}
+	}
+}
+
+// DryRunStrategyName returns the name used for the dry-run strategy
+// in the header of a recording.
+func DryRunStrategyName(drs common.DryRunStrategy) string {
+	switch drs {
+	case common.DryRunClient:
+		return "Client"
+	case common.DryRunServer:
+		return "Server"
+	default:
+		return "None"
+	}
+}
+
+// ParseDryRunStrategy is the inverse of DryRunStrategyName.
+func ParseDryRunStrategy(name string) (common.DryRunStrategy, error) {
+	switch name {
+	case "None", "":
+		return common.DryRunNone, nil
+	case "Client":
+		return common.DryRunClient, nil
+	case "Server":
+		return common.DryRunServer, nil
+	default:
+		return common.DryRunNone, fmt.Errorf("unknown dry-run strategy %q", name)
+	}
+}
+
+func fromIdentifier(id object.ObjMetadata) Identifier {
+	return Identifier{
+		Group:     id.GroupKind.Group,
+		Kind:      id.GroupKind.Kind,
+		Namespace: id.Namespace,
+		Name:      id.Name,
+	}
+}
+
+func fromIdentifiers(ids []object.ObjMetadata) []Identifier {
+	var res []Identifier
+	for _, id := range ids {
+		res = append(res, fromIdentifier(id))
+	}
+	return res
+}
+
+func (i Identifier) toObjMetadata() object.ObjMetadata {
+	return object.ObjMetadata{
+		GroupKind: schema.GroupKind{
+			Group: i.Group,
+			Kind:  i.Kind,
+		},
+		Namespace: i.Namespace,
+		Name:      i.Name,
+	}
+}
+
+func toIdentifiers(ids []Identifier) []object.ObjMetadata {
+	var res []object.ObjMetadata
+	for _, id := range ids {
+		res = append(res, id.toObjMetadata())
+	}
+	return res
+}
+
+// fromRetry returns nil for events that are not about retries.
+func fromRetry(r event.Retry) *RetryRecord {
+	if r.Attempt == 0 {
+		return nil
+	}
+	return &RetryRecord{
+		Attempt:      r.Attempt,
+		MaxAttempts:  r.MaxAttempts,
+		DelaySeconds: r.Delay.Seconds(),
+	}
+}
+
+func (r *RetryRecord) toRetry() event.Retry {
+	if r == nil {
+		return event.Retry{}
+	}
+	return event.Retry{
+		Attempt:     r.Attempt,
+		MaxAttempts: r.MaxAttempts,
+		Delay:       time.Duration(r.DelaySeconds * float64(time.Second)),
+	}
+}
+
+func errorMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func toError(msg string) error {
+	if msg == "" {
+		return nil
+	}
+	return errors.New(msg)
+}
+
+func fromError(err error) *ErrorRecord {
+	if err == nil {
+		return &ErrorRecord{}
+	}
+	er := &ErrorRecord{
+		Message: err.Error(),
+	}
+	if timeoutErr, ok := taskrunner.IsTimeoutError(err); ok {
+		tr := &TimeoutRecord{
+			Identifiers:    fromIdentifiers(timeoutErr.Identifiers),
+			TimeoutSeconds: timeoutErr.Timeout.Seconds(),
+			Condition:      string(timeoutErr.Condition),
+		}
+		for _, r := range timeoutErr.TimedOutResources {
+			tr.TimedOutResources = append(tr.TimedOutResources, TimedOutResourceRecord{
+				Identifier:    fromIdentifier(r.Identifier),
+				Status:        r.Status.String(),
+				Message:       r.Message,
+				WaitCondition: r.WaitCondition,
+			})
+		}
+		er.Timeout = tr
+	}
+	return er
+}
+
+func (er *ErrorRecord) toError() error {
+	if er.Timeout == nil {
+		return toError(er.Message)
+	}
+	timeoutErr := &taskrunner.TimeoutError{
+		Identifiers: toIdentifiers(er.Timeout.Identifiers),
+		Timeout:     time.Duration(er.Timeout.TimeoutSeconds * float64(time.Second)),
+		Condition:   taskrunner.Condition(er.Timeout.Condition),
+	}
+	for _, r := range er.Timeout.TimedOutResources {
+		timeoutErr.TimedOutResources = append(timeoutErr.TimedOutResources, taskrunner.TimedOutResource{
+			Identifier:    r.Identifier.toObjMetadata(),
+			Status:        status.Status(r.Status),
+			Message:       r.Message,
+			WaitCondition: r.WaitCondition,
+		})
+	}
+	return timeoutErr
+}
+
+func fromResourceStatus(rs *pollevent.ResourceStatus) *ResourceStatusRecord {
+	if rs == nil {
+		return nil
+	}
+	r := &ResourceStatusRecord{
+		Identifier: fromIdentifier(rs.Identifier),
+		Status:     rs.Status.String(),
+		Message:    rs.Message,
+		Error:      errorMessage(rs.Error),
+		Resource:   rs.Resource,
+	}
+	for _, g := range rs.GeneratedResources {
+		r.GeneratedResources = append(r.GeneratedResources, fromResourceStatus(g))
+	}
+	return r
+}
+
+func (r *ResourceStatusRecord) toResourceStatus() *pollevent.ResourceStatus {
+	if r == nil {
+		return nil
+	}
+	rs := &pollevent.ResourceStatus{
+		Identifier: r.Identifier.toObjMetadata(),
+		Status:     status.Status(r.Status),
+		Message:    r.Message,
+		Error:      toError(r.Error),
+		Resource:   r.Resource,
+	}
+	for _, g := range r.GeneratedResources {
+		rs.GeneratedResources = append(rs.GeneratedResources, g.toResourceStatus())
+	}
+	return rs
+}
@@ -0,0 +1,264 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package record
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
+	pollevent "sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+var (
+	depID = object.ObjMetadata{
+		GroupKind: schema.GroupKind{
+			Group: "apps",
+			Kind:  "Deployment",
+		},
+		Namespace: "default",
+		Name:      "foo",
+	}
+	rsID = object.ObjMetadata{
+		GroupKind: schema.GroupKind{
+			Group: "apps",
+			Kind:  "ReplicaSet",
+		},
+		Namespace: "default",
+		Name:      "foo-1234",
+	}
+)
+
+func TestRecordRoundTrip(t *testing.T) {
+	dep := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"name":      "foo",
+				"namespace": "default",
+			},
+		},
+	}
+
+	testCases := map[string]struct {
+		event event.Event
+	}{
+		"init event": {
+			event: event.Event{
+				Type: event.InitType,
+				InitEvent: event.InitEvent{
+					ResourceGroups: []event.ResourceGroup{
+						{
+							Action:      event.ApplyAction,
+							Stage:       "canary",
+							Identifiers: []object.ObjMetadata{depID},
+						},
+						{
+							Action:      event.PruneAction,
+							Identifiers: []object.ObjMetadata{depID},
+						},
+					},
+				},
+			},
+		},
+		"apply event with object and error": {
+			event: event.Event{
+				Type: event.ApplyType,
+				ApplyEvent: event.ApplyEvent{
+					Type:       event.ApplyEventResourceUpdate,
+					Operation:  event.Failed,
+					Identifier: depID,
+					Object:     dep,
+					Error:      fmt.Errorf("forbidden"),
+				},
+			},
+		},
+		"apply retry event": {
+			event: event.Event{
+				Type: event.ApplyType,
+				ApplyEvent: event.ApplyEvent{
+					Type:       event.ApplyEventRetry,
+					Identifier: depID,
+					Error:      fmt.Errorf("too many requests"),
+					Retry: event.Retry{
+						Attempt:     2,
+						MaxAttempts: 5,
+						Delay:       2 * time.Second,
+					},
+				},
+			},
+		},
+		"status event with generated resources": {
+			event: event.Event{
+				Type: event.StatusType,
+				StatusEvent: event.StatusEvent{
+					Type: event.StatusEventResourceUpdate,
+					Resource: &pollevent.ResourceStatus{
+						Identifier: depID,
+						Status:     status.InProgressStatus,
+						Message:    "Replicas: 1/2",
+						Resource:   dep,
+						GeneratedResources: pollevent.ResourceStatuses{
+							{
+								Identifier: rsID,
+								Status:     status.CurrentStatus,
+							},
+						},
+					},
+				},
+			},
+		},
+		"prune event": {
+			event: event.Event{
+				Type: event.PruneType,
+				PruneEvent: event.PruneEvent{
+					Type:       event.PruneEventResourceUpdate,
+					Operation:  event.PruneSkipped,
+					Identifier: depID,
+				},
+			},
+		},
+		"drift event with details": {
+			event: event.Event{
+				Type: event.DriftType,
+				DriftEvent: event.DriftEvent{
+					Type:       event.DriftEventResourceUpdate,
+					Operation:  event.FieldsChanged,
+					Identifier: depID,
+					Object:     dep,
+					Details:    []string{".spec.replicas is managed by kubectl-edit"},
+				},
+			},
+		},
+		"rollback event with object": {
+			event: event.Event{
+				Type: event.RollbackType,
+				RollbackEvent: event.RollbackEvent{
+					Type:       event.RollbackEventResourceUpdate,
+					Operation:  event.Restored,
+					Identifier: depID,
+					Object:     dep,
+				},
+			},
+		},
+		"rollback failed event": {
+			event: event.Event{
+				Type: event.RollbackType,
+				RollbackEvent: event.RollbackEvent{
+					Type:       event.RollbackEventFailed,
+					Operation:  event.RollbackDeleted,
+					Identifier: depID,
+					Error:      fmt.Errorf("forbidden"),
+				},
+			},
+		},
+		"delete event": {
+			event: event.Event{
+				Type: event.DeleteType,
+				DeleteEvent: event.DeleteEvent{
+					Type:       event.DeleteEventFailed,
+					Identifier: depID,
+					Error:      fmt.Errorf("not allowed"),
+				},
+			},
+		},
+		"timeout error event": {
+			event: event.Event{
+				Type: event.ErrorType,
+				ErrorEvent: event.ErrorEvent{
+					Err: &taskrunner.TimeoutError{
+						Identifiers: []object.ObjMetadata{depID},
+						Timeout:     30 * time.Second,
+						Condition:   taskrunner.AllCurrent,
+						TimedOutResources: []taskrunner.TimedOutResource{
+							{
+								Identifier:    depID,
+								Status:        status.InProgressStatus,
+								Message:       "Replicas: 1/2",
+								WaitCondition: "current",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			r, err := NewRecord(tc.event, time.Now())
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			b, err := json.Marshal(r)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			r = Record{}
+			if !assert.NoError(t, json.Unmarshal(b, &r)) {
+				t.FailNow()
+			}
+			e, err := r.Event()
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			assert.Equal(t, tc.event, e)
+		})
+	}
+}
+
+func TestNewRecord_UnsupportedType(t *testing.T) {
+	_, err := NewRecord(event.Event{Type: event.Type(-1)}, time.Now())
+	if assert.Error(t, err) {
+		assert.Equal(t, "unable to record event of type Type(-1)", err.Error())
+	}
+}
+
+func TestRecordEvent_Errors(t *testing.T) {
+	testCases := map[string]struct {
+		record      Record
+		expectedErr string
+	}{
+		"unknown type": {
+			record: Record{
+				Type: "FooType",
+			},
+			expectedErr: `unknown Type "FooType"`,
+		},
+		"unknown operation": {
+			record: Record{
+				Type: event.ApplyType.String(),
+				Apply: &ApplyRecord{
+					Type:      event.ApplyEventResourceUpdate.String(),
+					Operation: "Destroyed",
+				},
+			},
+			expectedErr: `unknown ApplyEventOperation "Destroyed"`,
+		},
+		"missing event": {
+			record: Record{
+				Type: event.PruneType.String(),
+			},
+			expectedErr: "record of type PruneType doesn't contain an event",
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			_, err := tc.record.Event()
+			if assert.Error(t, err) {
+				assert.Equal(t, tc.expectedErr, err.Error())
+			}
+		})
+	}
+}
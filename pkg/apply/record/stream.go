@@ -0,0 +1,245 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package record
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/common"
+)
+
+// now returns the current time. It is a variable to allow unit testing.
+var now = time.Now
+
+// sleep waits for the given duration. It is a variable to allow unit testing.
+var sleep = time.Sleep
+
+// Writer writes events to a recording. It is safe for use by multiple
+// goroutines.
+type Writer struct {
+	mux sync.Mutex
+	w   io.Writer
+	enc *json.Encoder
+	// err is the first error encountered while writing. Once an error
+	// has happened, no more events are written.
+	err error
+}
+
+// NewWriter returns a new Writer that writes to the provided io.Writer.
+// The header of the recording is written immediately.
+func NewWriter(w io.Writer, drs common.DryRunStrategy) *Writer {
+	rw := &Writer{
+		w:   w,
+		enc: json.NewEncoder(w),
+	}
+	rw.err = rw.enc.Encode(Header{
+		APIVersion:     APIVersion,
+		Kind:           Kind,
+		DryRunStrategy: DryRunStrategyName(drs),
+	})
+	return rw
+}
+
+// Write writes a single event to the recording.
+func (rw *Writer) Write(e event.Event) error {
+	rw.mux.Lock()
+	defer rw.mux.Unlock()
+	if rw.err != nil {
+		return rw.err
+	}
+	r, err := NewRecord(e, now())
+	if err != nil {
+		rw.err = err
+		return err
+	}
+	rw.err = rw.enc.Encode(r)
+	return rw.err
+}
+
+// Tee returns a channel that receives all events from the provided
+// channel, after they have been written to the recording. Errors from
+// writing do not stop the events from being forwarded, but are
+// returned from Err.
+func (rw *Writer) Tee(ch <-chan event.Event) <-chan event.Event {
+	out := make(chan event.Event)
+	go func() {
+		defer close(out)
+		for e := range ch {
+			_ = rw.Write(e)
+			out <- e
+		}
+	}()
+	return out
+}
+
+// Err returns the first error encountered while writing, if any.
+func (rw *Writer) Err() error {
+	rw.mux.Lock()
+	defer rw.mux.Unlock()
+	return rw.err
+}
+
+// FileWriter records the events of a command to a file. A nil
+// FileWriter records nothing, so commands can use it without checking
+// whether recording was requested.
+type FileWriter struct {
+	f  *os.File
+	rw *Writer
+}
+
+// CreateFile creates the file with the given path and writes the
+// header of the recording to it. It returns nil if the path is empty.
+// The file should be created before any changes are made to the
+// cluster, so a command fails early if it can't be created.
+func CreateFile(path string, drs common.DryRunStrategy) (*FileWriter, error) {
+	if path == "" {
+		return nil, nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("error creating record file: %v", err)
+	}
+	return &FileWriter{
+		f:  f,
+		rw: NewWriter(f, drs),
+	}, nil
+}
+
+// Tee records all events from the provided channel. See Writer.Tee.
+func (fw *FileWriter) Tee(ch <-chan event.Event) <-chan event.Event {
+	if fw == nil {
+		return ch
+	}
+	return fw.rw.Tee(ch)
+}
+
+// Err returns the first error encountered while writing to the file,
+// if any.
+func (fw *FileWriter) Err() error {
+	if fw == nil {
+		return nil
+	}
+	if err := fw.rw.Err(); err != nil {
+		return fmt.Errorf("error writing record file: %v", err)
+	}
+	return nil
+}
+
+// Close closes the file.
+func (fw *FileWriter) Close() error {
+	if fw == nil {
+		return nil
+	}
+	return fw.f.Close()
+}
+
+// Recording is a recording read back from a file.
+type Recording struct {
+	Header  Header
+	Records []Record
+}
+
+// Read reads a recording from the provided reader.
+func Read(r io.Reader) (*Recording, error) {
+	scanner := bufio.NewScanner(r)
+	// Events can contain full resources, so allow long lines.
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	rec := &Recording{}
+	line := 0
+	for scanner.Scan() {
+		line++
+		b := scanner.Bytes()
+		if len(b) == 0 {
+			continue
+		}
+		if line == 1 {
+			if err := json.Unmarshal(b, &rec.Header); err != nil {
+				return nil, fmt.Errorf("error reading header: %v", err)
+			}
+			if rec.Header.Kind != Kind {
+				return nil, fmt.Errorf("not a recording: expected kind %s, but found %q",
+					Kind, rec.Header.Kind)
+			}
+			if rec.Header.APIVersion != APIVersion {
+				return nil, fmt.Errorf("unsupported recording version %q", rec.Header.APIVersion)
+			}
+			continue
+		}
+		var r Record
+		if err := json.Unmarshal(b, &r); err != nil {
+			return nil, fmt.Errorf("error reading record on line %d: %v", line, err)
+		}
+		rec.Records = append(rec.Records, r)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if line == 0 {
+		return nil, fmt.Errorf("recording is empty")
+	}
+	return rec, nil
+}
+
+// ReadFile reads a recording from the file with the given path.
+func ReadFile(path string) (*Recording, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// DryRunStrategy returns the dry-run strategy the events were
+// produced with.
+func (rec *Recording) DryRunStrategy() (common.DryRunStrategy, error) {
+	return ParseDryRunStrategy(rec.Header.DryRunStrategy)
+}
+
+// Events converts all records into events.
+func (rec *Recording) Events() ([]event.Event, error) {
+	var events []event.Event
+	for i, r := range rec.Records {
+		e, err := r.Event()
+		if err != nil {
+			return nil, fmt.Errorf("error converting record %d: %v", i+1, err)
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+// Replay returns a channel that will receive all events in the
+// recording. The delay between the events is the same as when the
+// recording was made, divided by speed. If speed is 0 or less, the
+// events are sent without any delay. The channel is closed once all
+// events have been sent.
+func (rec *Recording) Replay(speed float64) (<-chan event.Event, error) {
+	events, err := rec.Events()
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan event.Event)
+	go func() {
+		defer close(ch)
+		for i, e := range events {
+			if i > 0 && speed > 0 {
+				delay := rec.Records[i].Timestamp.Sub(rec.Records[i-1].Timestamp)
+				if delay > 0 {
+					sleep(time.Duration(float64(delay) / speed))
+				}
+			}
+			ch <- e
+		}
+	}()
+	return ch, nil
+}
@@ -0,0 +1,193 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package record
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/common"
+)
+
+func TestWriteAndReplay(t *testing.T) {
+	events := []event.Event{
+		{
+			Type: event.ApplyType,
+			ApplyEvent: event.ApplyEvent{
+				Type:       event.ApplyEventResourceUpdate,
+				Operation:  event.Created,
+				Identifier: depID,
+			},
+		},
+		{
+			Type: event.ApplyType,
+			ApplyEvent: event.ApplyEvent{
+				Type: event.ApplyEventCompleted,
+			},
+		},
+		{
+			Type: event.PruneType,
+			PruneEvent: event.PruneEvent{
+				Type: event.PruneEventCompleted,
+			},
+		},
+	}
+
+	testCases := map[string]struct {
+		speed          float64
+		expectedDelays []time.Duration
+	}{
+		"original speed": {
+			speed:          1,
+			expectedDelays: []time.Duration{2 * time.Second, 4 * time.Second},
+		},
+		"accelerated": {
+			speed:          2,
+			expectedDelays: []time.Duration{time.Second, 2 * time.Second},
+		},
+		"no delay": {
+			speed: 0,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+			timestamps := []time.Time{start, start.Add(2 * time.Second), start.Add(6 * time.Second)}
+			defer func() {
+				now = time.Now
+				sleep = time.Sleep
+			}()
+			now = func() time.Time {
+				ts := timestamps[0]
+				timestamps = timestamps[1:]
+				return ts
+			}
+			var delays []time.Duration
+			sleep = func(d time.Duration) {
+				delays = append(delays, d)
+			}
+
+			var buf bytes.Buffer
+			w := NewWriter(&buf, common.DryRunServer)
+			in := make(chan event.Event)
+			out := w.Tee(in)
+			go func() {
+				defer close(in)
+				for _, e := range events {
+					in <- e
+				}
+			}()
+			var forwarded []event.Event
+			for e := range out {
+				forwarded = append(forwarded, e)
+			}
+			assert.NoError(t, w.Err())
+			assert.Equal(t, events, forwarded)
+			assert.Equal(t, 4, strings.Count(buf.String(), "\n"))
+
+			rec, err := Read(&buf)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			drs, err := rec.DryRunStrategy()
+			assert.NoError(t, err)
+			assert.Equal(t, common.DryRunServer, drs)
+
+			ch, err := rec.Replay(tc.speed)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			var replayed []event.Event
+			for e := range ch {
+				replayed = append(replayed, e)
+			}
+			assert.Equal(t, events, replayed)
+			assert.Equal(t, tc.expectedDelays, delays)
+		})
+	}
+}
+
+func TestRead_Errors(t *testing.T) {
+	testCases := map[string]struct {
+		content     string
+		expectedErr string
+	}{
+		"empty": {
+			content:     "",
+			expectedErr: "recording is empty",
+		},
+		"wrong kind": {
+			content:     `{"apiVersion":"v1","kind":"ConfigMap"}`,
+			expectedErr: `not a recording: expected kind EventStream, but found "ConfigMap"`,
+		},
+		"unsupported version": {
+			content:     `{"apiVersion":"cli-utils.sigs.k8s.io/v2","kind":"EventStream"}`,
+			expectedErr: `unsupported recording version "cli-utils.sigs.k8s.io/v2"`,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			_, err := Read(strings.NewReader(tc.content))
+			if assert.Error(t, err) {
+				assert.Equal(t, tc.expectedErr, err.Error())
+			}
+		})
+	}
+}
+
+func TestCreateFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "record-test")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+
+	// Without a path, nothing is recorded and events pass through.
+	fw, err := CreateFile("", common.DryRunNone)
+	assert.NoError(t, err)
+	assert.Nil(t, fw)
+	ch := make(chan event.Event)
+	assert.Equal(t, (<-chan event.Event)(ch), fw.Tee(ch))
+	assert.NoError(t, fw.Err())
+	assert.NoError(t, fw.Close())
+
+	_, err = CreateFile(filepath.Join(dir, "missing", "record.json"), common.DryRunNone)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "error creating record file")
+	}
+
+	path := filepath.Join(dir, "record.json")
+	fw, err = CreateFile(path, common.DryRunClient)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	in := make(chan event.Event)
+	out := fw.Tee(in)
+	go func() {
+		in <- event.Event{Type: event.InitType}
+		close(in)
+	}()
+	for range out {
+	}
+	assert.NoError(t, fw.Err())
+	assert.NoError(t, fw.Close())
+
+	rec, err := ReadFile(path)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	drs, err := rec.DryRunStrategy()
+	assert.NoError(t, err)
+	assert.Equal(t, common.DryRunClient, drs)
+	assert.Len(t, rec.Records, 1)
+}
@@ -0,0 +1,168 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package retry provides the policy used by the applier and the pruner
+// to retry operations that fail with transient errors, like conflicts,
+// throttling, webhook timeouts and etcd leader changes.
+package retry
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+const (
+	// DefaultInitialBackoff is used if the InitialBackoff of the
+	// policy is not set.
+	DefaultInitialBackoff = time.Second
+	// DefaultMaxBackoff is used if the MaxBackoff of the policy is
+	// not set.
+	DefaultMaxBackoff = 30 * time.Second
+)
+
+// Policy defines how an operation that fails with a retryable error
+// is retried. The zero value makes a single attempt.
+type Policy struct {
+	// MaxAttempts is the maximum number of attempts, including the
+	// first one. Operations are not retried if it is 1 or less.
+	MaxAttempts int
+
+	// InitialBackoff is how long to wait after the first failed
+	// attempt. The backoff is doubled after every failed attempt.
+	InitialBackoff time.Duration
+
+	// MaxBackoff is the upper limit for the backoff.
+	MaxBackoff time.Duration
+
+	// Retryable decides whether an error is retryable. IsRetryable
+	// is used if it is not set.
+	Retryable func(err error) bool
+}
+
+// Attempt contains information about a failed attempt that will be
+// retried.
+type Attempt struct {
+	// Number is the number of the attempt that failed, starting at 1.
+	Number int
+	// MaxAttempts is the maximum number of attempts.
+	MaxAttempts int
+	// Delay is how long to wait before the next attempt.
+	Delay time.Duration
+	// Err is the error from the failed attempt.
+	Err error
+}
+
+// wait waits for the given duration, or until the context is done.
+// It returns false if the context is done first. Used to allow unit
+// testing.
+var wait = func(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// Do calls fn until it succeeds, fails with an error that isn't
+// retryable, the maximum number of attempts has been made, or the
+// context is done while waiting for the next attempt. The onRetry
+// function is called with information about the failed attempt
+// before every retry. It returns the error from the last attempt.
+func (p Policy) Do(ctx context.Context, fn func() error, onRetry func(Attempt)) error {
+	retryable := p.Retryable
+	if retryable == nil {
+		retryable = IsRetryable
+	}
+	delay := p.InitialBackoff
+	if delay <= 0 {
+		delay = DefaultInitialBackoff
+	}
+	maxDelay := p.MaxBackoff
+	if maxDelay <= 0 {
+		maxDelay = DefaultMaxBackoff
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= p.MaxAttempts || !retryable(err) {
+			return err
+		}
+		if delay > maxDelay {
+			delay = maxDelay
+		}
+		if onRetry != nil {
+			onRetry(Attempt{
+				Number:      attempt,
+				MaxAttempts: p.MaxAttempts,
+				Delay:       delay,
+				Err:         err,
+			})
+		}
+		if !wait(ctx, delay) {
+			return err
+		}
+		delay *= 2
+	}
+}
+
+// transientMessages are parts of error messages from the API server
+// for errors that are usually gone when the request is made again.
+// They are returned as internal errors, so they can't be identified
+// by the reason alone.
+var transientMessages = []string{
+	"etcdserver: leader changed",
+	"etcdserver: request timed out",
+	"etcdserver: too many requests",
+	"failed calling webhook",
+}
+
+// IsRetryable returns true if the error is one that is likely to go
+// away if the request is made again. These are conflicts, throttling,
+// timeouts, unavailable servers and internal errors caused by webhook
+// timeouts or etcd leader changes. An aggregate error is retryable if
+// all the errors in it are retryable.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if agg, ok := err.(utilerrors.Aggregate); ok {
+		errs := agg.Errors()
+		if len(errs) == 0 {
+			return false
+		}
+		for _, e := range errs {
+			if !IsRetryable(e) {
+				return false
+			}
+		}
+		return true
+	}
+	switch {
+	case apierrors.IsConflict(err),
+		apierrors.IsTooManyRequests(err),
+		apierrors.IsServerTimeout(err),
+		apierrors.IsTimeout(err),
+		apierrors.IsServiceUnavailable(err):
+		return true
+	case apierrors.IsInternalError(err):
+		return hasTransientMessage(err)
+	}
+	return false
+}
+
+func hasTransientMessage(err error) bool {
+	msg := err.Error()
+	for _, m := range transientMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
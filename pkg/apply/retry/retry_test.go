@@ -0,0 +1,205 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package retry
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+var deploymentsResource = schema.GroupResource{Group: "apps", Resource: "deployments"}
+
+func TestIsRetryable(t *testing.T) {
+	testCases := map[string]struct {
+		err      error
+		expected bool
+	}{
+		"nil": {
+			err:      nil,
+			expected: false,
+		},
+		"conflict": {
+			err:      apierrors.NewConflict(deploymentsResource, "foo", fmt.Errorf("modified")),
+			expected: true,
+		},
+		"too many requests": {
+			err:      apierrors.NewTooManyRequests("slow down", 1),
+			expected: true,
+		},
+		"server timeout": {
+			err:      apierrors.NewServerTimeout(deploymentsResource, "patch", 1),
+			expected: true,
+		},
+		"timeout": {
+			err:      apierrors.NewTimeoutError("timed out", 1),
+			expected: true,
+		},
+		"service unavailable": {
+			err:      apierrors.NewServiceUnavailable("unavailable"),
+			expected: true,
+		},
+		"webhook timeout": {
+			err: apierrors.NewInternalError(fmt.Errorf(
+				`failed calling webhook "validate.example.com": context deadline exceeded`)),
+			expected: true,
+		},
+		"etcd leader changed": {
+			err:      apierrors.NewInternalError(fmt.Errorf("etcdserver: leader changed")),
+			expected: true,
+		},
+		"other internal error": {
+			err:      apierrors.NewInternalError(fmt.Errorf("something broke")),
+			expected: false,
+		},
+		"invalid": {
+			err:      apierrors.NewBadRequest("invalid"),
+			expected: false,
+		},
+		"not an api error": {
+			err:      fmt.Errorf("conflict"),
+			expected: false,
+		},
+		"aggregate of retryable errors": {
+			err: utilerrors.NewAggregate([]error{
+				apierrors.NewTooManyRequests("slow down", 1),
+				apierrors.NewServiceUnavailable("unavailable"),
+			}),
+			expected: true,
+		},
+		"aggregate with non-retryable error": {
+			err: utilerrors.NewAggregate([]error{
+				apierrors.NewTooManyRequests("slow down", 1),
+				apierrors.NewBadRequest("invalid"),
+			}),
+			expected: false,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			assert.Equal(t, tc.expected, IsRetryable(tc.err))
+		})
+	}
+}
+
+func TestPolicyDo(t *testing.T) {
+	retryable := apierrors.NewTooManyRequests("slow down", 1)
+	permanent := apierrors.NewBadRequest("invalid")
+
+	testCases := map[string]struct {
+		policy           Policy
+		errs             []error
+		expectedErr      error
+		expectedAttempts int
+		expectedDelays   []time.Duration
+	}{
+		"success on first attempt": {
+			policy:           Policy{MaxAttempts: 3},
+			errs:             []error{nil},
+			expectedErr:      nil,
+			expectedAttempts: 1,
+		},
+		"no retries with zero policy": {
+			policy:           Policy{},
+			errs:             []error{retryable},
+			expectedErr:      retryable,
+			expectedAttempts: 1,
+		},
+		"success after retries": {
+			policy:           Policy{MaxAttempts: 5, InitialBackoff: time.Second, MaxBackoff: 3 * time.Second},
+			errs:             []error{retryable, retryable, retryable, nil},
+			expectedErr:      nil,
+			expectedAttempts: 4,
+			expectedDelays:   []time.Duration{time.Second, 2 * time.Second, 3 * time.Second},
+		},
+		"gives up after max attempts": {
+			policy:           Policy{MaxAttempts: 2},
+			errs:             []error{retryable, retryable},
+			expectedErr:      retryable,
+			expectedAttempts: 2,
+			expectedDelays:   []time.Duration{DefaultInitialBackoff},
+		},
+		"permanent error is not retried": {
+			policy:           Policy{MaxAttempts: 5},
+			errs:             []error{permanent},
+			expectedErr:      permanent,
+			expectedAttempts: 1,
+		},
+		"custom classifier": {
+			policy: Policy{
+				MaxAttempts: 3,
+				Retryable:   func(err error) bool { return err == permanent },
+			},
+			errs:             []error{permanent, nil},
+			expectedErr:      nil,
+			expectedAttempts: 2,
+			expectedDelays:   []time.Duration{DefaultInitialBackoff},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			var slept []time.Duration
+			defaultWait := wait
+			wait = func(_ context.Context, d time.Duration) bool {
+				slept = append(slept, d)
+				return true
+			}
+			defer func() { wait = defaultWait }()
+
+			attempts := 0
+			var retries []Attempt
+			err := tc.policy.Do(context.Background(), func() error {
+				err := tc.errs[attempts]
+				attempts++
+				return err
+			}, func(a Attempt) {
+				retries = append(retries, a)
+			})
+
+			assert.Equal(t, tc.expectedErr, err)
+			assert.Equal(t, tc.expectedAttempts, attempts)
+			assert.Equal(t, tc.expectedDelays, slept)
+			if assert.Len(t, retries, len(tc.expectedDelays)) {
+				for i, r := range retries {
+					assert.Equal(t, i+1, r.Number)
+					assert.Equal(t, tc.policy.MaxAttempts, r.MaxAttempts)
+					assert.Equal(t, tc.expectedDelays[i], r.Delay)
+					assert.Equal(t, tc.errs[i], r.Err)
+				}
+			}
+		})
+	}
+}
+
+func TestPolicyDo_ContextCancelled(t *testing.T) {
+	retryable := apierrors.NewTooManyRequests("slow down", 1)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	attempts := 0
+	done := make(chan error)
+	go func() {
+		done <- Policy{MaxAttempts: 5, InitialBackoff: time.Hour}.Do(ctx, func() error {
+			attempts++
+			return retryable
+		}, func(Attempt) {
+			cancel()
+		})
+	}()
+
+	select {
+	case err := <-done:
+		assert.Equal(t, retryable, err)
+		assert.Equal(t, 1, attempts)
+	case <-time.After(10 * time.Second):
+		t.Fatal("retry did not stop when the context was cancelled")
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package rollback contains the functionality for restoring the
+// cluster to the state it was in before a failed atomic apply.
+// The state of every object is recorded in a Snapshot just before it
+// is applied, and Rollback uses the Snapshot to restore the objects
+// that were changed and delete the objects that were created.
+package rollback
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// Snapshot contains the state of objects in the cluster from before
+// they were applied.
+type Snapshot struct {
+	// ids contains the identifiers in the order the objects were
+	// recorded.
+	ids []object.ObjMetadata
+	// objects contains the object from the cluster for each identifier,
+	// or nil if the object didn't exist.
+	objects map[object.ObjMetadata]*unstructured.Unstructured
+	// sealed is true once the changes can no longer be rolled back.
+	sealed bool
+}
+
+// NewSnapshot returns a new empty Snapshot.
+func NewSnapshot() *Snapshot {
+	return &Snapshot{
+		objects: make(map[object.ObjMetadata]*unstructured.Unstructured),
+	}
+}
+
+// Record adds the state of the object in the cluster to the snapshot.
+// The obj parameter is nil if the object didn't exist. Only the first
+// recorded state is kept for each object, so the snapshot contains the
+// state from before any changes.
+func (s *Snapshot) Record(id object.ObjMetadata, obj *unstructured.Unstructured) {
+	if _, found := s.objects[id]; found {
+		return
+	}
+	s.ids = append(s.ids, id)
+	if obj != nil {
+		obj = obj.DeepCopy()
+	}
+	s.objects[id] = obj
+}
+
+// Seal marks the end of the changes that can be rolled back. It is
+// called when pruning starts, since pruned objects are not recorded
+// and can't be restored.
+func (s *Snapshot) Seal() {
+	s.sealed = true
+}
+
+// Sealed returns true if the changes can no longer be rolled back.
+func (s *Snapshot) Sealed() bool {
+	return s.sealed
+}
+
+// Ids returns the identifiers of the objects in the snapshot, in the
+// order they were recorded.
+func (s *Snapshot) Ids() []object.ObjMetadata {
+	return s.ids
+}
+
+// Rollback restores the objects in the snapshot, in the reverse order
+// of how they were recorded. Objects that didn't exist are deleted,
+// and objects that have changed are updated to the recorded state.
+// Objects that haven't changed are left alone. An event is sent for
+// each object that is restored or deleted, or that can't be. Rollback
+// doesn't stop when an object fails, and returns the number of
+// failures.
+func Rollback(snapshot *Snapshot, client dynamic.Interface, mapper meta.RESTMapper,
+	eventChannel chan<- event.Event) int {
+	var failed int
+	for i := len(snapshot.ids) - 1; i >= 0; i-- {
+		id := snapshot.ids[i]
+		obj := snapshot.objects[id]
+		op, changed, err := rollbackObject(id, obj, client, mapper)
+		if err != nil {
+			klog.V(4).Infof("rollback failed for %s/%s: %v", id.Namespace, id.Name, err)
+			eventChannel <- createRollbackEvent(id, obj, op, event.RollbackEventFailed, err)
+			failed++
+			continue
+		}
+		if changed {
+			eventChannel <- createRollbackEvent(id, obj, op, event.RollbackEventResourceUpdate, nil)
+		}
+	}
+	return failed
+}
+
+// rollbackObject restores a single object. It returns the operation
+// for the object, and whether anything was changed.
+func rollbackObject(id object.ObjMetadata, obj *unstructured.Unstructured, client dynamic.Interface,
+	mapper meta.RESTMapper) (event.RollbackEventOperation, bool, error) {
+	op := event.Restored
+	if obj == nil {
+		op = event.RollbackDeleted
+	}
+	mapping, err := mapper.RESTMapping(id.GroupKind)
+	if err != nil {
+		return op, false, err
+	}
+	namespacedClient := client.Resource(mapping.Resource).Namespace(id.Namespace)
+
+	if obj == nil {
+		klog.V(4).Infof("rollback deleting %s/%s", id.Namespace, id.Name)
+		background := metav1.DeletePropagationBackground
+		err := namespacedClient.Delete(context.TODO(), id.Name, metav1.DeleteOptions{
+			PropagationPolicy: &background,
+		})
+		if apierrors.IsNotFound(err) {
+			return op, false, nil
+		}
+		return op, err == nil, err
+	}
+
+	current, err := namespacedClient.Get(context.TODO(), id.Name, metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return op, false, err
+	}
+	restored := obj.DeepCopy()
+	unstructured.RemoveNestedField(restored.Object, "status")
+	if apierrors.IsNotFound(err) {
+		// The object has been deleted, for example to be recreated,
+		// so it is created again from the snapshot.
+		klog.V(4).Infof("rollback creating %s/%s", id.Namespace, id.Name)
+		clearServerFields(restored)
+		_, err = namespacedClient.Create(context.TODO(), restored, metav1.CreateOptions{})
+		return op, err == nil, err
+	}
+	if current.GetResourceVersion() == obj.GetResourceVersion() {
+		return op, false, nil
+	}
+	klog.V(4).Infof("rollback updating %s/%s", id.Namespace, id.Name)
+	restored.SetResourceVersion(current.GetResourceVersion())
+	_, err = namespacedClient.Update(context.TODO(), restored, metav1.UpdateOptions{})
+	return op, err == nil, err
+}
+
+// clearServerFields removes the metadata fields set by the server,
+// so the object can be created again.
+func clearServerFields(u *unstructured.Unstructured) {
+	u.SetResourceVersion("")
+	u.SetUID("")
+	u.SetSelfLink("")
+	u.SetGeneration(0)
+	u.SetCreationTimestamp(metav1.Time{})
+	u.SetManagedFields(nil)
+}
+
+func createRollbackEvent(id object.ObjMetadata, obj *unstructured.Unstructured, op event.RollbackEventOperation,
+	eventType event.RollbackEventType, err error) event.Event {
+	return event.Event{
+		Type: event.RollbackType,
+		RollbackEvent: event.RollbackEvent{
+			Type:       eventType,
+			Operation:  op,
+			Identifier: id,
+			Object:     obj,
+			Error:      err,
+		},
+	}
+}
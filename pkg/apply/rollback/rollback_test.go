@@ -0,0 +1,137 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package rollback
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	"k8s.io/kubectl/pkg/scheme"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+var configMapsResource = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+func newConfigMap(name, resourceVersion, value string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name":            name,
+				"namespace":       "default",
+				"resourceVersion": resourceVersion,
+			},
+			"data": map[string]interface{}{
+				"key": value,
+			},
+		},
+	}
+}
+
+func TestRollback(t *testing.T) {
+	testCases := map[string]struct {
+		// snapshotObj is the object recorded in the snapshot, or nil
+		// if it didn't exist before the apply.
+		snapshotObj *unstructured.Unstructured
+		// clusterObj is the object in the cluster at the time of the
+		// rollback, or nil if it doesn't exist.
+		clusterObj *unstructured.Unstructured
+		// expectedObj is the object expected in the cluster after the
+		// rollback, or nil if it should not exist.
+		expectedObj        *unstructured.Unstructured
+		expectedEventTypes []event.RollbackEventType
+		expectedOperation  event.RollbackEventOperation
+	}{
+		"created object is deleted": {
+			clusterObj:         newConfigMap("cm", "2", "new"),
+			expectedEventTypes: []event.RollbackEventType{event.RollbackEventResourceUpdate},
+			expectedOperation:  event.RollbackDeleted,
+		},
+		"created object that is already gone is skipped": {},
+		"modified object is restored": {
+			snapshotObj:        newConfigMap("cm", "1", "old"),
+			clusterObj:         newConfigMap("cm", "2", "new"),
+			expectedObj:        newConfigMap("cm", "2", "old"),
+			expectedEventTypes: []event.RollbackEventType{event.RollbackEventResourceUpdate},
+			expectedOperation:  event.Restored,
+		},
+		"unchanged object is skipped": {
+			snapshotObj: newConfigMap("cm", "1", "old"),
+			clusterObj:  newConfigMap("cm", "1", "old"),
+			expectedObj: newConfigMap("cm", "1", "old"),
+		},
+		"deleted object is created again": {
+			snapshotObj:        newConfigMap("cm", "1", "old"),
+			expectedObj:        newConfigMap("cm", "", "old"),
+			expectedEventTypes: []event.RollbackEventType{event.RollbackEventResourceUpdate},
+			expectedOperation:  event.Restored,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			var objs []runtime.Object
+			if tc.clusterObj != nil {
+				objs = append(objs, tc.clusterObj)
+			}
+			client := fake.NewSimpleDynamicClient(scheme.Scheme, objs...)
+			mapper := testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme,
+				scheme.Scheme.PrioritizedVersionsAllGroups()...)
+
+			id := object.UnstructuredToObjMeta(newConfigMap("cm", "", ""))
+			snapshot := NewSnapshot()
+			snapshot.Record(id, tc.snapshotObj)
+			// Only the first recorded state is kept.
+			snapshot.Record(id, newConfigMap("cm", "3", "later"))
+
+			eventChannel := make(chan event.Event, 10)
+			failed := Rollback(snapshot, client, mapper, eventChannel)
+			close(eventChannel)
+
+			assert.Equal(t, 0, failed)
+			var events []event.Event
+			for e := range eventChannel {
+				events = append(events, e)
+			}
+			if assert.Equal(t, len(tc.expectedEventTypes), len(events)) {
+				for i, e := range events {
+					assert.Equal(t, event.RollbackType, e.Type)
+					assert.Equal(t, tc.expectedEventTypes[i], e.RollbackEvent.Type)
+					assert.Equal(t, tc.expectedOperation, e.RollbackEvent.Operation)
+					assert.Equal(t, id, e.RollbackEvent.Identifier)
+				}
+			}
+
+			actual, err := client.Resource(configMapsResource).Namespace("default").
+				Get(context.TODO(), "cm", metav1.GetOptions{})
+			if tc.expectedObj == nil {
+				assert.True(t, apierrors.IsNotFound(err))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedObj.Object["data"], actual.Object["data"])
+		})
+	}
+}
+
+func TestSnapshotSeal(t *testing.T) {
+	snapshot := NewSnapshot()
+	id := object.UnstructuredToObjMeta(newConfigMap("foo", "1", "a"))
+	snapshot.Record(id, nil)
+	assert.False(t, snapshot.Sealed())
+
+	snapshot.Seal()
+	assert.True(t, snapshot.Sealed())
+	assert.Equal(t, []object.ObjMetadata{id}, snapshot.Ids())
+}
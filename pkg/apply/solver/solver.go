@@ -17,16 +17,20 @@ package solver
 import (
 	"time"
 
-	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
-	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog"
 	"k8s.io/kubectl/pkg/cmd/util"
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/hook"
 	"sigs.k8s.io/cli-utils/pkg/apply/info"
 	"sigs.k8s.io/cli-utils/pkg/apply/prune"
+	"sigs.k8s.io/cli-utils/pkg/apply/retry"
+	"sigs.k8s.io/cli-utils/pkg/apply/rollback"
+	"sigs.k8s.io/cli-utils/pkg/apply/stage"
 	"sigs.k8s.io/cli-utils/pkg/apply/task"
 	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
 	"sigs.k8s.io/cli-utils/pkg/common"
@@ -34,6 +38,8 @@ import (
 	"sigs.k8s.io/cli-utils/pkg/object"
 )
 
+var jobGroupKind = schema.GroupKind{Group: "batch", Kind: "Job"}
+
 type TaskQueueSolver struct {
 	PruneOptions *prune.PruneOptions
 	InfoHelper   info.InfoHelper
@@ -49,6 +55,12 @@ type Options struct {
 	PrunePropagationPolicy metav1.DeletionPropagation
 	PruneTimeout           time.Duration
 	InventoryPolicy        inventory.InventoryPolicy
+	RetryPolicy            retry.Policy
+	RecreateOptions        common.RecreateOptions
+	HookTimeout            time.Duration
+	Snapshot               *rollback.Snapshot
+	Stages                 []stage.Stage
+	Filter                 object.Filter
 }
 
 type resourceObjects interface {
@@ -57,6 +69,7 @@ type resourceObjects interface {
 	IdsForApply() []object.ObjMetadata
 	IdsForPrune() []object.ObjMetadata
 	IdsForPrevInv() []object.ObjMetadata
+	IdsForUnselected() []object.ObjMetadata
 }
 
 // BuildTaskQueue takes a set of resources in the form of info objects
@@ -65,7 +78,10 @@ type resourceObjects interface {
 func (t *TaskQueueSolver) BuildTaskQueue(ro resourceObjects,
 	o Options) chan taskrunner.Task {
 	var tasks []taskrunner.Task
-	remainingInfos := ro.ObjsForApply()
+	// Hooks are applied in their own phases, before and after the
+	// other resources.
+	preHooks, remainingInfos, postHooks := hook.Split(ro.ObjsForApply())
+	regularObjs := remainingInfos
 	// Convert slice of previous inventory objects into a map.
 	prevInvSlice := ro.IdsForPrevInv()
 	prevInventory := make(map[object.ObjMetadata]bool, len(prevInvSlice))
@@ -73,46 +89,19 @@ func (t *TaskQueueSolver) BuildTaskQueue(ro resourceObjects,
 		prevInventory[prevInvObj] = true
 	}
 
-	crdSplitRes, hasCRDs := splitAfterCRDs(remainingInfos)
-	if hasCRDs {
-		tasks = append(tasks, &task.ApplyTask{
-			Objects:           append(crdSplitRes.before, crdSplitRes.crds...),
-			CRDs:              crdSplitRes.crds,
-			PrevInventory:     prevInventory,
-			ServerSideOptions: o.ServerSideOptions,
-			DryRunStrategy:    o.DryRunStrategy,
-			InfoHelper:        t.InfoHelper,
-			Factory:           t.Factory,
-			Mapper:            t.Mapper,
-			InventoryPolicy:   o.InventoryPolicy,
-			InvInfo:           ro.Inventory(),
-		})
-		if !o.DryRunStrategy.ClientOrServerDryRun() {
-			objs := object.UnstructuredsToObjMetas(crdSplitRes.crds)
-			tasks = append(tasks, taskrunner.NewWaitTask(
-				objs,
-				taskrunner.AllCurrent,
-				1*time.Minute),
-				&task.ResetRESTMapperTask{
-					Mapper: t.Mapper,
-				})
+	tasks = append(tasks, t.buildHookTasks(ro, preHooks, prevInventory, o)...)
+
+	// The resources selected by a stage are applied one stage at a
+	// time, before the resources that are not selected by any stage.
+	if groups := stage.Split(remainingInfos, o.Stages); len(groups) > 0 {
+		for _, g := range groups[:len(groups)-1] {
+			tasks = append(tasks, t.buildStageTasks(ro, g, prevInventory, o)...)
 		}
-		remainingInfos = crdSplitRes.after
+		remainingInfos = groups[len(groups)-1].Objects
 	}
 
+	tasks = append(tasks, t.buildApplyTasks(ro, remainingInfos, prevInventory, o)...)
 	tasks = append(tasks,
-		&task.ApplyTask{
-			Objects:           remainingInfos,
-			CRDs:              crdSplitRes.crds,
-			PrevInventory:     prevInventory,
-			ServerSideOptions: o.ServerSideOptions,
-			DryRunStrategy:    o.DryRunStrategy,
-			InfoHelper:        t.InfoHelper,
-			Factory:           t.Factory,
-			Mapper:            t.Mapper,
-			InventoryPolicy:   o.InventoryPolicy,
-			InvInfo:           ro.Inventory(),
-		},
 		&task.SendEventTask{
 			Event: event.Event{
 				Type: event.ApplyType,
@@ -125,9 +114,9 @@ func (t *TaskQueueSolver) BuildTaskQueue(ro resourceObjects,
 
 	if !o.DryRunStrategy.ClientOrServerDryRun() && o.ReconcileTimeout != time.Duration(0) {
 		tasks = append(tasks,
-			taskrunner.NewWaitTask(
-				ro.IdsForApply(),
-				taskrunner.AllCurrent,
+			newApplyWaitTask(
+				object.UnstructuredsToObjMetas(regularObjs),
+				regularObjs,
 				o.ReconcileTimeout),
 			&task.SendEventTask{
 				Event: event.Event{
@@ -140,6 +129,8 @@ func (t *TaskQueueSolver) BuildTaskQueue(ro resourceObjects,
 		)
 	}
 
+	tasks = append(tasks, t.buildHookTasks(ro, postHooks, prevInventory, o)...)
+
 	if o.Prune {
 		tasks = append(tasks,
 			&task.PruneTask{
@@ -149,6 +140,10 @@ func (t *TaskQueueSolver) BuildTaskQueue(ro resourceObjects,
 				PropagationPolicy: o.PrunePropagationPolicy,
 				DryRunStrategy:    o.DryRunStrategy,
 				InventoryPolicy:   o.InventoryPolicy,
+				RetryPolicy:       o.RetryPolicy,
+				Filter:            o.Filter,
+				UnselectedIds:     ro.IdsForUnselected(),
+				Snapshot:          o.Snapshot,
 			},
 			&task.SendEventTask{
 				Event: event.Event{
@@ -181,6 +176,162 @@ func (t *TaskQueueSolver) BuildTaskQueue(ro resourceObjects,
 	return tasksToQueue(tasks)
 }
 
+// buildApplyTasks creates the tasks for applying the resources. If
+// there are any CRDs among the resources, they are applied together
+// with the resources before them, and waited on until they have been
+// established, before the rest of the resources are applied.
+func (t *TaskQueueSolver) buildApplyTasks(ro resourceObjects, objs []*unstructured.Unstructured,
+	prevInventory map[object.ObjMetadata]bool, o Options) []taskrunner.Task {
+	var tasks []taskrunner.Task
+	crdSplitRes, hasCRDs := splitAfterCRDs(objs)
+	if hasCRDs {
+		tasks = append(tasks, t.newApplyTask(ro, append(crdSplitRes.before, crdSplitRes.crds...),
+			crdSplitRes.crds, prevInventory, o))
+		if !o.DryRunStrategy.ClientOrServerDryRun() {
+			ids := object.UnstructuredsToObjMetas(crdSplitRes.crds)
+			tasks = append(tasks, newApplyWaitTask(
+				ids,
+				crdSplitRes.crds,
+				1*time.Minute),
+				&task.ResetRESTMapperTask{
+					Mapper: t.Mapper,
+				})
+		}
+		objs = crdSplitRes.after
+	}
+	return append(tasks, t.newApplyTask(ro, objs, crdSplitRes.crds, prevInventory, o))
+}
+
+// buildStageTasks creates the tasks for a stage. The resources in the
+// stage are applied and waited on until they are Current, followed by
+// the soak if the stage has one. A resource that fails makes the whole
+// task queue fail, so the following stages are not applied. For
+// dry-run, there is nothing to wait for.
+func (t *TaskQueueSolver) buildStageTasks(ro resourceObjects, g stage.Group,
+	prevInventory map[object.ObjMetadata]bool, o Options) []taskrunner.Task {
+	if len(g.Objects) == 0 {
+		return nil
+	}
+	tasks := t.buildApplyTasks(ro, g.Objects, prevInventory, o)
+	if o.DryRunStrategy.ClientOrServerDryRun() {
+		return tasks
+	}
+	timeout := g.Stage.Timeout.Duration
+	if timeout == 0 {
+		timeout = o.ReconcileTimeout
+	}
+	if timeout == 0 {
+		timeout = stage.DefaultTimeout
+	}
+	waitTask := newApplyWaitTask(g.Ids(), g.Objects, timeout)
+	waitTask.AbortOnFailure = true
+	tasks = append(tasks, waitTask)
+	if g.Stage.Soak.Duration > 0 {
+		tasks = append(tasks, taskrunner.NewSoakTask(g.Ids(), g.Stage.Soak.Duration))
+	}
+	return tasks
+}
+
+func (t *TaskQueueSolver) newApplyTask(ro resourceObjects, objs, crds []*unstructured.Unstructured,
+	prevInventory map[object.ObjMetadata]bool, o Options) *task.ApplyTask {
+	return &task.ApplyTask{
+		Objects:           objs,
+		CRDs:              crds,
+		PrevInventory:     prevInventory,
+		ServerSideOptions: o.ServerSideOptions,
+		DryRunStrategy:    o.DryRunStrategy,
+		InfoHelper:        t.InfoHelper,
+		Factory:           t.Factory,
+		Mapper:            t.Mapper,
+		InventoryPolicy:   o.InventoryPolicy,
+		InvInfo:           ro.Inventory(),
+		RetryPolicy:       o.RetryPolicy,
+		RecreateOptions:   o.RecreateOptions,
+		Snapshot:          o.Snapshot,
+	}
+}
+
+// buildHookTasks creates the tasks for a phase of hooks. The hooks with
+// the before-hook-creation delete policy are deleted first, then all
+// the hooks are applied and waited on until they are Current. A hook
+// that fails makes the whole task queue fail. Finally the hooks with
+// the hook-succeeded delete policy are deleted. For dry-run, there is
+// nothing to wait for.
+func (t *TaskQueueSolver) buildHookTasks(ro resourceObjects, hooks []*unstructured.Unstructured,
+	prevInventory map[object.ObjMetadata]bool, o Options) []taskrunner.Task {
+	if len(hooks) == 0 {
+		return nil
+	}
+	dryRun := o.DryRunStrategy.ClientOrServerDryRun()
+	var tasks []taskrunner.Task
+	if before := hook.WithDeletePolicy(hooks, hook.BeforeHookCreation); len(before) > 0 {
+		tasks = append(tasks, t.newDeleteHookTask(before, o))
+		if !dryRun {
+			tasks = append(tasks, taskrunner.NewWaitTask(
+				object.UnstructuredsToObjMetas(before),
+				taskrunner.AllNotFound,
+				o.HookTimeout))
+		}
+	}
+	tasks = append(tasks, t.newApplyTask(ro, hooks, nil, prevInventory, o))
+	if dryRun {
+		return tasks
+	}
+	waitTask := newApplyWaitTask(object.UnstructuredsToObjMetas(hooks), hooks, o.HookTimeout)
+	waitTask.AbortOnFailure = true
+	// A Job is Current as soon as it has started, so hooks that are
+	// Jobs wait until they have completed, unless they declare a
+	// wait condition.
+	for _, h := range hooks {
+		if h.GroupVersionKind().GroupKind() != jobGroupKind {
+			continue
+		}
+		if _, found := h.GetAnnotations()[common.WaitConditionAnnotation]; found {
+			continue
+		}
+		if waitTask.WaitConditions == nil {
+			waitTask.WaitConditions = make(map[object.ObjMetadata]taskrunner.WaitCondition)
+		}
+		waitTask.WaitConditions[object.UnstructuredToObjMeta(h)] = taskrunner.WaitCondition{
+			Type:            taskrunner.WaitForCondition,
+			ConditionType:   "Complete",
+			ConditionStatus: corev1.ConditionTrue,
+		}
+	}
+	tasks = append(tasks, waitTask)
+	if succeeded := hook.WithDeletePolicy(hooks, hook.HookSucceeded); len(succeeded) > 0 {
+		tasks = append(tasks, t.newDeleteHookTask(succeeded, o))
+	}
+	return tasks
+}
+
+func (t *TaskQueueSolver) newDeleteHookTask(hooks []*unstructured.Unstructured, o Options) *task.DeleteHookTask {
+	return &task.DeleteHookTask{
+		Objects:           hooks,
+		InfoHelper:        t.InfoHelper,
+		Factory:           t.Factory,
+		PropagationPolicy: metav1.DeletePropagationBackground,
+		DryRunStrategy:    o.DryRunStrategy,
+	}
+}
+
+// newApplyWaitTask creates a wait task that waits for the applied
+// resources to become Current, or to meet the wait condition declared
+// on the resources with the wait condition annotation.
+func newApplyWaitTask(ids []object.ObjMetadata, objs []*unstructured.Unstructured,
+	timeout time.Duration) *taskrunner.WaitTask {
+	waitTask := taskrunner.NewWaitTask(ids, taskrunner.AllCurrent, timeout)
+	// The annotations are validated before the task queue is built, so
+	// any errors here can only be logged.
+	waitConditions, err := taskrunner.WaitConditionsForObjects(objs)
+	if err != nil {
+		klog.Warningf("ignoring wait conditions: %v", err)
+		return waitTask
+	}
+	waitTask.WaitConditions = waitConditions
+	return waitTask
+}
+
 func tasksToQueue(tasks []taskrunner.Task) chan taskrunner.Task {
 	taskQueue := make(chan taskrunner.Task, len(tasks))
 	for _, t := range tasks {
@@ -207,7 +358,7 @@ func splitAfterCRDs(objs []*unstructured.Unstructured) (crdSplitResult, bool) {
 
 	var crds []*unstructured.Unstructured
 	for _, obj := range objs {
-		if IsCRD(obj) {
+		if object.IsCRD(obj) {
 			crds = append(crds, obj)
 			continue
 		}
@@ -224,23 +375,3 @@ func splitAfterCRDs(objs []*unstructured.Unstructured) (crdSplitResult, bool) {
 		crds:   crds,
 	}, len(crds) > 0
 }
-
-func IsCRD(info *unstructured.Unstructured) bool {
-	gvk, found := toGVK(info)
-	if !found {
-		return false
-	}
-	if (gvk.Group == v1.SchemeGroupVersion.Group ||
-		gvk.Group == v1beta1.SchemeGroupVersion.Group) &&
-		gvk.Kind == "CustomResourceDefinition" {
-		return true
-	}
-	return false
-}
-
-func toGVK(obj *unstructured.Unstructured) (schema.GroupVersionKind, bool) {
-	if obj != nil {
-		return obj.GroupVersionKind(), true
-	}
-	return schema.GroupVersionKind{}, false
-}
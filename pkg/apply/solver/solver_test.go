@@ -9,9 +9,11 @@ import (
 	"time"
 
 	"gotest.tools/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/cli-runtime/pkg/resource"
 	"sigs.k8s.io/cli-utils/pkg/apply/prune"
+	"sigs.k8s.io/cli-utils/pkg/apply/stage"
 	"sigs.k8s.io/cli-utils/pkg/apply/task"
 	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
 	"sigs.k8s.io/cli-utils/pkg/common"
@@ -26,6 +28,22 @@ var (
 	depInfo    = createInfo("apps/v1", "Deployment", "foo", "bar").Object.(*unstructured.Unstructured)
 	customInfo = createInfo("custom.io/v1", "Custom", "foo", "").Object.(*unstructured.Unstructured)
 	crdInfo    = createInfo("apiextensions.k8s.io/v1", "CustomResourceDefinition", "crd", "").Object.(*unstructured.Unstructured)
+
+	preHookInfo = withAnnotations(createInfo("batch/v1", "Job", "migrate", "bar"), map[string]string{
+		common.HookAnnotation: "pre-apply",
+	})
+	postHookInfo = withAnnotations(createInfo("batch/v1", "Job", "smoke-test", "bar"), map[string]string{
+		common.HookAnnotation:             "post-apply",
+		common.HookDeletePolicyAnnotation: "hook-succeeded",
+	})
+	canaryInfo = withLabels(createInfo("apps/v1", "Deployment", "canary", "bar"), map[string]string{
+		"stage": "canary",
+	})
+	canaryStage = stage.Stage{
+		Name:     "canary",
+		Selector: "stage=canary",
+		Soak:     metav1.Duration{Duration: 5 * time.Minute},
+	}
 )
 
 func TestTaskQueueSolver_BuildTaskQueue(t *testing.T) {
@@ -188,6 +206,172 @@ func TestTaskQueueSolver_BuildTaskQueue(t *testing.T) {
 				&task.SendEventTask{},
 			},
 		},
+		"pre-apply and post-apply hooks": {
+			objs: []*unstructured.Unstructured{
+				postHookInfo,
+				depInfo,
+				preHookInfo,
+			},
+			options: Options{
+				ReconcileTimeout: time.Minute,
+				HookTimeout:      time.Minute,
+				Prune:            true,
+			},
+			expectedTasks: []taskrunner.Task{
+				&task.DeleteHookTask{
+					Objects: []*unstructured.Unstructured{
+						preHookInfo,
+					},
+				},
+				taskrunner.NewWaitTask(
+					[]object.ObjMetadata{
+						ignoreErrInfoToObjMeta(preHookInfo),
+					},
+					taskrunner.AllNotFound, 1*time.Second),
+				&task.ApplyTask{
+					Objects: []*unstructured.Unstructured{
+						preHookInfo,
+					},
+				},
+				newAbortOnFailureWaitTask(
+					[]object.ObjMetadata{
+						ignoreErrInfoToObjMeta(preHookInfo),
+					}),
+				&task.ApplyTask{
+					Objects: []*unstructured.Unstructured{
+						depInfo,
+					},
+				},
+				&task.SendEventTask{},
+				taskrunner.NewWaitTask(
+					[]object.ObjMetadata{
+						ignoreErrInfoToObjMeta(depInfo),
+					},
+					taskrunner.AllCurrent, 1*time.Second),
+				&task.SendEventTask{},
+				&task.ApplyTask{
+					Objects: []*unstructured.Unstructured{
+						postHookInfo,
+					},
+				},
+				newAbortOnFailureWaitTask(
+					[]object.ObjMetadata{
+						ignoreErrInfoToObjMeta(postHookInfo),
+					}),
+				&task.DeleteHookTask{
+					Objects: []*unstructured.Unstructured{
+						postHookInfo,
+					},
+				},
+				&task.PruneTask{},
+				&task.SendEventTask{},
+			},
+		},
+		"no wait for hooks if it is a dryrun": {
+			objs: []*unstructured.Unstructured{
+				depInfo,
+				preHookInfo,
+			},
+			options: Options{
+				HookTimeout:    time.Minute,
+				DryRunStrategy: common.DryRunClient,
+			},
+			expectedTasks: []taskrunner.Task{
+				&task.DeleteHookTask{
+					Objects: []*unstructured.Unstructured{
+						preHookInfo,
+					},
+				},
+				&task.ApplyTask{
+					Objects: []*unstructured.Unstructured{
+						preHookInfo,
+					},
+				},
+				&task.ApplyTask{
+					Objects: []*unstructured.Unstructured{
+						depInfo,
+					},
+				},
+				&task.SendEventTask{},
+			},
+		},
+		"staged resources are applied first": {
+			objs: []*unstructured.Unstructured{
+				depInfo,
+				canaryInfo,
+			},
+			options: Options{
+				ReconcileTimeout: time.Minute,
+				Stages:           []stage.Stage{canaryStage},
+			},
+			expectedTasks: []taskrunner.Task{
+				&task.ApplyTask{
+					Objects: []*unstructured.Unstructured{
+						canaryInfo,
+					},
+				},
+				newStageWaitTask(
+					[]object.ObjMetadata{
+						ignoreErrInfoToObjMeta(canaryInfo),
+					}),
+				taskrunner.NewSoakTask(
+					[]object.ObjMetadata{
+						ignoreErrInfoToObjMeta(canaryInfo),
+					}, 5*time.Minute),
+				&task.ApplyTask{
+					Objects: []*unstructured.Unstructured{
+						depInfo,
+					},
+				},
+				&task.SendEventTask{},
+				taskrunner.NewWaitTask(
+					[]object.ObjMetadata{
+						ignoreErrInfoToObjMeta(depInfo),
+						ignoreErrInfoToObjMeta(canaryInfo),
+					},
+					taskrunner.AllCurrent, 1*time.Second),
+				&task.SendEventTask{},
+			},
+		},
+		"stages without resources are skipped": {
+			objs: []*unstructured.Unstructured{
+				depInfo,
+			},
+			options: Options{
+				Stages: []stage.Stage{canaryStage},
+			},
+			expectedTasks: []taskrunner.Task{
+				&task.ApplyTask{
+					Objects: []*unstructured.Unstructured{
+						depInfo,
+					},
+				},
+				&task.SendEventTask{},
+			},
+		},
+		"no wait or soak for stages if it is a dryrun": {
+			objs: []*unstructured.Unstructured{
+				depInfo,
+				canaryInfo,
+			},
+			options: Options{
+				Stages:         []stage.Stage{canaryStage},
+				DryRunStrategy: common.DryRunClient,
+			},
+			expectedTasks: []taskrunner.Task{
+				&task.ApplyTask{
+					Objects: []*unstructured.Unstructured{
+						canaryInfo,
+					},
+				},
+				&task.ApplyTask{
+					Objects: []*unstructured.Unstructured{
+						depInfo,
+					},
+				},
+				&task.SendEventTask{},
+			},
+		},
 		"no wait with CRDs if it is a dryrun": {
 			objs: []*unstructured.Unstructured{
 				crdInfo,
@@ -242,19 +426,71 @@ func TestTaskQueueSolver_BuildTaskQueue(t *testing.T) {
 						actObj := actApplyTask.Objects[j]
 						assert.Equal(t, ignoreErrInfoToObjMeta(obj), ignoreErrInfoToObjMeta(actObj))
 					}
+				case *task.DeleteHookTask:
+					actDeleteTask := actualTask.(*task.DeleteHookTask)
+					assert.Equal(t, len(expTsk.Objects), len(actDeleteTask.Objects))
+					for j, obj := range expTsk.Objects {
+						actObj := actDeleteTask.Objects[j]
+						assert.Equal(t, ignoreErrInfoToObjMeta(obj), ignoreErrInfoToObjMeta(actObj))
+					}
 				case *taskrunner.WaitTask:
 					actWaitTask := toWaitTask(t, actualTask)
+					assert.Equal(t, expTsk.Condition, actWaitTask.Condition)
+					assert.Equal(t, expTsk.AbortOnFailure, actWaitTask.AbortOnFailure)
 					assert.Equal(t, len(expTsk.Identifiers), len(actWaitTask.Identifiers))
 					for j, id := range expTsk.Identifiers {
 						actID := actWaitTask.Identifiers[j]
 						assert.Equal(t, id, actID)
 					}
+					assert.Equal(t, len(expTsk.WaitConditions), len(actWaitTask.WaitConditions))
+					for id, wc := range expTsk.WaitConditions {
+						assert.Equal(t, wc, actWaitTask.WaitConditions[id])
+					}
+				case *taskrunner.SoakTask:
+					actSoakTask := actualTask.(*taskrunner.SoakTask)
+					assert.Equal(t, expTsk.Duration, actSoakTask.Duration)
+					assert.DeepEqual(t, expTsk.Identifiers, actSoakTask.Identifiers)
 				}
 			}
 		})
 	}
 }
 
+// newAbortOnFailureWaitTask returns the wait task for hooks that are
+// Jobs without a wait condition.
+func newAbortOnFailureWaitTask(ids []object.ObjMetadata) *taskrunner.WaitTask {
+	waitTask := taskrunner.NewWaitTask(ids, taskrunner.AllCurrent, 1*time.Second)
+	waitTask.AbortOnFailure = true
+	waitTask.WaitConditions = make(map[object.ObjMetadata]taskrunner.WaitCondition)
+	for _, id := range ids {
+		waitTask.WaitConditions[id] = taskrunner.WaitCondition{
+			Type:            taskrunner.WaitForCondition,
+			ConditionType:   "Complete",
+			ConditionStatus: "True",
+		}
+	}
+	return waitTask
+}
+
+// newStageWaitTask returns the wait task for the resources in a stage.
+func newStageWaitTask(ids []object.ObjMetadata) *taskrunner.WaitTask {
+	waitTask := taskrunner.NewWaitTask(ids, taskrunner.AllCurrent, 1*time.Second)
+	waitTask.AbortOnFailure = true
+	return waitTask
+}
+
+func withLabels(info *resource.Info, labels map[string]string) *unstructured.Unstructured {
+	u := info.Object.(*unstructured.Unstructured)
+	u.SetLabels(labels)
+	return u
+}
+
+func withAnnotations(info *resource.Info, annotations map[string]string) *unstructured.Unstructured {
+	u := info.Object.(*unstructured.Unstructured)
+	u.SetAnnotations(annotations)
+	return u
+}
+
 func toWaitTask(t *testing.T, task taskrunner.Task) *taskrunner.WaitTask {
 	switch tsk := task.(type) {
 	case *taskrunner.WaitTask:
@@ -314,6 +550,7 @@ type fakeResourceObjects struct {
 	idsForApply   []object.ObjMetadata
 	idsForPrune   []object.ObjMetadata
 	idsForPrevInv []object.ObjMetadata
+	idsUnselected []object.ObjMetadata
 }
 
 func (f *fakeResourceObjects) ObjsForApply() []*unstructured.Unstructured {
@@ -336,6 +573,10 @@ func (f *fakeResourceObjects) IdsForPrevInv() []object.ObjMetadata {
 	return f.idsForPrevInv
 }
 
+func (f *fakeResourceObjects) IdsForUnselected() []object.ObjMetadata {
+	return f.idsUnselected
+}
+
 func ignoreErrInfoToObjMeta(info *unstructured.Unstructured) object.ObjMetadata {
 	objMeta := object.UnstructuredToObjMeta(info)
 	return objMeta
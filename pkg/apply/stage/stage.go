@@ -0,0 +1,174 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package stage contains the configuration for applying a package in
+// stages. Each stage selects resources with a label selector, and the
+// resources in a stage are applied and waited on until they are
+// Current, optionally followed by a soak period, before the next stage
+// is started. Resources that are not selected by any stage are applied
+// after all the stages, like they would be without stages.
+package stage
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// FileName is the name of the file in the root of a package that
+	// contains the stages for the package. It doesn't have a yaml
+	// extension, so it is not read as a manifest.
+	FileName = ".stages"
+
+	// APIVersion and Kind identify the stages file.
+	APIVersion = "cli-utils.sigs.k8s.io/v1alpha1"
+	Kind       = "ApplyStages"
+
+	// DefaultTimeout is how long to wait for the resources in a stage
+	// to become Current if neither the stage nor the apply sets a
+	// timeout.
+	DefaultTimeout = 5 * time.Minute
+)
+
+// Stage is a group of resources that are applied together, and must
+// become Current before the next stage is applied.
+type Stage struct {
+	// Name identifies the stage in the output.
+	Name string `json:"name"`
+	// Selector is the label selector for the resources in the stage,
+	// like "stage=canary". A resource that is selected by several
+	// stages belongs to the first of them.
+	Selector string `json:"selector"`
+	// Soak is how long to wait after the resources in the stage have
+	// become Current before the next stage is applied. The apply fails
+	// if any of the resources has the Failed status during the soak.
+	Soak metav1.Duration `json:"soak,omitempty"`
+	// Timeout is how long to wait for the resources in the stage to
+	// become Current. It defaults to the reconcile timeout for the
+	// apply, or DefaultTimeout if that is not set either.
+	Timeout metav1.Duration `json:"timeout,omitempty"`
+}
+
+// File is the content of the stages file, for example:
+//
+//	apiVersion: cli-utils.sigs.k8s.io/v1alpha1
+//	kind: ApplyStages
+//	stages:
+//	- name: canary
+//	  selector: stage=canary
+//	  soak: 5m
+type File struct {
+	APIVersion string  `json:"apiVersion"`
+	Kind       string  `json:"kind"`
+	Stages     []Stage `json:"stages"`
+}
+
+// Group is the resources that belong to a stage. The Stage has an
+// empty name for the group of resources that are not selected by any
+// stage.
+type Group struct {
+	Stage   Stage
+	Objects []*unstructured.Unstructured
+}
+
+// ReadFile reads the stages from the file with the given path.
+func ReadFile(path string) ([]Stage, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f File
+	if err := yaml.UnmarshalStrict(b, &f); err != nil {
+		return nil, fmt.Errorf("error reading stages file %s: %v", path, err)
+	}
+	if f.APIVersion != APIVersion || f.Kind != Kind {
+		return nil, fmt.Errorf("stages file %s must have apiVersion %s and kind %s",
+			path, APIVersion, Kind)
+	}
+	if err := Validate(f.Stages); err != nil {
+		return nil, fmt.Errorf("error reading stages file %s: %v", path, err)
+	}
+	return f.Stages, nil
+}
+
+// LoadPackageStages reads the stages file from the root of the package
+// directory. It returns no stages if the file doesn't exist.
+func LoadPackageStages(dir string) ([]Stage, error) {
+	stages, err := ReadFile(filepath.Join(dir, FileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return stages, err
+}
+
+// Validate checks that every stage has a unique name and a valid,
+// non-empty selector, and that the durations are not negative.
+func Validate(stages []Stage) error {
+	names := make(map[string]bool)
+	for _, s := range stages {
+		if s.Name == "" {
+			return fmt.Errorf("stage name must not be empty")
+		}
+		if names[s.Name] {
+			return fmt.Errorf("duplicate stage name %q", s.Name)
+		}
+		names[s.Name] = true
+		selector, err := labels.Parse(s.Selector)
+		if err != nil {
+			return fmt.Errorf("invalid selector for stage %q: %v", s.Name, err)
+		}
+		if selector.Empty() {
+			return fmt.Errorf("selector for stage %q must not be empty", s.Name)
+		}
+		if s.Soak.Duration < 0 || s.Timeout.Duration < 0 {
+			return fmt.Errorf("soak and timeout for stage %q must not be negative", s.Name)
+		}
+	}
+	return nil
+}
+
+// Split divides the resources into a group for each stage, followed
+// by a group for the resources that are not selected by any stage.
+// The order of the resources is kept within each group. Stages with
+// invalid selectors don't select any resources, so Validate should be
+// used to reject them first. Nil is returned if there are no stages.
+func Split(objs []*unstructured.Unstructured, stages []Stage) []Group {
+	if len(stages) == 0 {
+		return nil
+	}
+	groups := make([]Group, len(stages)+1)
+	selectors := make([]labels.Selector, len(stages))
+	for i, s := range stages {
+		groups[i].Stage = s
+		selector, err := labels.Parse(s.Selector)
+		if err != nil {
+			selector = labels.Nothing()
+		}
+		selectors[i] = selector
+	}
+	for _, obj := range objs {
+		index := len(stages)
+		for i, selector := range selectors {
+			if selector.Matches(labels.Set(obj.GetLabels())) {
+				index = i
+				break
+			}
+		}
+		groups[index].Objects = append(groups[index].Objects, obj)
+	}
+	return groups
+}
+
+// Ids returns the identifiers of the resources in the group.
+func (g Group) Ids() []object.ObjMetadata {
+	return object.UnstructuredsToObjMetas(g.Objects)
+}
@@ -0,0 +1,204 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package stage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestReadFile(t *testing.T) {
+	testCases := map[string]struct {
+		content        string
+		expectedStages []Stage
+		expectedErr    bool
+	}{
+		"valid file": {
+			content: `
+apiVersion: cli-utils.sigs.k8s.io/v1alpha1
+kind: ApplyStages
+stages:
+- name: canary
+  selector: stage=canary
+  soak: 5m
+- name: first
+  selector: stage in (first, second)
+  timeout: 10m
+`,
+			expectedStages: []Stage{
+				{
+					Name:     "canary",
+					Selector: "stage=canary",
+					Soak:     metav1.Duration{Duration: 5 * time.Minute},
+				},
+				{
+					Name:     "first",
+					Selector: "stage in (first, second)",
+					Timeout:  metav1.Duration{Duration: 10 * time.Minute},
+				},
+			},
+		},
+		"wrong kind": {
+			content: `
+apiVersion: cli-utils.sigs.k8s.io/v1alpha1
+kind: ConfigMap
+`,
+			expectedErr: true,
+		},
+		"unknown field": {
+			content: `
+apiVersion: cli-utils.sigs.k8s.io/v1alpha1
+kind: ApplyStages
+stages:
+- name: canary
+  labels: stage=canary
+`,
+			expectedErr: true,
+		},
+		"invalid selector": {
+			content: `
+apiVersion: cli-utils.sigs.k8s.io/v1alpha1
+kind: ApplyStages
+stages:
+- name: canary
+  selector: stage in (canary
+`,
+			expectedErr: true,
+		},
+		"empty selector": {
+			content: `
+apiVersion: cli-utils.sigs.k8s.io/v1alpha1
+kind: ApplyStages
+stages:
+- name: canary
+`,
+			expectedErr: true,
+		},
+		"duplicate name": {
+			content: `
+apiVersion: cli-utils.sigs.k8s.io/v1alpha1
+kind: ApplyStages
+stages:
+- name: canary
+  selector: stage=canary
+- name: canary
+  selector: stage=first
+`,
+			expectedErr: true,
+		},
+		"negative soak": {
+			content: `
+apiVersion: cli-utils.sigs.k8s.io/v1alpha1
+kind: ApplyStages
+stages:
+- name: canary
+  selector: stage=canary
+  soak: -1m
+`,
+			expectedErr: true,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "stage-test")
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer os.RemoveAll(dir)
+			err = ioutil.WriteFile(filepath.Join(dir, FileName), []byte(tc.content), 0600)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			stages, err := LoadPackageStages(dir)
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStages, stages)
+		})
+	}
+}
+
+func TestLoadPackageStagesMissingFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stage-test")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	stages, err := LoadPackageStages(dir)
+	assert.NoError(t, err)
+	assert.Nil(t, stages)
+}
+
+func newObj(name string, labels map[string]string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": "default",
+			},
+		},
+	}
+	u.SetLabels(labels)
+	return u
+}
+
+func TestSplit(t *testing.T) {
+	canary := newObj("canary", map[string]string{"stage": "canary"})
+	first := newObj("first", map[string]string{"stage": "first"})
+	both := newObj("both", map[string]string{"stage": "canary", "tier": "first"})
+	rest := newObj("rest", nil)
+
+	canaryStage := Stage{Name: "canary", Selector: "stage=canary"}
+	firstStage := Stage{Name: "first", Selector: "stage=first"}
+	tierStage := Stage{Name: "tier", Selector: "tier=first"}
+
+	testCases := map[string]struct {
+		objs           []*unstructured.Unstructured
+		stages         []Stage
+		expectedGroups []Group
+	}{
+		"no stages": {
+			objs: []*unstructured.Unstructured{canary, rest},
+		},
+		"resources are grouped by stage": {
+			objs:   []*unstructured.Unstructured{rest, first, canary},
+			stages: []Stage{canaryStage, firstStage},
+			expectedGroups: []Group{
+				{Stage: canaryStage, Objects: []*unstructured.Unstructured{canary}},
+				{Stage: firstStage, Objects: []*unstructured.Unstructured{first}},
+				{Objects: []*unstructured.Unstructured{rest}},
+			},
+		},
+		"resource belongs to the first matching stage": {
+			objs:   []*unstructured.Unstructured{both},
+			stages: []Stage{tierStage, canaryStage},
+			expectedGroups: []Group{
+				{Stage: tierStage, Objects: []*unstructured.Unstructured{both}},
+				{Stage: canaryStage},
+				{},
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			groups := Split(tc.objs, tc.stages)
+			assert.Equal(t, tc.expectedGroups, groups)
+		})
+	}
+}
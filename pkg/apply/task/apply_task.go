@@ -5,14 +5,17 @@ package task
 
 import (
 	"context"
+	"fmt"
 	"io/ioutil"
 	"strings"
+	"time"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/resource"
 	"k8s.io/client-go/dynamic"
@@ -24,6 +27,8 @@ import (
 	applyerror "sigs.k8s.io/cli-utils/pkg/apply/error"
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
 	"sigs.k8s.io/cli-utils/pkg/apply/info"
+	"sigs.k8s.io/cli-utils/pkg/apply/retry"
+	"sigs.k8s.io/cli-utils/pkg/apply/rollback"
 	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
@@ -57,6 +62,16 @@ type ApplyTask struct {
 	ServerSideOptions common.ServerSideOptions
 	InventoryPolicy   inventory.InventoryPolicy
 	InvInfo           inventory.InventoryInfo
+	// RetryPolicy defines how applying an object is retried if it
+	// fails with a transient error.
+	RetryPolicy retry.Policy
+	// RecreateOptions defines whether an object is deleted and created
+	// again if applying it fails because an immutable field has changed.
+	RecreateOptions common.RecreateOptions
+	// Snapshot is used for atomic apply. If it is set, the state of
+	// each object in the cluster is recorded before the object is
+	// applied, and the task fails if any of the objects fail to apply.
+	Snapshot *rollback.Snapshot
 }
 
 // applyOptionsFactoryFunc is a factory function for creating a new
@@ -66,6 +81,13 @@ var applyOptionsFactoryFunc = newApplyOptions
 // getClusterObj gets the cluster object. Used for allow unit testing.
 var getClusterObj = getClusterObject
 
+// deleteClusterObj deletes the cluster object. Used for allow unit testing.
+var deleteClusterObj = deleteClusterObject
+
+// recreatePollInterval is how often to check if an object that is
+// recreated has been deleted. Used for allow unit testing.
+var recreatePollInterval = time.Second
+
 // Start creates a new goroutine that will invoke
 // the Run function on the ApplyOptions to update
 // the cluster. It will push a TaskResult on the taskChannel
@@ -180,17 +202,23 @@ func (a *ApplyTask) Start(taskContext *taskrunner.TaskContext) {
 				taskContext.CaptureResourceFailure(id)
 				continue
 			}
+			if a.Snapshot != nil {
+				a.Snapshot.Record(id, clusterObj)
+			}
 			// add the inventory annotation to the resource being applied.
 			inventory.AddInventoryIDAnnotation(obj, a.InvInfo)
-			ao.SetObjects([]*resource.Info{info})
 			klog.V(5).Infof("applying %s/%s...", info.Namespace, info.Name)
-			err = ao.Run()
+			err = a.applyWithRetry(taskContext, ao, info, obj)
 			if err != nil && a.ServerSideOptions.ServerSideApply && isAPIService(obj) && isStreamError(err) {
 				// Server-side Apply doesn't work with APIService before k8s 1.21
 				// https://github.com/kubernetes/kubernetes/issues/89264
 				// Thus APIService is handled specially using client-side apply.
 				err = clientSideApply(info, taskContext.EventChannel(), a.DryRunStrategy, a.Factory)
 			}
+			if err != nil && a.canRecreate(obj, err) {
+				klog.V(4).Infof("immutable field changed for %s/%s; recreating", info.Namespace, info.Name)
+				err = a.recreate(taskContext, dynamic, info, obj)
+			}
 			if err != nil {
 				if klog.V(4) {
 					klog.Errorf("error applying (%s/%s) %s", info.Namespace, info.Name, err)
@@ -225,6 +253,90 @@ func (a *ApplyTask) Start(taskContext *taskrunner.TaskContext) {
 	}()
 }
 
+// applyWithRetry applies a single object, and retries according to the
+// retry policy if it fails with a transient error.
+func (a *ApplyTask) applyWithRetry(taskContext *taskrunner.TaskContext, ao applyOptions,
+	info *resource.Info, obj *unstructured.Unstructured) error {
+	id := object.UnstructuredToObjMeta(obj)
+	ao.SetObjects([]*resource.Info{info})
+	return a.RetryPolicy.Do(taskContext.Context(), func() error {
+		// Client-side apply replaces the object in the info with the
+		// object from the cluster, so the local object must be
+		// restored before every attempt.
+		info.Object = obj.DeepCopy()
+		return ao.Run()
+	}, func(attempt retry.Attempt) {
+		klog.V(4).Infof("retrying apply of %s/%s after attempt %d: %s",
+			info.Namespace, info.Name, attempt.Number, attempt.Err)
+		taskContext.EventChannel() <- createApplyRetryEvent(id, attempt)
+	})
+}
+
+// canRecreate returns true if the error is caused by a change to an
+// immutable field, and recreate is enabled either for all objects or
+// with the annotation on the object.
+func (a *ApplyTask) canRecreate(obj *unstructured.Unstructured, err error) bool {
+	if !a.RecreateOptions.Recreate && !common.RecreateOnImmutableChange(obj.GetAnnotations()) {
+		return false
+	}
+	return isImmutableFieldError(err)
+}
+
+// recreate deletes the object from the cluster, waits until it is gone
+// and then applies it again. The apply event is sent with the Recreated
+// operation. Nothing is changed for dry-run, but the event is still
+// sent so the preview shows that the object would be recreated.
+func (a *ApplyTask) recreate(taskContext *taskrunner.TaskContext, dynamic dynamic.Interface,
+	info *resource.Info, obj *unstructured.Unstructured) error {
+	id := object.UnstructuredToObjMeta(obj)
+	if a.DryRunStrategy.ClientOrServerDryRun() {
+		taskContext.EventChannel() <- createApplyEvent(id, event.Recreated, nil)
+		return nil
+	}
+
+	err := deleteClusterObj(dynamic, info, a.RecreateOptions.PropagationPolicy)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("error deleting %s/%s to recreate it: %v", info.Namespace, info.Name, err)
+	}
+	err = wait.PollImmediate(recreatePollInterval, a.RecreateOptions.Timeout, func() (bool, error) {
+		_, err := getClusterObj(dynamic, info)
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("timed out after %s waiting for %s/%s to be deleted so it can be recreated",
+			a.RecreateOptions.Timeout, info.Namespace, info.Name)
+	}
+	if err != nil {
+		return err
+	}
+
+	// The events from the ApplyOptions are sent through a separate
+	// channel, so the Created event can be changed into a Recreated event.
+	recreateChannel := make(chan event.Event)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for e := range recreateChannel {
+			if e.Type == event.ApplyType && e.ApplyEvent.Type == event.ApplyEventResourceUpdate {
+				e.ApplyEvent.Operation = event.Recreated
+			}
+			taskContext.EventChannel() <- e
+		}
+	}()
+	defer func() {
+		close(recreateChannel)
+		<-done
+	}()
+	ao, _, err := applyOptionsFactoryFunc(recreateChannel, a.ServerSideOptions, a.DryRunStrategy, a.Factory)
+	if err != nil {
+		return err
+	}
+	return a.applyWithRetry(taskContext, ao, info, obj)
+}
+
 func newApplyOptions(eventChannel chan event.Event, serverSideOptions common.ServerSideOptions,
 	strategy common.DryRunStrategy, factory util.Factory) (applyOptions, dynamic.Interface, error) {
 	discovery, err := factory.ToDiscoveryClient()
@@ -273,8 +385,31 @@ func getClusterObject(p dynamic.Interface, info *resource.Info) (*unstructured.U
 	return namespacedClient.Get(context.TODO(), info.Name, metav1.GetOptions{})
 }
 
+func deleteClusterObject(p dynamic.Interface, info *resource.Info, policy metav1.DeletionPropagation) error {
+	namespacedClient := p.Resource(info.Mapping.Resource).Namespace(info.Namespace)
+	return namespacedClient.Delete(context.TODO(), info.Name, metav1.DeleteOptions{
+		PropagationPolicy: &policy,
+	})
+}
+
+// sendTaskResult sends the TaskResult for the task. For atomic apply,
+// the result is an error if any of the objects failed to apply.
 func (a *ApplyTask) sendTaskResult(taskContext *taskrunner.TaskContext) {
-	taskContext.TaskChannel() <- taskrunner.TaskResult{}
+	var err error
+	if a.Snapshot != nil {
+		var failed int
+		for _, obj := range a.Objects {
+			if taskContext.ResourceFailed(object.UnstructuredToObjMeta(obj)) {
+				failed++
+			}
+		}
+		if failed > 0 {
+			err = fmt.Errorf("%d resource(s) failed to apply", failed)
+		}
+	}
+	taskContext.TaskChannel() <- taskrunner.TaskResult{
+		Err: err,
+	}
 }
 
 // filterCRsWithCRDInSet loops through all the resources and filters out the
@@ -385,6 +520,24 @@ func createApplyEvent(id object.ObjMetadata, operation event.ApplyEventOperation
 	}
 }
 
+// createApplyRetryEvent is a helper function to package an apply event
+// for a failed attempt that will be retried.
+func createApplyRetryEvent(id object.ObjMetadata, attempt retry.Attempt) event.Event {
+	return event.Event{
+		Type: event.ApplyType,
+		ApplyEvent: event.ApplyEvent{
+			Type:       event.ApplyEventRetry,
+			Identifier: id,
+			Error:      attempt.Err,
+			Retry: event.Retry{
+				Attempt:     attempt.Number,
+				MaxAttempts: attempt.MaxAttempts,
+				Delay:       attempt.Delay,
+			},
+		},
+	}
+}
+
 // sendBatchApplyEvents is a helper function to send out multiple apply events for
 // a list of resources when failed to initialize the apply process.
 func sendBatchApplyEvents(taskContext *taskrunner.TaskContext, objects []*unstructured.Unstructured, err error) {
@@ -401,6 +554,29 @@ func isAPIService(obj *unstructured.Unstructured) bool {
 	return gk.Group == "apiregistration.k8s.io" && gk.Kind == "APIService"
 }
 
+// immutableFieldMessages are parts of the messages from the validation
+// errors returned when an immutable field has been changed.
+var immutableFieldMessages = []string{
+	"field is immutable",
+	// StatefulSets don't use the generic message.
+	"updates to statefulset spec for fields other than",
+}
+
+// isImmutableFieldError checks if the error is a validation error
+// caused by a change to an immutable field.
+func isImmutableFieldError(err error) bool {
+	if !apierrors.IsInvalid(err) {
+		return false
+	}
+	msg := err.Error()
+	for _, m := range immutableFieldMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
+
 // isStreamError checks if the error is a StreamError. Since kubectl wraps the actual StreamError,
 // we can't check the error type.
 func isStreamError(err error) bool {
@@ -8,16 +8,20 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"gotest.tools/assert"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/cli-runtime/pkg/resource"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/kubectl/pkg/cmd/util"
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/retry"
 	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
@@ -659,6 +663,275 @@ func TestApplyTaskWithError(t *testing.T) {
 	}
 }
 
+func TestApplyTaskWithRetry(t *testing.T) {
+	throttled := apierrors.NewTooManyRequests("slow down", 1)
+	invalid := apierrors.NewBadRequest("invalid")
+
+	testCases := map[string]struct {
+		errs               []error
+		expectedRuns       int
+		expectedEventTypes []event.ApplyEventType
+		expectedError      error
+	}{
+		"succeeds after retries": {
+			errs:               []error{throttled, throttled, nil},
+			expectedRuns:       3,
+			expectedEventTypes: []event.ApplyEventType{event.ApplyEventRetry, event.ApplyEventRetry},
+		},
+		"fails after max attempts": {
+			errs:         []error{throttled, throttled, throttled},
+			expectedRuns: 3,
+			expectedEventTypes: []event.ApplyEventType{event.ApplyEventRetry, event.ApplyEventRetry,
+				event.ApplyEventResourceUpdate},
+			expectedError: throttled,
+		},
+		"non-retryable error is not retried": {
+			errs:               []error{invalid},
+			expectedRuns:       1,
+			expectedEventTypes: []event.ApplyEventType{event.ApplyEventResourceUpdate},
+			expectedError:      invalid,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			eventChannel := make(chan event.Event)
+			taskContext := taskrunner.NewTaskContext(eventChannel)
+
+			restMapper := testutil.NewFakeRESTMapper(schema.GroupVersionKind{
+				Group:   "apps",
+				Version: "v1",
+				Kind:    "Deployment",
+			})
+
+			ao := &flakyApplyOptions{errs: tc.errs}
+			oldAO := applyOptionsFactoryFunc
+			applyOptionsFactoryFunc = func(chan event.Event, common.ServerSideOptions, common.DryRunStrategy, util.Factory) (applyOptions, dynamic.Interface, error) {
+				return ao, nil, nil
+			}
+			defer func() { applyOptionsFactoryFunc = oldAO }()
+
+			getClusterObj = func(d dynamic.Interface, info *resource.Info) (*unstructured.Unstructured, error) {
+				return addOwningInventory(deployment, "id"), nil
+			}
+			applyTask := &ApplyTask{
+				Objects:        []*unstructured.Unstructured{deployment.DeepCopy()},
+				InfoHelper:     &fakeInfoHelper{},
+				Mapper:         restMapper,
+				DryRunStrategy: common.DryRunNone,
+				InvInfo:        &fakeInventoryInfo{},
+				RetryPolicy: retry.Policy{
+					MaxAttempts:    3,
+					InitialBackoff: time.Millisecond,
+				},
+			}
+
+			var events []event.Event
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for msg := range eventChannel {
+					events = append(events, msg)
+				}
+			}()
+
+			applyTask.Start(taskContext)
+			<-taskContext.TaskChannel()
+			close(eventChannel)
+			wg.Wait()
+
+			assert.Equal(t, tc.expectedRuns, ao.runs)
+			assert.Equal(t, len(tc.expectedEventTypes), len(events))
+			for i, e := range events {
+				assert.Equal(t, event.ApplyType, e.Type)
+				assert.Equal(t, tc.expectedEventTypes[i], e.ApplyEvent.Type)
+				if e.ApplyEvent.Type == event.ApplyEventRetry {
+					assert.Equal(t, i+1, e.ApplyEvent.Retry.Attempt)
+					assert.Equal(t, 3, e.ApplyEvent.Retry.MaxAttempts)
+					assert.Equal(t, tc.errs[i], e.ApplyEvent.Error)
+				}
+			}
+			last := events[len(events)-1].ApplyEvent
+			if tc.expectedError != nil {
+				assert.Equal(t, event.Failed, last.Operation)
+				assert.Assert(t, strings.Contains(last.Error.Error(), tc.expectedError.Error()))
+			}
+		})
+	}
+}
+
+// flakyApplyOptions returns the errors in order, one for each call to Run.
+type flakyApplyOptions struct {
+	errs []error
+	runs int
+}
+
+func (f *flakyApplyOptions) Run() error {
+	err := f.errs[f.runs]
+	f.runs++
+	return err
+}
+
+func (f *flakyApplyOptions) SetObjects([]*resource.Info) {}
+
+func TestApplyTaskWithRecreate(t *testing.T) {
+	immutable := apierrors.NewInvalid(schema.GroupKind{Group: "apps", Kind: "Deployment"}, "deploy",
+		field.ErrorList{field.Invalid(field.NewPath("spec", "selector"), "", "field is immutable")})
+	invalid := apierrors.NewInvalid(schema.GroupKind{Group: "apps", Kind: "Deployment"}, "deploy",
+		field.ErrorList{field.Required(field.NewPath("spec", "template"), "")})
+	annotated := deployment.DeepCopy()
+	annotated.SetAnnotations(map[string]string{
+		common.OnImmutableChangeAnnotation: common.OnImmutableChangeRecreate,
+	})
+
+	testCases := map[string]struct {
+		obj               *unstructured.Unstructured
+		recreate          bool
+		dryRunStrategy    common.DryRunStrategy
+		err               error
+		neverDeleted      bool
+		expectedDeletes   int
+		expectedOperation event.ApplyEventOperation
+	}{
+		"recreate with annotation": {
+			obj:               annotated,
+			err:               immutable,
+			expectedDeletes:   1,
+			expectedOperation: event.Recreated,
+		},
+		"recreate with option": {
+			obj:               deployment,
+			recreate:          true,
+			err:               immutable,
+			expectedDeletes:   1,
+			expectedOperation: event.Recreated,
+		},
+		"not recreated without annotation or option": {
+			obj:               deployment,
+			err:               immutable,
+			expectedOperation: event.Failed,
+		},
+		"not recreated for other validation errors": {
+			obj:               annotated,
+			err:               invalid,
+			expectedOperation: event.Failed,
+		},
+		"fails if the object is never deleted": {
+			obj:               annotated,
+			err:               immutable,
+			neverDeleted:      true,
+			expectedDeletes:   1,
+			expectedOperation: event.Failed,
+		},
+		"dry-run doesn't delete": {
+			obj:               annotated,
+			dryRunStrategy:    common.DryRunServer,
+			err:               immutable,
+			expectedOperation: event.Recreated,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			eventChannel := make(chan event.Event)
+			taskContext := taskrunner.NewTaskContext(eventChannel)
+
+			restMapper := testutil.NewFakeRESTMapper(schema.GroupVersionKind{
+				Group:   "apps",
+				Version: "v1",
+				Kind:    "Deployment",
+			})
+
+			ao := &flakyApplyOptions{errs: []error{tc.err}}
+			var recreateAO *recreateApplyOptions
+			oldAO := applyOptionsFactoryFunc
+			applyOptionsFactoryFunc = func(ch chan event.Event, _ common.ServerSideOptions, _ common.DryRunStrategy, _ util.Factory) (applyOptions, dynamic.Interface, error) {
+				if ao.runs == 0 {
+					return ao, nil, nil
+				}
+				recreateAO = &recreateApplyOptions{eventChannel: ch}
+				return recreateAO, nil, nil
+			}
+			defer func() { applyOptionsFactoryFunc = oldAO }()
+
+			deleted := false
+			var deletes int
+			oldDelete := deleteClusterObj
+			deleteClusterObj = func(_ dynamic.Interface, _ *resource.Info, policy metav1.DeletionPropagation) error {
+				assert.Equal(t, metav1.DeletePropagationForeground, policy)
+				deletes++
+				deleted = !tc.neverDeleted
+				return nil
+			}
+			defer func() { deleteClusterObj = oldDelete }()
+			oldGet := getClusterObj
+			getClusterObj = func(d dynamic.Interface, info *resource.Info) (*unstructured.Unstructured, error) {
+				if deleted {
+					return nil, apierrors.NewNotFound(schema.GroupResource{Group: "apps", Resource: "deployments"}, "deploy")
+				}
+				return addOwningInventory(deployment, "id"), nil
+			}
+			defer func() { getClusterObj = oldGet }()
+			oldInterval := recreatePollInterval
+			recreatePollInterval = time.Millisecond
+			defer func() { recreatePollInterval = oldInterval }()
+
+			applyTask := &ApplyTask{
+				Objects:        []*unstructured.Unstructured{tc.obj.DeepCopy()},
+				InfoHelper:     &fakeInfoHelper{},
+				Mapper:         restMapper,
+				DryRunStrategy: tc.dryRunStrategy,
+				InvInfo:        &fakeInventoryInfo{},
+				RecreateOptions: common.RecreateOptions{
+					Recreate:          tc.recreate,
+					PropagationPolicy: metav1.DeletePropagationForeground,
+					Timeout:           20 * time.Millisecond,
+				},
+			}
+
+			var events []event.Event
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for msg := range eventChannel {
+					events = append(events, msg)
+				}
+			}()
+
+			applyTask.Start(taskContext)
+			<-taskContext.TaskChannel()
+			close(eventChannel)
+			wg.Wait()
+
+			assert.Equal(t, tc.expectedDeletes, deletes)
+			assert.Equal(t, 1, len(events))
+			assert.Equal(t, event.ApplyType, events[0].Type)
+			assert.Equal(t, event.ApplyEventResourceUpdate, events[0].ApplyEvent.Type)
+			assert.Equal(t, tc.expectedOperation, events[0].ApplyEvent.Operation)
+			if tc.expectedOperation == event.Recreated && tc.expectedDeletes > 0 {
+				assert.Equal(t, 1, recreateAO.runs)
+			}
+		})
+	}
+}
+
+// recreateApplyOptions sends a Created event for the object on every
+// call to Run, like the ApplyOptions does when the object is created.
+type recreateApplyOptions struct {
+	eventChannel chan event.Event
+	runs         int
+}
+
+func (r *recreateApplyOptions) Run() error {
+	r.runs++
+	r.eventChannel <- createApplyEvent(deploymentObjMetadata[0], event.Created, nil)
+	return nil
+}
+
+func (r *recreateApplyOptions) SetObjects([]*resource.Info) {}
+
 var deployment = toUnstructured(map[string]interface{}{
 	"apiVersion": "apps/v1",
 	"kind":       "Deployment",
@@ -0,0 +1,88 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package task
+
+import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog"
+	"k8s.io/kubectl/pkg/cmd/util"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/info"
+	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// DeleteHookTask deletes hooks from the cluster, either before they
+// are applied so they are created again, or after they have completed.
+// Hooks that are not found in the cluster are skipped. Nothing is
+// deleted for dry-run.
+type DeleteHookTask struct {
+	Objects           []*unstructured.Unstructured
+	InfoHelper        info.InfoHelper
+	Factory           util.Factory
+	PropagationPolicy metav1.DeletionPropagation
+	DryRunStrategy    common.DryRunStrategy
+}
+
+// Start creates a new goroutine that deletes the hooks, and pushes a
+// TaskResult on the taskChannel when it is done. A hook that can't be
+// deleted is reported with a failed delete event, but doesn't fail the
+// task.
+func (d *DeleteHookTask) Start(taskContext *taskrunner.TaskContext) {
+	go func() {
+		if d.DryRunStrategy.ClientOrServerDryRun() {
+			taskContext.TaskChannel() <- taskrunner.TaskResult{}
+			return
+		}
+		dynamic, err := d.Factory.DynamicClient()
+		if err != nil {
+			taskContext.TaskChannel() <- taskrunner.TaskResult{
+				Err: err,
+			}
+			return
+		}
+		for _, obj := range d.Objects {
+			id := object.UnstructuredToObjMeta(obj)
+			info, err := d.InfoHelper.BuildInfo(obj)
+			if err == nil {
+				klog.V(4).Infof("deleting hook %s/%s", info.Namespace, info.Name)
+				err = deleteClusterObj(dynamic, info, d.PropagationPolicy)
+			}
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				if klog.V(4) {
+					klog.Errorf("error deleting hook %s/%s (%s)", obj.GetNamespace(), obj.GetName(), err)
+				}
+				taskContext.EventChannel() <- createDeleteHookEvent(id, nil, event.DeleteEventFailed, err)
+				continue
+			}
+			taskContext.EventChannel() <- createDeleteHookEvent(id, obj, event.DeleteEventResourceUpdate, nil)
+		}
+		taskContext.TaskChannel() <- taskrunner.TaskResult{}
+	}()
+}
+
+// ClearTimeout is not supported by the DeleteHookTask.
+func (d *DeleteHookTask) ClearTimeout() {}
+
+// createDeleteHookEvent is a helper function to package a delete event
+// for a hook.
+func createDeleteHookEvent(id object.ObjMetadata, obj *unstructured.Unstructured,
+	eventType event.DeleteEventType, err error) event.Event {
+	return event.Event{
+		Type: event.DeleteType,
+		DeleteEvent: event.DeleteEvent{
+			Type:       eventType,
+			Operation:  event.Deleted,
+			Object:     obj,
+			Identifier: id,
+			Error:      err,
+		},
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package task
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"gotest.tools/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/dynamic"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
+	"sigs.k8s.io/cli-utils/pkg/common"
+)
+
+func TestDeleteHookTask(t *testing.T) {
+	testCases := map[string]struct {
+		deleteErr          error
+		dryRunStrategy     common.DryRunStrategy
+		expectedDeletes    int
+		expectedEventTypes []event.DeleteEventType
+	}{
+		"hook is deleted": {
+			expectedDeletes:    1,
+			expectedEventTypes: []event.DeleteEventType{event.DeleteEventResourceUpdate},
+		},
+		"hook not found is skipped": {
+			deleteErr:       apierrors.NewNotFound(schema.GroupResource{Group: "apps", Resource: "deployments"}, "deploy"),
+			expectedDeletes: 1,
+		},
+		"delete failure is reported": {
+			deleteErr:          fmt.Errorf("forbidden"),
+			expectedDeletes:    1,
+			expectedEventTypes: []event.DeleteEventType{event.DeleteEventFailed},
+		},
+		"nothing is deleted for dry-run": {
+			dryRunStrategy: common.DryRunClient,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			eventChannel := make(chan event.Event)
+			taskContext := taskrunner.NewTaskContext(eventChannel)
+
+			var deletes int
+			oldDelete := deleteClusterObj
+			deleteClusterObj = func(_ dynamic.Interface, info *resource.Info, policy metav1.DeletionPropagation) error {
+				assert.Equal(t, "deploy", info.Name)
+				assert.Equal(t, metav1.DeletePropagationBackground, policy)
+				deletes++
+				return tc.deleteErr
+			}
+			defer func() { deleteClusterObj = oldDelete }()
+
+			tf := cmdtesting.NewTestFactory()
+			defer tf.Cleanup()
+			deleteTask := &DeleteHookTask{
+				Objects:           []*unstructured.Unstructured{deployment.DeepCopy()},
+				InfoHelper:        &fakeInfoHelper{},
+				Factory:           tf,
+				PropagationPolicy: metav1.DeletePropagationBackground,
+				DryRunStrategy:    tc.dryRunStrategy,
+			}
+
+			var events []event.Event
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for msg := range eventChannel {
+					events = append(events, msg)
+				}
+			}()
+
+			deleteTask.Start(taskContext)
+			result := <-taskContext.TaskChannel()
+			close(eventChannel)
+			wg.Wait()
+
+			assert.NilError(t, result.Err)
+			assert.Equal(t, tc.expectedDeletes, deletes)
+			assert.Equal(t, len(tc.expectedEventTypes), len(events))
+			for i, e := range events {
+				assert.Equal(t, event.DeleteType, e.Type)
+				assert.Equal(t, tc.expectedEventTypes[i], e.DeleteEvent.Type)
+				assert.Equal(t, deploymentObjMetadata[0], e.DeleteEvent.Identifier)
+			}
+		})
+	}
+}
@@ -7,9 +7,12 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/cli-utils/pkg/apply/prune"
+	"sigs.k8s.io/cli-utils/pkg/apply/retry"
+	"sigs.k8s.io/cli-utils/pkg/apply/rollback"
 	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
 )
 
 // PruneTask prunes objects from the cluster
@@ -22,6 +25,15 @@ type PruneTask struct {
 	DryRunStrategy    common.DryRunStrategy
 	PropagationPolicy metav1.DeletionPropagation
 	InventoryPolicy   inventory.InventoryPolicy
+	RetryPolicy       retry.Policy
+	// Filter and UnselectedIds limit the prune to the objects selected
+	// for the apply.
+	Filter        object.Filter
+	UnselectedIds []object.ObjMetadata
+	// Snapshot is sealed when the prune starts, so an atomic apply
+	// is not rolled back once objects have been deleted. Only set
+	// for atomic apply.
+	Snapshot *rollback.Snapshot
 }
 
 // Start creates a new goroutine that will invoke
@@ -29,6 +41,9 @@ type PruneTask struct {
 // the cluster. It will push a TaskResult on the taskChannel
 // to signal to the taskrunner that the task has completed (or failed).
 func (p *PruneTask) Start(taskContext *taskrunner.TaskContext) {
+	if p.Snapshot != nil {
+		p.Snapshot.Seal()
+	}
 	go func() {
 		currentUIDs := taskContext.AllResourceUIDs()
 		err := p.PruneOptions.Prune(p.InventoryObject, p.Objects,
@@ -36,6 +51,9 @@ func (p *PruneTask) Start(taskContext *taskrunner.TaskContext) {
 				DryRunStrategy:    p.DryRunStrategy,
 				PropagationPolicy: p.PropagationPolicy,
 				InventoryPolicy:   p.InventoryPolicy,
+				RetryPolicy:       p.RetryPolicy,
+				Filter:            p.Filter,
+				UnselectedIds:     p.UnselectedIds,
 			})
 		taskContext.TaskChannel() <- taskrunner.TaskResult{
 			Err: err,
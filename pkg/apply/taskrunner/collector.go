@@ -4,6 +4,7 @@
 package taskrunner
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
 	"sigs.k8s.io/cli-utils/pkg/object"
@@ -38,6 +39,9 @@ type resourceStatus struct {
 	CurrentStatus status.Status
 	Message       string
 	Generation    int64
+	// Resource is the latest seen version of the resource. It is
+	// needed to check wait conditions declared on the resource.
+	Resource *unstructured.Unstructured
 }
 
 // resourceStatus updates the collector with the latest
@@ -47,6 +51,7 @@ func (a *resourceStatusCollector) resourceStatus(r *event.ResourceStatus) {
 		ri.CurrentStatus = r.Status
 		ri.Message = r.Message
 		ri.Generation = getGeneration(r)
+		ri.Resource = r.Resource
 		a.resourceMap[r.Identifier] = ri
 	}
 }
@@ -66,7 +71,7 @@ func getGeneration(r *event.ResourceStatus) int64 {
 func (a *resourceStatusCollector) conditionMet(rwd []resourceWaitData, c Condition) bool {
 	switch c {
 	case AllCurrent:
-		return a.allMatchStatus(rwd, status.CurrentStatus)
+		return a.allMeetWaitCondition(rwd)
 	case AllNotFound:
 		return a.allMatchStatus(rwd, status.NotFoundStatus)
 	default:
@@ -89,6 +94,31 @@ func (a *resourceStatusCollector) allMatchStatus(rwd []resourceWaitData, s statu
 	return true
 }
 
+// allMeetWaitCondition checks whether all resources given by the
+// Identifiers parameter has the Current status, or meet the wait
+// condition set on the individual resource.
+func (a *resourceStatusCollector) allMeetWaitCondition(rwd []resourceWaitData) bool {
+	for _, wd := range rwd {
+		ri, found := a.resourceMap[wd.identifier]
+		if !found {
+			return false
+		}
+		if ri.Generation < wd.generation {
+			return false
+		}
+		if wd.waitCondition != nil {
+			if !wd.waitCondition.met(ri) {
+				return false
+			}
+			continue
+		}
+		if ri.CurrentStatus != status.CurrentStatus {
+			return false
+		}
+	}
+	return true
+}
+
 // noneMatchStatus checks whether none of the resources given
 // by the Identifiers parameters has the provided status.
 func (a *resourceStatusCollector) noneMatchStatus(rwd []resourceWaitData, s status.Status) bool {
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"gotest.tools/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
 	"sigs.k8s.io/cli-utils/pkg/object"
@@ -123,6 +124,87 @@ func TestCollector_ConditionMet(t *testing.T) {
 			condition:      AllCurrent,
 			expectedResult: false,
 		},
+		"resource with exists wait condition": {
+			collectorState: map[object.ObjMetadata]resourceStatus{
+				identifiers["dep"]: {
+					Identifier:    identifiers["dep"],
+					CurrentStatus: status.InProgressStatus,
+					Generation:    int64(42),
+				},
+			},
+			waitTaskData: []resourceWaitData{
+				{
+					identifier:    identifiers["dep"],
+					generation:    int64(42),
+					waitCondition: &WaitCondition{Type: WaitForExists},
+				},
+			},
+			condition:      AllCurrent,
+			expectedResult: true,
+		},
+		"resource with named wait condition not met": {
+			collectorState: map[object.ObjMetadata]resourceStatus{
+				identifiers["custom"]: {
+					Identifier:    identifiers["custom"],
+					CurrentStatus: status.CurrentStatus,
+					Resource:      customWithStatus("Ready", "False", "Pending"),
+				},
+			},
+			waitTaskData: []resourceWaitData{
+				{
+					identifier: identifiers["custom"],
+					waitCondition: &WaitCondition{
+						Type:            WaitForCondition,
+						ConditionType:   "Ready",
+						ConditionStatus: "True",
+					},
+				},
+			},
+			condition:      AllCurrent,
+			expectedResult: false,
+		},
+		"resource with named wait condition met": {
+			collectorState: map[object.ObjMetadata]resourceStatus{
+				identifiers["custom"]: {
+					Identifier:    identifiers["custom"],
+					CurrentStatus: status.InProgressStatus,
+					Resource:      customWithStatus("Ready", "True", "Pending"),
+				},
+			},
+			waitTaskData: []resourceWaitData{
+				{
+					identifier: identifiers["custom"],
+					waitCondition: &WaitCondition{
+						Type:            WaitForCondition,
+						ConditionType:   "Ready",
+						ConditionStatus: "True",
+					},
+				},
+			},
+			condition:      AllCurrent,
+			expectedResult: true,
+		},
+		"resource with jsonpath wait condition": {
+			collectorState: map[object.ObjMetadata]resourceStatus{
+				identifiers["custom"]: {
+					Identifier:    identifiers["custom"],
+					CurrentStatus: status.InProgressStatus,
+					Resource:      customWithStatus("Ready", "False", "Active"),
+				},
+			},
+			waitTaskData: []resourceWaitData{
+				{
+					identifier: identifiers["custom"],
+					waitCondition: &WaitCondition{
+						Type:     WaitForJSONPath,
+						JSONPath: "{.status.phase}",
+						Value:    "Active",
+					},
+				},
+			},
+			condition:      AllCurrent,
+			expectedResult: true,
+		},
 	}
 
 	for tn, tc := range testCases {
@@ -136,3 +218,24 @@ func TestCollector_ConditionMet(t *testing.T) {
 		})
 	}
 }
+
+func customWithStatus(conditionType, conditionStatus, phase string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "custom.io/v1",
+			"kind":       "Custom",
+			"metadata": map[string]interface{}{
+				"name": "Foo",
+			},
+			"status": map[string]interface{}{
+				"phase": phase,
+				"conditions": []interface{}{
+					map[string]interface{}{
+						"type":   conditionType,
+						"status": conditionStatus,
+					},
+				},
+			},
+		},
+	}
+}
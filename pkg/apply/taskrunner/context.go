@@ -4,6 +4,7 @@
 package taskrunner
 
 import (
+	"context"
 	"strings"
 
 	"k8s.io/apimachinery/pkg/types"
@@ -25,6 +26,10 @@ func NewTaskContext(eventChannel chan event.Event) *TaskContext {
 // TaskContext defines a context that is passed between all
 // the tasks that is in a taskqueue.
 type TaskContext struct {
+	// ctx is the context the task runner was started with. Tasks
+	// should stop waiting when it is done.
+	ctx context.Context
+
 	taskChannel chan TaskResult
 
 	eventChannel chan event.Event
@@ -35,6 +40,16 @@ type TaskContext struct {
 	failedResources map[object.ObjMetadata]struct{}
 }
 
+// Context returns the context the task runner was started with. If the
+// TaskContext was not created by the task runner, it returns an empty
+// context.
+func (tc *TaskContext) Context() context.Context {
+	if tc.ctx == nil {
+		return context.Background()
+	}
+	return tc.ctx
+}
+
 func (tc *TaskContext) TaskChannel() chan TaskResult {
 	return tc.taskChannel
 }
@@ -129,6 +129,7 @@ func (b *baseRunner) run(ctx context.Context, taskQueue chan Task,
 	// provides access to the eventChannel and the taskChannel, and
 	// also provides a way to pass data between tasks.
 	taskContext := NewTaskContext(eventChannel)
+	taskContext.ctx = ctx
 
 	// Find and start the first task in the queue.
 	currentTask, done := b.nextTask(taskQueue, taskContext)
@@ -195,10 +196,19 @@ func (b *baseRunner) run(ctx context.Context, taskQueue chan Task,
 			b.collector.resourceStatus(statusEvent.Resource)
 			// If the current task is a wait task, we check whether
 			// the condition has been met. If so, we complete the task.
-			if wt, ok := currentTask.(*WaitTask); ok {
-				if wt.checkCondition(taskContext, b.collector) {
+			switch st := currentTask.(type) {
+			case *WaitTask:
+				if err := st.checkFailure(taskContext, b.collector); err != nil {
+					st.fail(taskContext, err)
+				} else if st.checkCondition(taskContext, b.collector) {
 					completeIfWaitTask(currentTask, taskContext)
 				}
+			// A soak task fails as soon as one of the resources has
+			// failed.
+			case *SoakTask:
+				if err := st.checkFailure(b.collector); err != nil {
+					st.fail(taskContext, err)
+				}
 			}
 		// A message on the taskChannel means that the current task
 		// has either completed or failed. If it has failed, we return
@@ -240,24 +250,34 @@ func (b *baseRunner) amendTimeoutError(err error) {
 			if !found {
 				continue
 			}
-			if timeoutErr.Condition.Meets(ls.CurrentStatus) {
+			var waitCondition string
+			if wc, found := timeoutErr.WaitConditions[id]; found {
+				if wc.met(ls) {
+					continue
+				}
+				waitCondition = wc.String()
+			} else if timeoutErr.Condition.Meets(ls.CurrentStatus) {
 				continue
 			}
 			timedOutResources = append(timedOutResources, TimedOutResource{
-				Identifier: id,
-				Status:     ls.CurrentStatus,
-				Message:    ls.Message,
+				Identifier:    id,
+				Status:        ls.CurrentStatus,
+				Message:       ls.Message,
+				WaitCondition: waitCondition,
 			})
 		}
 		timeoutErr.TimedOutResources = timedOutResources
 	}
 }
 
-// completeIfWaitTask checks if the current task is a wait task. If so,
-// we invoke the complete function to complete it.
+// completeIfWaitTask checks if the current task is a wait task or a
+// soak task. If so, we invoke the complete function to complete it.
 func completeIfWaitTask(currentTask Task, taskContext *TaskContext) {
-	if wt, ok := currentTask.(*WaitTask); ok {
-		wt.complete(taskContext)
+	switch st := currentTask.(type) {
+	case *WaitTask:
+		st.complete(taskContext)
+	case *SoakTask:
+		st.complete(taskContext)
 	}
 }
 
@@ -283,7 +303,9 @@ func (b *baseRunner) nextTask(taskQueue chan Task,
 		// starting a new wait task, we check if the condition is already
 		// met. Without this check, a task might end up waiting for
 		// status events when the condition is in fact already met.
-		if st.checkCondition(taskContext, b.collector) {
+		if err := st.checkFailure(taskContext, b.collector); err != nil {
+			st.startAndFail(taskContext, err)
+		} else if st.checkCondition(taskContext, b.collector) {
 			st.startAndComplete(taskContext)
 		} else {
 			st.Start(taskContext)
@@ -314,6 +336,10 @@ type TimeoutError struct {
 	// Condition defines the criteria for which the task was waiting.
 	Condition Condition
 
+	// WaitConditions contains the wait conditions declared on
+	// individual resources, if any.
+	WaitConditions map[object.ObjMetadata]WaitCondition
+
 	TimedOutResources []TimedOutResource
 }
 
@@ -323,6 +349,10 @@ type TimedOutResource struct {
 	Status status.Status
 
 	Message string
+
+	// WaitCondition is the wait condition declared on the resource.
+	// It is empty if the resource uses the Condition of the task.
+	WaitCondition string
 }
 
 func (te TimeoutError) Error() string {
@@ -330,6 +360,19 @@ func (te TimeoutError) Error() string {
 		te.Timeout.Seconds(), len(te.Identifiers), te.Condition)
 }
 
+// ResourceFailedError is returned by a WaitTask with AbortOnFailure set,
+// or by a SoakTask, when one of the resources has failed.
+type ResourceFailedError struct {
+	Identifier object.ObjMetadata
+
+	Message string
+}
+
+func (e ResourceFailedError) Error() string {
+	return fmt.Sprintf("%s %s/%s failed: %s", e.Identifier.GroupKind.Kind,
+		e.Identifier.Namespace, e.Identifier.Name, e.Message)
+}
+
 // IsTimeoutError checks whether a given error is
 // a TimeoutError.
 func IsTimeoutError(err error) (*TimeoutError, bool) {
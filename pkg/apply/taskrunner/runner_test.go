@@ -116,6 +116,86 @@ func TestBaseRunner(t *testing.T) {
 				},
 			},
 		},
+		"wait task with abort on failure fails on Failed status": {
+			identifiers: []object.ObjMetadata{depID, cmID},
+			tasks: []Task{
+				newAbortOnFailureWaitTask([]object.ObjMetadata{depID, cmID}, 1*time.Minute),
+				&busyTask{
+					resultEvent: event.Event{
+						Type: event.PruneType,
+					},
+					duration: 1 * time.Second,
+				},
+			},
+			statusEventsDelay: time.Second,
+			statusEvents: []pollevent.Event{
+				{
+					EventType: pollevent.ResourceUpdateEvent,
+					Resource: &pollevent.ResourceStatus{
+						Identifier: depID,
+						Status:     status.FailedStatus,
+						Message:    "backoff limit exceeded",
+					},
+				},
+			},
+			expectedEventTypes: []event.Type{
+				event.StatusType,
+			},
+			expectedError: &ResourceFailedError{},
+		},
+		"soak task runs for the full duration": {
+			identifiers: []object.ObjMetadata{depID},
+			tasks: []Task{
+				NewSoakTask([]object.ObjMetadata{depID}, 2*time.Second),
+				&busyTask{
+					resultEvent: event.Event{
+						Type: event.PruneType,
+					},
+					duration: 1 * time.Second,
+				},
+			},
+			statusEventsDelay: time.Second,
+			statusEvents: []pollevent.Event{
+				{
+					EventType: pollevent.ResourceUpdateEvent,
+					Resource: &pollevent.ResourceStatus{
+						Identifier: depID,
+						Status:     status.CurrentStatus,
+					},
+				},
+			},
+			expectedEventTypes: []event.Type{
+				event.StatusType,
+				event.PruneType,
+			},
+		},
+		"soak task fails on Failed status": {
+			identifiers: []object.ObjMetadata{depID},
+			tasks: []Task{
+				NewSoakTask([]object.ObjMetadata{depID}, 1*time.Minute),
+				&busyTask{
+					resultEvent: event.Event{
+						Type: event.PruneType,
+					},
+					duration: 1 * time.Second,
+				},
+			},
+			statusEventsDelay: time.Second,
+			statusEvents: []pollevent.Event{
+				{
+					EventType: pollevent.ResourceUpdateEvent,
+					Resource: &pollevent.ResourceStatus{
+						Identifier: depID,
+						Status:     status.FailedStatus,
+						Message:    "crash loop",
+					},
+				},
+			},
+			expectedEventTypes: []event.Type{
+				event.StatusType,
+			},
+			expectedError: &ResourceFailedError{},
+		},
 		"tasks run in order": {
 			identifiers: []object.ObjMetadata{},
 			tasks: []Task{
@@ -267,6 +347,20 @@ func TestBaseRunnerCancellation(t *testing.T) {
 			contextTimeout:     2 * time.Second,
 			expectedEventTypes: []event.Type{},
 		},
+		"cancellation while soak task is running": {
+			identifiers: []object.ObjMetadata{depID},
+			tasks: []Task{
+				NewSoakTask([]object.ObjMetadata{depID}, 20*time.Second),
+				&busyTask{
+					resultEvent: event.Event{
+						Type: event.PruneType,
+					},
+					duration: 2 * time.Second,
+				},
+			},
+			contextTimeout:     2 * time.Second,
+			expectedEventTypes: []event.Type{},
+		},
 		"error while custom task is running": {
 			identifiers: []object.ObjMetadata{depID},
 			tasks: []Task{
@@ -379,6 +473,12 @@ func TestBaseRunnerCancellation(t *testing.T) {
 	}
 }
 
+func newAbortOnFailureWaitTask(ids []object.ObjMetadata, timeout time.Duration) *WaitTask {
+	wt := NewWaitTask(ids, AllCurrent, timeout)
+	wt.AbortOnFailure = true
+	return wt
+}
+
 type busyTask struct {
 	resultEvent event.Event
 	duration    time.Duration
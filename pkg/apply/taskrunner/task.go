@@ -4,6 +4,7 @@
 package taskrunner
 
 import (
+	"fmt"
 	"time"
 
 	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
@@ -48,6 +49,15 @@ type WaitTask struct {
 	// Timeout defines how long we are willing to wait for the condition
 	// to be met.
 	Timeout time.Duration
+	// WaitConditions contains the wait conditions declared on individual
+	// resources. They replace the Current status requirement of the
+	// AllCurrent condition for those resources, and are ignored for
+	// other conditions.
+	WaitConditions map[object.ObjMetadata]WaitCondition
+	// AbortOnFailure defines whether the task should fail as soon as
+	// one of the resources has failed to apply or has the Failed
+	// status, rather than skipping it or waiting for the timeout.
+	AbortOnFailure bool
 
 	// cancelFunc is a function that will cancel the timeout timer
 	// on the task.
@@ -84,9 +94,10 @@ func (w *WaitTask) setTimer(taskContext *TaskContext) {
 		case <-w.token:
 			taskContext.TaskChannel() <- TaskResult{
 				Err: &TimeoutError{
-					Identifiers: w.Identifiers,
-					Timeout:     w.Timeout,
-					Condition:   w.Condition,
+					Identifiers:    w.Identifiers,
+					Timeout:        w.Timeout,
+					Condition:      w.Condition,
+					WaitConditions: w.waitConditions(),
 				},
 			}
 		default:
@@ -105,6 +116,32 @@ func (w *WaitTask) checkCondition(taskContext *TaskContext, coll *resourceStatus
 	return coll.conditionMet(rwd, w.Condition)
 }
 
+// checkFailure returns an error if AbortOnFailure is set and one of
+// the resources has failed to apply, or has the Failed status after
+// it was applied.
+func (w *WaitTask) checkFailure(taskContext *TaskContext, coll *resourceStatusCollector) error {
+	if !w.AbortOnFailure {
+		return nil
+	}
+	for _, id := range w.Identifiers {
+		if taskContext.ResourceFailed(id) {
+			return &ResourceFailedError{
+				Identifier: id,
+				Message:    "failed to apply",
+			}
+		}
+		gen, _ := taskContext.ResourceGeneration(id)
+		rs, found := coll.resourceMap[id]
+		if found && rs.Generation >= gen && rs.CurrentStatus == status.FailedStatus {
+			return &ResourceFailedError{
+				Identifier: id,
+				Message:    rs.Message,
+			}
+		}
+	}
+	return nil
+}
+
 // computeResourceWaitData creates a slice of resourceWaitData for
 // the resources that is relevant to this wait task. The objective is
 // to match each resource with the generation seen after the resource
@@ -116,14 +153,34 @@ func (w *WaitTask) computeResourceWaitData(taskContext *TaskContext) []resourceW
 			continue
 		}
 		gen, _ := taskContext.ResourceGeneration(id)
-		rwd = append(rwd, resourceWaitData{
+		wd := resourceWaitData{
 			identifier: id,
 			generation: gen,
-		})
+		}
+		if wc, found := w.waitConditions()[id]; found {
+			wd.waitCondition = &wc
+		}
+		rwd = append(rwd, wd)
 	}
 	return rwd
 }
 
+// waitConditions returns the per-resource wait conditions that
+// apply for the condition of the task.
+func (w *WaitTask) waitConditions() map[object.ObjMetadata]WaitCondition {
+	if w.Condition != AllCurrent {
+		return nil
+	}
+	return w.WaitConditions
+}
+
+// startAndFail is invoked when one of the resources has already
+// failed when the task should be started.
+func (w *WaitTask) startAndFail(taskContext *TaskContext, err error) {
+	w.cancelFunc = func() {}
+	w.fail(taskContext, err)
+}
+
 // startAndComplete is invoked when the condition is already
 // met when the task should be started. In this case there is no
 // need to start a timer. So it just sets the cancelFunc and then
@@ -148,14 +205,130 @@ func (w *WaitTask) complete(taskContext *TaskContext) {
 	}
 }
 
+// fail is invoked by the taskrunner when AbortOnFailure is set and one
+// of the resources has failed.
+func (w *WaitTask) fail(taskContext *TaskContext, err error) {
+	select {
+	// Only do something if we can get the token.
+	case <-w.token:
+		go func() {
+			taskContext.TaskChannel() <- TaskResult{
+				Err: err,
+			}
+		}()
+	default:
+		return
+	}
+}
+
 // ClearTimeout cancels the timeout for the wait task.
 func (w *WaitTask) ClearTimeout() {
 	w.cancelFunc()
 }
 
+// NewSoakTask creates a new soak task that waits for the given
+// duration while the resources specified by ids keep running.
+func NewSoakTask(ids []object.ObjMetadata, duration time.Duration) *SoakTask {
+	// Create the token channel and only add one item.
+	tokenChannel := make(chan struct{}, 1)
+	tokenChannel <- struct{}{}
+
+	return &SoakTask{
+		Identifiers: ids,
+		Duration:    duration,
+
+		token: tokenChannel,
+	}
+}
+
+// SoakTask is an implementation of the Task interface that waits for a
+// fixed duration after a set of resources has been applied and has
+// become Current, so problems with the resources can show up before
+// anything else is applied. The task fails if any of the resources gets
+// the Failed status before the duration has passed. Like the wait task,
+// the soak task is handled by the taskrunner, so it can be interrupted.
+type SoakTask struct {
+	// Identifiers is the list of resources that are watched during
+	// the soak.
+	Identifiers []object.ObjMetadata
+	// Duration is how long the soak lasts.
+	Duration time.Duration
+
+	// cancelFunc is a function that will cancel the timer on the task.
+	cancelFunc func()
+
+	// token makes sure that the task only results in one message on
+	// the taskChannel. See the WaitTask for details.
+	token chan struct{}
+}
+
+// Start kicks off the task by setting up the timer. The task completes
+// when the timer expires.
+func (s *SoakTask) Start(taskContext *TaskContext) {
+	// The function runs in its own goroutine once the timer expires, so
+	// nothing is left behind if the timer is stopped before that.
+	timer := time.AfterFunc(s.Duration, func() {
+		s.complete(taskContext)
+	})
+	s.cancelFunc = func() {
+		timer.Stop()
+	}
+}
+
+// checkFailure returns an error if one of the resources has the
+// Failed status.
+func (s *SoakTask) checkFailure(coll *resourceStatusCollector) error {
+	for _, id := range s.Identifiers {
+		rs, found := coll.resourceMap[id]
+		if found && rs.CurrentStatus == status.FailedStatus {
+			return &ResourceFailedError{
+				Identifier: id,
+				Message:    fmt.Sprintf("Failed status during soak: %s", rs.Message),
+			}
+		}
+	}
+	return nil
+}
+
+// complete is invoked when the soak has lasted for the full duration,
+// or by the taskrunner when the task needs to be stopped.
+func (s *SoakTask) complete(taskContext *TaskContext) {
+	select {
+	// Only do something if we can get the token.
+	case <-s.token:
+		go func() {
+			taskContext.TaskChannel() <- TaskResult{}
+		}()
+	default:
+		return
+	}
+}
+
+// fail is invoked by the taskrunner when one of the resources has
+// failed during the soak.
+func (s *SoakTask) fail(taskContext *TaskContext, err error) {
+	select {
+	// Only do something if we can get the token.
+	case <-s.token:
+		go func() {
+			taskContext.TaskChannel() <- TaskResult{
+				Err: err,
+			}
+		}()
+	default:
+		return
+	}
+}
+
+// ClearTimeout cancels the timer for the soak task.
+func (s *SoakTask) ClearTimeout() {
+	s.cancelFunc()
+}
+
 type resourceWaitData struct {
-	identifier object.ObjMetadata
-	generation int64
+	identifier    object.ObjMetadata
+	generation    int64
+	waitCondition *WaitCondition
 }
 
 // Condition is a type that defines the types of conditions
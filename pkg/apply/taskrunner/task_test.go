@@ -83,3 +83,42 @@ func TestWaitTask_SingleTaskResult(t *testing.T) {
 		return
 	}
 }
+
+func TestSoakTask_Completed(t *testing.T) {
+	task := NewSoakTask([]object.ObjMetadata{}, 100*time.Millisecond)
+
+	eventChannel := make(chan event.Event)
+	taskContext := NewTaskContext(eventChannel)
+	defer close(eventChannel)
+
+	task.Start(taskContext)
+	timer := time.NewTimer(2 * time.Second)
+
+	select {
+	case res := <-taskContext.TaskChannel():
+		if res.Err != nil {
+			t.Errorf("expected soak to complete, but got %v", res.Err)
+		}
+	case <-timer.C:
+		t.Errorf("expected soak to complete, but it didn't")
+	}
+}
+
+func TestSoakTask_TimeoutCancelled(t *testing.T) {
+	task := NewSoakTask([]object.ObjMetadata{}, 100*time.Millisecond)
+
+	eventChannel := make(chan event.Event)
+	taskContext := NewTaskContext(eventChannel)
+	defer close(eventChannel)
+
+	task.Start(taskContext)
+	task.ClearTimeout()
+	timer := time.NewTimer(1 * time.Second)
+
+	select {
+	case res := <-taskContext.TaskChannel():
+		t.Errorf("didn't expect soak to complete, but got %v", res)
+	case <-timer.C:
+		return
+	}
+}
@@ -0,0 +1,247 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package taskrunner
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// WaitConditionType defines the different kinds of wait conditions
+// that can be set on individual resources.
+type WaitConditionType string
+
+const (
+	// WaitForExists means we only wait until the resource
+	// exists in the cluster.
+	WaitForExists WaitConditionType = "exists"
+
+	// WaitForCurrent means we wait until the resource has the
+	// Current status. This is the default.
+	WaitForCurrent WaitConditionType = "current"
+
+	// WaitForCondition means we wait until the resource has a
+	// condition of the given type with the given status.
+	WaitForCondition WaitConditionType = "condition"
+
+	// WaitForJSONPath means we wait until the JSONPath expression
+	// evaluates to the given value.
+	WaitForJSONPath WaitConditionType = "jsonpath"
+)
+
+// WaitCondition is the condition a WaitTask uses for a single
+// resource, as declared by the common.WaitConditionAnnotation.
+// The supported values for the annotation are:
+//   exists
+//   current
+//   condition=<type>[=<status>]   (status defaults to True)
+//   jsonpath=<expression>[=<value>]
+// A JSONPath expression without a value is met when the expression
+// evaluates to a non-empty result.
+type WaitCondition struct {
+	Type WaitConditionType
+
+	// ConditionType and ConditionStatus are only used with the
+	// WaitForCondition type.
+	ConditionType   string
+	ConditionStatus corev1.ConditionStatus
+
+	// JSONPath and Value are only used with the WaitForJSONPath type.
+	JSONPath string
+	Value    string
+}
+
+// ParseWaitCondition parses the value of the wait condition annotation.
+func ParseWaitCondition(s string) (WaitCondition, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case s == string(WaitForExists):
+		return WaitCondition{Type: WaitForExists}, nil
+	case s == string(WaitForCurrent):
+		return WaitCondition{Type: WaitForCurrent}, nil
+	case strings.HasPrefix(s, string(WaitForCondition)+"="):
+		parts := strings.SplitN(strings.TrimPrefix(s, string(WaitForCondition)+"="), "=", 2)
+		wc := WaitCondition{
+			Type:            WaitForCondition,
+			ConditionType:   parts[0],
+			ConditionStatus: corev1.ConditionTrue,
+		}
+		if len(parts) == 2 {
+			wc.ConditionStatus = corev1.ConditionStatus(parts[1])
+		}
+		if wc.ConditionType == "" {
+			return WaitCondition{}, fmt.Errorf("wait condition %q is missing the condition type", s)
+		}
+		switch wc.ConditionStatus {
+		case corev1.ConditionTrue, corev1.ConditionFalse, corev1.ConditionUnknown:
+		default:
+			return WaitCondition{}, fmt.Errorf("wait condition %q has invalid condition status %q",
+				s, wc.ConditionStatus)
+		}
+		return wc, nil
+	case strings.HasPrefix(s, string(WaitForJSONPath)+"="):
+		expr := strings.TrimPrefix(s, string(WaitForJSONPath)+"=")
+		// The expression ends with the brace that closes the opening
+		// one, so anything after that must be the expected value.
+		end := closingBrace(expr)
+		if end == -1 {
+			return WaitCondition{}, fmt.Errorf("wait condition %q must have a JSONPath expression "+
+				"enclosed in braces", s)
+		}
+		wc := WaitCondition{
+			Type:     WaitForJSONPath,
+			JSONPath: expr[:end+1],
+		}
+		rest := expr[end+1:]
+		if rest != "" {
+			if !strings.HasPrefix(rest, "=") {
+				return WaitCondition{}, fmt.Errorf("wait condition %q has unexpected content after "+
+					"the JSONPath expression", s)
+			}
+			wc.Value = strings.TrimPrefix(rest, "=")
+		}
+		if err := jsonpath.New("wait").Parse(wc.JSONPath); err != nil {
+			return WaitCondition{}, fmt.Errorf("wait condition %q has invalid JSONPath expression: %v", s, err)
+		}
+		return wc, nil
+	default:
+		return WaitCondition{}, fmt.Errorf("unknown wait condition %q", s)
+	}
+}
+
+// closingBrace returns the index of the brace that closes the brace
+// at the start of the JSONPath expression, or -1 if the expression
+// doesn't start with a brace or it is never closed. Braces inside
+// quoted strings in the expression are ignored.
+func closingBrace(expr string) int {
+	if !strings.HasPrefix(expr, "{") {
+		return -1
+	}
+	depth := 0
+	var quote byte
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+		if quote != 0 {
+			switch c {
+			case '\\':
+				i++
+			case quote:
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '"', '\'':
+			quote = c
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// String returns the wait condition in the same format as
+// used in the annotation.
+func (wc WaitCondition) String() string {
+	switch wc.Type {
+	case WaitForCondition:
+		return fmt.Sprintf("%s=%s=%s", wc.Type, wc.ConditionType, wc.ConditionStatus)
+	case WaitForJSONPath:
+		if wc.Value == "" {
+			return fmt.Sprintf("%s=%s", wc.Type, wc.JSONPath)
+		}
+		return fmt.Sprintf("%s=%s=%s", wc.Type, wc.JSONPath, wc.Value)
+	default:
+		return string(wc.Type)
+	}
+}
+
+// WaitConditionsForObjects looks up the wait condition annotation on
+// the provided resources. Resources without the annotation are not
+// included in the returned map.
+func WaitConditionsForObjects(objs []*unstructured.Unstructured) (map[object.ObjMetadata]WaitCondition, error) {
+	waitConditions := make(map[object.ObjMetadata]WaitCondition)
+	for _, obj := range objs {
+		value, found := obj.GetAnnotations()[common.WaitConditionAnnotation]
+		if !found {
+			continue
+		}
+		wc, err := ParseWaitCondition(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s annotation on %s/%s: %v",
+				common.WaitConditionAnnotation, obj.GetKind(), obj.GetName(), err)
+		}
+		waitConditions[object.UnstructuredToObjMeta(obj)] = wc
+	}
+	return waitConditions, nil
+}
+
+// met checks whether the wait condition holds for the latest seen
+// status of a resource.
+func (wc WaitCondition) met(rs resourceStatus) bool {
+	switch wc.Type {
+	case WaitForExists:
+		return rs.CurrentStatus != status.NotFoundStatus &&
+			rs.CurrentStatus != status.UnknownStatus
+	case WaitForCondition:
+		return rs.Resource != nil && hasCondition(rs.Resource, wc.ConditionType, wc.ConditionStatus)
+	case WaitForJSONPath:
+		if rs.Resource == nil {
+			return false
+		}
+		value, found := evaluateJSONPath(rs.Resource, wc.JSONPath)
+		if !found {
+			return false
+		}
+		if wc.Value == "" {
+			return value != ""
+		}
+		return value == wc.Value
+	default:
+		return rs.CurrentStatus == status.CurrentStatus
+	}
+}
+
+// hasCondition checks whether the resource has a condition with the
+// given type and status.
+func hasCondition(u *unstructured.Unstructured, conditionType string, conditionStatus corev1.ConditionStatus) bool {
+	objWithConditions, err := status.GetObjectWithConditions(u.Object)
+	if err != nil {
+		return false
+	}
+	for _, c := range objWithConditions.Status.Conditions {
+		if c.Type == conditionType {
+			return c.Status == conditionStatus
+		}
+	}
+	return false
+}
+
+// evaluateJSONPath evaluates the JSONPath expression against the
+// resource. The second return value is false if the expression could
+// not be evaluated.
+func evaluateJSONPath(u *unstructured.Unstructured, expr string) (string, bool) {
+	jp := jsonpath.New("wait")
+	if err := jp.Parse(expr); err != nil {
+		return "", false
+	}
+	var buf bytes.Buffer
+	if err := jp.Execute(&buf, u.Object); err != nil {
+		return "", false
+	}
+	return buf.String(), true
+}
@@ -0,0 +1,142 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package taskrunner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cli-utils/pkg/common"
+)
+
+func TestParseWaitCondition(t *testing.T) {
+	testCases := map[string]struct {
+		value          string
+		expected       WaitCondition
+		expectedErrMsg string
+	}{
+		"exists": {
+			value:    "exists",
+			expected: WaitCondition{Type: WaitForExists},
+		},
+		"current": {
+			value:    " current ",
+			expected: WaitCondition{Type: WaitForCurrent},
+		},
+		"condition with default status": {
+			value: "condition=Ready",
+			expected: WaitCondition{
+				Type:            WaitForCondition,
+				ConditionType:   "Ready",
+				ConditionStatus: "True",
+			},
+		},
+		"condition with status": {
+			value: "condition=Degraded=False",
+			expected: WaitCondition{
+				Type:            WaitForCondition,
+				ConditionType:   "Degraded",
+				ConditionStatus: "False",
+			},
+		},
+		"condition with invalid status": {
+			value:          "condition=Ready=Yes",
+			expectedErrMsg: "invalid condition status",
+		},
+		"condition without type": {
+			value:          "condition=",
+			expectedErrMsg: "missing the condition type",
+		},
+		"jsonpath with value": {
+			value: "jsonpath={.status.phase}=Active",
+			expected: WaitCondition{
+				Type:     WaitForJSONPath,
+				JSONPath: "{.status.phase}",
+				Value:    "Active",
+			},
+		},
+		"jsonpath without value": {
+			value: "jsonpath={.status.loadBalancer.ingress[0].ip}",
+			expected: WaitCondition{
+				Type:     WaitForJSONPath,
+				JSONPath: "{.status.loadBalancer.ingress[0].ip}",
+			},
+		},
+		"jsonpath with value containing braces": {
+			value: `jsonpath={.data.config}={"mode": {"enabled": true}}`,
+			expected: WaitCondition{
+				Type:     WaitForJSONPath,
+				JSONPath: "{.data.config}",
+				Value:    `{"mode": {"enabled": true}}`,
+			},
+		},
+		"jsonpath with filter containing braces": {
+			value: `jsonpath={.status.conditions[?(@.reason=="}")].status}=True`,
+			expected: WaitCondition{
+				Type:     WaitForJSONPath,
+				JSONPath: `{.status.conditions[?(@.reason=="}")].status}`,
+				Value:    "True",
+			},
+		},
+		"jsonpath without closing brace": {
+			value:          "jsonpath={.status.phase",
+			expectedErrMsg: "enclosed in braces",
+		},
+		"jsonpath without braces": {
+			value:          "jsonpath=.status.phase",
+			expectedErrMsg: "enclosed in braces",
+		},
+		"unknown": {
+			value:          "ready",
+			expectedErrMsg: "unknown wait condition",
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			wc, err := ParseWaitCondition(tc.value)
+
+			if tc.expectedErrMsg != "" {
+				if !assert.Error(t, err) {
+					t.FailNow()
+				}
+				assert.Contains(t, err.Error(), tc.expectedErrMsg)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, wc)
+		})
+	}
+}
+
+func TestWaitConditionsForObjects(t *testing.T) {
+	withAnnotation := &unstructured.Unstructured{}
+	withAnnotation.SetAPIVersion("batch/v1")
+	withAnnotation.SetKind("Job")
+	withAnnotation.SetName("foo")
+	withAnnotation.SetAnnotations(map[string]string{
+		common.WaitConditionAnnotation: "exists",
+	})
+	withoutAnnotation := &unstructured.Unstructured{}
+	withoutAnnotation.SetAPIVersion("v1")
+	withoutAnnotation.SetKind("ConfigMap")
+	withoutAnnotation.SetName("bar")
+
+	waitConditions, err := WaitConditionsForObjects([]*unstructured.Unstructured{withAnnotation, withoutAnnotation})
+	assert.NoError(t, err)
+	assert.Len(t, waitConditions, 1)
+	for _, wc := range waitConditions {
+		assert.Equal(t, "exists", wc.String())
+	}
+
+	withAnnotation.SetAnnotations(map[string]string{
+		common.WaitConditionAnnotation: "bogus",
+	})
+	_, err = WaitConditionsForObjects([]*unstructured.Unstructured{withAnnotation})
+	if !assert.Error(t, err) {
+		t.FailNow()
+	}
+	assert.Contains(t, err.Error(), "Job/foo")
+}
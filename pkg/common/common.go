@@ -6,7 +6,9 @@ package common
 import (
 	"fmt"
 	"math/rand"
+	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 )
 
@@ -25,10 +27,32 @@ const (
 	// used as a suffix of the inventory object name. Example:
 	//   inventory-1e5824fb
 	InventoryHash = "cli-utils.sigs.k8s.io/inventory-hash"
+	// WaitConditionAnnotation is the annotation used to declare the
+	// condition kapply waits for after a resource has been applied.
+	// The value is one of "exists", "current" (the default),
+	// "condition=<type>[=<status>]" or "jsonpath=<expression>[=<value>]".
+	WaitConditionAnnotation = "cli-utils.sigs.k8s.io/wait-condition"
 	// Resource lifecycle annotation key for "on-remove" operations.
 	OnRemoveAnnotation = "cli-utils.sigs.k8s.io/on-remove"
 	// Resource lifecycle annotation value to prevent deletion.
 	OnRemoveKeep = "keep"
+	// OnImmutableChangeAnnotation is the resource lifecycle annotation
+	// key for what to do when applying a resource fails because an
+	// immutable field has been changed.
+	OnImmutableChangeAnnotation = "cli-utils.sigs.k8s.io/on-immutable-change"
+	// Resource lifecycle annotation value to delete the resource and
+	// create it again when an immutable field has been changed.
+	OnImmutableChangeRecreate = "recreate"
+	// HookAnnotation is the annotation used to declare a resource as
+	// a hook. The value is either "pre-apply" or "post-apply". Hooks
+	// are applied in their own phase, before or after the other
+	// resources, and are not stored in the inventory.
+	HookAnnotation = "cli-utils.sigs.k8s.io/hook"
+	// HookDeletePolicyAnnotation is the annotation used to declare when
+	// a hook is deleted. The value is a comma-separated list of
+	// "before-hook-creation" and "hook-succeeded". It defaults to
+	// "before-hook-creation".
+	HookDeletePolicyAnnotation = "cli-utils.sigs.k8s.io/hook-delete-policy"
 	// Maximum random number, non-inclusive, eight digits.
 	maxRandInt = 100000000
 	// DefaultFieldManager is default owner of applied fields in
@@ -64,6 +88,13 @@ func NoDeletion(key, value string) bool {
 	return false
 }
 
+// RecreateOnImmutableChange returns true if the annotations allow
+// the resource to be deleted and created again when an immutable
+// field has been changed.
+func RecreateOnImmutableChange(annotations map[string]string) bool {
+	return annotations[OnImmutableChangeAnnotation] == OnImmutableChangeRecreate
+}
+
 var Strategies = []DryRunStrategy{DryRunClient, DryRunServer}
 
 type DryRunStrategy int
@@ -127,3 +158,20 @@ type ServerSideOptions struct {
 	// FieldManager identifies the client "owner" of the applied fields (e.g. kubectl)
 	FieldManager string
 }
+
+// RecreateOptions encapsulates the fields to recreate resources when
+// applying them fails because an immutable field has been changed.
+type RecreateOptions struct {
+	// Recreate allows every resource to be deleted and created again
+	// if an immutable field has been changed. If false, only resources
+	// with the on-immutable-change annotation set to recreate are.
+	Recreate bool
+
+	// PropagationPolicy is the propagation policy used when deleting
+	// the resource.
+	PropagationPolicy metav1.DeletionPropagation
+
+	// Timeout is how long to wait for the resource to be deleted
+	// before it is created again.
+	Timeout time.Duration
+}
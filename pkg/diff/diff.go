@@ -0,0 +1,188 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package diff computes the changed fields between two versions of an
+// object, like the live object in the cluster and the object as it
+// would look after apply.
+//
+// Fields that are populated by the server, like the status and most of
+// the metadata managed by the API server, are ignored since they don't
+// reflect changes made by the user.
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ChangeType describes how a field has changed.
+type ChangeType string
+
+const (
+	// Added means the field doesn't exist in the old version.
+	Added ChangeType = "Added"
+	// Removed means the field doesn't exist in the new version.
+	Removed ChangeType = "Removed"
+	// Modified means the field exists in both versions, but the values
+	// are different.
+	Modified ChangeType = "Modified"
+)
+
+// Change is a single changed field. The path uses JSONPath syntax, like
+// .spec.template.spec.containers[0].image. Map keys that are not simple
+// identifiers use the bracket notation, like
+// .metadata.labels['app.kubernetes.io/name'].
+type Change struct {
+	Path string      `json:"path"`
+	Type ChangeType  `json:"type"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+// lastAppliedAnnotation is updated on every client-side apply, so it
+// would always show up as changed.
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// ignoredFields are the fields that are populated by the server and
+// removed before comparing.
+var ignoredFields = [][]string{
+	{"status"},
+	{"metadata", "managedFields"},
+	{"metadata", "resourceVersion"},
+	{"metadata", "uid"},
+	{"metadata", "selfLink"},
+	{"metadata", "creationTimestamp"},
+	{"metadata", "generation"},
+	{"metadata", "annotations", lastAppliedAnnotation},
+}
+
+var identifierRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*$`)
+
+// Normalize returns a copy of the object without the fields that are
+// populated by the server. It returns nil if the object is nil.
+func Normalize(obj *unstructured.Unstructured) *unstructured.Unstructured {
+	if obj == nil {
+		return nil
+	}
+	u := obj.DeepCopy()
+	for _, fields := range ignoredFields {
+		unstructured.RemoveNestedField(u.Object, fields...)
+	}
+	// Don't leave an empty annotations map behind if we removed the
+	// last-applied annotation.
+	if annotations, found, _ := unstructured.NestedMap(u.Object, "metadata", "annotations"); found &&
+		len(annotations) == 0 {
+		unstructured.RemoveNestedField(u.Object, "metadata", "annotations")
+	}
+	return u
+}
+
+// Compare returns the changed fields between the old and new versions
+// of an object, sorted by path. Fields populated by the server are
+// ignored. If either of the objects is nil, the object is being created
+// or deleted and no changes are returned.
+func Compare(oldObj, newObj *unstructured.Unstructured) []Change {
+	if oldObj == nil || newObj == nil {
+		return nil
+	}
+	var changes []Change
+	compareValues("", Normalize(oldObj).Object, Normalize(newObj).Object, &changes)
+	return changes
+}
+
+func compareValues(path string, oldValue, newValue interface{}, changes *[]Change) {
+	switch oldTyped := oldValue.(type) {
+	case map[string]interface{}:
+		if newTyped, ok := newValue.(map[string]interface{}); ok {
+			compareMaps(path, oldTyped, newTyped, changes)
+			return
+		}
+	case []interface{}:
+		if newTyped, ok := newValue.([]interface{}); ok {
+			compareSlices(path, oldTyped, newTyped, changes)
+			return
+		}
+	}
+	if !reflect.DeepEqual(oldValue, newValue) {
+		*changes = append(*changes, Change{
+			Path: path,
+			Type: Modified,
+			Old:  oldValue,
+			New:  newValue,
+		})
+	}
+}
+
+func compareMaps(path string, oldMap, newMap map[string]interface{}, changes *[]Change) {
+	keys := make(map[string]bool)
+	for k := range oldMap {
+		keys[k] = true
+	}
+	for k := range newMap {
+		keys[k] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	for _, k := range sorted {
+		fieldPath := path + KeySegment(k)
+		oldValue, inOld := oldMap[k]
+		newValue, inNew := newMap[k]
+		switch {
+		case !inOld:
+			*changes = append(*changes, Change{
+				Path: fieldPath,
+				Type: Added,
+				New:  newValue,
+			})
+		case !inNew:
+			*changes = append(*changes, Change{
+				Path: fieldPath,
+				Type: Removed,
+				Old:  oldValue,
+			})
+		default:
+			compareValues(fieldPath, oldValue, newValue, changes)
+		}
+	}
+}
+
+// compareSlices compares the elements by index. Elements beyond the
+// length of the other slice are reported as added or removed.
+func compareSlices(path string, oldSlice, newSlice []interface{}, changes *[]Change) {
+	for i := 0; i < len(oldSlice) || i < len(newSlice); i++ {
+		elemPath := fmt.Sprintf("%s[%d]", path, i)
+		switch {
+		case i >= len(oldSlice):
+			*changes = append(*changes, Change{
+				Path: elemPath,
+				Type: Added,
+				New:  newSlice[i],
+			})
+		case i >= len(newSlice):
+			*changes = append(*changes, Change{
+				Path: elemPath,
+				Type: Removed,
+				Old:  oldSlice[i],
+			})
+		default:
+			compareValues(elemPath, oldSlice[i], newSlice[i], changes)
+		}
+	}
+}
+
+// KeySegment returns the path segment for a map key, using the bracket
+// notation if the key is not a simple identifier.
+func KeySegment(key string) string {
+	if identifierRegexp.MatchString(key) {
+		return "." + key
+	}
+	return fmt.Sprintf("['%s']", key)
+}
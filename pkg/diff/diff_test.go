@@ -0,0 +1,130 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func deployment(spec map[string]interface{}, metadata map[string]interface{}) *unstructured.Unstructured {
+	md := map[string]interface{}{
+		"name":      "foo",
+		"namespace": "default",
+	}
+	for k, v := range metadata {
+		md[k] = v
+	}
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   md,
+			"spec":       spec,
+		},
+	}
+}
+
+func TestCompare(t *testing.T) {
+	testCases := map[string]struct {
+		oldObj          *unstructured.Unstructured
+		newObj          *unstructured.Unstructured
+		expectedChanges []Change
+	}{
+		"nil objects": {
+			oldObj:          nil,
+			newObj:          deployment(nil, nil),
+			expectedChanges: nil,
+		},
+		"no changes": {
+			oldObj:          deployment(map[string]interface{}{"replicas": int64(1)}, nil),
+			newObj:          deployment(map[string]interface{}{"replicas": int64(1)}, nil),
+			expectedChanges: nil,
+		},
+		"modified, added and removed fields": {
+			oldObj: deployment(map[string]interface{}{
+				"replicas": int64(1),
+				"paused":   true,
+			}, nil),
+			newObj: deployment(map[string]interface{}{
+				"replicas":        int64(3),
+				"minReadySeconds": int64(10),
+			}, nil),
+			expectedChanges: []Change{
+				{Path: ".spec.minReadySeconds", Type: Added, New: int64(10)},
+				{Path: ".spec.paused", Type: Removed, Old: true},
+				{Path: ".spec.replicas", Type: Modified, Old: int64(1), New: int64(3)},
+			},
+		},
+		"lists are compared by index": {
+			oldObj: deployment(map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{"name": "app", "image": "app:v1"},
+				},
+			}, nil),
+			newObj: deployment(map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{"name": "app", "image": "app:v2"},
+					map[string]interface{}{"name": "sidecar"},
+				},
+			}, nil),
+			expectedChanges: []Change{
+				{Path: ".spec.containers[0].image", Type: Modified, Old: "app:v1", New: "app:v2"},
+				{Path: ".spec.containers[1]", Type: Added, New: map[string]interface{}{"name": "sidecar"}},
+			},
+		},
+		"keys that are not identifiers": {
+			oldObj: deployment(nil, map[string]interface{}{
+				"labels": map[string]interface{}{"app.kubernetes.io/name": "foo"},
+			}),
+			newObj: deployment(nil, map[string]interface{}{
+				"labels": map[string]interface{}{"app.kubernetes.io/name": "bar"},
+			}),
+			expectedChanges: []Change{
+				{Path: ".metadata.labels['app.kubernetes.io/name']", Type: Modified, Old: "foo", New: "bar"},
+			},
+		},
+		"server populated fields are ignored": {
+			oldObj: func() *unstructured.Unstructured {
+				u := deployment(nil, map[string]interface{}{
+					"resourceVersion":   "1",
+					"uid":               "abc",
+					"generation":        int64(1),
+					"creationTimestamp": "2020-01-01T00:00:00Z",
+					"managedFields":     []interface{}{map[string]interface{}{"manager": "a"}},
+				})
+				u.Object["status"] = map[string]interface{}{"replicas": int64(1)}
+				return u
+			}(),
+			newObj: deployment(nil, map[string]interface{}{
+				"resourceVersion": "2",
+				"generation":      int64(2),
+				"managedFields":   []interface{}{map[string]interface{}{"manager": "b"}},
+				"annotations": map[string]interface{}{
+					lastAppliedAnnotation: "{}",
+				},
+			}),
+			expectedChanges: nil,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			changes := Compare(tc.oldObj, tc.newObj)
+			assert.Equal(t, tc.expectedChanges, changes)
+		})
+	}
+}
+
+func TestNormalizeDoesNotModifyInput(t *testing.T) {
+	u := deployment(nil, map[string]interface{}{
+		"resourceVersion": "1",
+	})
+	normalized := Normalize(u)
+	assert.Equal(t, "1", u.GetResourceVersion())
+	assert.Equal(t, "", normalized.GetResourceVersion())
+	assert.Nil(t, Normalize(nil))
+}
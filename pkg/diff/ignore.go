@@ -0,0 +1,300 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package diff
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// IgnoreAnnotation can be set on an object to ignore changes to
+	// some of its fields. The value is a comma-separated list of
+	// JSONPath expressions, like ".spec.replicas,.metadata.labels['foo']".
+	IgnoreAnnotation = "cli-utils.sigs.k8s.io/diff-ignore"
+
+	// IgnoreFileName is the name of the file in the root of a package
+	// that contains the ignore rules for the package. It doesn't have a
+	// yaml extension, so it is not read as a manifest.
+	IgnoreFileName = ".diffignore"
+
+	// IgnoreAPIVersion and IgnoreKind identify the ignore file.
+	IgnoreAPIVersion = "cli-utils.sigs.k8s.io/v1alpha1"
+	IgnoreKind       = "DiffIgnore"
+)
+
+// IgnoreRule lists fields that should be ignored. If Group or Kind is
+// set, the rule only applies to objects with the given group or kind.
+// An empty group and kind applies the rule to all objects. Use "core"
+// for the group of the core types, like ConfigMap.
+type IgnoreRule struct {
+	Group string   `json:"group,omitempty"`
+	Kind  string   `json:"kind,omitempty"`
+	Paths []string `json:"paths"`
+}
+
+// IgnoreFile is the content of the ignore file, for example:
+//
+//	apiVersion: cli-utils.sigs.k8s.io/v1alpha1
+//	kind: DiffIgnore
+//	rules:
+//	- group: apps
+//	  kind: Deployment
+//	  paths:
+//	  - .spec.replicas
+type IgnoreFile struct {
+	APIVersion string       `json:"apiVersion"`
+	Kind       string       `json:"kind"`
+	Rules      []IgnoreRule `json:"rules"`
+}
+
+// IgnoreRules is a set of rules for fields that should be ignored when
+// comparing objects.
+type IgnoreRules []IgnoreRule
+
+// ReadIgnoreFile reads the ignore rules from the file with the given
+// path.
+func ReadIgnoreFile(path string) (IgnoreRules, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f IgnoreFile
+	if err := yaml.UnmarshalStrict(b, &f); err != nil {
+		return nil, fmt.Errorf("error reading ignore file %s: %v", path, err)
+	}
+	if f.APIVersion != IgnoreAPIVersion || f.Kind != IgnoreKind {
+		return nil, fmt.Errorf("ignore file %s must have apiVersion %s and kind %s",
+			path, IgnoreAPIVersion, IgnoreKind)
+	}
+	for _, r := range f.Rules {
+		for _, p := range r.Paths {
+			if _, err := parsePath(p); err != nil {
+				return nil, fmt.Errorf("error reading ignore file %s: %v", path, err)
+			}
+		}
+	}
+	return f.Rules, nil
+}
+
+// LoadPackageIgnoreRules reads the ignore file from the root of the
+// package directory. It returns no rules if the file doesn't exist.
+func LoadPackageIgnoreRules(dir string) (IgnoreRules, error) {
+	rules, err := ReadIgnoreFile(filepath.Join(dir, IgnoreFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return rules, err
+}
+
+// PathsFor returns the paths that should be ignored for the object.
+// The objects are different versions of the same object, like the live
+// and the merged versions, and the paths from the ignore annotation on
+// any of them are included. Nil objects are skipped.
+func (r IgnoreRules) PathsFor(objs ...*unstructured.Unstructured) []string {
+	var paths []string
+	seen := make(map[string]bool)
+	add := func(p string) {
+		p = strings.TrimSpace(p)
+		if p != "" && !seen[p] {
+			seen[p] = true
+			paths = append(paths, p)
+		}
+	}
+	var gk schema.GroupKind
+	for _, obj := range objs {
+		if obj == nil {
+			continue
+		}
+		gk = obj.GroupVersionKind().GroupKind()
+		if value, found := obj.GetAnnotations()[IgnoreAnnotation]; found {
+			for _, p := range strings.Split(value, ",") {
+				add(p)
+			}
+		}
+	}
+	for _, rule := range r {
+		if !rule.matches(gk) {
+			continue
+		}
+		for _, p := range rule.Paths {
+			add(p)
+		}
+	}
+	return paths
+}
+
+func (rule IgnoreRule) matches(gk schema.GroupKind) bool {
+	if rule.Group != "" {
+		group := gk.Group
+		if group == "" {
+			group = "core"
+		}
+		if rule.Group != group {
+			return false
+		}
+	}
+	return rule.Kind == "" || rule.Kind == gk.Kind
+}
+
+// RemovePaths returns a copy of the object with the fields at the given
+// paths removed. It returns nil if the object is nil.
+func RemovePaths(obj *unstructured.Unstructured, paths []string) (*unstructured.Unstructured, error) {
+	if obj == nil {
+		return nil, nil
+	}
+	u := obj.DeepCopy()
+	for _, p := range paths {
+		segments, err := parsePath(p)
+		if err != nil {
+			return nil, err
+		}
+		removePath(u.Object, segments)
+	}
+	return u, nil
+}
+
+// CompareIgnoring is like Compare, but ignores the fields given by the
+// rules and the ignore annotations on the objects.
+func CompareIgnoring(oldObj, newObj *unstructured.Unstructured, rules IgnoreRules) ([]Change, error) {
+	paths := rules.PathsFor(oldObj, newObj)
+	oldStripped, err := RemovePaths(oldObj, paths)
+	if err != nil {
+		return nil, err
+	}
+	newStripped, err := RemovePaths(newObj, paths)
+	if err != nil {
+		return nil, err
+	}
+	return Compare(oldStripped, newStripped), nil
+}
+
+// segment is a single part of a path. It is either a map key, a list
+// index or a wildcard matching all keys or elements.
+type segment struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// parsePath parses the subset of JSONPath used for ignore rules: field
+// names (.spec.replicas), quoted keys (['app.kubernetes.io/name']),
+// list indexes ([0]) and wildcards (.* or [*]). The expression can be
+// wrapped in braces.
+func parsePath(path string) ([]segment, error) {
+	p := strings.TrimSpace(path)
+	if strings.HasPrefix(p, "{") && strings.HasSuffix(p, "}") {
+		p = p[1 : len(p)-1]
+	}
+	if !strings.HasPrefix(p, ".") && !strings.HasPrefix(p, "[") {
+		return nil, fmt.Errorf("invalid path %q: must start with '.' or '['", path)
+	}
+	var segments []segment
+	for len(p) > 0 {
+		switch p[0] {
+		case '.':
+			p = p[1:]
+			end := strings.IndexAny(p, ".[")
+			if end < 0 {
+				end = len(p)
+			}
+			name := p[:end]
+			if name == "" {
+				return nil, fmt.Errorf("invalid path %q: empty field name", path)
+			}
+			p = p[end:]
+			if name == "*" {
+				segments = append(segments, segment{wildcard: true})
+			} else {
+				segments = append(segments, segment{key: name})
+			}
+		case '[':
+			end := strings.Index(p, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid path %q: missing ']'", path)
+			}
+			inner := p[1:end]
+			p = p[end+1:]
+			switch {
+			case inner == "*":
+				segments = append(segments, segment{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				segments = append(segments, segment{key: inner[1 : len(inner)-1]})
+			default:
+				i, err := strconv.Atoi(inner)
+				if err != nil || i < 0 {
+					return nil, fmt.Errorf("invalid path %q: %q is not a quoted key, "+
+						"an index or a wildcard", path, inner)
+				}
+				segments = append(segments, segment{index: i, isIndex: true})
+			}
+		default:
+			return nil, fmt.Errorf("invalid path %q: unexpected %q", path, p[0])
+		}
+	}
+	return segments, nil
+}
+
+// removePath removes the field at the path from the value. Missing
+// fields are ignored.
+func removePath(value interface{}, segments []segment) {
+	if len(segments) == 0 {
+		return
+	}
+	seg, rest := segments[0], segments[1:]
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		if seg.isIndex {
+			return
+		}
+		if seg.wildcard {
+			for k := range typed {
+				if len(rest) == 0 {
+					delete(typed, k)
+				} else {
+					removePath(typed[k], rest)
+				}
+			}
+			return
+		}
+		if len(rest) == 0 {
+			delete(typed, seg.key)
+			return
+		}
+		if child, found := typed[seg.key]; found {
+			removePath(child, rest)
+		}
+	case []interface{}:
+		// Removing elements would shift the indexes of the remaining
+		// elements, so the last segment of a path into a list clears
+		// the element rather than removing it.
+		if seg.wildcard {
+			for i := range typed {
+				if len(rest) == 0 {
+					typed[i] = nil
+				} else {
+					removePath(typed[i], rest)
+				}
+			}
+			return
+		}
+		if !seg.isIndex || seg.index >= len(typed) {
+			return
+		}
+		if len(rest) == 0 {
+			typed[seg.index] = nil
+			return
+		}
+		removePath(typed[seg.index], rest)
+	}
+}
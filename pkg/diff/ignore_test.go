@@ -0,0 +1,277 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package diff
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestReadIgnoreFile(t *testing.T) {
+	testCases := map[string]struct {
+		content       string
+		expectedRules IgnoreRules
+		expectedErr   bool
+	}{
+		"valid file": {
+			content: `
+apiVersion: cli-utils.sigs.k8s.io/v1alpha1
+kind: DiffIgnore
+rules:
+- group: apps
+  kind: Deployment
+  paths:
+  - .spec.replicas
+- paths:
+  - .metadata.annotations['deployment.kubernetes.io/revision']
+`,
+			expectedRules: IgnoreRules{
+				{
+					Group: "apps",
+					Kind:  "Deployment",
+					Paths: []string{".spec.replicas"},
+				},
+				{
+					Paths: []string{".metadata.annotations['deployment.kubernetes.io/revision']"},
+				},
+			},
+		},
+		"wrong kind": {
+			content: `
+apiVersion: cli-utils.sigs.k8s.io/v1alpha1
+kind: ConfigMap
+`,
+			expectedErr: true,
+		},
+		"unknown field": {
+			content: `
+apiVersion: cli-utils.sigs.k8s.io/v1alpha1
+kind: DiffIgnore
+rules:
+- kinds: Deployment
+`,
+			expectedErr: true,
+		},
+		"invalid path": {
+			content: `
+apiVersion: cli-utils.sigs.k8s.io/v1alpha1
+kind: DiffIgnore
+rules:
+- paths:
+  - spec.replicas
+`,
+			expectedErr: true,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "diff-ignore-test")
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer os.RemoveAll(dir)
+			err = ioutil.WriteFile(filepath.Join(dir, IgnoreFileName), []byte(tc.content), 0600)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			rules, err := LoadPackageIgnoreRules(dir)
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedRules, rules)
+		})
+	}
+}
+
+func TestLoadPackageIgnoreRulesMissingFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "diff-ignore-test")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	rules, err := LoadPackageIgnoreRules(dir)
+	assert.NoError(t, err)
+	assert.Nil(t, rules)
+}
+
+func TestPathsFor(t *testing.T) {
+	rules := IgnoreRules{
+		{Group: "apps", Kind: "Deployment", Paths: []string{".spec.replicas"}},
+		{Kind: "ConfigMap", Paths: []string{".data.generated"}},
+		{Group: "core", Paths: []string{".metadata.labels.core"}},
+		{Paths: []string{".metadata.labels.all"}},
+	}
+	configMap := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name": "foo",
+				"annotations": map[string]interface{}{
+					IgnoreAnnotation: ".data.a, .data.b",
+				},
+			},
+		},
+	}
+
+	testCases := map[string]struct {
+		objs          []*unstructured.Unstructured
+		expectedPaths []string
+	}{
+		"rules scoped by group and kind": {
+			objs: []*unstructured.Unstructured{deployment(nil, nil)},
+			expectedPaths: []string{
+				".spec.replicas",
+				".metadata.labels.all",
+			},
+		},
+		"core group and annotation": {
+			objs: []*unstructured.Unstructured{configMap},
+			expectedPaths: []string{
+				".data.a",
+				".data.b",
+				".data.generated",
+				".metadata.labels.core",
+				".metadata.labels.all",
+			},
+		},
+		"nil objects are skipped": {
+			objs: []*unstructured.Unstructured{nil, deployment(nil, map[string]interface{}{
+				"annotations": map[string]interface{}{
+					IgnoreAnnotation: ".spec.replicas,.spec.paused",
+				},
+			})},
+			expectedPaths: []string{
+				".spec.replicas",
+				".spec.paused",
+				".metadata.labels.all",
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			assert.Equal(t, tc.expectedPaths, rules.PathsFor(tc.objs...))
+		})
+	}
+}
+
+func TestRemovePaths(t *testing.T) {
+	obj := deployment(map[string]interface{}{
+		"replicas": int64(3),
+		"template": map[string]interface{}{
+			"spec": map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{"name": "app", "image": "app:v1"},
+					map[string]interface{}{"name": "sidecar", "image": "sidecar:v1"},
+				},
+			},
+		},
+	}, map[string]interface{}{
+		"labels": map[string]interface{}{
+			"app.kubernetes.io/name": "foo",
+			"keep":                   "yes",
+		},
+	})
+
+	testCases := map[string]struct {
+		paths       []string
+		check       func(t *testing.T, u *unstructured.Unstructured)
+		expectedErr bool
+	}{
+		"field": {
+			paths: []string{".spec.replicas"},
+			check: func(t *testing.T, u *unstructured.Unstructured) {
+				_, found, _ := unstructured.NestedInt64(u.Object, "spec", "replicas")
+				assert.False(t, found)
+			},
+		},
+		"quoted key in braces": {
+			paths: []string{"{.metadata.labels['app.kubernetes.io/name']}"},
+			check: func(t *testing.T, u *unstructured.Unstructured) {
+				assert.Equal(t, map[string]string{"keep": "yes"}, u.GetLabels())
+			},
+		},
+		"wildcard in list": {
+			paths: []string{".spec.template.spec.containers[*].image"},
+			check: func(t *testing.T, u *unstructured.Unstructured) {
+				containers, _, _ := unstructured.NestedSlice(u.Object, "spec", "template", "spec", "containers")
+				assert.Equal(t, []interface{}{
+					map[string]interface{}{"name": "app"},
+					map[string]interface{}{"name": "sidecar"},
+				}, containers)
+			},
+		},
+		"list index": {
+			paths: []string{".spec.template.spec.containers[1]"},
+			check: func(t *testing.T, u *unstructured.Unstructured) {
+				containers, _, _ := unstructured.NestedSlice(u.Object, "spec", "template", "spec", "containers")
+				assert.Equal(t, []interface{}{
+					map[string]interface{}{"name": "app", "image": "app:v1"},
+					nil,
+				}, containers)
+			},
+		},
+		"missing fields are ignored": {
+			paths: []string{".spec.paused", ".spec.template.spec.containers[5].image", ".spec.replicas.foo"},
+			check: func(t *testing.T, u *unstructured.Unstructured) {
+				assert.Equal(t, obj, u)
+			},
+		},
+		"invalid path": {
+			paths:       []string{".spec[foo]"},
+			expectedErr: true,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			u, err := RemovePaths(obj, tc.paths)
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			tc.check(t, u)
+		})
+	}
+}
+
+func TestCompareIgnoring(t *testing.T) {
+	oldObj := deployment(map[string]interface{}{
+		"replicas": int64(1),
+		"paused":   false,
+	}, nil)
+	newObj := deployment(map[string]interface{}{
+		"replicas": int64(5),
+		"paused":   true,
+	}, map[string]interface{}{
+		"annotations": map[string]interface{}{
+			IgnoreAnnotation: ".spec.paused,.metadata.annotations",
+		},
+	})
+	rules := IgnoreRules{
+		{Group: "apps", Kind: "Deployment", Paths: []string{".spec.replicas"}},
+	}
+
+	changes, err := CompareIgnoring(oldObj, newObj, rules)
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+
+	changes, err = CompareIgnoring(oldObj, newObj, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []Change{
+		{Path: ".spec.replicas", Type: Modified, Old: int64(1), New: int64(5)},
+	}, changes)
+}
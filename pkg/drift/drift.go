@@ -0,0 +1,460 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package drift detects objects that have drifted from the state they
+// were applied in. It compares the inventory and the local objects of
+// a package against the live objects in the cluster, and reports
+// objects that have been deleted out of band, fields that are now
+// owned by other field managers, objects where the owning inventory
+// annotation has been changed, and objects that belong to the package
+// but are not in the inventory.
+package drift
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-errors/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/diff"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/provider"
+)
+
+// Options contains the settings for drift detection.
+type Options struct {
+	// FieldManagers are the field managers used when applying the
+	// package. Fields set by the package that are owned by other field
+	// managers are reported as changed.
+	FieldManagers []string
+
+	// IgnoreRules lists fields that are not checked for changes, in
+	// addition to the fields listed in the ignore annotation on each
+	// object.
+	IgnoreRules diff.IgnoreRules
+
+	// Selector matches the objects that belong to the package. Objects
+	// that match it but are not in the inventory are reported. If nil,
+	// only objects with the owning inventory annotation for the
+	// inventory are reported.
+	Selector labels.Selector
+}
+
+// NewDetector returns a new Detector.
+func NewDetector(provider provider.Provider) *Detector {
+	return &Detector{
+		provider: provider,
+	}
+}
+
+// Detector compares the inventory and the local objects of a package
+// against the live objects in the cluster.
+type Detector struct {
+	provider  provider.Provider
+	invClient inventory.InventoryClient
+	mapper    meta.RESTMapper
+	client    dynamic.Interface
+}
+
+// Initialize sets up the clients needed for talking to the cluster.
+func (d *Detector) Initialize() error {
+	var err error
+	d.invClient, err = d.provider.InventoryClient()
+	if err != nil {
+		return err
+	}
+	d.mapper, err = d.provider.Factory().ToRESTMapper()
+	if err != nil {
+		return errors.WrapPrefix(err, "error creating rest mapper", 1)
+	}
+	d.client, err = d.provider.Factory().DynamicClient()
+	if err != nil {
+		return errors.WrapPrefix(err, "error creating dynamic client", 1)
+	}
+	return nil
+}
+
+// Run checks every object in the inventory for drift, and then looks
+// for objects that belong to the package but are not in the inventory.
+// It returns a channel with a drift event for every object, followed by
+// a completed event. Local objects are used for finding the fields
+// set by the package, so objects that are in the inventory but not in
+// the package are only checked for deletion and the owning inventory.
+func (d *Detector) Run(inv inventory.InventoryInfo, localObjs []*unstructured.Unstructured,
+	options Options) <-chan event.Event {
+	ch := make(chan event.Event)
+
+	go func() {
+		defer close(ch)
+		if err := d.run(ch, inv, localObjs, options); err != nil {
+			ch <- event.Event{
+				Type: event.ErrorType,
+				ErrorEvent: event.ErrorEvent{
+					Err: err,
+				},
+			}
+			return
+		}
+		ch <- event.Event{
+			Type: event.DriftType,
+			DriftEvent: event.DriftEvent{
+				Type: event.DriftEventCompleted,
+			},
+		}
+	}()
+	return ch
+}
+
+func (d *Detector) run(ch chan<- event.Event, inv inventory.InventoryInfo,
+	localObjs []*unstructured.Unstructured, options Options) error {
+	invIds, err := d.invClient.GetClusterObjs(inv)
+	if err != nil {
+		return errors.WrapPrefix(err, "error reading inventory", 1)
+	}
+	klog.V(4).Infof("checking %d inventory objects for drift", len(invIds))
+	local := make(map[object.ObjMetadata]*unstructured.Unstructured)
+	for _, obj := range localObjs {
+		local[object.UnstructuredToObjMeta(obj)] = obj
+	}
+	inInventory := make(map[object.ObjMetadata]bool)
+	for _, id := range invIds {
+		inInventory[id] = true
+		live, err := d.getObject(id)
+		if err != nil {
+			return err
+		}
+		de, err := checkObject(inv.ID(), id, local[id], live, options)
+		if err != nil {
+			return err
+		}
+		ch <- event.Event{
+			Type:       event.DriftType,
+			DriftEvent: de,
+		}
+	}
+
+	ids := object.Union(invIds, object.UnstructuredsToObjMetas(localObjs))
+	extra, err := d.findExtraObjects(inv.ID(), ids, inInventory, options.Selector)
+	if err != nil {
+		return err
+	}
+	for _, de := range extra {
+		ch <- event.Event{
+			Type:       event.DriftType,
+			DriftEvent: de,
+		}
+	}
+	return nil
+}
+
+// getObject returns the live object, or nil if it doesn't exist. An
+// object whose type no longer exists, like a custom resource after the
+// CRD has been deleted, is also treated as deleted.
+func (d *Detector) getObject(id object.ObjMetadata) (*unstructured.Unstructured, error) {
+	mapping, err := d.mapper.RESTMapping(id.GroupKind)
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	obj, err := d.resourceInterface(mapping, id.Namespace).Get(context.TODO(), id.Name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.WrapPrefix(err, fmt.Sprintf("error getting %s", id.String()), 1)
+	}
+	return obj, nil
+}
+
+func (d *Detector) resourceInterface(mapping *meta.RESTMapping, namespace string) dynamic.ResourceInterface {
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		return d.client.Resource(mapping.Resource).Namespace(namespace)
+	}
+	return d.client.Resource(mapping.Resource)
+}
+
+// scope is a type and namespace that is searched for objects that
+// are not in the inventory.
+type scope struct {
+	groupKind schema.GroupKind
+	namespace string
+}
+
+// findExtraObjects lists the objects of the same types and in the same
+// namespaces as the given objects, and returns an event for each object
+// that belongs to the package but is not in the inventory.
+func (d *Detector) findExtraObjects(invID string, ids []object.ObjMetadata,
+	inInventory map[object.ObjMetadata]bool, selector labels.Selector) ([]event.DriftEvent, error) {
+	scopes := make(map[scope]bool)
+	for _, id := range ids {
+		scopes[scope{groupKind: id.GroupKind, namespace: id.Namespace}] = true
+	}
+	sorted := make([]scope, 0, len(scopes))
+	for s := range scopes {
+		sorted = append(sorted, s)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].groupKind.String() != sorted[j].groupKind.String() {
+			return sorted[i].groupKind.String() < sorted[j].groupKind.String()
+		}
+		return sorted[i].namespace < sorted[j].namespace
+	})
+
+	listOptions := metav1.ListOptions{}
+	if selector != nil {
+		listOptions.LabelSelector = selector.String()
+	}
+	var events []event.DriftEvent
+	for _, s := range sorted {
+		mapping, err := d.mapper.RESTMapping(s.groupKind)
+		if err != nil {
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			return nil, err
+		}
+		list, err := d.resourceInterface(mapping, s.namespace).List(context.TODO(), listOptions)
+		if err != nil {
+			return nil, errors.WrapPrefix(err, fmt.Sprintf("error listing %s", s.groupKind), 1)
+		}
+		for i := range list.Items {
+			obj := &list.Items[i]
+			if de, found := extraObject(invID, obj, inInventory, selector != nil); found {
+				events = append(events, de)
+			}
+		}
+	}
+	return events, nil
+}
+
+// extraObject returns an event if the object belongs to the package but
+// is not in the inventory. Objects from the list always match the
+// selector if there is one.
+func extraObject(invID string, obj *unstructured.Unstructured, inInventory map[object.ObjMetadata]bool,
+	matchesSelector bool) (event.DriftEvent, bool) {
+	id := object.UnstructuredToObjMeta(obj)
+	if inInventory[id] || inventory.IsInventoryObject(obj) {
+		return event.DriftEvent{}, false
+	}
+	var details []string
+	switch {
+	case inventory.OwningInventory(obj) == invID:
+		details = append(details, "annotated as owned by the inventory")
+	case matchesSelector:
+		details = append(details, "matches the package selector")
+	default:
+		return event.DriftEvent{}, false
+	}
+	return event.DriftEvent{
+		Type:       event.DriftEventResourceUpdate,
+		Operation:  event.NotInInventory,
+		Object:     obj,
+		Identifier: id,
+		Details:    details,
+	}, true
+}
+
+// checkObject compares an object from the inventory against the live
+// object, which is nil if it doesn't exist. The local object is nil if
+// the object is no longer in the package.
+func checkObject(invID string, id object.ObjMetadata, local, live *unstructured.Unstructured,
+	options Options) (event.DriftEvent, error) {
+	de := event.DriftEvent{
+		Type:       event.DriftEventResourceUpdate,
+		Operation:  event.InSync,
+		Object:     live,
+		Identifier: id,
+	}
+	if live == nil {
+		de.Operation = event.DeletedOutOfBand
+		de.Object = local
+		return de, nil
+	}
+	if owner := inventory.OwningInventory(live); owner != invID {
+		de.Operation = event.InventoryChanged
+		if owner == "" {
+			de.Details = []string{"owning inventory annotation removed"}
+		} else {
+			de.Details = []string{fmt.Sprintf("owned by inventory %q", owner)}
+		}
+		return de, nil
+	}
+	if local == nil {
+		return de, nil
+	}
+	changed, err := changedFields(local, live, options)
+	if err != nil {
+		return de, err
+	}
+	if len(changed) > 0 {
+		de.Operation = event.FieldsChanged
+		de.Details = changed
+	}
+	return de, nil
+}
+
+// changedFields returns a message for every field set in the local
+// object that is owned by some other field manager in the live object,
+// and not by any of the field managers used for applying the package.
+// Fields ignored by the rules or by the ignore annotation are skipped.
+func changedFields(local, live *unstructured.Unstructured, options Options) ([]string, error) {
+	stripped, err := diff.RemovePaths(local, options.IgnoreRules.PathsFor(local, live))
+	if err != nil {
+		return nil, err
+	}
+	ours := make(map[string]bool)
+	for _, m := range options.FieldManagers {
+		ours[m] = true
+	}
+
+	ownedByUs := make(map[string]bool)
+	ownedByOthers := make(map[string]string)
+	for _, entry := range live.GetManagedFields() {
+		if entry.FieldsV1 == nil {
+			continue
+		}
+		var fields map[string]interface{}
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+			return nil, fmt.Errorf("error reading managed fields for %s: %v", entry.Manager, err)
+		}
+		var paths []string
+		collectFields(fields, stripped.Object, "", &paths)
+		for _, p := range paths {
+			if ours[entry.Manager] {
+				ownedByUs[p] = true
+			} else if _, found := ownedByOthers[p]; !found {
+				ownedByOthers[p] = entry.Manager
+			}
+		}
+	}
+
+	var changed []string
+	for p, manager := range ownedByOthers {
+		if !ownedByUs[p] {
+			changed = append(changed, fmt.Sprintf("%s changed by %q", p, manager))
+		}
+	}
+	sort.Strings(changed)
+	return changed, nil
+}
+
+// collectFields walks the fields from a managed fields entry, and adds
+// the path of every leaf field that is also set in the value.
+func collectFields(fields map[string]interface{}, value interface{}, path string, paths *[]string) {
+	for key, child := range fields {
+		// The "." key means the element itself is owned, which is
+		// covered by the element's path.
+		if key == "." {
+			continue
+		}
+		childValue, childPath, found := lookupField(key, value, path)
+		if !found {
+			continue
+		}
+		childFields, _ := child.(map[string]interface{})
+		if len(childFields) == 0 || (len(childFields) == 1 && childFields["."] != nil) {
+			*paths = append(*paths, childPath)
+			continue
+		}
+		collectFields(childFields, childValue, childPath, paths)
+	}
+}
+
+// lookupField returns the value and path for a key from a managed
+// fields entry. The key is a field (f:name), a list element identified
+// by its keys (k:{"name":"app"}), a set value (v:"value") or a list
+// index (i:0).
+func lookupField(key string, value interface{}, path string) (interface{}, string, bool) {
+	if len(key) < 2 || key[1] != ':' {
+		return nil, "", false
+	}
+	prefix, rest := key[:1], key[2:]
+	if prefix == "f" {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, "", false
+		}
+		v, found := m[rest]
+		return v, path + diff.KeySegment(rest), found
+	}
+
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, "", false
+	}
+	switch prefix {
+	case "i":
+		i, err := strconv.Atoi(rest)
+		if err != nil || i < 0 || i >= len(list) {
+			return nil, "", false
+		}
+		return list[i], fmt.Sprintf("%s[%d]", path, i), true
+	case "k":
+		var keys map[string]interface{}
+		if err := json.Unmarshal([]byte(rest), &keys); err != nil {
+			return nil, "", false
+		}
+		for _, elem := range list {
+			m, ok := elem.(map[string]interface{})
+			if ok && matchesKeys(m, keys) {
+				return elem, fmt.Sprintf("%s[%s]", path, formatKeys(keys)), true
+			}
+		}
+	case "v":
+		for _, elem := range list {
+			if jsonEqual(elem, json.RawMessage(rest)) {
+				return elem, fmt.Sprintf("%s[%s]", path, rest), true
+			}
+		}
+	}
+	return nil, "", false
+}
+
+func matchesKeys(m, keys map[string]interface{}) bool {
+	for k, v := range keys {
+		if !jsonEqual(m[k], v) {
+			return false
+		}
+	}
+	return true
+}
+
+// jsonEqual compares values by their json encoding, since numbers are
+// decoded as float64 from json but are int64 in unstructured objects.
+func jsonEqual(a, b interface{}) bool {
+	ja, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	jb, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return string(ja) == string(jb)
+}
+
+// formatKeys formats the keys of a list element as name=value pairs,
+// sorted by name.
+func formatKeys(keys map[string]interface{}) string {
+	pairs := make([]string, 0, len(keys))
+	for k, v := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%v", k, v))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
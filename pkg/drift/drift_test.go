@@ -0,0 +1,271 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package drift
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/diff"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+const (
+	invID              = "inv-123"
+	owningInventoryKey = "config.k8s.io/owning-inventory"
+)
+
+var depID = object.ObjMetadata{
+	Namespace: "default",
+	Name:      "foo",
+	GroupKind: schema.GroupKind{Group: "apps", Kind: "Deployment"},
+}
+
+func deployment(owner string, replicas int64, image string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"name":      "foo",
+				"namespace": "default",
+			},
+			"spec": map[string]interface{}{
+				"replicas": replicas,
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{
+								"name":  "app",
+								"image": image,
+								"ports": []interface{}{
+									map[string]interface{}{
+										"containerPort": int64(80),
+										"protocol":      "TCP",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if owner != "" {
+		u.SetAnnotations(map[string]string{owningInventoryKey: owner})
+	}
+	return u
+}
+
+func managedFields(manager, fields string) metav1.ManagedFieldsEntry {
+	return metav1.ManagedFieldsEntry{
+		Manager:   manager,
+		Operation: metav1.ManagedFieldsOperationUpdate,
+		FieldsV1:  &metav1.FieldsV1{Raw: []byte(fields)},
+	}
+}
+
+func withManagedFields(u *unstructured.Unstructured, entries ...metav1.ManagedFieldsEntry) *unstructured.Unstructured {
+	u.SetManagedFields(entries)
+	return u
+}
+
+const ourFields = `{
+  "f:spec": {
+    "f:replicas": {},
+    "f:template": {"f:spec": {"f:containers": {
+      "k:{\"name\":\"app\"}": {
+        ".": {},
+        "f:image": {},
+        "f:name": {},
+        "f:ports": {"k:{\"containerPort\":80,\"protocol\":\"TCP\"}": {".": {}, "f:containerPort": {}}}
+      }
+    }}}
+  }
+}`
+
+func TestCheckObject(t *testing.T) {
+	options := Options{
+		FieldManagers: []string{"kubectl"},
+	}
+
+	testCases := map[string]struct {
+		local             *unstructured.Unstructured
+		live              *unstructured.Unstructured
+		options           Options
+		expectedOperation event.DriftEventOperation
+		expectedDetails   []string
+	}{
+		"in sync": {
+			local:             deployment("", 1, "app:v1"),
+			live:              withManagedFields(deployment(invID, 1, "app:v1"), managedFields("kubectl", ourFields)),
+			options:           options,
+			expectedOperation: event.InSync,
+		},
+		"deleted out of band": {
+			local:             deployment("", 1, "app:v1"),
+			live:              nil,
+			options:           options,
+			expectedOperation: event.DeletedOutOfBand,
+		},
+		"owning inventory annotation removed": {
+			local:             deployment("", 1, "app:v1"),
+			live:              deployment("", 1, "app:v1"),
+			options:           options,
+			expectedOperation: event.InventoryChanged,
+			expectedDetails:   []string{"owning inventory annotation removed"},
+		},
+		"owned by other inventory": {
+			local:             deployment("", 1, "app:v1"),
+			live:              deployment("other", 1, "app:v1"),
+			options:           options,
+			expectedOperation: event.InventoryChanged,
+			expectedDetails:   []string{`owned by inventory "other"`},
+		},
+		"fields changed by other field managers": {
+			local: deployment("", 1, "app:v1"),
+			live: withManagedFields(deployment(invID, 5, "app:v2"),
+				managedFields("kubectl", `{"f:spec": {"f:template": {}}}`),
+				managedFields("hpa", `{"f:spec": {"f:replicas": {}}}`),
+				managedFields("kubectl-edit", `{"f:spec": {"f:template": {"f:spec": {"f:containers": {
+					"k:{\"name\":\"app\"}": {"f:image": {}}}}}}}`),
+			),
+			options:           options,
+			expectedOperation: event.FieldsChanged,
+			expectedDetails: []string{
+				`.spec.replicas changed by "hpa"`,
+				`.spec.template.spec.containers[name=app].image changed by "kubectl-edit"`,
+			},
+		},
+		"fields owned by us and others are not reported": {
+			local: deployment("", 1, "app:v1"),
+			live: withManagedFields(deployment(invID, 1, "app:v1"),
+				managedFields("kubectl", ourFields),
+				managedFields("other", `{"f:spec": {"f:replicas": {}}}`),
+			),
+			options:           options,
+			expectedOperation: event.InSync,
+		},
+		"fields not in the package are not reported": {
+			local: deployment("", 1, "app:v1"),
+			live: withManagedFields(deployment(invID, 1, "app:v1"),
+				managedFields("kubectl", ourFields),
+				managedFields("injector", `{"f:spec": {"f:template": {"f:spec": {"f:containers": {
+					"k:{\"name\":\"sidecar\"}": {"f:image": {}}}}}}}`),
+				managedFields("controller", `{"f:status": {"f:replicas": {}}}`),
+			),
+			options:           options,
+			expectedOperation: event.InSync,
+		},
+		"ignored fields are not reported": {
+			local: deployment("", 1, "app:v1"),
+			live: withManagedFields(deployment(invID, 5, "app:v1"),
+				managedFields("kubectl", ourFields),
+				managedFields("hpa", `{"f:spec": {"f:replicas": {}}}`),
+			),
+			options: Options{
+				FieldManagers: []string{"kubectl"},
+				IgnoreRules: diff.IgnoreRules{
+					{Group: "apps", Kind: "Deployment", Paths: []string{".spec.replicas"}},
+				},
+			},
+			expectedOperation: event.InSync,
+		},
+		"object no longer in the package": {
+			local:             nil,
+			live:              withManagedFields(deployment(invID, 5, "app:v1"), managedFields("hpa", ourFields)),
+			options:           options,
+			expectedOperation: event.InSync,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			de, err := checkObject(invID, depID, tc.local, tc.live, tc.options)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, event.DriftEventResourceUpdate, de.Type)
+			assert.Equal(t, depID, de.Identifier)
+			assert.Equal(t, tc.expectedOperation, de.Operation)
+			assert.Equal(t, tc.expectedDetails, de.Details)
+		})
+	}
+}
+
+func TestCollectFields(t *testing.T) {
+	fields := map[string]interface{}{
+		"f:spec": map[string]interface{}{
+			"f:template": map[string]interface{}{
+				"f:spec": map[string]interface{}{
+					"f:containers": map[string]interface{}{
+						"i:0": map[string]interface{}{
+							"f:ports": map[string]interface{}{
+								`k:{"containerPort":80,"protocol":"TCP"}`: map[string]interface{}{
+									".": map[string]interface{}{},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	var paths []string
+	collectFields(fields, deployment("", 1, "app:v1").Object, "", &paths)
+	assert.Equal(t, []string{
+		".spec.template.spec.containers[0].ports[containerPort=80,protocol=TCP]",
+	}, paths)
+}
+
+func TestExtraObject(t *testing.T) {
+	testCases := map[string]struct {
+		obj             *unstructured.Unstructured
+		inInventory     bool
+		matchesSelector bool
+		expectedFound   bool
+		expectedDetails []string
+	}{
+		"object in inventory": {
+			obj:           deployment(invID, 1, "app:v1"),
+			inInventory:   true,
+			expectedFound: false,
+		},
+		"annotated for the inventory": {
+			obj:             deployment(invID, 1, "app:v1"),
+			expectedFound:   true,
+			expectedDetails: []string{"annotated as owned by the inventory"},
+		},
+		"matches selector": {
+			obj:             deployment("", 1, "app:v1"),
+			matchesSelector: true,
+			expectedFound:   true,
+			expectedDetails: []string{"matches the package selector"},
+		},
+		"unrelated object": {
+			obj:           deployment("other", 1, "app:v1"),
+			expectedFound: false,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			inInventory := map[object.ObjMetadata]bool{}
+			if tc.inInventory {
+				inInventory[depID] = true
+			}
+			de, found := extraObject(invID, tc.obj, inInventory, tc.matchesSelector)
+			assert.Equal(t, tc.expectedFound, found)
+			if tc.expectedFound {
+				assert.Equal(t, event.NotInInventory, de.Operation)
+				assert.Equal(t, depID, de.Identifier)
+				assert.Equal(t, tc.expectedDetails, de.Details)
+			}
+		})
+	}
+}
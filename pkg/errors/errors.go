@@ -48,6 +48,7 @@ Timeout after {{printf "%.0f" .err.Timeout.Seconds}} seconds waiting for {{print
 
 {{- range .err.TimedOutResources}}
 {{printf "%s/%s %s %s" .Identifier.GroupKind.Kind .Identifier.Name .Status .Message }}
+{{- if .WaitCondition}}{{printf " (waiting for %s)" .WaitCondition}}{{end}}
 {{- end}}
 `
 
@@ -118,6 +119,15 @@ func findErrType(err error) (reflect.Type, bool) {
 	}
 }
 
+// ExitCodeForError returns the exit code kapply uses for the provided
+// error. It returns 0 if the error is nil.
+func ExitCodeForError(err error) int {
+	if err == nil {
+		return 0
+	}
+	return findErrExitCode(err)
+}
+
 // findErrExitCode looks up if there is a defined error code for the provided
 // error type.
 func findErrExitCode(err error) int {
@@ -83,6 +83,40 @@ func TestTextForError(t *testing.T) {
 			expectedErrText: `
 Timeout after 2 seconds waiting for 1 out of 1 resources to reach condition AllCurrent:
 Deployment/foo InProgress
+`,
+		},
+		"timeout error with wait condition": {
+			err: &taskrunner.TimeoutError{
+				Timeout: 2 * time.Second,
+				Identifiers: []object.ObjMetadata{
+					{
+						GroupKind: schema.GroupKind{
+							Kind:  "Job",
+							Group: "batch",
+						},
+						Name: "foo",
+					},
+				},
+				Condition: taskrunner.AllCurrent,
+				TimedOutResources: []taskrunner.TimedOutResource{
+					{
+						Identifier: object.ObjMetadata{
+							GroupKind: schema.GroupKind{
+								Kind:  "Job",
+								Group: "batch",
+							},
+							Name: "foo",
+						},
+						Status:        status.NotFoundStatus,
+						WaitCondition: "exists",
+					},
+				},
+			},
+			cmdNameBase: "kapply",
+			expectFound: true,
+			expectedErrText: `
+Timeout after 2 seconds waiting for 1 out of 1 resources to reach condition AllCurrent:
+Job/foo NotFound  (waiting for exists)
 `,
 		},
 	}
@@ -107,3 +141,29 @@ type sliceError []string
 func (s sliceError) Error() string {
 	return "this is a test"
 }
+
+func TestExitCodeForError(t *testing.T) {
+	testCases := map[string]struct {
+		err              error
+		expectedExitCode int
+	}{
+		"no error": {
+			err:              nil,
+			expectedExitCode: 0,
+		},
+		"timeout error": {
+			err:              &taskrunner.TimeoutError{},
+			expectedExitCode: TimeoutErrorExitCode,
+		},
+		"unknown error": {
+			err:              fmt.Errorf("this is a test"),
+			expectedExitCode: DefaultErrorExitCode,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			assert.Equal(t, tc.expectedExitCode, ExitCodeForError(tc.err))
+		})
+	}
+}
@@ -92,4 +92,4 @@ func (fic *FakeInventoryClient) UpdateLabels(inv InventoryInfo, labels map[strin
 
 func (fic *FakeInventoryClient) ApplyInventoryObj(u *unstructured.Unstructured) error {
 	return nil
-}
\ No newline at end of file
+}
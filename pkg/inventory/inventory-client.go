@@ -429,6 +429,12 @@ func (cic *ClusterInventoryClient) deleteInventoryObj(obj *unstructured.Unstruct
 	return err
 }
 
+// InventoryObj returns the local inventory object for the passed
+// InventoryInfo, using the function the client was created with.
+func (cic *ClusterInventoryClient) InventoryObj(inv InventoryInfo) *unstructured.Unstructured {
+	return cic.invToUnstructuredFunc(inv)
+}
+
 // SetDryRun sets whether the inventory client will mutate the inventory
 // object in the cluster.
 func (cic *ClusterInventoryClient) SetDryRunStrategy(drs common.DryRunStrategy) {
@@ -77,6 +77,16 @@ const (
 	NoMatch
 )
 
+// OwningInventory returns the id of the inventory that owns the object,
+// based on the owning-inventory annotation. It returns an empty string
+// if the object doesn't have the annotation.
+func OwningInventory(obj *unstructured.Unstructured) string {
+	if obj == nil {
+		return ""
+	}
+	return obj.GetAnnotations()[owningInventoryKey]
+}
+
 func inventoryIDMatch(inv InventoryInfo, obj *unstructured.Unstructured) inventoryIDMatchStatus {
 	annotations := obj.GetAnnotations()
 	value, found := annotations[owningInventoryKey]
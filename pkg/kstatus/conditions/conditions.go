@@ -0,0 +1,99 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package conditions
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+)
+
+// now returns the current time. It is a variable to allow unit testing.
+var now = metav1.Now
+
+// Condition is the format of conditions used by the helpers in this
+// package. It extends the status.Condition with the time of the last
+// transition, so it can be used directly in the status struct of
+// custom resources.
+type Condition struct {
+	status.Condition `json:",inline"`
+
+	// LastTransitionTime is the last time the condition transitioned
+	// from one status to another.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// NewCondition returns a new condition with the provided values.
+func NewCondition(conditionType status.ConditionType, conditionStatus corev1.ConditionStatus,
+	reason, message string) Condition {
+	return Condition{
+		Condition: status.Condition{
+			Type:    conditionType,
+			Status:  conditionStatus,
+			Reason:  reason,
+			Message: message,
+		},
+	}
+}
+
+// Get returns the condition with the given type from the slice of
+// conditions. The second return value is false if no condition of
+// the given type exists.
+func Get(conditions []Condition, conditionType status.ConditionType) (Condition, bool) {
+	for _, c := range conditions {
+		if c.Type == conditionType {
+			return c, true
+		}
+	}
+	return Condition{}, false
+}
+
+// IsTrue returns true if the condition with the given type exists
+// and has the status True.
+func IsTrue(conditions []Condition, conditionType status.ConditionType) bool {
+	c, found := Get(conditions, conditionType)
+	return found && c.Status == corev1.ConditionTrue
+}
+
+// Set adds the provided condition to the slice of conditions, or updates
+// the existing condition with the same type. The LastTransitionTime is
+// only updated if the status of the condition changes.
+func Set(conditions *[]Condition, condition Condition) {
+	for i := range *conditions {
+		existing := &(*conditions)[i]
+		if existing.Type != condition.Type {
+			continue
+		}
+		if existing.Status != condition.Status || existing.LastTransitionTime.IsZero() {
+			existing.LastTransitionTime = transitionTime(condition)
+		}
+		existing.Status = condition.Status
+		existing.Reason = condition.Reason
+		existing.Message = condition.Message
+		return
+	}
+	condition.LastTransitionTime = transitionTime(condition)
+	*conditions = append(*conditions, condition)
+}
+
+// Remove removes the condition with the given type from the slice of
+// conditions. It does nothing if the condition doesn't exist.
+func Remove(conditions *[]Condition, conditionType status.ConditionType) {
+	var filtered []Condition
+	for _, c := range *conditions {
+		if c.Type != conditionType {
+			filtered = append(filtered, c)
+		}
+	}
+	*conditions = filtered
+}
+
+// transitionTime returns the LastTransitionTime of the condition if it
+// is set, and the current time otherwise.
+func transitionTime(c Condition) metav1.Time {
+	if !c.LastTransitionTime.IsZero() {
+		return c.LastTransitionTime
+	}
+	return now()
+}
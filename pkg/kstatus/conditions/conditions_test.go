@@ -0,0 +1,121 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package conditions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+)
+
+var (
+	t1 = metav1.NewTime(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	t2 = metav1.NewTime(time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC))
+)
+
+func withNow(t metav1.Time) func() {
+	orig := now
+	now = func() metav1.Time { return t }
+	return func() { now = orig }
+}
+
+func TestSet(t *testing.T) {
+	testCases := map[string]struct {
+		existing []Condition
+		set      Condition
+		expected []Condition
+	}{
+		"add new condition": {
+			existing: nil,
+			set:      NewCondition(status.ConditionReconciling, corev1.ConditionTrue, "Foo", "foo"),
+			expected: []Condition{
+				{
+					Condition:          status.Condition{Type: status.ConditionReconciling, Status: corev1.ConditionTrue, Reason: "Foo", Message: "foo"},
+					LastTransitionTime: t2,
+				},
+			},
+		},
+		"update without status change keeps transition time": {
+			existing: []Condition{
+				{
+					Condition:          status.Condition{Type: status.ConditionReconciling, Status: corev1.ConditionTrue, Reason: "Foo", Message: "foo"},
+					LastTransitionTime: t1,
+				},
+			},
+			set: NewCondition(status.ConditionReconciling, corev1.ConditionTrue, "Bar", "bar"),
+			expected: []Condition{
+				{
+					Condition:          status.Condition{Type: status.ConditionReconciling, Status: corev1.ConditionTrue, Reason: "Bar", Message: "bar"},
+					LastTransitionTime: t1,
+				},
+			},
+		},
+		"status change updates transition time": {
+			existing: []Condition{
+				{
+					Condition:          status.Condition{Type: status.ConditionReconciling, Status: corev1.ConditionTrue, Reason: "Foo", Message: "foo"},
+					LastTransitionTime: t1,
+				},
+			},
+			set: NewCondition(status.ConditionReconciling, corev1.ConditionFalse, "Done", ""),
+			expected: []Condition{
+				{
+					Condition:          status.Condition{Type: status.ConditionReconciling, Status: corev1.ConditionFalse, Reason: "Done"},
+					LastTransitionTime: t2,
+				},
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			defer withNow(t2)()
+
+			conditions := tc.existing
+			Set(&conditions, tc.set)
+
+			assert.Equal(t, tc.expected, conditions)
+		})
+	}
+}
+
+func TestRemove(t *testing.T) {
+	conditions := []Condition{
+		NewCondition(status.ConditionReconciling, corev1.ConditionTrue, "", ""),
+		NewCondition(status.ConditionStalled, corev1.ConditionTrue, "", ""),
+	}
+
+	Remove(&conditions, status.ConditionReconciling)
+	_, found := Get(conditions, status.ConditionReconciling)
+	assert.False(t, found)
+	assert.True(t, IsTrue(conditions, status.ConditionStalled))
+
+	Remove(&conditions, "DoesNotExist")
+	assert.Len(t, conditions, 1)
+}
+
+func TestStatusMarkers(t *testing.T) {
+	s := &Status{}
+
+	s.MarkReconciling("Progressing", "working")
+	assert.True(t, s.IsReconciling())
+	assert.False(t, s.IsStalled())
+
+	s.MarkStalled("Error", "broken")
+	assert.False(t, s.IsReconciling())
+	assert.True(t, s.IsStalled())
+
+	s.MarkReconciling("Progressing", "retrying")
+	assert.True(t, s.IsReconciling())
+	assert.False(t, s.IsStalled())
+
+	s.MarkReconciled(3)
+	assert.False(t, s.IsReconciling())
+	assert.False(t, s.IsStalled())
+	assert.Equal(t, int64(3), s.ObservedGeneration)
+}
@@ -0,0 +1,41 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package conditions contains helpers for controllers that want to
+// report the status of their custom resources in a way that is
+// compatible with the kstatus library.
+//
+// The status package computes status from the outside, by looking at the
+// observedGeneration field and the Reconciling and Stalled conditions.
+// This package provides the opposite: types that can be embedded in the
+// status struct of a custom resource, and functions for setting the
+// standard conditions correctly from inside a controller.
+//
+//   import (
+//     "sigs.k8s.io/cli-utils/pkg/kstatus/conditions"
+//   )
+//
+//   type MyResourceStatus struct {
+//     conditions.Status `json:",inline"`
+//
+//     // Other status fields.
+//   }
+//
+// Inside the reconcile loop, the controller marks the resource as
+// Reconciling while it is making progress, Stalled if it is unable to
+// make progress, and Reconciled when the desired state has been reached:
+//
+//   res.Status.MarkReconciling("Progressing", "Waiting for pods")
+//   res.Status.MarkStalled("InvalidSpec", "The image can not be empty")
+//   res.Status.MarkReconciled(res.GetGeneration())
+//
+// The Verify function can be used in tests to check that the status
+// reported by the controller is interpreted by the status.Compute
+// function as intended.
+//
+//   err := conditions.Verify(created, status.InProgressStatus)
+//
+// To verify a whole reconcile lifecycle, use the conformance package
+// in pkg/kstatus/status/conformance, which runs Verify for every
+// snapshot of the resource.
+package conditions
@@ -0,0 +1,68 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package conditions
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+)
+
+// Status contains the fields the status library looks at when computing
+// the status of a resource. It should be embedded inline in the status
+// struct of custom resources.
+type Status struct {
+	// ObservedGeneration is the generation of the resource that was
+	// most recently observed by the controller.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions contains the standard conditions for the resource,
+	// and possibly other conditions set by the controller.
+	Conditions []Condition `json:"conditions,omitempty"`
+}
+
+// MarkReconciling sets the Reconciling condition to True. This means
+// the controller is working on making the actual state match the
+// desired state, so the resource will be InProgress. Any Stalled
+// condition is removed, since the controller is making progress.
+func (s *Status) MarkReconciling(reason, message string) {
+	Remove(&s.Conditions, status.ConditionStalled)
+	Set(&s.Conditions, NewCondition(status.ConditionReconciling, corev1.ConditionTrue, reason, message))
+}
+
+// MarkStalled sets the Stalled condition to True. This means the
+// controller has encountered an error or some other issue that
+// prevents it from making progress, so the resource will be Failed.
+// The Reconciling condition is removed, since it would otherwise take
+// precedence over the Stalled condition.
+func (s *Status) MarkStalled(reason, message string) {
+	Remove(&s.Conditions, status.ConditionReconciling)
+	Set(&s.Conditions, NewCondition(status.ConditionStalled, corev1.ConditionTrue, reason, message))
+}
+
+// MarkReconciled removes both the Reconciling and Stalled conditions
+// and sets the observedGeneration to the provided generation. This means
+// the actual state matches the desired state for the given generation,
+// so the resource will be Current.
+func (s *Status) MarkReconciled(generation int64) {
+	Remove(&s.Conditions, status.ConditionReconciling)
+	Remove(&s.Conditions, status.ConditionStalled)
+	s.ObservedGeneration = generation
+}
+
+// ObserveGeneration sets the observedGeneration to the provided value.
+// It should be called once the controller has seen the given generation
+// of the resource and updated the conditions accordingly.
+func (s *Status) ObserveGeneration(generation int64) {
+	s.ObservedGeneration = generation
+}
+
+// IsReconciling returns true if the Reconciling condition is True.
+func (s *Status) IsReconciling() bool {
+	return IsTrue(s.Conditions, status.ConditionReconciling)
+}
+
+// IsStalled returns true if the Stalled condition is True.
+func (s *Status) IsStalled() bool {
+	return IsTrue(s.Conditions, status.ConditionStalled)
+}
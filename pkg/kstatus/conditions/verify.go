@@ -0,0 +1,109 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package conditions
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+)
+
+// MismatchError is returned by Verify if the status computed for the
+// object doesn't match the expected status.
+type MismatchError struct {
+	// Expected is the status the object was expected to have.
+	Expected status.Status
+
+	// Actual is the status computed by status.Compute.
+	Actual status.Status
+
+	// Message is the message returned by status.Compute, or the
+	// error if status could not be computed.
+	Message string
+}
+
+func (e *MismatchError) Error() string {
+	return fmt.Sprintf("expected status %s, but got %s: %s", e.Expected, e.Actual, e.Message)
+}
+
+// PolarityError is returned by Verify if the Reconciling or Stalled
+// condition is False while the object is expected to have the status
+// the condition stands for. Both conditions are abnormal-true, so they
+// must be True when they apply.
+type PolarityError struct {
+	// ConditionType is the type of the condition that is False.
+	ConditionType status.ConditionType
+
+	// Expected is the status the object was expected to have.
+	Expected status.Status
+}
+
+func (e *PolarityError) Error() string {
+	return fmt.Sprintf("condition %s is False, but the resource is expected to be %s. %s is an "+
+		"abnormal-true condition and must be True when it applies", e.ConditionType, e.Expected, e.ConditionType)
+}
+
+// Verify computes the status of the provided object and checks that it
+// matches the expected status, and that the Reconciling and Stalled
+// conditions are used with the right polarity. The object can be either
+// a typed object or an Unstructured. If the checks fail, the returned
+// error is an aggregate of MismatchError and PolarityError.
+//
+// Verify checks a single object. The conformance package in
+// pkg/kstatus/status/conformance uses it to verify every snapshot in
+// a reconcile lifecycle, and also looks for other common mistakes.
+func Verify(obj runtime.Object, expected status.Status) error {
+	u, err := toUnstructured(obj)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	mismatch := &MismatchError{Expected: expected}
+	res, err := status.Compute(u)
+	if err != nil {
+		mismatch.Actual = status.UnknownStatus
+		mismatch.Message = err.Error()
+	} else {
+		mismatch.Actual = res.Status
+		mismatch.Message = res.Message
+	}
+	if mismatch.Actual != expected {
+		errs = append(errs, mismatch)
+	}
+
+	// If conditions can't be read, status.Compute has also failed,
+	// which is already reported as a mismatch.
+	if objWithConditions, err := status.GetObjectWithConditions(u.Object); err == nil {
+		for _, c := range objWithConditions.Status.Conditions {
+			if c.Status != corev1.ConditionFalse {
+				continue
+			}
+			if c.Type == string(status.ConditionReconciling) && expected == status.InProgressStatus ||
+				c.Type == string(status.ConditionStalled) && expected == status.FailedStatus {
+				errs = append(errs, &PolarityError{
+					ConditionType: status.ConditionType(c.Type),
+					Expected:      expected,
+				})
+			}
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// toUnstructured converts the provided object into an Unstructured.
+func toUnstructured(obj runtime.Object) (*unstructured.Unstructured, error) {
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		return u, nil
+	}
+	m, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, fmt.Errorf("error converting object to unstructured: %v", err)
+	}
+	return &unstructured.Unstructured{Object: m}, nil
+}
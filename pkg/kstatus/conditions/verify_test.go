@@ -0,0 +1,136 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package conditions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+)
+
+// testResource is a minimal custom resource that embeds the Status
+// type from this package in its status.
+type testResource struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status testResourceStatus `json:"status,omitempty"`
+}
+
+type testResourceStatus struct {
+	Status `json:",inline"`
+
+	Replicas int64 `json:"replicas,omitempty"`
+}
+
+func (r *testResource) DeepCopyObject() runtime.Object {
+	c := *r
+	r.ObjectMeta.DeepCopyInto(&c.ObjectMeta)
+	c.Status.Conditions = append([]Condition(nil), r.Status.Conditions...)
+	return &c
+}
+
+func newTestResource(generation int64) *testResource {
+	return &testResource{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "example.com/v1",
+			Kind:       "Foo",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "foo",
+			Namespace:  "default",
+			Generation: generation,
+		},
+	}
+}
+
+func TestVerify(t *testing.T) {
+	created := newTestResource(1)
+	created.Status.MarkReconciling("Progressing", "Creating pods")
+	created.Status.ObserveGeneration(1)
+
+	stalled := created.DeepCopyObject().(*testResource)
+	stalled.Status.MarkStalled("InvalidImage", "The image can not be pulled")
+
+	reconciled := created.DeepCopyObject().(*testResource)
+	reconciled.Status.MarkReconciled(1)
+
+	updated := reconciled.DeepCopyObject().(*testResource)
+	updated.Generation = 2
+
+	wrongPolarity := newTestResource(1)
+	wrongPolarity.Status.ObserveGeneration(1)
+	Set(&wrongPolarity.Status.Conditions, NewCondition(status.ConditionReconciling, corev1.ConditionFalse,
+		"Progressing", "Creating pods"))
+
+	testCases := map[string]struct {
+		object         runtime.Object
+		expectedStatus status.Status
+		expectedErrs   []error
+	}{
+		"created": {
+			object:         created,
+			expectedStatus: status.InProgressStatus,
+		},
+		"stalled": {
+			object:         stalled,
+			expectedStatus: status.FailedStatus,
+		},
+		"reconciled": {
+			object:         reconciled,
+			expectedStatus: status.CurrentStatus,
+		},
+		"updated": {
+			object:         updated,
+			expectedStatus: status.InProgressStatus,
+		},
+		"unexpected status": {
+			object:         created,
+			expectedStatus: status.CurrentStatus,
+			expectedErrs: []error{
+				&MismatchError{
+					Expected: status.CurrentStatus,
+					Actual:   status.InProgressStatus,
+					Message:  "Creating pods",
+				},
+			},
+		},
+		"wrong polarity": {
+			object:         wrongPolarity,
+			expectedStatus: status.InProgressStatus,
+			expectedErrs: []error{
+				&MismatchError{
+					Expected: status.InProgressStatus,
+					Actual:   status.CurrentStatus,
+					Message:  "Resource is current",
+				},
+				&PolarityError{
+					ConditionType: status.ConditionReconciling,
+					Expected:      status.InProgressStatus,
+				},
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			err := Verify(tc.object, tc.expectedStatus)
+
+			if len(tc.expectedErrs) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			agg, ok := err.(utilerrors.Aggregate)
+			if !assert.True(t, ok, "expected an aggregate error, got %v", err) {
+				t.FailNow()
+			}
+			assert.Equal(t, tc.expectedErrs, agg.Errors())
+		})
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package collector
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// now returns the current time. It is a variable to allow unit testing.
+var now = time.Now
+
+// Transition is a change in the status of a resource.
+type Transition struct {
+	Status  status.Status
+	Message string
+	Time    time.Time
+}
+
+// Timeline contains all status transitions seen for a single resource,
+// in the order they happened.
+type Timeline struct {
+	Identifier  object.ObjMetadata
+	Transitions []Transition
+}
+
+// TimelineRecorder keeps track of the full sequence of status transitions
+// for every resource it is given, unlike the ResourceStatusCollector that
+// only keeps the latest status. It is safe for use by multiple goroutines.
+type TimelineRecorder struct {
+	mux sync.Mutex
+
+	start     time.Time
+	timelines map[object.ObjMetadata]*Timeline
+	// order keeps track of the order in which resources were
+	// first seen, so the output is deterministic.
+	order []object.ObjMetadata
+}
+
+// NewTimelineRecorder returns a new TimelineRecorder. Durations in the
+// summary are computed relative to the time the recorder was created.
+func NewTimelineRecorder() *TimelineRecorder {
+	return &TimelineRecorder{
+		start:     now(),
+		timelines: make(map[object.ObjMetadata]*Timeline),
+	}
+}
+
+// Record adds a transition to the timeline of the resource if the status
+// is different from the last recorded status.
+func (t *TimelineRecorder) Record(rs *event.ResourceStatus) {
+	if rs == nil {
+		return
+	}
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	tl, found := t.timelines[rs.Identifier]
+	if !found {
+		tl = &Timeline{
+			Identifier: rs.Identifier,
+		}
+		t.timelines[rs.Identifier] = tl
+		t.order = append(t.order, rs.Identifier)
+	}
+	if n := len(tl.Transitions); n > 0 && tl.Transitions[n-1].Status == rs.Status {
+		return
+	}
+	tl.Transitions = append(tl.Transitions, Transition{
+		Status:  rs.Status,
+		Message: rs.Message,
+		Time:    now(),
+	})
+}
+
+// Timelines returns a copy of the timelines for all resources, in
+// the order the resources were first seen.
+func (t *TimelineRecorder) Timelines() []Timeline {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	timelines := make([]Timeline, 0, len(t.order))
+	for _, id := range t.order {
+		tl := t.timelines[id]
+		timelines = append(timelines, Timeline{
+			Identifier:  tl.Identifier,
+			Transitions: append([]Transition{}, tl.Transitions...),
+		})
+	}
+	return timelines
+}
+
+// ResourceDuration contains how long it took a resource to become
+// Current.
+type ResourceDuration struct {
+	Identifier object.ObjMetadata
+	// Current is true if the resource has become Current.
+	Current bool
+	// Duration is the time from the recorder was created until the
+	// resource became Current. If the resource never became Current,
+	// it is the time until the summary was created.
+	Duration time.Duration
+	// Status is the latest status of the resource.
+	Status status.Status
+	// Timeline is the full timeline for the resource.
+	Timeline Timeline
+}
+
+// TimelineSummary summarizes the timelines of all resources.
+type TimelineSummary struct {
+	Start time.Time
+	End   time.Time
+	// Resources is sorted with the slowest resources first. Resources
+	// that never became Current are considered slower than all other
+	// resources.
+	Resources []ResourceDuration
+}
+
+// Summary computes how long each resource took to become Current. A
+// resource that became Current and later changed status is still only
+// measured until the first time it became Current.
+func (t *TimelineRecorder) Summary() TimelineSummary {
+	end := now()
+	summary := TimelineSummary{
+		Start: t.start,
+		End:   end,
+	}
+	for _, tl := range t.Timelines() {
+		rd := ResourceDuration{
+			Identifier: tl.Identifier,
+			Duration:   end.Sub(t.start),
+			Timeline:   tl,
+		}
+		if n := len(tl.Transitions); n > 0 {
+			rd.Status = tl.Transitions[n-1].Status
+		}
+		for _, tr := range tl.Transitions {
+			if tr.Status == status.CurrentStatus {
+				rd.Current = true
+				rd.Duration = tr.Time.Sub(t.start)
+				break
+			}
+		}
+		summary.Resources = append(summary.Resources, rd)
+	}
+	sort.SliceStable(summary.Resources, func(i, j int) bool {
+		ri, rj := summary.Resources[i], summary.Resources[j]
+		if ri.Current != rj.Current {
+			return !ri.Current
+		}
+		return ri.Duration > rj.Duration
+	})
+	return summary
+}
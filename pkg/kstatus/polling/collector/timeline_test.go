@@ -0,0 +1,76 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package collector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+func TestTimelineRecorder(t *testing.T) {
+	dep := object.ObjMetadata{
+		GroupKind: schema.GroupKind{Group: "apps", Kind: "Deployment"},
+		Name:      "dep",
+		Namespace: "default",
+	}
+	sts := object.ObjMetadata{
+		GroupKind: schema.GroupKind{Group: "apps", Kind: "StatefulSet"},
+		Name:      "sts",
+		Namespace: "default",
+	}
+	cm := object.ObjMetadata{
+		GroupKind: schema.GroupKind{Kind: "ConfigMap"},
+		Name:      "cm",
+		Namespace: "default",
+	}
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := start
+	defer func(orig func() time.Time) { now = orig }(now)
+	now = func() time.Time { return clock }
+
+	recorder := NewTimelineRecorder()
+	record := func(offset time.Duration, id object.ObjMetadata, s status.Status) {
+		clock = start.Add(offset)
+		recorder.Record(&event.ResourceStatus{
+			Identifier: id,
+			Status:     s,
+		})
+	}
+
+	record(1*time.Second, dep, status.InProgressStatus)
+	record(1*time.Second, sts, status.InProgressStatus)
+	record(1*time.Second, cm, status.CurrentStatus)
+	record(2*time.Second, dep, status.InProgressStatus)
+	record(5*time.Second, dep, status.CurrentStatus)
+	record(6*time.Second, dep, status.InProgressStatus)
+	record(7*time.Second, dep, status.CurrentStatus)
+	clock = start.Add(10 * time.Second)
+
+	timelines := recorder.Timelines()
+	assert.Len(t, timelines, 3)
+	assert.Equal(t, dep, timelines[0].Identifier)
+	// Updates without a change in status are not recorded.
+	assert.Len(t, timelines[0].Transitions, 4)
+
+	summary := recorder.Summary()
+	assert.Equal(t, 10*time.Second, summary.End.Sub(summary.Start))
+
+	var ids []object.ObjMetadata
+	var durations []time.Duration
+	for _, rd := range summary.Resources {
+		ids = append(ids, rd.Identifier)
+		durations = append(durations, rd.Duration)
+	}
+	assert.Equal(t, []object.ObjMetadata{sts, dep, cm}, ids)
+	assert.Equal(t, []time.Duration{10 * time.Second, 5 * time.Second, 1 * time.Second}, durations)
+	assert.False(t, summary.Resources[0].Current)
+	assert.Equal(t, status.InProgressStatus, summary.Resources[0].Status)
+}
@@ -0,0 +1,157 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package conformance
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/conditions"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+)
+
+// Check identifies one of the common mistakes the harness looks for.
+type Check string
+
+const (
+	// MissingObservedGeneration means the resource has a generation
+	// and a status, but the status doesn't contain observedGeneration.
+	// Without it, there is no way to tell whether the status is
+	// up to date with the latest spec.
+	MissingObservedGeneration Check = "MissingObservedGeneration"
+
+	// ReadyConditionSetLate means the resource only uses the Ready
+	// condition, and it was not set in every snapshot. Since the
+	// absence of any known conditions means the resource is Current,
+	// the resource will be considered Current before it is ready.
+	ReadyConditionSetLate Check = "ReadyConditionSetLate"
+
+	// WrongConditionPolarity means the resource uses the Reconciling
+	// or Stalled conditions as if they were normal-true, i.e. set to
+	// False when the resource is reconciling or stalled. These
+	// conditions are abnormal-true and must be True when they apply.
+	WrongConditionPolarity Check = "WrongConditionPolarity"
+)
+
+// negatedConditions are condition types that look like attempts to
+// use normal-true versions of the standard conditions. The status
+// library doesn't know about them.
+var negatedConditions = map[string]status.ConditionType{
+	"Reconciled":     status.ConditionReconciling,
+	"NotReconciling": status.ConditionReconciling,
+	"NotStalled":     status.ConditionStalled,
+}
+
+// Finding describes a common mistake found in one of the snapshots.
+type Finding struct {
+	// Index is the position of the snapshot in the sequence.
+	Index int
+
+	// Description is the description of the snapshot.
+	Description string
+
+	// Check is the mistake that was found.
+	Check Check
+
+	// Message provides details about the mistake.
+	Message string
+}
+
+// snapshotFindings looks for common mistakes in a single snapshot. The
+// polarity errors are the ones found by conditions.Verify.
+func snapshotFindings(index int, s Snapshot, polarityErrs []*conditions.PolarityError) []Finding {
+	var findings []Finding
+	newFinding := func(check Check, message string) {
+		findings = append(findings, Finding{
+			Index:       index,
+			Description: s.Description,
+			Check:       check,
+			Message:     message,
+		})
+	}
+
+	if missingObservedGeneration(s.Object) {
+		newFinding(MissingObservedGeneration,
+			"status.observedGeneration must be set when the resource has a metadata.generation")
+	}
+	for _, c := range snapshotConditions(s) {
+		if replacement, found := negatedConditions[c.Type]; found {
+			newFinding(WrongConditionPolarity, fmt.Sprintf(
+				"condition %s is not understood by kstatus, use the abnormal-true condition %s instead",
+				c.Type, replacement))
+		}
+	}
+	for _, e := range polarityErrs {
+		newFinding(WrongConditionPolarity, e.Error())
+	}
+	return findings
+}
+
+// readyConditionFindings looks for snapshots where the Ready condition
+// is set late. The Ready condition is only a problem if it is the only
+// way the resource reports status and it is missing from earlier
+// snapshots.
+func readyConditionFindings(snapshots []Snapshot) []Finding {
+	var findings []Finding
+	conds := make([][]status.BasicCondition, len(snapshots))
+	lastWithReady := -1
+	for i, s := range snapshots {
+		conds[i] = snapshotConditions(s)
+		if hasCondition(conds[i], "Ready") {
+			lastWithReady = i
+		}
+		if hasCondition(conds[i], string(status.ConditionReconciling)) ||
+			hasCondition(conds[i], string(status.ConditionStalled)) {
+			return nil
+		}
+	}
+	for i := 0; i < lastWithReady; i++ {
+		s := snapshots[i]
+		if hasCondition(conds[i], "Ready") || status.GetLegacyConditionsFn(s.Object) != nil {
+			continue
+		}
+		findings = append(findings, Finding{
+			Index:       i,
+			Description: s.Description,
+			Check:       ReadyConditionSetLate,
+			Message: "the Ready condition is set in later snapshots but not in this one, " +
+				"so the resource will be considered Current before it is ready",
+		})
+	}
+	return findings
+}
+
+// snapshotConditions returns the conditions of the snapshot. If the
+// conditions can't be read, status.Compute will also fail, and this
+// is already reported as a mismatch.
+func snapshotConditions(s Snapshot) []status.BasicCondition {
+	objWithConditions, err := status.GetObjectWithConditions(s.Object.Object)
+	if err != nil {
+		return nil
+	}
+	return objWithConditions.Status.Conditions
+}
+
+// missingObservedGeneration returns true if the resource has a
+// generation and a status, but no observedGeneration in the status.
+func missingObservedGeneration(u *unstructured.Unstructured) bool {
+	if u.GetGeneration() == 0 {
+		return false
+	}
+	s, found, err := unstructured.NestedMap(u.Object, "status")
+	if err != nil || !found || len(s) == 0 {
+		return false
+	}
+	_, found = s["observedGeneration"]
+	return !found
+}
+
+func hasCondition(conditions []status.BasicCondition, conditionType string) bool {
+	for _, c := range conditions {
+		if c.Type == conditionType {
+			return true
+		}
+	}
+	return false
+}
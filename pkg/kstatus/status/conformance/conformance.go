@@ -0,0 +1,219 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package conformance
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/diff"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/conditions"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+)
+
+// Snapshot is the state of a resource at one point in its reconcile
+// lifecycle, together with the status we expect it to have.
+type Snapshot struct {
+	// Description is a human readable description of the snapshot. It
+	// is included in the report.
+	Description string
+
+	// ExpectedStatus is the status status.Compute should return
+	// for the Object.
+	ExpectedStatus status.Status
+
+	// Object is the resource.
+	Object *unstructured.Unstructured
+}
+
+// snapshotFixture is the format of a snapshot in a YAML or JSON fixture.
+type snapshotFixture struct {
+	Description    string          `json:"description,omitempty"`
+	ExpectedStatus status.Status   `json:"expectedStatus"`
+	Object         json.RawMessage `json:"object"`
+}
+
+// Mismatch describes a snapshot where the status computed by
+// status.Compute didn't match the expected status.
+type Mismatch struct {
+	// Index is the position of the snapshot in the sequence.
+	Index int
+
+	// Description is the description of the snapshot.
+	Description string
+
+	// Expected is the status the snapshot was expected to have.
+	Expected status.Status
+
+	// Actual is the status computed by status.Compute.
+	Actual status.Status
+
+	// Message is the message returned by status.Compute, or the
+	// error if status could not be computed.
+	Message string
+
+	// Diff shows how the status of the resource changed from the
+	// previous snapshot.
+	Diff string
+}
+
+// Report contains the result of verifying a sequence of snapshots.
+type Report struct {
+	Mismatches []Mismatch
+	Findings   []Finding
+}
+
+// Passed returns true if status was computed as expected for all
+// snapshots and no common mistakes were found.
+func (r *Report) Passed() bool {
+	return len(r.Mismatches) == 0 && len(r.Findings) == 0
+}
+
+// String returns a human readable version of the report.
+func (r *Report) String() string {
+	var b strings.Builder
+	for _, m := range r.Mismatches {
+		_, _ = fmt.Fprintf(&b, "snapshot %s: expected status %s, but got %s: %s\n",
+			snapshotName(m.Index, m.Description), m.Expected, m.Actual, m.Message)
+		if m.Diff != "" {
+			_, _ = fmt.Fprintf(&b, "status changes from previous snapshot:\n%s\n", m.Diff)
+		}
+	}
+	for _, f := range r.Findings {
+		_, _ = fmt.Fprintf(&b, "snapshot %s: %s: %s\n",
+			snapshotName(f.Index, f.Description), f.Check, f.Message)
+	}
+	return b.String()
+}
+
+// ReadSnapshots reads a sequence of snapshots from the provided reader.
+// Each YAML or JSON document must contain a single snapshot.
+func ReadSnapshots(reader io.Reader) ([]Snapshot, error) {
+	var snapshots []Snapshot
+	decoder := yaml.NewYAMLOrJSONDecoder(reader, 4096)
+	for {
+		var raw json.RawMessage
+		err := decoder.Decode(&raw)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(raw)) == 0 || bytes.Equal(raw, []byte("null")) {
+			continue
+		}
+		var fixture snapshotFixture
+		if err := json.Unmarshal(raw, &fixture); err != nil {
+			return nil, err
+		}
+		if len(fixture.Object) == 0 {
+			return nil, fmt.Errorf("snapshot %s does not have an object",
+				snapshotName(len(snapshots), fixture.Description))
+		}
+		// Decoding with the UnstructuredJSONScheme makes sure numbers
+		// end up as int64 as they would when read from the cluster.
+		obj, err := runtime.Decode(unstructured.UnstructuredJSONScheme, fixture.Object)
+		if err != nil {
+			return nil, err
+		}
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			return nil, fmt.Errorf("snapshot %s: unexpected object type %T",
+				snapshotName(len(snapshots), fixture.Description), obj)
+		}
+		snapshots = append(snapshots, Snapshot{
+			Description:    fixture.Description,
+			ExpectedStatus: fixture.ExpectedStatus,
+			Object:         u,
+		})
+	}
+	return snapshots, nil
+}
+
+// ReadSnapshotsFromFile reads a sequence of snapshots from the file
+// with the given path.
+func ReadSnapshotsFromFile(path string) ([]Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadSnapshots(f)
+}
+
+// Run verifies that status.Compute returns the expected status for
+// every snapshot, and checks the sequence for common mistakes. Every
+// snapshot is verified with conditions.Verify.
+func Run(snapshots []Snapshot) *Report {
+	report := &Report{}
+	var previousStatus interface{}
+	for i, s := range snapshots {
+		currentStatus := s.Object.Object["status"]
+
+		var polarityErrs []*conditions.PolarityError
+		for _, err := range verify(s) {
+			switch e := err.(type) {
+			case *conditions.MismatchError:
+				report.Mismatches = append(report.Mismatches, Mismatch{
+					Index:       i,
+					Description: s.Description,
+					Expected:    e.Expected,
+					Actual:      e.Actual,
+					Message:     e.Message,
+					Diff:        diff.ObjectReflectDiff(previousStatus, currentStatus),
+				})
+			case *conditions.PolarityError:
+				polarityErrs = append(polarityErrs, e)
+			}
+		}
+		report.Findings = append(report.Findings, snapshotFindings(i, s, polarityErrs)...)
+		previousStatus = currentStatus
+	}
+	report.Findings = append(report.Findings, readyConditionFindings(snapshots)...)
+	return report
+}
+
+// verify runs conditions.Verify for the snapshot and returns the
+// individual errors.
+func verify(s Snapshot) []error {
+	err := conditions.Verify(s.Object, s.ExpectedStatus)
+	if err == nil {
+		return nil
+	}
+	if agg, ok := err.(utilerrors.Aggregate); ok {
+		return agg.Errors()
+	}
+	return []error{err}
+}
+
+// VerifyFile reads the snapshots from the file with the given path and
+// verifies them. An error containing the report is returned if
+// verification fails.
+func VerifyFile(path string) error {
+	snapshots, err := ReadSnapshotsFromFile(path)
+	if err != nil {
+		return err
+	}
+	report := Run(snapshots)
+	if !report.Passed() {
+		return fmt.Errorf("kstatus conformance failed for %s:\n%s", path, report)
+	}
+	return nil
+}
+
+func snapshotName(index int, description string) string {
+	if description == "" {
+		return fmt.Sprintf("%d", index)
+	}
+	return fmt.Sprintf("%d (%s)", index, description)
+}
@@ -0,0 +1,240 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package conformance
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+)
+
+var conformingLifecycle = `
+description: created
+expectedStatus: InProgress
+object:
+  apiVersion: example.com/v1
+  kind: Foo
+  metadata:
+    name: foo
+    generation: 1
+  status:
+    observedGeneration: 1
+    conditions:
+    - type: Reconciling
+      status: "True"
+      reason: Progressing
+---
+description: reconciled
+expectedStatus: Current
+object:
+  apiVersion: example.com/v1
+  kind: Foo
+  metadata:
+    name: foo
+    generation: 1
+  status:
+    observedGeneration: 1
+---
+description: spec updated
+expectedStatus: InProgress
+object:
+  apiVersion: example.com/v1
+  kind: Foo
+  metadata:
+    name: foo
+    generation: 2
+  status:
+    observedGeneration: 1
+---
+description: stalled
+expectedStatus: Failed
+object:
+  apiVersion: example.com/v1
+  kind: Foo
+  metadata:
+    name: foo
+    generation: 2
+  status:
+    observedGeneration: 2
+    conditions:
+    - type: Stalled
+      status: "True"
+      reason: InvalidSpec
+`
+
+var readyOnlyLifecycle = `
+description: created
+expectedStatus: InProgress
+object:
+  apiVersion: example.com/v1
+  kind: Foo
+  metadata:
+    name: foo
+---
+description: ready
+expectedStatus: Current
+object:
+  apiVersion: example.com/v1
+  kind: Foo
+  metadata:
+    name: foo
+  status:
+    conditions:
+    - type: Ready
+      status: "True"
+`
+
+var wrongPolarityLifecycle = `
+description: reconciling
+expectedStatus: InProgress
+object:
+  apiVersion: example.com/v1
+  kind: Foo
+  metadata:
+    name: foo
+    generation: 1
+  status:
+    conditions:
+    - type: Reconciling
+      status: "False"
+    - type: Reconciled
+      status: "False"
+`
+
+func TestRun(t *testing.T) {
+	testCases := map[string]struct {
+		fixture            string
+		expectedMismatches []Mismatch
+		expectedChecks     []Check
+	}{
+		"conforming lifecycle": {
+			fixture: conformingLifecycle,
+		},
+		"ready condition set late": {
+			fixture: readyOnlyLifecycle,
+			expectedMismatches: []Mismatch{
+				{
+					Index:       0,
+					Description: "created",
+					Expected:    status.InProgressStatus,
+					Actual:      status.CurrentStatus,
+					Message:     "Resource is current",
+				},
+			},
+			expectedChecks: []Check{ReadyConditionSetLate},
+		},
+		"wrong polarity and missing observedGeneration": {
+			fixture: wrongPolarityLifecycle,
+			expectedMismatches: []Mismatch{
+				{
+					Index:       0,
+					Description: "reconciling",
+					Expected:    status.InProgressStatus,
+					Actual:      status.CurrentStatus,
+					Message:     "Resource is current",
+				},
+			},
+			expectedChecks: []Check{
+				MissingObservedGeneration,
+				WrongConditionPolarity,
+				WrongConditionPolarity,
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			snapshots, err := ReadSnapshots(strings.NewReader(tc.fixture))
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+
+			report := Run(snapshots)
+
+			if !assert.Equal(t, len(tc.expectedMismatches), len(report.Mismatches)) {
+				t.FailNow()
+			}
+			for i, expected := range tc.expectedMismatches {
+				actual := report.Mismatches[i]
+				// The diff is checked separately.
+				actual.Diff = ""
+				assert.Equal(t, expected, actual)
+			}
+
+			var checks []Check
+			for _, f := range report.Findings {
+				checks = append(checks, f.Check)
+			}
+			assert.Equal(t, tc.expectedChecks, checks)
+			assert.Equal(t, len(tc.expectedMismatches) == 0 && len(tc.expectedChecks) == 0, report.Passed())
+		})
+	}
+}
+
+func TestRunReportsDiff(t *testing.T) {
+	fixture := conformingLifecycle + `
+---
+description: reconciled again
+expectedStatus: Current
+object:
+  apiVersion: example.com/v1
+  kind: Foo
+  metadata:
+    name: foo
+    generation: 2
+  status:
+    observedGeneration: 2
+    conditions:
+    - type: Reconciling
+      status: "True"
+`
+	snapshots, err := ReadSnapshots(strings.NewReader(fixture))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	report := Run(snapshots)
+
+	if !assert.Len(t, report.Mismatches, 1) {
+		t.FailNow()
+	}
+	mismatch := report.Mismatches[0]
+	assert.Equal(t, 4, mismatch.Index)
+	assert.Contains(t, mismatch.Diff, "Reconciling")
+	assert.Contains(t, report.String(), "snapshot 4 (reconciled again): expected status Current, but got InProgress")
+}
+
+func TestReadSnapshotsMissingObject(t *testing.T) {
+	_, err := ReadSnapshots(strings.NewReader("description: empty\nexpectedStatus: Current\n"))
+	if !assert.Error(t, err) {
+		t.FailNow()
+	}
+	assert.Contains(t, err.Error(), "snapshot 0 (empty) does not have an object")
+}
+
+func TestVerifyFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "conformance-test")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+
+	good := filepath.Join(dir, "good.yaml")
+	bad := filepath.Join(dir, "bad.yaml")
+	assert.NoError(t, ioutil.WriteFile(good, []byte(conformingLifecycle), 0600))
+	assert.NoError(t, ioutil.WriteFile(bad, []byte(readyOnlyLifecycle), 0600))
+
+	assert.NoError(t, VerifyFile(good))
+
+	err = VerifyFile(bad)
+	if !assert.Error(t, err) {
+		t.FailNow()
+	}
+	assert.Contains(t, err.Error(), string(ReadyConditionSetLate))
+}
@@ -0,0 +1,41 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package conformance provides a test harness that CRD authors can use
+// to verify that the status reported by their controllers is interpreted
+// correctly by the kstatus library, and therefore by kapply when it
+// waits for resources to reconcile.
+//
+// The input is a sequence of snapshots of a resource, each describing
+// the resource at one point of its reconcile lifecycle together with
+// the status it is expected to have. Snapshots are usually kept as
+// YAML fixtures with one document per snapshot:
+//
+//   description: Resource has just been created
+//   expectedStatus: InProgress
+//   object:
+//     apiVersion: example.com/v1
+//     kind: Foo
+//     metadata:
+//       name: foo
+//       generation: 1
+//   ---
+//   description: Controller has finished reconciling
+//   expectedStatus: Current
+//   object:
+//     ...
+//
+// The fixtures can be verified from a regular go test:
+//
+//   func TestKstatusConformance(t *testing.T) {
+//     if err := conformance.VerifyFile("testdata/lifecycle.yaml"); err != nil {
+//       t.Error(err)
+//     }
+//   }
+//
+// Besides checking that status.Compute returns the expected status
+// for every snapshot, the harness also looks for common mistakes that
+// make the status unreliable, like not setting observedGeneration,
+// only setting the Ready condition once the resource is ready, and
+// using the Reconciling and Stalled conditions with the wrong polarity.
+package conformance
@@ -0,0 +1,91 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package manifestreader
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFingerprint(t *testing.T) {
+	testCases := map[string]struct {
+		change  func(dir string) error
+		changed bool
+	}{
+		"no change": {
+			change:  func(string) error { return nil },
+			changed: false,
+		},
+		"file added": {
+			change: func(dir string) error {
+				return ioutil.WriteFile(filepath.Join(dir, "cm.yaml"), []byte(cmManifest), 0600)
+			},
+			changed: true,
+		},
+		"file removed": {
+			change: func(dir string) error {
+				return os.Remove(filepath.Join(dir, "dep.yaml"))
+			},
+			changed: true,
+		},
+		"file modified": {
+			change: func(dir string) error {
+				path := filepath.Join(dir, "dep.yaml")
+				if err := ioutil.WriteFile(path, []byte(depManifest+"\n"), 0600); err != nil {
+					return err
+				}
+				mtime := time.Now().Add(time.Hour)
+				return os.Chtimes(path, mtime, mtime)
+			},
+			changed: true,
+		},
+		"file in subdirectory added": {
+			change: func(dir string) error {
+				sub := filepath.Join(dir, "sub")
+				if err := os.Mkdir(sub, 0700); err != nil {
+					return err
+				}
+				return ioutil.WriteFile(filepath.Join(sub, "cm.yaml"), []byte(cmManifest), 0600)
+			},
+			changed: true,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "fingerprint-test")
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			defer os.RemoveAll(dir)
+			err = ioutil.WriteFile(filepath.Join(dir, "dep.yaml"), []byte(depManifest), 0600)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+
+			before, err := Fingerprint(dir)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			if !assert.NoError(t, tc.change(dir)) {
+				t.FailNow()
+			}
+			after, err := Fingerprint(dir)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			assert.Equal(t, tc.changed, before != after)
+		})
+	}
+}
+
+func TestFingerprint_MissingDirectory(t *testing.T) {
+	_, err := Fingerprint(filepath.Join(os.TempDir(), "fingerprint-test-does-not-exist"))
+	assert.Error(t, err)
+}
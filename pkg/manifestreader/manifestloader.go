@@ -18,16 +18,32 @@ type ManifestLoader interface {
 	ManifestReader(reader io.Reader, args []string) (ManifestReader, error)
 }
 
+// LoaderOptions configures the readers returned by the manifestLoader.
+type LoaderOptions struct {
+	// Validate enables validation of the objects against the schemas
+	// for their types. See ReaderOptions.Validate.
+	Validate bool
+}
+
 // manifestLoader implements the ManifestLoader interface
 // for ConfigMap as the inventory object.
 type manifestLoader struct {
 	factory util.Factory
+	options *LoaderOptions
 }
 
 // NewManifestLoader returns an instance of manifestLoader.
 func NewManifestLoader(f util.Factory) ManifestLoader {
+	return NewManifestLoaderWithOptions(f, &LoaderOptions{})
+}
+
+// NewManifestLoaderWithOptions returns an instance of manifestLoader
+// configured with the provided options. The options are read every time
+// a reader is created, so they can be bound to command line flags.
+func NewManifestLoaderWithOptions(f util.Factory, options *LoaderOptions) ManifestLoader {
 	return &manifestLoader{
 		factory: f,
+		options: options,
 	}
 }
 
@@ -58,6 +74,16 @@ func (f *manifestLoader) ManifestReader(reader io.Reader, args []string) (Manife
 		Namespace:        namespace,
 		EnforceNamespace: enforceNamespace,
 	}
+	if f.options.Validate {
+		// The OpenAPI schema is only fetched from the cluster if the
+		// objects are validated.
+		validator, err := f.factory.Validator(true)
+		if err != nil {
+			return nil, err
+		}
+		readerOptions.Validate = true
+		readerOptions.Validator = validator
+	}
 
 	var mReader ManifestReader
 	if len(args) == 0 {
@@ -6,6 +6,7 @@ package manifestreader
 import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/kubectl/pkg/validation"
 )
 
 // ManifestReader defines the interface for reading a set
@@ -17,8 +18,15 @@ type ManifestReader interface {
 // ReaderOptions defines the shared inputs for the different
 // implementations of the ManifestReader interface.
 type ReaderOptions struct {
-	Mapper           meta.RESTMapper
-	Validate         bool
+	Mapper meta.RESTMapper
+	// Validate defines whether the objects are validated against the
+	// schemas for their types. All the errors found are returned
+	// together as ValidationErrors.
+	Validate bool
+	// Validator validates objects against the OpenAPI schema of the
+	// cluster. Objects with types defined by CustomResourceDefinitions
+	// in the manifests are validated against those instead.
+	Validator        validation.Schema
 	Namespace        string
 	EnforceNamespace bool
 }
@@ -43,6 +43,12 @@ func (p *PathManifestReader) Read() ([]*unstructured.Unstructured, error) {
 		objs = append(objs, u)
 	}
 
+	if p.Validate {
+		if err := validateNodes(p.Validator, nodes, objs, p.Path, p.Path); err != nil {
+			return objs, err
+		}
+	}
+
 	objs = FilterLocalConfig(objs)
 
 	err = SetNamespaces(p.Mapper, objs, p.Namespace, p.EnforceNamespace)
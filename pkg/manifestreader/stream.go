@@ -46,6 +46,12 @@ func (r *StreamManifestReader) Read() ([]*unstructured.Unstructured, error) {
 		objs = append(objs, u)
 	}
 
+	if r.Validate {
+		if err := validateNodes(r.Validator, nodes, objs, r.ReaderName, ""); err != nil {
+			return objs, err
+		}
+	}
+
 	objs = FilterLocalConfig(objs)
 
 	err = SetNamespaces(r.Mapper, objs, r.Namespace, r.EnforceNamespace)
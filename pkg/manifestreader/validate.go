@@ -0,0 +1,379 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package manifestreader
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	openapivalidation "k8s.io/kube-openapi/pkg/util/proto/validation"
+	"k8s.io/kubectl/pkg/validation"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/kustomize/kyaml/kio/filters"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// ValidationError is an error in a manifest found when validating
+// it against the schema for its type.
+type ValidationError struct {
+	// Source is the file the manifest was read from, or the name of
+	// the reader.
+	Source string
+	// Line is the line of the invalid field in the source, or zero if
+	// it is not known.
+	Line int
+	// Object identifies the object, like "Deployment default/web".
+	Object string
+	Err    error
+}
+
+func (e ValidationError) Error() string {
+	location := e.Source
+	if e.Line > 0 {
+		location = fmt.Sprintf("%s:%d", e.Source, e.Line)
+	}
+	return fmt.Sprintf("%s: %s: %v", location, e.Object, e.Err)
+}
+
+// ValidationErrors contains all the errors found when validating a
+// set of manifests, so they can be reported at once.
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "found %d validation error(s) in the manifests:", len(e))
+	for _, err := range e {
+		fmt.Fprintf(&b, "\n  %v", err)
+	}
+	return b.String()
+}
+
+// validateNodes validates the objects against their schemas, where
+// objs[i] was read from nodes[i]. Objects with a type defined by a
+// CustomResourceDefinition among the objects are validated against
+// the openAPIV3Schema in the CustomResourceDefinition, and the other
+// objects are validated by the validator, if it is set. Objects that
+// are local configuration are not applied, so they are not validated.
+// The source is used for objects without a path annotation, and the
+// path annotations are relative to the dir.
+func validateNodes(validator validation.Schema, nodes []*yaml.RNode, objs []*unstructured.Unstructured,
+	source, dir string) error {
+	var errs ValidationErrors
+	crdSchemas := make(map[schema.GroupVersionKind]*apiextensionsv1.JSONSchemaProps)
+	for i, obj := range objs {
+		if !object.IsCRD(obj) {
+			continue
+		}
+		if err := addCRDSchemas(crdSchemas, obj); err != nil {
+			errs = append(errs, newValidationError(nodes[i], obj, source, dir, nil, err))
+		}
+	}
+
+	for i, obj := range objs {
+		if _, found := obj.GetAnnotations()[filters.LocalConfigAnnotation]; found {
+			continue
+		}
+		gvk := obj.GroupVersionKind()
+		if s, found := crdSchemas[gvk]; found {
+			for _, fe := range validateValue([]string{gvk.Kind}, obj.Object, s, true) {
+				errs = append(errs, newValidationError(nodes[i], obj, source, dir, fe.path[1:], fe.err))
+			}
+			continue
+		}
+		if validator == nil {
+			continue
+		}
+		b, err := json.Marshal(obj.Object)
+		if err != nil {
+			return err
+		}
+		err = validator.ValidateBytes(b)
+		if err == nil {
+			continue
+		}
+		for _, e := range flatten(err) {
+			errs = append(errs, newValidationError(nodes[i], obj, source, dir, openAPIErrorPath(e), e))
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// newValidationError returns a ValidationError for the field with the
+// path in the object read from the node.
+func newValidationError(node *yaml.RNode, obj *unstructured.Unstructured, source, dir string,
+	path []string, err error) ValidationError {
+	if p, found := obj.GetAnnotations()[kioutil.PathAnnotation]; found {
+		source = filepath.Join(dir, p)
+	}
+	name := obj.GetName()
+	if ns := obj.GetNamespace(); ns != "" {
+		name = ns + "/" + name
+	}
+	return ValidationError{
+		Source: source,
+		Line:   lineFor(node.YNode(), path),
+		Object: fmt.Sprintf("%s %s", obj.GetKind(), name),
+		Err:    err,
+	}
+}
+
+// flatten returns the errors in an aggregate error, which the
+// validators nest.
+func flatten(err error) []error {
+	if agg, ok := err.(utilerrors.Aggregate); ok {
+		return utilerrors.Flatten(agg).Errors()
+	}
+	return []error{err}
+}
+
+// openAPIErrorPath returns the path to the field in an error from
+// validation against the OpenAPI schema of the cluster, or nil if
+// the error doesn't have a path.
+func openAPIErrorPath(err error) []string {
+	verr, ok := err.(openapivalidation.ValidationError)
+	if !ok {
+		return nil
+	}
+	// The path starts with the kind of the object, like
+	// "Deployment.spec.template.spec.containers[0]".
+	var path []string
+	for i, segment := range strings.Split(verr.Path, ".") {
+		if i == 0 {
+			continue
+		}
+		name := segment
+		var indexes []string
+		if j := strings.Index(segment, "["); j >= 0 {
+			name = segment[:j]
+			for _, index := range strings.Split(segment[j:], "]") {
+				if index != "" {
+					indexes = append(indexes, index+"]")
+				}
+			}
+		}
+		path = append(append(path, name), indexes...)
+	}
+	if unknown, ok := verr.Err.(openapivalidation.UnknownFieldError); ok {
+		path = append(path, unknown.Field)
+	}
+	return path
+}
+
+// lineFor returns the line of the field with the path in the node.
+// If the field is not found, the line of the closest parent is
+// returned.
+func lineFor(node *yaml.Node, path []string) int {
+	if node == nil {
+		return 0
+	}
+	line := node.Line
+	for _, segment := range path {
+		var next *yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == segment {
+					line = node.Content[i].Line
+					next = node.Content[i+1]
+					break
+				}
+			}
+		case yaml.SequenceNode:
+			index, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(segment, "["), "]"))
+			if err == nil && index >= 0 && index < len(node.Content) {
+				next = node.Content[index]
+				line = next.Line
+			}
+		}
+		if next == nil {
+			return line
+		}
+		node = next
+	}
+	return line
+}
+
+// addCRDSchemas adds the openAPIV3Schema for every version defined by
+// the CustomResourceDefinition. Both the v1 and v1beta1 versions of
+// CustomResourceDefinition are supported.
+func addCRDSchemas(schemas map[schema.GroupVersionKind]*apiextensionsv1.JSONSchemaProps,
+	crd *unstructured.Unstructured) error {
+	group, _, _ := unstructured.NestedString(crd.Object, "spec", "group")
+	kind, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "kind")
+	// The v1beta1 schema can be shared by all the versions.
+	shared, _, _ := unstructured.NestedMap(crd.Object, "spec", "validation", "openAPIV3Schema")
+
+	versions := make(map[string]map[string]interface{})
+	if version, found, _ := unstructured.NestedString(crd.Object, "spec", "version"); found {
+		versions[version] = shared
+	}
+	list, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	for _, v := range list {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(m, "name")
+		s, found, _ := unstructured.NestedMap(m, "schema", "openAPIV3Schema")
+		if !found {
+			s = shared
+		}
+		versions[name] = s
+	}
+
+	for version, s := range versions {
+		if s == nil {
+			continue
+		}
+		b, err := json.Marshal(s)
+		if err != nil {
+			return err
+		}
+		props := &apiextensionsv1.JSONSchemaProps{}
+		if err := json.Unmarshal(b, props); err != nil {
+			return fmt.Errorf("invalid openAPIV3Schema for version %s: %v", version, err)
+		}
+		schemas[schema.GroupVersionKind{Group: group, Version: version, Kind: kind}] = props
+	}
+	return nil
+}
+
+type fieldError struct {
+	path []string
+	err  error
+}
+
+// validateValue validates the value with the path against the schema
+// of a custom resource. It checks the types, required fields, unknown
+// fields and enums, which covers the mistakes that are otherwise only
+// found when the object is applied, or silently pruned by the server.
+// The apiVersion, kind and metadata of the object are not checked.
+func validateValue(path []string, value interface{}, s *apiextensionsv1.JSONSchemaProps, root bool) []fieldError {
+	p := fieldPath(path)
+	if value == nil {
+		if s.Nullable || s.Type == "" {
+			return nil
+		}
+		return []fieldError{{path, fmt.Errorf("invalid type for %s: got %q, expected %q", p, "null", s.Type)}}
+	}
+	if actual := jsonType(value); !typeMatches(s, actual) {
+		return []fieldError{{path, fmt.Errorf("invalid type for %s: got %q, expected %q", p, actual, s.Type)}}
+	}
+	if len(s.Enum) > 0 {
+		b, _ := json.Marshal(value)
+		found := false
+		var allowed []string
+		for _, e := range s.Enum {
+			if string(e.Raw) == string(b) {
+				found = true
+				break
+			}
+			allowed = append(allowed, string(e.Raw))
+		}
+		if !found {
+			return []fieldError{{path, fmt.Errorf("unsupported value %s for %s, must be one of %s",
+				string(b), p, strings.Join(allowed, ", "))}}
+		}
+	}
+
+	var errs []fieldError
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, r := range s.Required {
+			if _, found := v[r]; !found {
+				errs = append(errs, fieldError{path, fmt.Errorf("missing required field %q in %s", r, p)})
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		preserveUnknown := s.XPreserveUnknownFields != nil && *s.XPreserveUnknownFields
+		for _, k := range keys {
+			if root && (k == "apiVersion" || k == "kind" || k == "metadata") {
+				continue
+			}
+			keyPath := append(append([]string{}, path...), k)
+			if prop, found := s.Properties[k]; found {
+				prop := prop
+				errs = append(errs, validateValue(keyPath, v[k], &prop, false)...)
+			} else if s.AdditionalProperties != nil && s.AdditionalProperties.Schema != nil {
+				errs = append(errs, validateValue(keyPath, v[k], s.AdditionalProperties.Schema, false)...)
+			} else if len(s.Properties) > 0 && !preserveUnknown &&
+				(s.AdditionalProperties == nil || !s.AdditionalProperties.Allows) {
+				errs = append(errs, fieldError{keyPath, fmt.Errorf("unknown field %q in %s", k, p)})
+			}
+		}
+	case []interface{}:
+		if s.Items != nil && s.Items.Schema != nil {
+			for i, item := range v {
+				itemPath := append(append([]string{}, path...), fmt.Sprintf("[%d]", i))
+				errs = append(errs, validateValue(itemPath, item, s.Items.Schema, false)...)
+			}
+		}
+	}
+	return errs
+}
+
+// fieldPath returns the path in the form used in the error messages,
+// like "CronTab.spec.containers[0]".
+func fieldPath(path []string) string {
+	var b strings.Builder
+	for i, segment := range path {
+		if i > 0 && !strings.HasPrefix(segment, "[") {
+			b.WriteString(".")
+		}
+		b.WriteString(segment)
+	}
+	return b.String()
+}
+
+// jsonType returns the JSON schema type of the value.
+func jsonType(value interface{}) string {
+	switch v := value.(type) {
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case int, int32, int64:
+		return "integer"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func typeMatches(s *apiextensionsv1.JSONSchemaProps, actual string) bool {
+	if s.XIntOrString {
+		return actual == "integer" || actual == "string"
+	}
+	switch s.Type {
+	case "":
+		return true
+	case "number":
+		return actual == "number" || actual == "integer"
+	}
+	return s.Type == actual
+}
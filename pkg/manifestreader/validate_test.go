@@ -0,0 +1,304 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package manifestreader
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	openapivalidation "k8s.io/kube-openapi/pkg/util/proto/validation"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+)
+
+var (
+	crdManifest = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: crontabs.example.com
+spec:
+  group: example.com
+  scope: Namespaced
+  names:
+    kind: CronTab
+    plural: crontabs
+  versions:
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            required:
+            - schedule
+            properties:
+              schedule:
+                type: string
+              replicas:
+                type: integer
+              policy:
+                type: string
+                enum:
+                - Allow
+                - Forbid
+              labels:
+                type: object
+                additionalProperties:
+                  type: string
+              extra:
+                type: object
+                x-kubernetes-preserve-unknown-fields: true
+`
+
+	validCronTabManifest = `apiVersion: example.com/v1
+kind: CronTab
+metadata:
+  name: cron
+spec:
+  schedule: "* * * * *"
+  replicas: 2
+  policy: Allow
+  labels:
+    app: cron
+  extra:
+    anything: goes
+`
+
+	invalidCronTabManifest = `apiVersion: example.com/v1
+kind: CronTab
+metadata:
+  name: cron
+spec:
+  replicas: two
+  policy: Replace
+  schedul: "* * * * *"
+`
+
+	localCronTabManifest = `apiVersion: example.com/v1
+kind: CronTab
+metadata:
+  name: local
+  annotations:
+    config.kubernetes.io/local-config: "true"
+spec:
+  unknown: true
+`
+
+	deploymentTypoManifest = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: dep
+spec:
+  replica: 1
+`
+)
+
+// fakeValidator reports an unknown replica field in Deployments, like
+// the validator for the OpenAPI schema of the cluster.
+type fakeValidator struct{}
+
+func (fakeValidator) ValidateBytes(data []byte) error {
+	var obj map[string]interface{}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	if obj["kind"] != "Deployment" {
+		return nil
+	}
+	spec, _ := obj["spec"].(map[string]interface{})
+	if _, found := spec["replica"]; !found {
+		return nil
+	}
+	return utilerrors.NewAggregate([]error{
+		utilerrors.NewAggregate([]error{
+			openapivalidation.ValidationError{
+				Path: "Deployment.spec",
+				Err: openapivalidation.UnknownFieldError{
+					Path:  "io.k8s.api.apps.v1.DeploymentSpec",
+					Field: "replica",
+				},
+			},
+		}),
+	})
+}
+
+func TestValidate(t *testing.T) {
+	type expectedError struct {
+		source  string
+		line    int
+		object  string
+		message string
+	}
+
+	testCases := map[string]struct {
+		manifests      map[string]string
+		expectedErrors []expectedError
+	}{
+		"valid objects": {
+			manifests: map[string]string{
+				"crd.yaml":     crdManifest,
+				"crontab.yaml": validCronTabManifest,
+				"cm.yaml":      cmManifest,
+			},
+		},
+		"custom resource is validated against the crd in the package": {
+			manifests: map[string]string{
+				"crd.yaml":     crdManifest,
+				"crontab.yaml": invalidCronTabManifest,
+			},
+			expectedErrors: []expectedError{
+				{
+					source:  "crontab.yaml",
+					line:    5,
+					object:  "CronTab cron",
+					message: `missing required field "schedule" in CronTab.spec`,
+				},
+				{
+					source:  "crontab.yaml",
+					line:    7,
+					object:  "CronTab cron",
+					message: `unsupported value "Replace" for CronTab.spec.policy, must be one of "Allow", "Forbid"`,
+				},
+				{
+					source:  "crontab.yaml",
+					line:    6,
+					object:  "CronTab cron",
+					message: `invalid type for CronTab.spec.replicas: got "string", expected "integer"`,
+				},
+				{
+					source:  "crontab.yaml",
+					line:    8,
+					object:  "CronTab cron",
+					message: `unknown field "schedul" in CronTab.spec`,
+				},
+			},
+		},
+		"local config is not validated": {
+			manifests: map[string]string{
+				"crd.yaml":     crdManifest,
+				"crontab.yaml": localCronTabManifest,
+			},
+		},
+		"errors from the cluster schema have the line of the field": {
+			manifests: map[string]string{
+				"dep.yaml": deploymentTypoManifest,
+			},
+			expectedErrors: []expectedError{
+				{
+					source:  "dep.yaml",
+					line:    6,
+					object:  "Deployment dep",
+					message: `unknown field "replica"`,
+				},
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			tf := cmdtesting.NewTestFactory().WithNamespace("test-ns")
+			defer tf.Cleanup()
+
+			mapper, err := tf.ToRESTMapper()
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+
+			dir, err := ioutil.TempDir("", "validate-test")
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			defer os.RemoveAll(dir)
+			for filename, content := range tc.manifests {
+				err = ioutil.WriteFile(filepath.Join(dir, filename), []byte(content), 0600)
+				if !assert.NoError(t, err) {
+					t.FailNow()
+				}
+			}
+
+			_, err = (&PathManifestReader{
+				Path: dir,
+				ReaderOptions: ReaderOptions{
+					Mapper:    mapper,
+					Namespace: "default",
+					Validate:  true,
+					Validator: fakeValidator{},
+				},
+			}).Read()
+
+			if len(tc.expectedErrors) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			validationErrs, ok := err.(ValidationErrors)
+			if !assert.True(t, ok, "expected ValidationErrors, got %v", err) {
+				return
+			}
+			if !assert.Equal(t, len(tc.expectedErrors), len(validationErrs)) {
+				return
+			}
+			for i, expected := range tc.expectedErrors {
+				actual := validationErrs[i]
+				assert.Equal(t, filepath.Join(dir, expected.source), actual.Source)
+				assert.Equal(t, expected.line, actual.Line)
+				assert.Equal(t, expected.object, actual.Object)
+				assert.Contains(t, actual.Err.Error(), expected.message)
+			}
+		})
+	}
+}
+
+func TestValidationErrors(t *testing.T) {
+	err := ValidationErrors{
+		{
+			Source: "crontab.yaml",
+			Line:   8,
+			Object: "CronTab default/cron",
+			Err:    openapivalidation.UnknownFieldError{Path: "CronTab.spec", Field: "schedul"},
+		},
+		{
+			Source: "stdin",
+			Object: "Deployment default/dep",
+			Err:    openapivalidation.UnknownFieldError{Path: "Deployment.spec", Field: "replica"},
+		},
+	}
+	assert.Equal(t, strings.Join([]string{
+		"found 2 validation error(s) in the manifests:",
+		`  crontab.yaml:8: CronTab default/cron: unknown field "schedul" in CronTab.spec`,
+		`  stdin: Deployment default/dep: unknown field "replica" in Deployment.spec`,
+	}, "\n"), err.Error())
+}
+
+func TestManifestLoaderValidateOption(t *testing.T) {
+	tf := cmdtesting.NewTestFactory().WithNamespace("test-ns")
+	defer tf.Cleanup()
+
+	options := &LoaderOptions{}
+	loader := NewManifestLoaderWithOptions(tf, options)
+
+	reader, err := loader.ManifestReader(strings.NewReader(cmManifest), nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.False(t, reader.(*StreamManifestReader).Validate)
+
+	// The options are read when the reader is created, so changes
+	// made after the loader was created are used.
+	options.Validate = true
+	reader, err = loader.ManifestReader(strings.NewReader(cmManifest), nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	sr := reader.(*StreamManifestReader)
+	assert.True(t, sr.Validate)
+	assert.NotNil(t, sr.Validator)
+}
@@ -0,0 +1,107 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package object
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Filter selects a subset of objects by label selector, kind and
+// name. An object is selected if it matches all the fields that are
+// set, so an empty Filter selects all objects.
+type Filter struct {
+	// Selector is a label selector, like "app=web".
+	Selector string
+	// Kinds are the kinds of the selected objects. A kind can be
+	// qualified with the group, like "Deployment.apps". Kinds are
+	// matched case-insensitively.
+	Kinds []string
+	// Names are the names of the selected objects.
+	Names []string
+}
+
+// IsEmpty returns true if the filter selects all objects.
+func (f Filter) IsEmpty() bool {
+	return f.Selector == "" && len(f.Kinds) == 0 && len(f.Names) == 0
+}
+
+// Validate checks that the selector of the filter can be parsed.
+func (f Filter) Validate() error {
+	if _, err := labels.Parse(f.Selector); err != nil {
+		return fmt.Errorf("invalid selector %q: %v", f.Selector, err)
+	}
+	return nil
+}
+
+// Matches returns true if the object is selected by the filter. An
+// invalid selector doesn't match any objects, so Validate should be
+// used to reject it first.
+func (f Filter) Matches(obj *unstructured.Unstructured) bool {
+	if !f.MatchesID(UnstructuredToObjMeta(obj)) {
+		return false
+	}
+	return f.MatchesLabels(obj)
+}
+
+// MatchesID returns true if the kind and name of the identifier are
+// selected by the filter. The selector is not checked, since that
+// requires the labels of the object.
+func (f Filter) MatchesID(id ObjMetadata) bool {
+	if len(f.Kinds) > 0 {
+		found := false
+		for _, k := range f.Kinds {
+			if strings.EqualFold(k, id.GroupKind.Kind) ||
+				(id.GroupKind.Group != "" && strings.EqualFold(k, id.GroupKind.Kind+"."+id.GroupKind.Group)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(f.Names) > 0 {
+		found := false
+		for _, n := range f.Names {
+			if n == id.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchesLabels returns true if the labels of the object are selected
+// by the selector of the filter.
+func (f Filter) MatchesLabels(obj *unstructured.Unstructured) bool {
+	if f.Selector == "" {
+		return true
+	}
+	selector, err := labels.Parse(f.Selector)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(obj.GetLabels()))
+}
+
+// Split divides the objects into the ones that are selected by the
+// filter and the ones that are not. The order of the objects is kept.
+func (f Filter) Split(objs []*unstructured.Unstructured) (selected, unselected []*unstructured.Unstructured) {
+	for _, obj := range objs {
+		if f.Matches(obj) {
+			selected = append(selected, obj)
+		} else {
+			unselected = append(unselected, obj)
+		}
+	}
+	return selected, unselected
+}
@@ -0,0 +1,85 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package object
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newFilterTestObj(apiVersion, kind, name string, labels map[string]string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": apiVersion,
+			"kind":       kind,
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": "default",
+			},
+		},
+	}
+	u.SetLabels(labels)
+	return u
+}
+
+func TestFilter(t *testing.T) {
+	dep := newFilterTestObj("apps/v1", "Deployment", "web", map[string]string{"app": "web"})
+	cm := newFilterTestObj("v1", "ConfigMap", "web", map[string]string{"app": "web"})
+	svc := newFilterTestObj("v1", "Service", "db", map[string]string{"app": "db"})
+	objs := []*unstructured.Unstructured{dep, cm, svc}
+
+	testCases := map[string]struct {
+		filter             Filter
+		expectedSelected   []*unstructured.Unstructured
+		expectedUnselected []*unstructured.Unstructured
+	}{
+		"empty filter selects all objects": {
+			expectedSelected: objs,
+		},
+		"selector": {
+			filter:             Filter{Selector: "app=web"},
+			expectedSelected:   []*unstructured.Unstructured{dep, cm},
+			expectedUnselected: []*unstructured.Unstructured{svc},
+		},
+		"kind is matched case-insensitively": {
+			filter:             Filter{Kinds: []string{"deployment", "Service"}},
+			expectedSelected:   []*unstructured.Unstructured{dep, svc},
+			expectedUnselected: []*unstructured.Unstructured{cm},
+		},
+		"kind qualified with group": {
+			filter:             Filter{Kinds: []string{"Deployment.apps"}},
+			expectedSelected:   []*unstructured.Unstructured{dep},
+			expectedUnselected: []*unstructured.Unstructured{cm, svc},
+		},
+		"all fields must match": {
+			filter: Filter{
+				Selector: "app=web",
+				Kinds:    []string{"ConfigMap", "Service"},
+				Names:    []string{"web", "db"},
+			},
+			expectedSelected:   []*unstructured.Unstructured{cm},
+			expectedUnselected: []*unstructured.Unstructured{dep, svc},
+		},
+		"invalid selector matches nothing": {
+			filter:             Filter{Selector: "app in (web"},
+			expectedUnselected: objs,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			selected, unselected := tc.filter.Split(objs)
+			assert.Equal(t, tc.expectedSelected, selected)
+			assert.Equal(t, tc.expectedUnselected, unselected)
+		})
+	}
+}
+
+func TestFilterValidate(t *testing.T) {
+	assert.NoError(t, Filter{}.Validate())
+	assert.NoError(t, Filter{Selector: "app=web,tier!=db"}.Validate())
+	assert.Error(t, Filter{Selector: "app in (web"}.Validate())
+}
@@ -0,0 +1,22 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package object
+
+import (
+	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// IsCRD returns true if the object is a CustomResourceDefinition in
+// either the v1 or the v1beta1 version of the apiextensions API.
+func IsCRD(obj *unstructured.Unstructured) bool {
+	if obj == nil {
+		return false
+	}
+	gvk := obj.GroupVersionKind()
+	return (gvk.Group == v1.SchemeGroupVersion.Group ||
+		gvk.Group == v1beta1.SchemeGroupVersion.Group) &&
+		gvk.Kind == "CustomResourceDefinition"
+}
@@ -0,0 +1,50 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package object
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestIsCRD(t *testing.T) {
+	testCases := map[string]struct {
+		apiVersion string
+		kind       string
+		expected   bool
+	}{
+		"v1 crd": {
+			apiVersion: "apiextensions.k8s.io/v1",
+			kind:       "CustomResourceDefinition",
+			expected:   true,
+		},
+		"v1beta1 crd": {
+			apiVersion: "apiextensions.k8s.io/v1beta1",
+			kind:       "CustomResourceDefinition",
+			expected:   true,
+		},
+		"crd kind in another group": {
+			apiVersion: "example.com/v1",
+			kind:       "CustomResourceDefinition",
+			expected:   false,
+		},
+		"not a crd": {
+			apiVersion: "v1",
+			kind:       "ConfigMap",
+			expected:   false,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			u := &unstructured.Unstructured{}
+			u.SetAPIVersion(tc.apiVersion)
+			u.SetKind(tc.kind)
+			assert.Equal(t, tc.expected, IsCRD(u))
+		})
+	}
+	assert.False(t, IsCRD(nil))
+}
@@ -0,0 +1,129 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package junit contains types for writing JUnit XML reports. The
+// reports follow the format understood by most CI systems, where
+// a report has a number of test suites, each containing test cases
+// that can pass, fail or be skipped.
+package junit
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// TestSuites is the root element of a JUnit report.
+type TestSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Time     float64      `xml:"time,attr"`
+	Suites   []*TestSuite `xml:"testsuite"`
+}
+
+// TestSuite is a group of test cases.
+type TestSuite struct {
+	XMLName   xml.Name    `xml:"testsuite"`
+	Name      string      `xml:"name,attr"`
+	Tests     int         `xml:"tests,attr"`
+	Failures  int         `xml:"failures,attr"`
+	Skipped   int         `xml:"skipped,attr"`
+	Timestamp string      `xml:"timestamp,attr,omitempty"`
+	Time      float64     `xml:"time,attr"`
+	TestCases []*TestCase `xml:"testcase"`
+}
+
+// TestCase is a single test case. If neither Failure nor Skipped is
+// set, the test case passed.
+type TestCase struct {
+	XMLName   xml.Name `xml:"testcase"`
+	Name      string   `xml:"name,attr"`
+	ClassName string   `xml:"classname,attr"`
+	Time      float64  `xml:"time,attr"`
+	Failure   *Failure `xml:"failure,omitempty"`
+	Skipped   *Skipped `xml:"skipped,omitempty"`
+}
+
+// Failure describes why a test case failed.
+type Failure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// Skipped describes why a test case was skipped.
+type Skipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+// NewTestSuite returns a new, empty test suite with the given name.
+func NewTestSuite(name string, start time.Time) *TestSuite {
+	return &TestSuite{
+		Name:      name,
+		Timestamp: start.UTC().Format(time.RFC3339),
+	}
+}
+
+// AddTestCase adds the test case to the suite and updates the counts.
+func (ts *TestSuite) AddTestCase(tc *TestCase) {
+	ts.TestCases = append(ts.TestCases, tc)
+	ts.Tests++
+	if tc.Failure != nil {
+		ts.Failures++
+	}
+	if tc.Skipped != nil {
+		ts.Skipped++
+	}
+}
+
+// NewTestSuites returns the root element for the provided suites, with
+// the counts computed from the suites. Empty suites are left out.
+func NewTestSuites(suites ...*TestSuite) *TestSuites {
+	res := &TestSuites{}
+	for _, s := range suites {
+		if s == nil || len(s.TestCases) == 0 {
+			continue
+		}
+		res.Suites = append(res.Suites, s)
+		res.Tests += s.Tests
+		res.Failures += s.Failures
+		res.Skipped += s.Skipped
+		res.Time += s.Time
+	}
+	return res
+}
+
+// Write writes the report as XML to the provided writer.
+func (t *TestSuites) Write(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(t); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// WriteFile writes the report to the file with the given path. If the
+// path is empty, the report is written to the provided writer.
+func (t *TestSuites) WriteFile(path string, w io.Writer) error {
+	if path == "" {
+		return t.Write(w)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating report file: %v", err)
+	}
+	if err := t.Write(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
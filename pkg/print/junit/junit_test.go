@@ -0,0 +1,61 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package junit
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	apply := NewTestSuite("apply", start)
+	apply.AddTestCase(&TestCase{Name: "deployment.apps/foo", ClassName: "apply.default"})
+	apply.AddTestCase(&TestCase{
+		Name:      "deployment.apps/bar",
+		ClassName: "apply.default",
+		Failure: &Failure{
+			Message: "apply failed: boom",
+			Text:    "boom",
+		},
+	})
+	prune := NewTestSuite("prune", start)
+	prune.AddTestCase(&TestCase{
+		Name:      "configmap/cm",
+		ClassName: "prune.default",
+		Skipped:   &Skipped{Message: "prune skipped"},
+	})
+	empty := NewTestSuite("delete", start)
+
+	report := NewTestSuites(apply, prune, empty)
+	assert.Equal(t, 3, report.Tests)
+	assert.Equal(t, 1, report.Failures)
+	assert.Equal(t, 1, report.Skipped)
+	assert.Len(t, report.Suites, 2)
+
+	var buf bytes.Buffer
+	assert.NoError(t, report.Write(&buf))
+
+	expected := `
+<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="3" failures="1" skipped="1" time="0">
+  <testsuite name="apply" tests="2" failures="1" skipped="0" timestamp="2020-01-01T00:00:00Z" time="0">
+    <testcase name="deployment.apps/foo" classname="apply.default" time="0"></testcase>
+    <testcase name="deployment.apps/bar" classname="apply.default" time="0">
+      <failure message="apply failed: boom">boom</failure>
+    </testcase>
+  </testsuite>
+  <testsuite name="prune" tests="1" failures="0" skipped="1" timestamp="2020-01-01T00:00:00Z" time="0">
+    <testcase name="configmap/cm" classname="prune.default" time="0">
+      <skipped message="prune skipped"></skipped>
+    </testcase>
+  </testsuite>
+</testsuites>
+`
+	assert.Equal(t, strings.TrimSpace(expected), strings.TrimSpace(buf.String()))
+}
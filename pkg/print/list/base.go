@@ -9,6 +9,7 @@ import (
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
 	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/collector"
 	"sigs.k8s.io/cli-utils/pkg/object"
 )
 
@@ -20,12 +21,44 @@ type Formatter interface {
 	FormatErrorEvent(ee event.ErrorEvent) error
 }
 
+// StatusSummaryFormatter can be implemented by formatters that are able
+// to print a summary of how long it took for resources to become Current.
+// It is a separate interface so existing Formatter implementations
+// don't need to change.
+type StatusSummaryFormatter interface {
+	FormatStatusSummary(ts collector.TimelineSummary) error
+}
+
+// FinishFormatter can be implemented by formatters that need to do
+// something once all events have been processed, like writing a report.
+// Finish is also called if processing stops because of an error event.
+type FinishFormatter interface {
+	Finish() error
+}
+
+// DriftFormatter can be implemented by formatters that are able to print
+// the events from drift detection. The printer fails on drift events if
+// the formatter doesn't implement it, so drift is never silently lost.
+type DriftFormatter interface {
+	FormatDriftEvent(de event.DriftEvent, ds *DriftStats) error
+}
+
+// RollbackFormatter can be implemented by formatters that are able to
+// print the events from rolling back a failed atomic apply.
+type RollbackFormatter interface {
+	FormatRollbackEvent(re event.RollbackEvent, rs *RollbackStats) error
+}
+
 type FormatterFactory func(ioStreams genericclioptions.IOStreams,
 	previewStrategy common.DryRunStrategy) Formatter
 
 type BaseListPrinter struct {
 	FormatterFactory FormatterFactory
 	IOStreams        genericclioptions.IOStreams
+	// StatusSummary enables recording the status transitions for all
+	// resources, and printing a summary at the end if the formatter
+	// implements the StatusSummaryFormatter interface.
+	StatusSummary bool
 }
 
 type ApplyStats struct {
@@ -34,6 +67,7 @@ type ApplyStats struct {
 	Unchanged         int
 	Configured        int
 	Failed            int
+	Recreated         int
 }
 
 func (a *ApplyStats) inc(op event.ApplyEventOperation) {
@@ -48,13 +82,15 @@ func (a *ApplyStats) inc(op event.ApplyEventOperation) {
 		a.Configured++
 	case event.Failed:
 		a.Failed++
+	case event.Recreated:
+		a.Recreated++
 	default:
 		panic(fmt.Errorf("unknown apply operation %s", op.String()))
 	}
 }
 
 func (a *ApplyStats) Sum() int {
-	return a.ServersideApplied + a.Configured + a.Unchanged + a.Created + a.Failed
+	return a.ServersideApplied + a.Configured + a.Unchanged + a.Created + a.Failed + a.Recreated
 }
 
 type PruneStats struct {
@@ -93,6 +129,55 @@ func (d *DeleteStats) incFailed() {
 	d.Failed++
 }
 
+type DriftStats struct {
+	InSync           int
+	DeletedOutOfBand int
+	FieldsChanged    int
+	InventoryChanged int
+	NotInInventory   int
+}
+
+func (d *DriftStats) inc(op event.DriftEventOperation) {
+	switch op {
+	case event.InSync:
+		d.InSync++
+	case event.DeletedOutOfBand:
+		d.DeletedOutOfBand++
+	case event.FieldsChanged:
+		d.FieldsChanged++
+	case event.InventoryChanged:
+		d.InventoryChanged++
+	case event.NotInInventory:
+		d.NotInInventory++
+	default:
+		panic(fmt.Errorf("unknown drift operation %s", op.String()))
+	}
+}
+
+// Drifted returns the number of resources that have drifted.
+func (d *DriftStats) Drifted() int {
+	return d.DeletedOutOfBand + d.FieldsChanged + d.InventoryChanged + d.NotInInventory
+}
+
+type RollbackStats struct {
+	Restored int
+	Deleted  int
+	Failed   int
+}
+
+func (r *RollbackStats) inc(re event.RollbackEvent) {
+	switch {
+	case re.Type == event.RollbackEventFailed:
+		r.Failed++
+	case re.Operation == event.Restored:
+		r.Restored++
+	case re.Operation == event.RollbackDeleted:
+		r.Deleted++
+	default:
+		panic(fmt.Errorf("unknown rollback operation %s", re.Operation.String()))
+	}
+}
+
 type Collector interface {
 	LatestStatus() map[object.ObjMetadata]event.StatusEvent
 }
@@ -121,11 +206,20 @@ func (b *BaseListPrinter) Print(ch <-chan event.Event, previewStrategy common.Dr
 	printStatus := false
 	pruneStats := &PruneStats{}
 	deleteStats := &DeleteStats{}
+	driftStats := &DriftStats{}
+	rollbackStats := &RollbackStats{}
 	formatter := b.FormatterFactory(b.IOStreams, previewStrategy)
+	var timeline *collector.TimelineRecorder
+	if b.StatusSummary {
+		timeline = collector.NewTimelineRecorder()
+	}
 	for e := range ch {
 		switch e.Type {
 		case event.ErrorType:
 			_ = formatter.FormatErrorEvent(e.ErrorEvent)
+			if ff, ok := formatter.(FinishFormatter); ok {
+				_ = ff.Finish()
+			}
 			return e.ErrorEvent.Err
 		case event.ApplyType:
 			if e.ApplyEvent.Type == event.ApplyEventResourceUpdate {
@@ -140,6 +234,9 @@ func (b *BaseListPrinter) Print(ch <-chan event.Event, previewStrategy common.Dr
 		case event.StatusType:
 			if se := e.StatusEvent; se.Type == event.StatusEventResourceUpdate {
 				statusCollector.updateStatus(e.StatusEvent.Resource.Identifier, e.StatusEvent)
+				if timeline != nil {
+					timeline.Record(e.StatusEvent.Resource)
+				}
 				if printStatus {
 					if err := formatter.FormatStatusEvent(e.StatusEvent, statusCollector); err != nil {
 						return err
@@ -176,11 +273,44 @@ func (b *BaseListPrinter) Print(ch <-chan event.Event, previewStrategy common.Dr
 			if err := formatter.FormatDeleteEvent(e.DeleteEvent, deleteStats); err != nil {
 				return err
 			}
+		case event.DriftType:
+			if e.DriftEvent.Type == event.DriftEventResourceUpdate {
+				driftStats.inc(e.DriftEvent.Operation)
+			}
+			df, ok := formatter.(DriftFormatter)
+			if !ok {
+				return fmt.Errorf("the output format doesn't support drift events")
+			}
+			if err := df.FormatDriftEvent(e.DriftEvent, driftStats); err != nil {
+				return err
+			}
+		case event.RollbackType:
+			if e.RollbackEvent.Type != event.RollbackEventCompleted {
+				rollbackStats.inc(e.RollbackEvent)
+			}
+			if rf, ok := formatter.(RollbackFormatter); ok {
+				if err := rf.FormatRollbackEvent(e.RollbackEvent, rollbackStats); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if sf, ok := formatter.(StatusSummaryFormatter); ok && timeline != nil {
+		if err := sf.FormatStatusSummary(timeline.Summary()); err != nil {
+			return err
+		}
+	}
+	if ff, ok := formatter.(FinishFormatter); ok {
+		if err := ff.Finish(); err != nil {
+			return err
 		}
 	}
 	failedSum := applyStats.Failed + pruneStats.Failed + deleteStats.Failed
 	if failedSum > 0 {
 		return fmt.Errorf("%d resources failed", failedSum)
 	}
+	if drifted := driftStats.Drifted(); drifted > 0 {
+		return fmt.Errorf("%d resources drifted", drifted)
+	}
 	return nil
 }
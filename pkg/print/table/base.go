@@ -46,6 +46,9 @@ type Resource interface {
 type BaseTablePrinter struct {
 	IOStreams genericclioptions.IOStreams
 	Columns   []ColumnDefinition
+	// SortBy is optional. If set, the top-level resources are sorted
+	// by the given column.
+	SortBy *SortBy
 }
 
 // PrintTable prints the resources defined in ResourceStates. It will
@@ -72,7 +75,11 @@ func (t *BaseTablePrinter) PrintTable(rs ResourceStates,
 		}
 	}
 
-	for _, resource := range rs.Resources() {
+	resources := rs.Resources()
+	if t.SortBy != nil {
+		resources = SortResources(resources, t.SortBy)
+	}
+	for _, resource := range resources {
 		for i, column := range t.Columns {
 			written, err := column.PrintResource(t.IOStreams.Out, column.Width(), resource)
 			if err != nil {
@@ -10,6 +10,7 @@ import (
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/utils/integer"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
 	"sigs.k8s.io/cli-utils/pkg/print/common"
 )
 
@@ -22,6 +23,9 @@ type ColumnDef struct {
 	ColumnHeader      string
 	ColumnWidth       int
 	PrintResourceFunc func(w io.Writer, width int, r Resource) (int, error)
+	// SortKeyFunc is optional. If set, it returns the value used when
+	// sorting by the column. If not set, the printed text is used.
+	SortKeyFunc func(r Resource) string
 }
 
 // Name returns the name of the column.
@@ -47,6 +51,14 @@ func (c ColumnDef) PrintResource(w io.Writer, width int, r Resource) (int, error
 	return c.PrintResourceFunc(w, width, r)
 }
 
+// SortKey returns the value used when sorting resources by the column.
+func (c ColumnDef) SortKey(r Resource) string {
+	if c.SortKeyFunc != nil {
+		return c.SortKeyFunc(r)
+	}
+	return printedText(c, r)
+}
+
 // MustColumn returns the pre-defined column definition with the
 // provided name. If the name doesn't exist, it will panic.
 func MustColumn(name string) ColumnDef {
@@ -187,18 +199,8 @@ var (
 				if rs == nil {
 					return 0, nil
 				}
-				u := rs.Resource
-				if u == nil {
-					return fmt.Fprint(w, "-")
-				}
-
-				timestamp, found, err := unstructured.NestedString(u.Object,
-					"metadata", "creationTimestamp")
-				if !found || err != nil || timestamp == "" {
-					return fmt.Fprint(w, "-")
-				}
-				parsedTime, err := time.Parse(time.RFC3339, timestamp)
-				if err != nil {
+				parsedTime, found := creationTimestamp(r)
+				if !found {
 					return fmt.Fprint(w, "-")
 				}
 				age := time.Since(parsedTime)
@@ -214,6 +216,93 @@ var (
 						integer.RoundToInt32(age.Round(time.Hour).Hours()))
 				}
 			},
+			// The printed age uses different units, so sort by the
+			// number of seconds instead.
+			SortKeyFunc: func(r Resource) string {
+				parsedTime, found := creationTimestamp(r)
+				if !found {
+					return ""
+				}
+				return fmt.Sprintf("%d", int64(time.Since(parsedTime).Seconds()))
+			},
+		},
+		// group defines a column that outputs the group of a resource.
+		"group": {
+			ColumnName:   "group",
+			ColumnHeader: "GROUP",
+			ColumnWidth:  20,
+			PrintResourceFunc: func(w io.Writer, width int, r Resource) (int,
+				error) {
+				return printTrimmed(w, width, r.Identifier().GroupKind.Group)
+			},
+		},
+		// generation defines a column that outputs the generation from
+		// the metadata of a resource.
+		"generation": {
+			ColumnName:   "generation",
+			ColumnHeader: "GENERATION",
+			ColumnWidth:  10,
+			PrintResourceFunc: func(w io.Writer, width int, r Resource) (int,
+				error) {
+				return printNestedInt(w, width, r, "metadata", "generation")
+			},
+		},
+		// observedgeneration defines a column that outputs the observed
+		// generation from the status of a resource.
+		"observedgeneration": {
+			ColumnName:   "observedgeneration",
+			ColumnHeader: "OBSERVED",
+			ColumnWidth:  8,
+			PrintResourceFunc: func(w io.Writer, width int, r Resource) (int,
+				error) {
+				return printNestedInt(w, width, r, "status", "observedGeneration")
+			},
+		},
+		// owner defines a column that outputs the kind and name of the
+		// owner of a resource. If the resource has multiple owners, the
+		// controller is used.
+		"owner": {
+			ColumnName:   "owner",
+			ColumnHeader: "OWNER",
+			ColumnWidth:  30,
+			PrintResourceFunc: func(w io.Writer, width int, r Resource) (int,
+				error) {
+				rs := r.ResourceStatus()
+				if rs == nil || rs.Resource == nil {
+					return printTrimmed(w, width, "-")
+				}
+				owners := rs.Resource.GetOwnerReferences()
+				if len(owners) == 0 {
+					return printTrimmed(w, width, "<None>")
+				}
+				owner := owners[0]
+				for _, o := range owners {
+					if o.Controller != nil && *o.Controller {
+						owner = o
+						break
+					}
+				}
+				return printTrimmed(w, width, fmt.Sprintf("%s/%s", owner.Kind, owner.Name))
+			},
+		},
+		// inventory defines a column that outputs the id of the inventory
+		// that owns the resource, based on the owning-inventory annotation.
+		"inventory": {
+			ColumnName:   "inventory",
+			ColumnHeader: "INVENTORY",
+			ColumnWidth:  20,
+			PrintResourceFunc: func(w io.Writer, width int, r Resource) (int,
+				error) {
+				rs := r.ResourceStatus()
+				if rs == nil || rs.Resource == nil {
+					return printTrimmed(w, width, "-")
+				}
+				id := inventory.OwningInventory(rs.Resource)
+				if id == "" {
+					id = "<None>"
+				}
+				return printTrimmed(w, width, id)
+			},
 		},
 		// message defines a column that outputs the message from a
 		// ResourceStatus, or if there is a non-nil error, output the text
@@ -241,3 +330,45 @@ var (
 		},
 	}
 )
+
+// printTrimmed prints the text, trimmed to the given width.
+func printTrimmed(w io.Writer, width int, text string) (int, error) {
+	if len(text) > width {
+		text = text[:width]
+	}
+	_, err := fmt.Fprint(w, text)
+	return len(text), err
+}
+
+// printNestedInt prints the integer found at the given path in the
+// resource, or a dash if the field doesn't exist.
+func printNestedInt(w io.Writer, width int, r Resource, fields ...string) (int, error) {
+	rs := r.ResourceStatus()
+	if rs == nil || rs.Resource == nil {
+		return printTrimmed(w, width, "-")
+	}
+	value, found, err := unstructured.NestedInt64(rs.Resource.Object, fields...)
+	if !found || err != nil {
+		return printTrimmed(w, width, "-")
+	}
+	return printTrimmed(w, width, fmt.Sprintf("%d", value))
+}
+
+// creationTimestamp returns the creation timestamp of the resource, if
+// it is available.
+func creationTimestamp(r Resource) (time.Time, bool) {
+	rs := r.ResourceStatus()
+	if rs == nil || rs.Resource == nil {
+		return time.Time{}, false
+	}
+	timestamp, found, err := unstructured.NestedString(rs.Resource.Object,
+		"metadata", "creationTimestamp")
+	if !found || err != nil || timestamp == "" {
+		return time.Time{}, false
+	}
+	parsedTime, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return parsedTime, true
+}
@@ -153,6 +153,113 @@ func TestColumnDefs(t *testing.T) {
 			columnWidth:    10,
 			expectedOutput: "45m",
 		},
+		"group": {
+			columnName: "group",
+			resource: &fakeResource{
+				resourceStatus: &pe.ResourceStatus{
+					Identifier: object.ObjMetadata{
+						GroupKind: schema.GroupKind{
+							Group: "apps",
+							Kind:  "Deployment",
+						},
+					},
+				},
+			},
+			columnWidth:    20,
+			expectedOutput: "apps",
+		},
+		"generation": {
+			columnName: "generation",
+			resource: &fakeResource{
+				resourceStatus: &pe.ResourceStatus{
+					Resource: mustResourceWithFields(map[string]interface{}{
+						"metadata": map[string]interface{}{
+							"generation": int64(3),
+						},
+					}),
+				},
+			},
+			columnWidth:    10,
+			expectedOutput: "3",
+		},
+		"generation not found": {
+			columnName: "generation",
+			resource: &fakeResource{
+				resourceStatus: &pe.ResourceStatus{
+					Resource: &unstructured.Unstructured{},
+				},
+			},
+			columnWidth:    10,
+			expectedOutput: "-",
+		},
+		"observed generation": {
+			columnName: "observedgeneration",
+			resource: &fakeResource{
+				resourceStatus: &pe.ResourceStatus{
+					Resource: mustResourceWithFields(map[string]interface{}{
+						"status": map[string]interface{}{
+							"observedGeneration": int64(2),
+						},
+					}),
+				},
+			},
+			columnWidth:    8,
+			expectedOutput: "2",
+		},
+		"owner uses controller": {
+			columnName: "owner",
+			resource: &fakeResource{
+				resourceStatus: &pe.ResourceStatus{
+					Resource: mustResourceWithFields(map[string]interface{}{
+						"metadata": map[string]interface{}{
+							"ownerReferences": []interface{}{
+								map[string]interface{}{
+									"apiVersion": "v1",
+									"kind":       "ConfigMap",
+									"name":       "cm",
+									"uid":        "1",
+								},
+								map[string]interface{}{
+									"apiVersion": "apps/v1",
+									"kind":       "ReplicaSet",
+									"name":       "rs",
+									"uid":        "2",
+									"controller": true,
+								},
+							},
+						},
+					}),
+				},
+			},
+			columnWidth:    30,
+			expectedOutput: "ReplicaSet/rs",
+		},
+		"owner not set": {
+			columnName: "owner",
+			resource: &fakeResource{
+				resourceStatus: &pe.ResourceStatus{
+					Resource: &unstructured.Unstructured{},
+				},
+			},
+			columnWidth:    30,
+			expectedOutput: "<None>",
+		},
+		"inventory": {
+			columnName: "inventory",
+			resource: &fakeResource{
+				resourceStatus: &pe.ResourceStatus{
+					Resource: mustResourceWithFields(map[string]interface{}{
+						"metadata": map[string]interface{}{
+							"annotations": map[string]interface{}{
+								"config.k8s.io/owning-inventory": "inventory-id",
+							},
+						},
+					}),
+				},
+			},
+			columnWidth:    10,
+			expectedOutput: "inventory-",
+		},
 		"message without error": {
 			columnName: "message",
 			resource: &fakeResource{
@@ -237,3 +344,9 @@ func mustResourceWithCreationTimestamp(age time.Duration) *unstructured.Unstruct
 	})
 	return u
 }
+
+func mustResourceWithFields(fields map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: fields,
+	}
+}
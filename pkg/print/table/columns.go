@@ -0,0 +1,346 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package table
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/client-go/util/jsonpath"
+	"k8s.io/kubectl/pkg/util/term"
+)
+
+const (
+	// defaultJSONPathColumnWidth is the width used for custom columns
+	// unless the header is wider.
+	defaultJSONPathColumnWidth = 20
+
+	// minFlexibleColumnWidth is the smallest width FitColumns will
+	// shrink a column to.
+	minFlexibleColumnWidth = 20
+
+	// unlimitedWidth is used when printing the content of a column
+	// for sorting, so the text is not trimmed.
+	unlimitedWidth = 1 << 16
+)
+
+// flexibleColumns lists the names of the columns that FitColumns can
+// resize, in the order they will be shrunk if the table is too wide.
+var flexibleColumns = []string{"message", "conditions", "resource"}
+
+var ansiEscapeRegexp = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// NewJSONPathColumn returns a column that prints the value found at the
+// given JSONPath expression in the resource, for example
+// {.spec.replicas}. The braces are optional.
+func NewJSONPathColumn(header, path string) (ColumnDefinition, error) {
+	if !strings.HasPrefix(path, "{") {
+		path = fmt.Sprintf("{%s}", path)
+	}
+	jp := jsonpath.New(header).AllowMissingKeys(true)
+	if err := jp.Parse(path); err != nil {
+		return nil, fmt.Errorf("invalid JSONPath expression %q: %v", path, err)
+	}
+	width := defaultJSONPathColumnWidth
+	if len(header) > width {
+		width = len(header)
+	}
+	return ColumnDef{
+		ColumnName:   strings.ToLower(header),
+		ColumnHeader: strings.ToUpper(header),
+		ColumnWidth:  width,
+		PrintResourceFunc: func(w io.Writer, width int, r Resource) (int, error) {
+			rs := r.ResourceStatus()
+			if rs == nil || rs.Resource == nil {
+				return printTrimmed(w, width, "-")
+			}
+			var buf bytes.Buffer
+			if err := jp.Execute(&buf, rs.Resource.Object); err != nil {
+				return printTrimmed(w, width, "-")
+			}
+			text := buf.String()
+			if text == "" {
+				text = "<none>"
+			}
+			return printTrimmed(w, width, text)
+		},
+	}, nil
+}
+
+// ParseColumns parses a comma-separated list of columns. Each entry is
+// either the name of a column, or a custom column on the form
+// HEADER:{.json.path}. Names are looked up in the additional columns
+// first, and then in the pre-defined columns.
+func ParseColumns(spec string, additional ...ColumnDefinition) ([]ColumnDefinition, error) {
+	var columns []ColumnDefinition
+	for _, entry := range splitColumns(spec) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if i := strings.Index(entry, ":"); i >= 0 {
+			header, path := entry[:i], entry[i+1:]
+			if header == "" {
+				return nil, fmt.Errorf("custom column %q is missing a header", entry)
+			}
+			c, err := NewJSONPathColumn(header, path)
+			if err != nil {
+				return nil, err
+			}
+			columns = append(columns, c)
+			continue
+		}
+		c, found := lookupColumn(strings.ToLower(entry), additional)
+		if !found {
+			return nil, fmt.Errorf("unknown column %q, must be one of %s or HEADER:{.json.path}",
+				entry, strings.Join(columnNames(additional), ", "))
+		}
+		columns = append(columns, c)
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("no columns specified")
+	}
+	return columns, nil
+}
+
+// splitColumns splits the spec on commas that are not inside braces,
+// so JSONPath expressions can contain commas.
+func splitColumns(spec string) []string {
+	var entries []string
+	depth := 0
+	start := 0
+	for i, c := range spec {
+		switch c {
+		case '{':
+			depth++
+		case '}':
+			if depth > 0 {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				entries = append(entries, spec[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(entries, spec[start:])
+}
+
+func lookupColumn(name string, additional []ColumnDefinition) (ColumnDefinition, bool) {
+	for _, c := range additional {
+		if c.Name() == name {
+			return c, true
+		}
+	}
+	c, found := columnDefinitions[name]
+	return c, found
+}
+
+func columnNames(additional []ColumnDefinition) []string {
+	var names []string
+	for _, c := range additional {
+		names = append(names, c.Name())
+	}
+	var predefined []string
+	for name := range columnDefinitions {
+		predefined = append(predefined, name)
+	}
+	sort.Strings(predefined)
+	return append(names, predefined...)
+}
+
+// SortBy defines how the resources in a table should be sorted.
+type SortBy struct {
+	Column     ColumnDefinition
+	Descending bool
+}
+
+// ParseSortBy parses the column to sort by. The spec is either the name
+// of a column or a JSONPath expression. A leading '-' sorts in
+// descending order. Names are looked up in the provided columns first,
+// and then in the pre-defined columns. An empty spec returns nil.
+func ParseSortBy(spec string, columns []ColumnDefinition) (*SortBy, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+	sortBy := &SortBy{}
+	if strings.HasPrefix(spec, "-") {
+		sortBy.Descending = true
+		spec = spec[1:]
+	}
+	if strings.HasPrefix(spec, "{") || strings.HasPrefix(spec, ".") {
+		c, err := NewJSONPathColumn("sort", spec)
+		if err != nil {
+			return nil, err
+		}
+		sortBy.Column = c
+		return sortBy, nil
+	}
+	c, found := lookupColumn(strings.ToLower(spec), columns)
+	if !found {
+		return nil, fmt.Errorf("unknown sort column %q, must be one of %s or a JSONPath expression",
+			spec, strings.Join(columnNames(columns), ", "))
+	}
+	sortBy.Column = c
+	return sortBy, nil
+}
+
+// SortResources returns a copy of the resources, sorted by the
+// provided column. The sort is stable, so resources with the same
+// value keep their order. Numeric values are compared as numbers.
+func SortResources(resources []Resource, sortBy *SortBy) []Resource {
+	type keyed struct {
+		resource Resource
+		key      string
+	}
+	entries := make([]keyed, len(resources))
+	for i, r := range resources {
+		entries[i] = keyed{resource: r}
+		if sortBy != nil && sortBy.Column != nil {
+			entries[i].key = sortKey(sortBy.Column, r)
+		}
+	}
+	if sortBy != nil && sortBy.Column != nil {
+		sort.SliceStable(entries, func(i, j int) bool {
+			if sortBy.Descending {
+				return lessKey(entries[j].key, entries[i].key)
+			}
+			return lessKey(entries[i].key, entries[j].key)
+		})
+	}
+	sorted := make([]Resource, len(entries))
+	for i, e := range entries {
+		sorted[i] = e.resource
+	}
+	return sorted
+}
+
+func sortKey(c ColumnDefinition, r Resource) string {
+	if sk, ok := c.(interface{ SortKey(Resource) string }); ok {
+		return sk.SortKey(r)
+	}
+	return printedText(c, r)
+}
+
+// lessKey compares two sort keys. If both are numbers, they are
+// compared numerically.
+func lessKey(a, b string) bool {
+	na, errA := strconv.ParseFloat(a, 64)
+	nb, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil {
+		return na < nb
+	}
+	return a < b
+}
+
+// printedText returns the text printed by the column for the resource,
+// without trimming and without any color codes.
+func printedText(c ColumnDefinition, r Resource) string {
+	var buf bytes.Buffer
+	if _, err := c.PrintResource(&buf, unlimitedWidth, r); err != nil {
+		return ""
+	}
+	return ansiEscapeRegexp.ReplaceAllString(buf.String(), "")
+}
+
+// resizedColumn wraps a column to change its width.
+type resizedColumn struct {
+	ColumnDefinition
+	width int
+}
+
+func (r resizedColumn) Width() int {
+	return r.width
+}
+
+// FitColumns returns the columns with the message, conditions and
+// resource columns resized so the table matches the provided width.
+// The message column, or if it doesn't exist, the last of the resizable
+// columns grows to fill any extra space. If the table is too wide, the
+// resizable columns are shrunk, but never below a minimum width. If
+// width is 0 or less, the columns are returned unchanged.
+func FitColumns(columns []ColumnDefinition, width int) []ColumnDefinition {
+	if width <= 0 || len(columns) == 0 {
+		return columns
+	}
+	widths := make([]int, len(columns))
+	total := 2 * (len(columns) - 1)
+	for i, c := range columns {
+		widths[i] = c.Width()
+		total += c.Width()
+	}
+
+	indexOf := func(name string) int {
+		for i, c := range columns {
+			if c.Name() == name {
+				return i
+			}
+		}
+		return -1
+	}
+
+	diff := width - total
+	switch {
+	case diff > 0:
+		for _, name := range flexibleColumns {
+			if i := indexOf(name); i >= 0 {
+				widths[i] += diff
+				break
+			}
+		}
+	case diff < 0:
+		for _, name := range flexibleColumns {
+			i := indexOf(name)
+			if i < 0 {
+				continue
+			}
+			min := minFlexibleColumnWidth
+			if len(columns[i].Header()) > min {
+				min = len(columns[i].Header())
+			}
+			if widths[i] <= min {
+				continue
+			}
+			shrink := -diff
+			if widths[i]-shrink < min {
+				shrink = widths[i] - min
+			}
+			widths[i] -= shrink
+			diff += shrink
+			if diff == 0 {
+				break
+			}
+		}
+	}
+
+	fitted := make([]ColumnDefinition, len(columns))
+	for i, c := range columns {
+		if widths[i] == c.Width() {
+			fitted[i] = c
+			continue
+		}
+		fitted[i] = resizedColumn{
+			ColumnDefinition: c,
+			width:            widths[i],
+		}
+	}
+	return fitted
+}
+
+// TerminalWidth returns the width of the terminal the provided writer
+// is connected to, or 0 if it is not a terminal.
+func TerminalWidth(out io.Writer) int {
+	size := term.TTY{Out: out}.GetSize()
+	if size == nil {
+		return 0
+	}
+	return int(size.Width)
+}
@@ -0,0 +1,324 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package table
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	pe "sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+func TestParseColumns(t *testing.T) {
+	testCases := map[string]struct {
+		spec            string
+		additional      []ColumnDefinition
+		expectedNames   []string
+		expectedHeaders []string
+		expectedErr     string
+	}{
+		"pre-defined columns": {
+			spec:            "namespace,resource,generation",
+			expectedNames:   []string{"namespace", "resource", "generation"},
+			expectedHeaders: []string{"NAMESPACE", "RESOURCE", "GENERATION"},
+		},
+		"names are case insensitive and trimmed": {
+			spec:            " Namespace , STATUS ",
+			expectedNames:   []string{"namespace", "status"},
+			expectedHeaders: []string{"NAMESPACE", "STATUS"},
+		},
+		"additional columns": {
+			spec:            "end,resource",
+			additional:      []ColumnDefinition{endColumnDef},
+			expectedNames:   []string{"end", "resource"},
+			expectedHeaders: []string{"END", "RESOURCE"},
+		},
+		"custom column with commas in expression": {
+			spec:            "resource,images:{.spec.containers[0,1].image}",
+			expectedNames:   []string{"resource", "images"},
+			expectedHeaders: []string{"RESOURCE", "IMAGES"},
+		},
+		"unknown column": {
+			spec:        "resource,foo",
+			expectedErr: `unknown column "foo"`,
+		},
+		"custom column without header": {
+			spec:        ":{.spec}",
+			expectedErr: "missing a header",
+		},
+		"invalid expression": {
+			spec:        "replicas:{.spec.replicas",
+			expectedErr: "invalid JSONPath expression",
+		},
+		"empty": {
+			spec:        " , ",
+			expectedErr: "no columns specified",
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			columns, err := ParseColumns(tc.spec, tc.additional...)
+			if tc.expectedErr != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			var names, headers []string
+			for _, c := range columns {
+				names = append(names, c.Name())
+				headers = append(headers, c.Header())
+			}
+			assert.Equal(t, tc.expectedNames, names)
+			assert.Equal(t, tc.expectedHeaders, headers)
+		})
+	}
+}
+
+func TestJSONPathColumn(t *testing.T) {
+	testCases := map[string]struct {
+		path           string
+		resource       *unstructured.Unstructured
+		expectedOutput string
+	}{
+		"value found": {
+			path: "{.spec.replicas}",
+			resource: mustResourceWithFields(map[string]interface{}{
+				"spec": map[string]interface{}{
+					"replicas": int64(3),
+				},
+			}),
+			expectedOutput: "3",
+		},
+		"braces are optional": {
+			path: ".metadata.name",
+			resource: mustResourceWithFields(map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"name": "foo",
+				},
+			}),
+			expectedOutput: "foo",
+		},
+		"missing value": {
+			path:           "{.spec.replicas}",
+			resource:       mustResourceWithFields(map[string]interface{}{}),
+			expectedOutput: "<none>",
+		},
+		"no resource": {
+			path:           "{.spec.replicas}",
+			expectedOutput: "-",
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			c, err := NewJSONPathColumn("replicas", tc.path)
+			assert.NoError(t, err)
+
+			var buf bytes.Buffer
+			_, err = c.PrintResource(&buf, c.Width(), &fakeResource{
+				resourceStatus: &pe.ResourceStatus{
+					Resource: tc.resource,
+				},
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedOutput, buf.String())
+		})
+	}
+}
+
+func TestParseSortBy(t *testing.T) {
+	testCases := map[string]struct {
+		spec               string
+		columns            []ColumnDefinition
+		expectedNil        bool
+		expectedName       string
+		expectedDescending bool
+		expectedErr        string
+	}{
+		"empty": {
+			spec:        "",
+			expectedNil: true,
+		},
+		"pre-defined column": {
+			spec:         "age",
+			expectedName: "age",
+		},
+		"descending": {
+			spec:               "-namespace",
+			expectedName:       "namespace",
+			expectedDescending: true,
+		},
+		"provided column": {
+			spec:         "end",
+			columns:      []ColumnDefinition{endColumnDef},
+			expectedName: "end",
+		},
+		"json path": {
+			spec:         "{.spec.replicas}",
+			expectedName: "sort",
+		},
+		"unknown column": {
+			spec:        "foo",
+			expectedErr: `unknown sort column "foo"`,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			sortBy, err := ParseSortBy(tc.spec, tc.columns)
+			if tc.expectedErr != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			if tc.expectedNil {
+				assert.Nil(t, sortBy)
+				return
+			}
+			assert.Equal(t, tc.expectedName, sortBy.Column.Name())
+			assert.Equal(t, tc.expectedDescending, sortBy.Descending)
+		})
+	}
+}
+
+func TestSortResources(t *testing.T) {
+	newResource := func(name string, generation int64, age time.Duration) Resource {
+		u := mustResourceWithCreationTimestamp(age)
+		u.SetGeneration(generation)
+		return &fakeResource{
+			resourceStatus: &pe.ResourceStatus{
+				Identifier: object.ObjMetadata{
+					Name: name,
+					GroupKind: schema.GroupKind{
+						Kind: "Deployment",
+					},
+				},
+				Resource: u,
+			},
+		}
+	}
+	resources := []Resource{
+		newResource("b", 10, 2*time.Minute),
+		newResource("c", 9, 30*time.Second),
+		newResource("a", 10, 3*time.Hour),
+	}
+
+	testCases := map[string]struct {
+		sortBy        *SortBy
+		expectedOrder []string
+	}{
+		"no sorting": {
+			expectedOrder: []string{"b", "c", "a"},
+		},
+		"by resource": {
+			sortBy: &SortBy{
+				Column: MustColumn("resource"),
+			},
+			expectedOrder: []string{"a", "b", "c"},
+		},
+		"by generation compares numbers and is stable": {
+			sortBy: &SortBy{
+				Column: MustColumn("generation"),
+			},
+			expectedOrder: []string{"c", "b", "a"},
+		},
+		"by age uses the duration": {
+			sortBy: &SortBy{
+				Column: MustColumn("age"),
+			},
+			expectedOrder: []string{"c", "b", "a"},
+		},
+		"descending": {
+			sortBy: &SortBy{
+				Column:     MustColumn("resource"),
+				Descending: true,
+			},
+			expectedOrder: []string{"c", "b", "a"},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			sorted := SortResources(resources, tc.sortBy)
+			var names []string
+			for _, r := range sorted {
+				names = append(names, r.Identifier().Name)
+			}
+			assert.Equal(t, tc.expectedOrder, names)
+		})
+	}
+}
+
+func TestFitColumns(t *testing.T) {
+	columns := []ColumnDefinition{
+		MustColumn("namespace"),
+		MustColumn("resource"),
+		MustColumn("status"),
+		MustColumn("message"),
+	}
+	// 10 + 40 + 10 + 40 plus 3 separators of 2.
+	defaultWidth := 106
+
+	testCases := map[string]struct {
+		columns        []ColumnDefinition
+		width          int
+		expectedWidths []int
+	}{
+		"unknown width": {
+			columns:        columns,
+			width:          0,
+			expectedWidths: []int{10, 40, 10, 40},
+		},
+		"exact width": {
+			columns:        columns,
+			width:          defaultWidth,
+			expectedWidths: []int{10, 40, 10, 40},
+		},
+		"message grows": {
+			columns:        columns,
+			width:          defaultWidth + 30,
+			expectedWidths: []int{10, 40, 10, 70},
+		},
+		"resource grows without message": {
+			columns:        columns[:3],
+			width:          100,
+			expectedWidths: []int{10, 76, 10},
+		},
+		"message shrinks first": {
+			columns:        columns,
+			width:          defaultWidth - 10,
+			expectedWidths: []int{10, 40, 10, 30},
+		},
+		"resource shrinks after message": {
+			columns:        columns,
+			width:          defaultWidth - 30,
+			expectedWidths: []int{10, 30, 10, 20},
+		},
+		"never below minimum": {
+			columns:        columns,
+			width:          20,
+			expectedWidths: []int{10, 20, 10, 20},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			fitted := FitColumns(tc.columns, tc.width)
+			var widths []int
+			for i, c := range fitted {
+				assert.Equal(t, tc.columns[i].Name(), c.Name())
+				widths = append(widths, c.Width())
+			}
+			assert.Equal(t, tc.expectedWidths, widths)
+		})
+	}
+}